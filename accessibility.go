@@ -0,0 +1,72 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Animation speed settings for ambient animations like grass, weather and
+// blinking markers
+const (
+	AnimationNormal = iota
+	AnimationSlow
+	AnimationOff
+	AnimationCount
+)
+
+// animationNames label the animation speed settings
+var animationNames = [AnimationCount]string{"Normal", "Slow", "Off"}
+
+// animationRates are how many ambient frames pass per game frame
+var animationRates = [AnimationCount]float64{1, 0.35, 0}
+
+// updateAmbientClock advances the clock ambient animations run on, at the
+// chosen animation speed
+func (g *Game) updateAmbientClock() {
+	g.ambientClock += animationRates[g.settings.Animation]
+	g.ticks = int(g.ambientClock)
+}
+
+// luminance returns how bright a color looks, from 0 to 255
+func luminance(c color.Color) int {
+	r, gr, b, _ := color.RGBAModel.Convert(c).RGBA()
+	return int(r>>8*299+gr>>8*587+b>>8*114) / 1000
+}
+
+// uiText returns the color to draw UI text in. High contrast turns greys to
+// pure black or white and brightens other colors as far as they go.
+func (g *Game) uiText(c color.Color) color.Color {
+	if !g.settings.HighContrast {
+		return c
+	}
+
+	rgba := color.RGBAModel.Convert(c).(color.RGBA)
+	lo := min(int(rgba.R), min(int(rgba.G), int(rgba.B)))
+	hi := max(rgba.R, rgba.G, rgba.B)
+	switch {
+	case int(hi)-lo < 40 && luminance(rgba) >= 128:
+		return color.White
+	case int(hi)-lo < 40:
+		return color.Black
+	}
+	scale := 255 / float64(hi)
+	return color.RGBA{uint8(float64(rgba.R) * scale), uint8(float64(rgba.G) * scale), uint8(float64(rgba.B) * scale), 255}
+}
+
+// drawPanel draws the background of a menu or box. High contrast makes it
+// solid black or white, outlined in the opposite color.
+func (g *Game) drawPanel(screen *ebiten.Image, x, y, width, height float32, c color.RGBA) {
+	if !g.settings.HighContrast {
+		vector.DrawFilledRect(screen, x, y, width, height, c, true)
+		return
+	}
+
+	fill, outline := color.Black, color.White
+	if luminance(c) >= 128 {
+		fill, outline = color.White, color.Black
+	}
+	vector.DrawFilledRect(screen, x, y, width, height, fill, true)
+	vector.StrokeRect(screen, x+1, y+1, width-2, height-2, 2, outline, true)
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"image/color"
+	"strconv"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Area map layout constants
+const (
+	areaMapCells   = 32  // Cells across the overworld is split into
+	areaMapSize    = 128 // Pixels the whole overworld is scaled into
+	areaMapOriginX = 20
+	areaMapOriginY = 44
+)
+
+// Habitat is a kind of place a species is found in the wild, worked out from
+// the encounter tables
+type Habitat struct {
+	place string
+	// Biome whose grass it's found in, or -1 if it's found elsewhere
+	biome              int
+	minLevel, maxLevel int
+	// When it's found, if it's never found without a condition
+	note string
+}
+
+// findHabitats looks through every encounter table for the places a species
+// is found
+func findHabitats(name string) []Habitat {
+	habitats := []Habitat{}
+	add := func(place string, biome int, table []EncounterEntry) {
+		h := Habitat{place: place, biome: biome}
+		found, always := false, false
+		notes := []string{}
+		for _, entry := range table {
+			if entry.species != name {
+				continue
+			}
+			if !found {
+				h.minLevel, h.maxLevel = entry.minLevel, entry.maxLevel
+			}
+			h.minLevel, h.maxLevel = min(h.minLevel, entry.minLevel), max(h.maxLevel, entry.maxLevel)
+			found = true
+			if note := entry.when.describe(); note != "" {
+				notes = append(notes, note)
+			} else {
+				always = true
+			}
+		}
+		if !found {
+			return
+		}
+		if !always {
+			h.note = strings.Join(notes, ", ")
+		}
+		habitats = append(habitats, h)
+	}
+
+	for i, biome := range biomes {
+		add(biome.name, i, biome.encounters)
+
+		// Note which regional form lives there, if any
+		form := regionalForm(name, i)
+		if last := len(habitats) - 1; form != "" && last >= 0 && habitats[last].biome == i {
+			habitats[last].note = strings.TrimSuffix(form+" form, "+habitats[last].note, ", ")
+		}
+	}
+	add("Caves", -1, caveEncounters)
+	add("Surfing", -1, waterEncounters)
+	add("Safari Zone", -1, safariEncounters)
+	if name == roamerSpecies {
+		habitats = append(habitats, Habitat{place: "Roaming", biome: -1, minLevel: roamerLevel, maxLevel: roamerLevel})
+	}
+	return habitats
+}
+
+// describe puts an encounter condition into words, or returns nothing for
+// the zero condition
+func (c EncounterCondition) describe() string {
+	parts := []string{}
+	switch c.time {
+	case TimeDay:
+		parts = append(parts, "by day")
+	case TimeNight:
+		parts = append(parts, "at night")
+	}
+	for _, weather := range c.weather {
+		parts = append(parts, "in "+weatherNames[weather])
+	}
+	if c.minBadges > 0 {
+		parts = append(parts, strconv.Itoa(c.minBadges)+"+ badges")
+	}
+	if c.rustle {
+		parts = append(parts, "in rustling grass")
+	}
+	return strings.Join(parts, " ")
+}
+
+// otherOrigins describes how to get a species that isn't found in the wild
+func otherOrigins(name string) []string {
+	origins := []string{}
+	if parts := findSpecies(name).fusedFrom; parts[0] != "" {
+		origins = append(origins, "Fuse a "+parts[0]+" with a "+parts[1]+" at the Fusion Lab")
+	}
+	for _, species := range speciesList {
+		if species.evolution == name {
+			origins = append(origins, "Evolve "+species.name+" at Lv."+strconv.Itoa(species.evolveLevel))
+		}
+		if species.tradeEvolution == name {
+			origins = append(origins, "Trade a "+species.name)
+		}
+	}
+	return origins
+}
+
+// areaBiomes returns the biome at the middle of each cell of the area map,
+// worked out once for each world
+func (g *Game) areaBiomes() *[areaMapCells][areaMapCells]uint8 {
+	d := &g.dex
+	overworld := g.maps[overworldID]
+	if d.areaReady && d.areaSeed == overworld.seed {
+		return &d.areaCells
+	}
+
+	cellSize := worldWidth / areaMapCells
+	for y := range areaMapCells {
+		for x := range areaMapCells {
+			d.areaCells[y][x] = uint8(overworld.climateBiome(x*cellSize+cellSize/2, y*cellSize+cellSize/2))
+		}
+	}
+	d.areaReady, d.areaSeed = true, overworld.seed
+	return &d.areaCells
+}
+
+// areaMapPoint converts an overworld tile to a position on the area map
+func areaMapPoint(p Point) (float32, float32) {
+	return areaMapOriginX + float32(p.x)*areaMapSize/worldWidth,
+		areaMapOriginY + float32(p.y)*areaMapSize/worldHeight
+}
+
+// updateAreaDex closes the area map of the species picked in the dex
+func (g *Game) updateAreaDex() {
+	if g.input.IsActionJustPressed(ebiten.KeyEscape) || g.input.IsActionJustPressed(ebiten.KeySpace) || g.input.IsActionJustPressed(ebiten.KeyB) {
+		g.dex.areaOpen = false
+	}
+}
+
+// drawAreaDex draws a mini map of the overworld with the biomes a species
+// lives in lit up and its other haunts marked, beside a list of where it's
+// found and at what levels
+func (g *Game) drawAreaDex(screen *ebiten.Image) {
+	species := speciesAt(g.dex.selected)
+	habitats := findHabitats(species.name)
+	plan := g.maps[overworldID].plan
+	g.drawPanel(screen, 10, 10, float32(screenWidth-20), float32(screenHeight-20), color.RGBA{150, 40, 40, 240})
+
+	drawText := func(x, y int, line string, clr color.Color) {
+		op := &text.DrawOptions{}
+		op.GeoM.Translate(float64(x), float64(y))
+		op.ColorScale.ScaleWithColor(g.uiText(clr))
+		text.Draw(screen, line, g.fontFace, op)
+	}
+	drawText(20, 30, species.name+" - Area", color.White)
+
+	// Biomes it lives in are lit up in their grass color, the rest dimmed
+	lives := [BiomeCount]bool{}
+	caves, safari := false, false
+	for _, h := range habitats {
+		switch {
+		case h.biome >= 0:
+			lives[h.biome] = true
+		case h.place == "Caves":
+			caves = true
+		case h.place == "Safari Zone":
+			safari = true
+		}
+	}
+	cells := g.areaBiomes()
+	cell := float32(areaMapSize) / areaMapCells
+	for y := range areaMapCells {
+		for x := range areaMapCells {
+			biome := cells[y][x]
+			clr := shade(biomes[biome].palette[TileGrass], 0.35)
+			if lives[biome] {
+				clr = biomes[biome].palette[TileGrass]
+			}
+			vector.DrawFilledRect(screen, areaMapOriginX+float32(x)*cell, areaMapOriginY+float32(y)*cell, cell+0.5, cell+0.5, clr, false)
+		}
+	}
+
+	// Routes, with the roamer's lit up once it has been met
+	for i, route := range plan.routes {
+		clr := color.RGBA{180, 160, 130, 255}
+		if species.name == roamerSpecies && i == g.roamer.route && g.roamer.seen && !g.roamer.defeated {
+			clr = color.RGBA{250, 220, 60, 255}
+		}
+		for j := range len(route.points) - 1 {
+			x1, y1 := areaMapPoint(route.points[j])
+			x2, y2 := areaMapPoint(route.points[j+1])
+			vector.StrokeLine(screen, x1, y1, x2, y2, 1.5, clr, true)
+		}
+	}
+
+	// Towns, with the caves and safari gate it lives behind ringed
+	for _, region := range plan.regions {
+		x, y := areaMapPoint(region.town)
+		vector.DrawFilledRect(screen, x-2, y-2, 4, 4, color.RGBA{220, 60, 60, 255}, true)
+		for _, b := range region.buildings {
+			if (caves && b.kind == BuildingCave) || (safari && b.kind == BuildingSafari) {
+				x, y := areaMapPoint(b.door)
+				vector.StrokeCircle(screen, x, y, 4, 1.5, color.White, true)
+			}
+		}
+	}
+
+	// Where it's found, and at what levels
+	x, y := areaMapOriginX+areaMapSize+12, areaMapOriginY
+	width := screenWidth - 20 - x
+	drawLine := func(line string, indent int, clr color.Color) {
+		for _, wrapped := range g.wrapText(line, width-indent) {
+			drawText(x+indent, y, wrapped, clr)
+			y += g.lineSpacing()
+		}
+	}
+	if len(habitats) == 0 {
+		drawLine("Not found in the wild.", 0, color.RGBA{200, 200, 200, 255})
+		for _, origin := range otherOrigins(species.name) {
+			drawLine(origin, 0, color.White)
+		}
+	}
+	for _, h := range habitats {
+		levels := "Lv." + strconv.Itoa(h.minLevel)
+		if h.maxLevel != h.minLevel {
+			levels += "-" + strconv.Itoa(h.maxLevel)
+		}
+		drawLine(h.place+" "+levels, 0, color.White)
+		if h.note != "" {
+			drawLine(h.note, 10, color.RGBA{200, 200, 200, 255})
+		}
+	}
+
+	g.drawHint(screen, "ESC to go back")
+}
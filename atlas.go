@@ -0,0 +1,164 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Atlas constants
+const (
+	// Width and height of the atlas texture, small enough for any GPU
+	atlasSize = 1024
+	// Gap left around each sprite, so no sprite picks up its neighbour's
+	// pixels when drawn scaled
+	atlasPadding = 1
+)
+
+// Atlas packs sprites into one texture, so drawing them one after another
+// batches into a single draw call. Sprites are placed left to right along
+// shelves as tall as the tallest sprite on them.
+type Atlas struct {
+	image *ebiten.Image
+	// Where the next sprite goes, and how tall the current shelf is
+	x, y, shelfHeight int
+	sprites           map[string]*ebiten.Image
+	// A white pixel, scaled and tinted to fill rectangles
+	white *ebiten.Image
+	// Draw options reused by fillRect
+	rectOptions ebiten.DrawImageOptions
+}
+
+// theAtlas holds every sprite, made the first time one is needed
+var theAtlas *Atlas
+
+// spriteAtlas returns the sprite atlas
+func spriteAtlas() *Atlas {
+	if theAtlas == nil {
+		theAtlas = newAtlas()
+	}
+	return theAtlas
+}
+
+// newAtlas creates an empty atlas holding only the white pixel
+func newAtlas() *Atlas {
+	a := &Atlas{
+		image:   ebiten.NewImageWithOptions(image.Rect(0, 0, atlasSize, atlasSize), &ebiten.NewImageOptions{Unmanaged: true}),
+		sprites: map[string]*ebiten.Image{},
+	}
+	// The pixel is the middle of a white square, so filtering at its edges
+	// stays white
+	square := a.allocate(3, 3)
+	square.Fill(color.White)
+	corner := square.Bounds().Min
+	a.white = square.SubImage(image.Rect(corner.X+1, corner.Y+1, corner.X+2, corner.Y+2)).(*ebiten.Image)
+	return a
+}
+
+// allocate reserves a region of the atlas. Once the atlas is full, sprites
+// get images of their own, which still work but don't batch.
+func (a *Atlas) allocate(width, height int) *ebiten.Image {
+	if a.x+width+atlasPadding > atlasSize {
+		a.x, a.y, a.shelfHeight = 0, a.y+a.shelfHeight+atlasPadding, 0
+	}
+	if a.x+width > atlasSize || a.y+height > atlasSize {
+		return ebiten.NewImage(width, height)
+	}
+
+	region := a.image.SubImage(image.Rect(a.x, a.y, a.x+width, a.y+height)).(*ebiten.Image)
+	a.x += width + atlasPadding
+	a.shelfHeight = max(a.shelfHeight, height)
+	return region
+}
+
+// sprite returns the named sprite, packing it into the atlas the first time
+// it's asked for. A loose image loaded under the name is used if there is
+// one; otherwise draw draws it on a width by height canvas.
+func (a *Atlas) sprite(name string, width, height int, draw func(canvas *ebiten.Image)) *ebiten.Image {
+	if sprite, ok := a.sprites[name]; ok {
+		return sprite
+	}
+	sprite := a.allocate(width, height)
+	drawInto(sprite, width, height, draw)
+	a.sprites[name] = sprite
+	return sprite
+}
+
+// drawInto draws a picture into an image. Drawing onto part of an image uses
+// the whole image's coordinates, so the picture is drawn on a canvas of its
+// own from 0, 0 and then copied across.
+func drawInto(dst *ebiten.Image, width, height int, draw func(canvas *ebiten.Image)) {
+	canvas := ebiten.NewImage(width, height)
+	defer canvas.Deallocate()
+	draw(canvas)
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(dst.Bounds().Min.X), float64(dst.Bounds().Min.Y))
+	dst.DrawImage(canvas, op)
+}
+
+// add packs a loose image into the atlas under a name
+func (a *Atlas) add(name string, img image.Image) {
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Src)
+
+	sprite := a.allocate(bounds.Dx(), bounds.Dy())
+	sprite.WritePixels(rgba.Pix)
+	a.sprites[name] = sprite
+}
+
+// loadSprites packs every PNG image in a folder into the atlas, named after
+// its file without the extension. They take the place of the sprites the
+// game would draw under the same names, like "ball" or "person-f0c040".
+func (a *Atlas) loadSprites(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".png")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		f, err := os.Open(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return err
+		}
+		img, err := png.Decode(f)
+		f.Close()
+		if err != nil {
+			log.Println("Skipping sprite", entry.Name()+":", err)
+			continue
+		}
+		a.add(name, img)
+	}
+	return nil
+}
+
+// fillRect draws a solid rectangle from the atlas's white pixel, which
+// batches with the sprites around it
+func (a *Atlas) fillRect(dst *ebiten.Image, x, y, width, height float32, clr color.Color) {
+	op := &a.rectOptions
+	op.GeoM.Reset()
+	op.GeoM.Scale(float64(width), float64(height))
+	op.GeoM.Translate(float64(x), float64(y))
+	op.ColorScale.Reset()
+	op.ColorScale.ScaleWithColor(clr)
+	dst.DrawImage(a.white, op)
+}
+
+// ballSprite returns the item ball lying on the map
+func ballSprite() *ebiten.Image {
+	return spriteAtlas().sprite("ball", 20, 20, func(canvas *ebiten.Image) {
+		drawBall(canvas, 10, 10, 8)
+	})
+}
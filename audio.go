@@ -0,0 +1,488 @@
+package main
+
+import (
+	"bytes"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2/audio"
+)
+
+// sampleRate is the audio sample rate used for every sound
+const sampleRate = 44100
+
+// Music layering constants
+const (
+	// How much a layer's or the music's volume changes each step as it fades
+	crossfadeStep = 1.0 / 30
+	// Volume the music drops to while a sting plays over it
+	duckVolume = 0.25
+)
+
+// Waveform constants for synthesized notes
+const (
+	WaveSine = iota
+	WaveSquare
+	WaveNoise
+)
+
+// Note is a single synthesized tone; a frequency of 0 is a rest
+type Note struct {
+	freq     float64
+	duration float64 // Seconds
+	wave     int
+}
+
+// soundEffects are the synthesized sound effects, keyed by name
+var soundEffects = map[string][]Note{
+	"door": {
+		{freq: 220, duration: 0.05, wave: WaveSquare},
+		{freq: 150, duration: 0.09, wave: WaveSquare},
+	},
+	"heal": {
+		{freq: 523, duration: 0.12, wave: WaveSine},
+		{freq: 659, duration: 0.12, wave: WaveSine},
+		{freq: 784, duration: 0.12, wave: WaveSine},
+		{freq: 1047, duration: 0.3, wave: WaveSine},
+	},
+	"bump": {
+		{freq: 90, duration: 0.08, wave: WaveNoise},
+	},
+	"hit": {
+		{freq: 160, duration: 0.06, wave: WaveNoise},
+		{freq: 80, duration: 0.06, wave: WaveSquare},
+	},
+	"whoosh": {
+		{freq: 400, duration: 0.1, wave: WaveNoise},
+	},
+	"zap": {
+		{freq: 1200, duration: 0.03, wave: WaveSquare},
+		{freq: 600, duration: 0.03, wave: WaveSquare},
+		{freq: 1400, duration: 0.05, wave: WaveSquare},
+	},
+	"fanfare": {
+		{freq: 523, duration: 0.1, wave: WaveSquare},
+		{freq: 659, duration: 0.1, wave: WaveSquare},
+		{freq: 784, duration: 0.1, wave: WaveSquare},
+		{freq: 0, duration: 0.05, wave: WaveSquare},
+		{freq: 784, duration: 0.1, wave: WaveSquare},
+		{freq: 1047, duration: 0.4, wave: WaveSquare},
+	},
+	"ball": {
+		{freq: 880, duration: 0.04, wave: WaveSquare},
+		{freq: 120, duration: 0.12, wave: WaveNoise},
+	},
+	// The sting over the music as a ball wobbles: three shakes, then a chime
+	"capture": {
+		{freq: 200, duration: 0.05, wave: WaveNoise},
+		{freq: 0, duration: 0.2, wave: WaveSquare},
+		{freq: 200, duration: 0.05, wave: WaveNoise},
+		{freq: 0, duration: 0.2, wave: WaveSquare},
+		{freq: 200, duration: 0.05, wave: WaveNoise},
+		{freq: 0, duration: 0.25, wave: WaveSquare},
+		{freq: 1047, duration: 0.1, wave: WaveSine},
+		{freq: 1319, duration: 0.35, wave: WaveSine},
+	},
+}
+
+// musicTracks are the synthesized tunes looped in the background, keyed by
+// name
+var musicTracks = map[string][]Note{
+	// A bouncy riff for riding the bike
+	"bike": {
+		{freq: 392, duration: 0.15, wave: WaveSquare},
+		{freq: 494, duration: 0.15, wave: WaveSquare},
+		{freq: 587, duration: 0.15, wave: WaveSquare},
+		{freq: 494, duration: 0.15, wave: WaveSquare},
+		{freq: 523, duration: 0.15, wave: WaveSquare},
+		{freq: 659, duration: 0.15, wave: WaveSquare},
+		{freq: 587, duration: 0.3, wave: WaveSquare},
+		{freq: 392, duration: 0.15, wave: WaveSquare},
+		{freq: 440, duration: 0.15, wave: WaveSquare},
+		{freq: 494, duration: 0.15, wave: WaveSquare},
+		{freq: 440, duration: 0.15, wave: WaveSquare},
+		{freq: 392, duration: 0.3, wave: WaveSquare},
+		{freq: 0, duration: 0.3, wave: WaveSquare},
+	},
+	// A bright, easy-going tune for open grassland
+	"grassland": {
+		{freq: 523, duration: 0.25, wave: WaveSine},
+		{freq: 659, duration: 0.25, wave: WaveSine},
+		{freq: 784, duration: 0.25, wave: WaveSine},
+		{freq: 659, duration: 0.25, wave: WaveSine},
+		{freq: 698, duration: 0.25, wave: WaveSine},
+		{freq: 880, duration: 0.25, wave: WaveSine},
+		{freq: 784, duration: 0.25, wave: WaveSine},
+		{freq: 0, duration: 0.25, wave: WaveSine},
+		{freq: 659, duration: 0.25, wave: WaveSine},
+		{freq: 587, duration: 0.25, wave: WaveSine},
+		{freq: 523, duration: 0.25, wave: WaveSine},
+		{freq: 587, duration: 0.25, wave: WaveSine},
+		{freq: 659, duration: 0.25, wave: WaveSine},
+		{freq: 523, duration: 0.25, wave: WaveSine},
+		{freq: 587, duration: 0.25, wave: WaveSine},
+		{freq: 0, duration: 0.25, wave: WaveSine},
+	},
+	// A wandering minor line under the forest canopy
+	"forest": {
+		{freq: 440, duration: 0.3, wave: WaveSine},
+		{freq: 523, duration: 0.3, wave: WaveSine},
+		{freq: 587, duration: 0.3, wave: WaveSine},
+		{freq: 659, duration: 0.3, wave: WaveSine},
+		{freq: 587, duration: 0.3, wave: WaveSine},
+		{freq: 523, duration: 0.3, wave: WaveSine},
+		{freq: 440, duration: 0.3, wave: WaveSine},
+		{freq: 0, duration: 0.3, wave: WaveSine},
+		{freq: 392, duration: 0.3, wave: WaveSine},
+		{freq: 440, duration: 0.3, wave: WaveSine},
+		{freq: 523, duration: 0.3, wave: WaveSine},
+		{freq: 440, duration: 0.3, wave: WaveSine},
+		{freq: 392, duration: 0.3, wave: WaveSine},
+		{freq: 330, duration: 0.3, wave: WaveSine},
+		{freq: 392, duration: 0.3, wave: WaveSine},
+		{freq: 0, duration: 0.3, wave: WaveSine},
+	},
+	// A sparse, sun-baked melody for the desert
+	"desert": {
+		{freq: 330, duration: 0.2, wave: WaveSquare},
+		{freq: 349, duration: 0.2, wave: WaveSquare},
+		{freq: 415, duration: 0.2, wave: WaveSquare},
+		{freq: 440, duration: 0.2, wave: WaveSquare},
+		{freq: 415, duration: 0.2, wave: WaveSquare},
+		{freq: 349, duration: 0.2, wave: WaveSquare},
+		{freq: 330, duration: 0.2, wave: WaveSquare},
+		{freq: 0, duration: 0.2, wave: WaveSquare},
+		{freq: 330, duration: 0.2, wave: WaveSquare},
+		{freq: 349, duration: 0.2, wave: WaveSquare},
+		{freq: 415, duration: 0.2, wave: WaveSquare},
+		{freq: 494, duration: 0.2, wave: WaveSquare},
+		{freq: 440, duration: 0.2, wave: WaveSquare},
+		{freq: 415, duration: 0.2, wave: WaveSquare},
+		{freq: 349, duration: 0.2, wave: WaveSquare},
+		{freq: 330, duration: 0.2, wave: WaveSquare},
+	},
+	// A slow, murky drone for the swamp
+	"swamp": {
+		{freq: 147, duration: 0.4, wave: WaveSine},
+		{freq: 175, duration: 0.4, wave: WaveSine},
+		{freq: 165, duration: 0.4, wave: WaveSine},
+		{freq: 147, duration: 0.4, wave: WaveSine},
+		{freq: 131, duration: 0.4, wave: WaveSine},
+		{freq: 147, duration: 0.4, wave: WaveSine},
+		{freq: 175, duration: 0.4, wave: WaveSine},
+		{freq: 0, duration: 0.4, wave: WaveSine},
+	},
+	// Thin, high notes that ring out over the snow
+	"snowfield": {
+		{freq: 1047, duration: 0.3, wave: WaveSine},
+		{freq: 0, duration: 0.3, wave: WaveSine},
+		{freq: 1319, duration: 0.3, wave: WaveSine},
+		{freq: 0, duration: 0.3, wave: WaveSine},
+		{freq: 1175, duration: 0.3, wave: WaveSine},
+		{freq: 0, duration: 0.3, wave: WaveSine},
+		{freq: 988, duration: 0.3, wave: WaveSine},
+		{freq: 0, duration: 0.3, wave: WaveSine},
+		{freq: 1047, duration: 0.3, wave: WaveSine},
+		{freq: 0, duration: 0.3, wave: WaveSine},
+		{freq: 880, duration: 0.3, wave: WaveSine},
+		{freq: 0, duration: 0.3, wave: WaveSine},
+		{freq: 988, duration: 0.3, wave: WaveSine},
+		{freq: 0, duration: 0.3, wave: WaveSine},
+		{freq: 784, duration: 0.3, wave: WaveSine},
+		{freq: 0, duration: 0.3, wave: WaveSine},
+	},
+	// A low, pounding riff for the volcanic slopes
+	"volcanic": {
+		{freq: 110, duration: 0.15, wave: WaveSquare},
+		{freq: 110, duration: 0.15, wave: WaveSquare},
+		{freq: 131, duration: 0.15, wave: WaveSquare},
+		{freq: 110, duration: 0.15, wave: WaveSquare},
+		{freq: 147, duration: 0.15, wave: WaveSquare},
+		{freq: 110, duration: 0.15, wave: WaveSquare},
+		{freq: 131, duration: 0.15, wave: WaveSquare},
+		{freq: 0, duration: 0.15, wave: WaveSquare},
+		{freq: 110, duration: 0.15, wave: WaveSquare},
+		{freq: 110, duration: 0.15, wave: WaveSquare},
+		{freq: 165, duration: 0.15, wave: WaveSquare},
+		{freq: 147, duration: 0.15, wave: WaveSquare},
+		{freq: 131, duration: 0.15, wave: WaveSquare},
+		{freq: 110, duration: 0.15, wave: WaveSquare},
+		{freq: 98, duration: 0.15, wave: WaveSquare},
+		{freq: 0, duration: 0.15, wave: WaveSquare},
+	},
+	// A driving minor riff for battles
+	"battle": {
+		{freq: 440, duration: 0.15, wave: WaveSquare},
+		{freq: 523, duration: 0.15, wave: WaveSquare},
+		{freq: 659, duration: 0.15, wave: WaveSquare},
+		{freq: 523, duration: 0.15, wave: WaveSquare},
+		{freq: 440, duration: 0.15, wave: WaveSquare},
+		{freq: 659, duration: 0.15, wave: WaveSquare},
+		{freq: 587, duration: 0.15, wave: WaveSquare},
+		{freq: 523, duration: 0.15, wave: WaveSquare},
+		{freq: 494, duration: 0.15, wave: WaveSquare},
+		{freq: 587, duration: 0.15, wave: WaveSquare},
+		{freq: 698, duration: 0.15, wave: WaveSquare},
+		{freq: 587, duration: 0.15, wave: WaveSquare},
+		{freq: 494, duration: 0.15, wave: WaveSquare},
+		{freq: 392, duration: 0.15, wave: WaveSquare},
+		{freq: 440, duration: 0.15, wave: WaveSquare},
+		{freq: 0, duration: 0.15, wave: WaveSquare},
+	},
+	// Drums and a high line over the battle riff, for when things get
+	// close. A layer lasts exactly as long as its track, note for note, so
+	// the two stay in time as they loop.
+	"battle danger": {
+		{freq: 100, duration: 0.15, wave: WaveNoise},
+		{freq: 0, duration: 0.15, wave: WaveSquare},
+		{freq: 1319, duration: 0.15, wave: WaveSquare},
+		{freq: 0, duration: 0.15, wave: WaveSquare},
+		{freq: 100, duration: 0.15, wave: WaveNoise},
+		{freq: 100, duration: 0.15, wave: WaveNoise},
+		{freq: 1175, duration: 0.15, wave: WaveSquare},
+		{freq: 0, duration: 0.15, wave: WaveSquare},
+		{freq: 100, duration: 0.15, wave: WaveNoise},
+		{freq: 0, duration: 0.15, wave: WaveSquare},
+		{freq: 1319, duration: 0.15, wave: WaveSquare},
+		{freq: 0, duration: 0.15, wave: WaveSquare},
+		{freq: 100, duration: 0.15, wave: WaveNoise},
+		{freq: 100, duration: 0.15, wave: WaveNoise},
+		{freq: 1568, duration: 0.15, wave: WaveSquare},
+		{freq: 0, duration: 0.15, wave: WaveSquare},
+	},
+}
+
+// musicLayers are the layers each music track has, looped along with it
+// and faded in when the game calls for them
+var musicLayers = map[string][]string{
+	"battle": {"battle danger"},
+}
+
+// MusicLayer is a layer looping in time with the music, fading toward full
+// volume while it's on and to silence while it's off
+type MusicLayer struct {
+	player *audio.Player
+	volume float64
+	on     bool
+}
+
+// AudioManager plays the game's synthesized sounds
+type AudioManager struct {
+	context *audio.Context
+	// Rendered PCM for each sound effect, and for each music track
+	sounds map[string][]byte
+	tracks map[string][]byte
+	// The music track looping now, if any, and its name
+	music     *audio.Player
+	musicName string
+	// Layers of the music track playing, by name
+	layers map[string]*MusicLayer
+	// Sting playing over the music, and the music's volume as it drops
+	// under the sting and comes back after
+	sting       *audio.Player
+	musicVolume float64
+	// No sounds play while fast-forwarding, where they'd pile up on each other
+	muted bool
+}
+
+// newAudioManager creates the audio context and renders every sound effect
+func newAudioManager() *AudioManager {
+	// A game made after another, as in tests, shares its audio context
+	context := audio.CurrentContext()
+	if context == nil {
+		context = audio.NewContext(sampleRate)
+	}
+	a := &AudioManager{
+		context: context,
+		sounds:  make(map[string][]byte),
+		tracks:  make(map[string][]byte),
+		layers:  make(map[string]*MusicLayer),
+
+		musicVolume: 1,
+	}
+
+	for name, notes := range soundEffects {
+		a.sounds[name] = synthesize(notes, 0.3)
+	}
+	for name, notes := range musicTracks {
+		a.tracks[name] = synthesize(notes, 0.12)
+	}
+
+	return a
+}
+
+// playSound starts a sound effect; unknown names are ignored
+func (a *AudioManager) playSound(name string) {
+	pcm, ok := a.sounds[name]
+	if !ok || a.muted {
+		return
+	}
+	a.context.NewPlayerFromBytes(pcm).Play()
+}
+
+// playMusic loops a music track in place of whatever was playing, or stops
+// the music for an empty name. Asking for the track already playing carries
+// on with it. The track's layers start along with it, silent until they're
+// turned on.
+func (a *AudioManager) playMusic(name string) {
+	if name == a.musicName {
+		return
+	}
+	if a.music != nil {
+		a.music.Close()
+		a.music = nil
+	}
+	for layer, l := range a.layers {
+		l.player.Close()
+		delete(a.layers, layer)
+	}
+	a.musicName = name
+
+	player := a.loopTrack(name)
+	if player == nil {
+		return
+	}
+	a.music = player
+	a.music.SetVolume(a.musicVolume)
+	for _, layer := range musicLayers[name] {
+		if p := a.loopTrack(layer); p != nil {
+			p.SetVolume(0)
+			a.layers[layer] = &MusicLayer{player: p}
+		}
+	}
+	// Started together, so the layers stay in time with the track
+	a.music.Play()
+	for _, l := range a.layers {
+		l.player.Play()
+	}
+}
+
+// loopTrack makes a player looping a music track, or nil if there isn't one
+func (a *AudioManager) loopTrack(name string) *audio.Player {
+	pcm, ok := a.tracks[name]
+	if !ok {
+		return nil
+	}
+	player, err := a.context.NewPlayer(audio.NewInfiniteLoop(bytes.NewReader(pcm), int64(len(pcm))))
+	if err != nil {
+		return nil
+	}
+	return player
+}
+
+// setLayer turns a layer of the music playing on or off; it fades in or out
+// from there. Layers the track doesn't have are ignored.
+func (a *AudioManager) setLayer(name string, on bool) {
+	if l, ok := a.layers[name]; ok {
+		l.on = on
+	}
+}
+
+// playSting plays a short sting over the music, which drops in volume
+// until it's done
+func (a *AudioManager) playSting(name string) {
+	pcm, ok := a.sounds[name]
+	if !ok || a.muted {
+		return
+	}
+	if a.sting != nil {
+		a.sting.Close()
+	}
+	a.sting = a.context.NewPlayerFromBytes(pcm)
+	a.sting.Play()
+}
+
+// update crossfades the music's layers toward being on or off, and the
+// music itself down under a sting and back up once it's over
+func (a *AudioManager) update() {
+	target := 1.0
+	if a.sting != nil && a.sting.IsPlaying() {
+		target = duckVolume
+	} else if a.sting != nil {
+		a.sting.Close()
+		a.sting = nil
+	}
+	a.musicVolume = fadeToward(a.musicVolume, target)
+	if a.music != nil {
+		a.music.SetVolume(a.musicVolume)
+	}
+	for _, l := range a.layers {
+		on := 0.0
+		if l.on {
+			on = 1
+		}
+		l.volume = fadeToward(l.volume, on)
+		l.player.SetVolume(l.volume * a.musicVolume)
+	}
+}
+
+// fadeToward moves a volume one crossfade step toward a target
+func fadeToward(volume, target float64) float64 {
+	if volume < target {
+		return math.Min(volume+crossfadeStep, target)
+	}
+	return math.Max(volume-crossfadeStep, target)
+}
+
+// playCry plays a creature's cry: a few square-wave chirps whose pitch and
+// shape come from its species name, so each species always sounds the same
+func (a *AudioManager) playCry(species string) {
+	name := "cry " + species
+	if _, ok := a.sounds[name]; !ok {
+		seed := 0
+		for _, r := range species {
+			seed = seed*31 + int(r)
+		}
+		seed &= 0xffff
+		base := 300 + float64(seed%400)
+		notes := []Note{
+			{freq: base, duration: 0.08, wave: WaveSquare},
+			{freq: base * (1 + float64(seed/400%5)/8), duration: 0.1, wave: WaveSquare},
+			{freq: base * 0.75, duration: 0.16, wave: WaveSquare},
+		}
+		a.sounds[name] = synthesize(notes, 0.25)
+	}
+	a.playSound(name)
+}
+
+// synthesize renders notes to 16-bit stereo PCM at the given volume
+func synthesize(notes []Note, volume float64) []byte {
+	var pcm []byte
+	noise := uint32(1)
+
+	for _, note := range notes {
+		samples := int(note.duration * sampleRate)
+		for i := range samples {
+			t := float64(i) / sampleRate
+
+			var v float64
+			switch note.wave {
+			case WaveSquare:
+				if math.Sin(2*math.Pi*note.freq*t) >= 0 {
+					v = 1
+				} else {
+					v = -1
+				}
+			case WaveNoise:
+				// Cheap xorshift noise
+				noise ^= noise << 13
+				noise ^= noise >> 17
+				noise ^= noise << 5
+				v = float64(noise)/float64(math.MaxUint32)*2 - 1
+			default:
+				v = math.Sin(2 * math.Pi * note.freq * t)
+			}
+			if note.freq == 0 && note.wave != WaveNoise {
+				v = 0
+			}
+
+			// Fade each note out to avoid clicks between notes
+			envelope := 1 - float64(i)/float64(samples)
+			sample := int16(v * envelope * volume * math.MaxInt16)
+
+			// Left and right channels
+			pcm = append(pcm, byte(sample), byte(sample>>8), byte(sample), byte(sample>>8))
+		}
+	}
+
+	return pcm
+}
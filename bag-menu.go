@@ -0,0 +1,186 @@
+package main
+
+import (
+	"image/color"
+	"strconv"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+)
+
+// bagActions are the choices offered for the selected item
+var bagActions = []string{"Use", "Give", "Cancel"}
+
+// openBag switches to the bag screen
+func (g *Game) openBag() {
+	g.gameState = StateBag
+	g.selectedItem = 0
+	g.bagActionOpen = false
+	g.bagMessage = ""
+}
+
+// updateBagMenu handles updates for the bag screen
+func (g *Game) updateBagMenu() {
+	items := g.bagContents()
+
+	if g.bagActionOpen {
+		g.updateBagActions(items[g.selectedItem])
+		return
+	}
+
+	if len(items) > 0 {
+		if g.input.IsActionJustPressed(ebiten.KeyUp) {
+			g.selectedItem = (g.selectedItem - 1 + len(items)) % len(items)
+		} else if g.input.IsActionJustPressed(ebiten.KeyDown) {
+			g.selectedItem = (g.selectedItem + 1) % len(items)
+		}
+
+		clicked := g.mouseSelect(g.bagItemRects(items), &g.selectedItem)
+
+		if g.input.IsActionJustPressed(ebiten.KeySpace) || g.input.IsActionJustPressed(ebiten.KeyEnter) || clicked {
+			g.bagActionOpen = true
+			g.selectedBagAction = 0
+			g.bagMessage = ""
+		}
+	}
+
+	if g.input.IsActionJustPressed(ebiten.KeyEscape) || g.input.IsActionJustPressed(ebiten.KeyB) {
+		g.gameState = StateOverworld
+	}
+}
+
+// updateBagActions handles the Use/Give choice for the selected item; both act
+// on the active creature
+func (g *Game) updateBagActions(name string) {
+	if g.input.IsActionJustPressed(ebiten.KeyUp) {
+		g.selectedBagAction = (g.selectedBagAction - 1 + len(bagActions)) % len(bagActions)
+	} else if g.input.IsActionJustPressed(ebiten.KeyDown) {
+		g.selectedBagAction = (g.selectedBagAction + 1) % len(bagActions)
+	}
+
+	if g.input.IsActionJustPressed(ebiten.KeyEscape) {
+		g.bagActionOpen = false
+		return
+	}
+
+	clicked := g.mouseSelect(g.bagActionRects(), &g.selectedBagAction)
+
+	if g.input.IsActionJustPressed(ebiten.KeySpace) || g.input.IsActionJustPressed(ebiten.KeyEnter) || clicked {
+		creature := &g.creatures[g.activeCreature]
+		switch bagActions[g.selectedBagAction] {
+		case "Use":
+			g.bagMessage = g.useItem(name, creature)
+		case "Give":
+			// Mail is written on before it's handed over
+			if item := findItem(name); item != nil && item.category == ItemMail && !creature.egg {
+				g.bagActionOpen = false
+				g.openMailWriter(g.activeCreature)
+				return
+			}
+			g.bagMessage = g.giveItem(name, creature)
+		}
+		g.bagActionOpen = false
+
+		// Keep the selection in range if the last of an item was used up
+		if items := g.bagContents(); g.selectedItem >= len(items) {
+			g.selectedItem = max(len(items)-1, 0)
+		}
+	}
+}
+
+// bagActionRects lays out the action choice beside the item list
+func (g *Game) bagActionRects() []Rect {
+	width := g.widestText(bagActions) + 30
+	return listRects(screenWidth-20-width, 55, width, g.rowHeight(), len(bagActions))
+}
+
+// bagItemRects lays out the item list, leaving room for the counts
+func (g *Game) bagItemRects(items []string) []Rect {
+	return listRects(20, g.listTop(), g.selectorWidth()+g.widestText(items)+10, g.rowHeight(), len(items))
+}
+
+// drawBagMenu draws the bag screen
+func (g *Game) drawBagMenu(screen *ebiten.Image) {
+	// Draw the menu background
+	g.drawPanel(screen, 10, 10, float32(screenWidth-20), float32(screenHeight-20), color.RGBA{100, 60, 40, 240})
+
+	// Draw title
+	titleOp := &text.DrawOptions{}
+	titleOp.GeoM.Translate(20, 30)
+	titleOp.ColorScale.ScaleWithColor(g.uiText(color.White))
+	text.Draw(screen, "Bag", g.fontFace, titleOp)
+
+	moneyOp := &text.DrawOptions{}
+	moneyOp.GeoM.Translate(float64(screenWidth-100), 30)
+	moneyOp.ColorScale.ScaleWithColor(g.uiText(color.White))
+	text.Draw(screen, "$"+strconv.Itoa(g.money), g.fontFace, moneyOp)
+
+	items := g.bagContents()
+	if len(items) == 0 {
+		op := &text.DrawOptions{}
+		op.GeoM.Translate(30, float64(g.listTop()))
+		op.ColorScale.ScaleWithColor(g.uiText(color.RGBA{200, 200, 200, 255}))
+		text.Draw(screen, "The bag is empty.", g.fontFace, op)
+	}
+
+	// Draw item list, with the counts lined up after the longest name
+	rects := g.bagItemRects(items)
+	for i, name := range items {
+		op := &text.DrawOptions{}
+		op.GeoM.Translate(float64(rects[i].x+g.selectorWidth()), float64(rects[i].y))
+
+		if i == g.selectedItem {
+			op.ColorScale.ScaleWithColor(g.uiText(color.RGBA{255, 255, 0, 255})) // Yellow for selected
+
+			// Draw selector arrow
+			selectorOp := &text.DrawOptions{}
+			selectorOp.GeoM.Translate(float64(rects[i].x), float64(rects[i].y))
+			selectorOp.ColorScale.ScaleWithColor(g.uiText(color.RGBA{255, 255, 0, 255}))
+			text.Draw(screen, ">", g.fontFace, selectorOp)
+		} else {
+			op.ColorScale.ScaleWithColor(g.uiText(color.White))
+		}
+
+		text.Draw(screen, name, g.fontFace, op)
+
+		countOp := &text.DrawOptions{}
+		countOp.GeoM.Translate(float64(max(220, rects[i].x+rects[i].width)), float64(rects[i].y))
+		countOp.ColorScale.ScaleWithColor(g.uiText(color.White))
+		text.Draw(screen, "x"+strconv.Itoa(g.bag[name]), g.fontFace, countOp)
+	}
+
+	// Draw the action choice beside the selected item
+	if g.bagActionOpen {
+		rects := g.bagActionRects()
+		g.drawPanel(screen, float32(rects[0].x), 50, float32(rects[0].width), float32(10+len(bagActions)*g.rowHeight()), color.RGBA{60, 40, 30, 250})
+		for i, action := range bagActions {
+			op := &text.DrawOptions{}
+			op.GeoM.Translate(float64(rects[i].x+20), float64(rects[i].y))
+			if i == g.selectedBagAction {
+				op.ColorScale.ScaleWithColor(g.uiText(color.RGBA{255, 255, 0, 255}))
+			} else {
+				op.ColorScale.ScaleWithColor(g.uiText(color.White))
+			}
+			text.Draw(screen, action, g.fontFace, op)
+		}
+	}
+
+	// Draw instructions
+	hintTop := g.drawHint(screen, "Arrows to navigate, Space to use, ESC to exit")
+
+	// Show the result of the last action, or describe the selected item, just
+	// above the instructions
+	message, messageColor := g.bagMessage, color.RGBA{255, 255, 180, 255}
+	if message == "" && g.selectedItem < len(items) {
+		if item := findItem(items[g.selectedItem]); item != nil {
+			message, messageColor = item.description, color.RGBA{230, 230, 230, 255}
+		}
+	}
+	lines := g.wrapText(message, screenWidth-40)
+	for i, line := range lines {
+		op := &text.DrawOptions{}
+		op.GeoM.Translate(20, float64(hintTop-(len(lines)-i)*g.lineSpacing()))
+		op.ColorScale.ScaleWithColor(g.uiText(messageColor))
+		text.Draw(screen, line, g.fontFace, op)
+	}
+}
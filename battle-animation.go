@@ -0,0 +1,136 @@
+package main
+
+import (
+	"image/color"
+	"strconv"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+)
+
+// popupFrames is how long damage numbers and their popups stay on screen
+const popupFrames = 45
+
+// BattleAnimation is one step of the battle animation queue. Steps play one
+// after another over the battle, and the battle waits for them to finish.
+type BattleAnimation struct {
+	frames, frame int
+	// Draws the step, given how far through it is from 0 to 1
+	draw func(screen *ebiten.Image, progress float32)
+	// Sound effect played as the step starts
+	sound string
+	// Whether HP bars wait for the step, as they do for a move landing
+	holdsBars bool
+	// How hard the step shakes the enemy, or the player's creature
+	shake      float32
+	shakeEnemy bool
+}
+
+// Hit is the result of an attack landing
+type Hit struct {
+	damage int
+	// Damage multiplier from the move's type against the defender's
+	effectiveness float32
+	critical      bool
+}
+
+// queueAnimation adds a step to the end of the battle animation queue
+func (g *Game) queueAnimation(frames int, draw func(screen *ebiten.Image, progress float32)) {
+	g.battle.animations = append(g.battle.animations, BattleAnimation{frames: frames, draw: draw})
+}
+
+// updateBattleAnimations advances the step at the front of the queue
+func (g *Game) updateBattleAnimations() {
+	if len(g.battle.animations) == 0 {
+		return
+	}
+	step := &g.battle.animations[0]
+	if step.frame == 0 && step.sound != "" {
+		g.audio.playSound(step.sound)
+	}
+	step.frame++
+	if step.frame >= step.frames {
+		g.battle.animations = g.battle.animations[1:]
+	}
+}
+
+// drawBattleAnimations draws the step at the front of the queue
+func (g *Game) drawBattleAnimations(screen *ebiten.Image) {
+	if len(g.battle.animations) == 0 {
+		return
+	}
+	step := &g.battle.animations[0]
+	step.draw(screen, float32(step.frame)/float32(step.frames))
+}
+
+// battlerRect returns where a creature is drawn in battle: the enemy's up
+// top in the middle, and bigger in a raid, the player's just above the
+// battle UI
+func (g *Game) battlerRect(enemy bool) Rect {
+	if enemy && g.battle.raid != nil {
+		return Rect{screenWidth/2 - 30, 36, 60, 60}
+	}
+	if enemy {
+		return Rect{screenWidth/2 - 20, 50, 40, 40}
+	}
+	return Rect{50, g.battleUITop() - 30, 40, 40}
+}
+
+// queueHitPopup queues the damage number for a hit, rising and fading from
+// the middle of the creature it hit, with popups for critical hits and type
+// effectiveness
+func (g *Game) queueHitPopup(hit Hit, enemy bool) {
+	target := g.battlerRect(enemy)
+	x := float64(target.x + target.width/2)
+	y := float64(target.y + target.height/2)
+
+	// Numbers are colored by how well the move hit, and doubled in size for
+	// critical hits
+	numberColor := color.RGBA{255, 255, 255, 255}
+	switch {
+	case hit.effectiveness > 1:
+		numberColor = color.RGBA{255, 140, 40, 255}
+	case hit.effectiveness < 1:
+		numberColor = color.RGBA{170, 170, 170, 255}
+	}
+	numberScale := 1.0
+	if hit.critical {
+		numberScale = 2
+	}
+
+	var popups []string
+	if hit.critical {
+		popups = append(popups, "Critical!")
+	}
+	if effect := effectivenessText(hit.effectiveness); effect != "" {
+		popups = append(popups, effect)
+	}
+
+	g.queueAnimation(popupFrames, func(screen *ebiten.Image, progress float32) {
+		// Rise, unless animations are off, and fade out over the last third
+		rise := float64(progress) * 20
+		if g.settings.Animation == AnimationOff {
+			rise = 0
+		}
+		alpha := min32(1, (1-progress)*3)
+
+		top := y - 10 - rise - float64(g.lineHeight())*numberScale
+		if hit.damage > 0 {
+			number := strconv.Itoa(hit.damage)
+			op := &text.DrawOptions{}
+			op.GeoM.Scale(numberScale, numberScale)
+			op.GeoM.Translate(x-float64(g.textWidth(number))*numberScale/2, top)
+			op.ColorScale.ScaleWithColor(g.uiText(numberColor))
+			op.ColorScale.ScaleAlpha(alpha)
+			text.Draw(screen, number, g.fontFace, op)
+		}
+
+		for i, popup := range popups {
+			op := &text.DrawOptions{}
+			op.GeoM.Translate(x-float64(g.textWidth(popup))/2, top-float64((len(popups)-i)*g.lineSpacing()))
+			op.ColorScale.ScaleWithColor(g.uiText(color.RGBA{255, 230, 80, 255}))
+			op.ColorScale.ScaleAlpha(alpha)
+			text.Draw(screen, popup, g.fontFace, op)
+		}
+	})
+}
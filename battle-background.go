@@ -0,0 +1,170 @@
+package main
+
+import (
+	"image/color"
+	"math"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Battle backdrop constants, for the kind of ground a battle starts on
+const (
+	BackdropField = iota
+	BackdropTallGrass
+	BackdropWater
+	BackdropCave
+	BackdropBridge
+)
+
+// battleHorizon is where the sky meets the ground behind a battle
+const battleHorizon = 78
+
+// Backdrop is the scenery drawn behind a battle, picked when it starts
+type Backdrop struct {
+	kind int
+	// Colors of the ground, and of the sky at its top and at the horizon
+	ground         color.RGBA
+	skyTop, skyLow color.RGBA
+	// Bridge planks, and whether it's dark out
+	planks color.RGBA
+	night  bool
+}
+
+// newBackdrop picks the scenery for a battle from the tile the player is
+// standing on, the biome around them and the time of day
+func (g *Game) newBackdrop(now time.Time) Backdrop {
+	x, y := g.player.tileX, g.player.tileY
+	palette := &biomes[g.worldMap.BiomeAt(x, y)].palette
+	tileColor := func(tile int) color.RGBA {
+		if palette[tile].A == 0 {
+			return tileColors[tile]
+		}
+		return palette[tile]
+	}
+
+	b := Backdrop{kind: BackdropField, ground: tileColor(TileGrass), planks: tileColor(TileBridge)}
+	switch g.worldMap.Tile(LayerBase, x, y) {
+	case TileTallGrass, TileRustlingGrass:
+		b.kind = BackdropTallGrass
+	case TileWater:
+		b.kind, b.ground = BackdropWater, tileColor(TileWater)
+	case TileCaveFloor, TileRock, TileSwitch:
+		b.kind, b.ground = BackdropCave, tileColor(TileCaveFloor)
+	case TilePath, TileSand, TileFloor:
+		b.ground = tileColor(g.worldMap.Tile(LayerBase, x, y))
+	}
+	if g.player.currentLayer == LayerOverlay {
+		b.kind, b.ground = BackdropBridge, tileColor(TileWater)
+	}
+
+	// Caves look the same at any hour
+	hour := now.Hour()
+	switch {
+	case b.kind == BackdropCave:
+		b.skyTop, b.skyLow = color.RGBA{30, 26, 24, 255}, color.RGBA{70, 62, 56, 255}
+	case isNight(now):
+		b.skyTop, b.skyLow = color.RGBA{10, 14, 40, 255}, color.RGBA{40, 50, 90, 255}
+		b.night = true
+	case hour < 8 || hour >= 17:
+		b.skyTop, b.skyLow = color.RGBA{90, 110, 170, 255}, color.RGBA{250, 170, 110, 255}
+	default:
+		b.skyTop, b.skyLow = color.RGBA{100, 170, 235, 255}, color.RGBA{200, 230, 250, 255}
+	}
+	return b
+}
+
+// drawBackdrop draws the scenery behind a battle, with a platform under each
+// creature: the enemy's at enemyX, enemyY and the player's at playerX, playerY
+func (g *Game) drawBackdrop(screen *ebiten.Image, enemyX, enemyY, playerX, playerY float32) {
+	b := &g.battle.backdrop
+
+	// Sky, or cave wall, fading towards the horizon
+	const bands = 13
+	bandHeight := float32(battleHorizon) / bands
+	for i := range bands {
+		clr := mixColor(b.skyTop, b.skyLow, float32(i)/(bands-1))
+		vector.DrawFilledRect(screen, 0, float32(i)*bandHeight, screenWidth, bandHeight+1, clr, false)
+	}
+
+	// A few stars at night, and rocky ledges in caves
+	if b.night {
+		for i := range 12 {
+			sx := float32((i*97 + 13) % screenWidth)
+			sy := float32((i*53 + 7) % (battleHorizon - 20))
+			vector.DrawFilledRect(screen, sx, sy, 1, 1, color.RGBA{230, 230, 255, 255}, false)
+		}
+	}
+	if b.kind == BackdropCave {
+		for i := range 6 {
+			lx := float32(i * screenWidth / 6)
+			vector.DrawFilledRect(screen, lx, float32(18+(i*29)%40), float32(screenWidth/6-8), 4, shade(b.skyLow, 1.3), false)
+		}
+	}
+
+	// Ground, a little darker at night
+	ground := b.ground
+	if b.night {
+		ground = shade(ground, 0.55)
+	}
+	vector.DrawFilledRect(screen, 0, battleHorizon, screenWidth, screenHeight-battleHorizon, ground, false)
+
+	platform := shade(ground, 0.8)
+	switch b.kind {
+	case BackdropTallGrass:
+		// Blades of tall grass along the horizon
+		blade := shade(ground, 0.7)
+		for x := float32(0); x < screenWidth; x += 6 {
+			vector.DrawFilledRect(screen, x, battleHorizon-6, 2, 8, blade, false)
+			vector.DrawFilledRect(screen, x+3, battleHorizon-3, 2, 5, blade, false)
+		}
+	case BackdropWater:
+		// Ripples across the surface, with lighter rings to float on
+		g.drawRipples(screen, shade(ground, 1.2))
+		platform = shade(ground, 1.25)
+	case BackdropBridge:
+		// The bridge runs across the water under the player
+		g.drawRipples(screen, shade(ground, 1.2))
+		planks := b.planks
+		if b.night {
+			planks = shade(planks, 0.55)
+		}
+		vector.DrawFilledRect(screen, 0, playerY-14, screenWidth, 32, planks, false)
+		for x := float32(0); x < screenWidth; x += 12 {
+			vector.DrawFilledRect(screen, x, playerY-14, 1, 32, shade(planks, 0.7), false)
+		}
+		platform = shade(planks, 0.8)
+	}
+
+	drawEllipse(screen, enemyX, enemyY, 38, 8, platform)
+	drawEllipse(screen, playerX, playerY, 42, 9, platform)
+}
+
+// drawRipples draws short wave lines across the ground of a battle backdrop
+func (g *Game) drawRipples(screen *ebiten.Image, clr color.RGBA) {
+	for row := range 8 {
+		y := float32(battleHorizon + 8 + row*20)
+		offset := float32((row*37 + g.ticks/8) % 40)
+		for x := -40 + offset; x < screenWidth; x += 40 {
+			vector.DrawFilledRect(screen, x, y, 14, 1, clr, false)
+		}
+	}
+}
+
+// drawEllipse draws a filled ellipse row by row, keeping its edges crisp
+func drawEllipse(screen *ebiten.Image, cx, cy, rx, ry float32, clr color.RGBA) {
+	for dy := -ry; dy <= ry; dy++ {
+		t := dy / ry
+		half := rx * float32(math.Sqrt(float64(1-t*t)))
+		vector.DrawFilledRect(screen, cx-half, cy+dy, half*2, 1, clr, false)
+	}
+}
+
+// mixColor blends from one color towards another by t, between 0 and 1
+func mixColor(from, to color.RGBA, t float32) color.RGBA {
+	mix := func(a, b uint8) uint8 {
+		return uint8(float32(a) + (float32(b)-float32(a))*t)
+	}
+	return color.RGBA{mix(from.R, to.R), mix(from.G, to.G), mix(from.B, to.B), mix(from.A, to.A)}
+}
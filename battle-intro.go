@@ -0,0 +1,184 @@
+package main
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Battle intro phase constants, played in order before the first turn
+const (
+	IntroEnemyEnter  = iota // The wild creature or the trainer slides in
+	IntroAnnounce           // The battle is announced
+	IntroTrainerSend        // The trainer steps aside and throws out their creature
+	IntroPlayerThrow        // The player's creature is thrown out
+	IntroDone
+)
+
+// introFrames is how long each intro phase lasts
+var introFrames = [IntroDone]int{
+	IntroEnemyEnter:  40,
+	IntroAnnounce:    70,
+	IntroTrainerSend: 40,
+	IntroPlayerThrow: 40,
+}
+
+// introLength returns how many frames an intro phase lasts; only the
+// announcement is left when animations are off
+func (g *Game) introLength(phase int) int {
+	if g.settings.Animation == AnimationOff && phase != IntroAnnounce {
+		return 0
+	}
+	return introFrames[phase]
+}
+
+// updateBattleIntro advances the battle intro; the announcement can be
+// skipped with the confirm button
+func (g *Game) updateBattleIntro() {
+	b := &g.battle
+	b.introFrame++
+
+	skip := b.intro == IntroAnnounce && g.input.IsActionJustPressed(ebiten.KeySpace)
+	if b.introFrame < g.introLength(b.intro) && !skip {
+		return
+	}
+
+	b.introFrame = 0
+	switch b.intro {
+	case IntroEnemyEnter:
+		b.intro = IntroAnnounce
+		b.battleText = b.announcement
+		if b.trainer == "" {
+			g.audio.playCry(b.enemyCreature.name)
+		}
+	case IntroAnnounce:
+		if b.trainer != "" {
+			b.intro = IntroTrainerSend
+			b.battleText = b.trainer + " sent out " + b.enemyCreature.displayName() + "!"
+			g.audio.playSound("ball")
+		} else {
+			g.throwOutPlayer()
+		}
+	case IntroTrainerSend:
+		g.audio.playCry(b.enemyCreature.name)
+		g.throwOutPlayer()
+	case IntroPlayerThrow:
+		g.audio.playCry(b.playerCreature.name)
+		b.intro = IntroDone
+	}
+}
+
+// throwOutPlayer sends out the player's creature, the last step of the intro;
+// nobody is sent out in the safari zone
+func (g *Game) throwOutPlayer() {
+	if g.battle.safari {
+		g.battle.intro = IntroDone
+		return
+	}
+	g.battle.intro = IntroPlayerThrow
+	g.battle.battleText = "Go, " + g.battle.playerCreature.displayName() + "!"
+	g.audio.playSound("ball")
+}
+
+// introProgress returns how far through the current intro phase the battle
+// is, from 0 to 1
+func (g *Game) introProgress() float32 {
+	if g.battle.intro == IntroDone {
+		return 1
+	}
+	length := g.introLength(g.battle.intro)
+	if length == 0 {
+		return 1
+	}
+	return float32(g.battle.introFrame) / float32(length)
+}
+
+// introCreatureScale returns how big to draw a creature thrown out in an
+// intro phase: nothing before its ball opens, growing to full size after
+func (g *Game) introCreatureScale(phase int) float32 {
+	switch {
+	case g.battle.intro < phase:
+		return 0
+	case g.battle.intro > phase:
+		return 1
+	}
+	return max(0, g.introProgress()*2-1)
+}
+
+// introEnemyScale returns how big to draw the enemy creature; wild ones are
+// there from the start, trainers' ones are thrown out
+func (g *Game) introEnemyScale() float32 {
+	if g.battle.trainer == "" {
+		return 1
+	}
+	return g.introCreatureScale(IntroTrainerSend)
+}
+
+// introSlide returns how far right of its place whatever is sliding in at
+// the start of the battle still is
+func (g *Game) introSlide() float32 {
+	if g.battle.intro != IntroEnemyEnter {
+		return 0
+	}
+	return (1 - g.introProgress()) * screenWidth
+}
+
+// drawBattleIntro draws the trainer and the capture balls of the intro, given
+// the bottom centers of the enemy and player creatures
+func (g *Game) drawBattleIntro(screen *ebiten.Image, enemyX, enemyY, playerX, playerY float32) {
+	b := &g.battle
+	t := g.introProgress()
+
+	// The trainer slides in, then steps off to the right to throw
+	if b.trainer != "" && b.intro <= IntroTrainerSend {
+		x := enemyX + g.introSlide()
+		if b.intro == IntroTrainerSend {
+			x += min32(t*2, 1) * screenWidth / 2
+		}
+		size := float32(spriteFrameSize * 3)
+		b.trainerSprite.direction = DirectionDown
+		b.trainerSprite.play("idle", g.ticks)
+		b.trainerSprite.drawScaled(screen, x-size/2, enemyY-size, 3, g.ticks)
+	}
+
+	if b.intro == IntroTrainerSend {
+		// The ball drops onto the enemy's spot, then bursts open
+		if t < 0.5 {
+			drawBall(screen, enemyX, enemyY-60+t*2*40, 6)
+		} else {
+			g.drawBallBurst(screen, enemyX, enemyY-20, t*2-1)
+		}
+	}
+
+	if b.intro == IntroPlayerThrow {
+		// The ball is lobbed in from the left, then bursts open
+		if t < 0.5 {
+			arc := t * 2
+			x := playerX * arc
+			y := playerY - 50 + arc*30 - float32(math.Sin(float64(arc)*math.Pi))*40
+			drawBall(screen, x, y, 6)
+		} else {
+			g.drawBallBurst(screen, playerX, playerY-20, t*2-1)
+		}
+	}
+}
+
+// drawBallBurst draws a capture ball opening, with a ring spreading out as it
+// goes from 0 to 1 and a white flash unless flashes are turned down
+func (g *Game) drawBallBurst(screen *ebiten.Image, x, y, progress float32) {
+	fade := uint8(255 * (1 - progress))
+	if !g.settings.ReduceFlashes {
+		vector.DrawFilledCircle(screen, x, y, 10+progress*20, color.RGBA{fade, fade, fade, fade}, true)
+	}
+	vector.StrokeCircle(screen, x, y, 6+progress*28, 2, color.RGBA{fade, fade, fade / 2, fade}, true)
+}
+
+// drawBall draws a red and white capture ball centered on x, y
+func drawBall(screen *ebiten.Image, x, y, radius float32) {
+	vector.DrawFilledCircle(screen, x, y, radius+1, color.RGBA{40, 40, 40, 255}, true)
+	vector.DrawFilledCircle(screen, x, y, radius, color.RGBA{220, 40, 40, 255}, true)
+	vector.DrawFilledRect(screen, x-radius, y, radius*2, radius, color.White, true)
+	vector.DrawFilledCircle(screen, x, y, radius*0.3, color.RGBA{40, 40, 40, 255}, true)
+}
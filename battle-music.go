@@ -0,0 +1,23 @@
+package main
+
+// updateMusic plays the music for what's going on: the battle theme during
+// battles, its layers following how the battle's going, and otherwise the
+// bike's tune while riding. The audio manager then fades everything toward
+// where it should be.
+func (g *Game) updateMusic() {
+	if g.gameState == StateBattle {
+		g.audio.playMusic("battle")
+		g.updateBattleMusic()
+	} else {
+		g.updateBikeMusic()
+	}
+	g.audio.update()
+}
+
+// updateBattleMusic brings in the battle theme's drums once either side's
+// creature is low on HP, and takes them out again once neither is
+func (g *Game) updateBattleMusic() {
+	b := &g.battle
+	danger := b.enemyCreature.lowHP() || b.playerCreature != nil && b.playerCreature.lowHP()
+	g.audio.setLayer("battle danger", danger)
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"image/color"
+	"strconv"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+)
+
+// battlePartyActions are the choices offered for a creature picked from the
+// party in battle
+var battlePartyActions = []string{"Switch", "Use Item", "Cancel"}
+
+// BattleParty is the party list opened in battle, for switching creatures or
+// using an item on one
+type BattleParty struct {
+	open     bool
+	selected int
+	// Choice open for the selected creature, and the item list opened from it
+	actionOpen bool
+	action     int
+	items      []string
+	item       int
+	itemsOpen  bool
+}
+
+// openBattleParty opens the party list on the player's turn
+func (g *Game) openBattleParty() {
+	g.battle.party = BattleParty{open: true}
+	for i := range g.creatures {
+		if &g.creatures[i] == g.battle.playerCreature {
+			g.battle.party.selected = i
+		}
+	}
+}
+
+// updateBattleParty handles the party list in battle; switching or using an
+// item takes the player's turn
+func (g *Game) updateBattleParty() {
+	p := &g.battle.party
+
+	switch {
+	case p.itemsOpen:
+		g.updateChoice(p.items, &p.item, func() { p.itemsOpen = false }, func() {
+			g.useBattleItem(p.items[p.item], &g.creatures[p.selected])
+		})
+	case p.actionOpen:
+		g.updateChoice(battlePartyActions, &p.action, func() { p.actionOpen = false }, func() {
+			switch battlePartyActions[p.action] {
+			case "Switch":
+				if g.challengeForbids(RuleNoSwitching, "Switching isn't allowed in this challenge!") {
+					p.open = false
+					return
+				}
+				g.switchBattler(p.selected)
+			case "Use Item":
+				if g.challengeForbids(RuleNoItems, "Items aren't allowed in this challenge!") {
+					p.open = false
+					return
+				}
+				p.items = g.medicineContents()
+				if len(p.items) == 0 {
+					g.battle.battleText = "There's nothing in the bag to use."
+					g.battle.battleTextTimer = 40
+					p.open = false
+					return
+				}
+				p.itemsOpen, p.item = true, 0
+			default:
+				p.actionOpen = false
+			}
+		})
+	default:
+		choose := func() { p.actionOpen, p.action = true, 0 }
+		g.updateChoice(g.partyLabels(), &p.selected, func() { p.open = false }, choose)
+		if g.mouseSelect(g.partyRects(), &p.selected) {
+			choose()
+		}
+	}
+}
+
+// updateChoice moves through a list of choices with up and down, calling
+// back on cancel or on picking the selected one
+func (g *Game) updateChoice(choices []string, selected *int, back, pick func()) {
+	if g.input.IsActionJustPressed(ebiten.KeyUp) {
+		*selected = (*selected - 1 + len(choices)) % len(choices)
+	} else if g.input.IsActionJustPressed(ebiten.KeyDown) {
+		*selected = (*selected + 1) % len(choices)
+	}
+
+	if g.input.IsActionJustPressed(ebiten.KeyEscape) {
+		back()
+	} else if g.input.IsActionJustPressed(ebiten.KeySpace) || g.input.IsActionJustPressed(ebiten.KeyEnter) {
+		pick()
+	}
+}
+
+// switchBattler sends out another party creature in place of the one fighting
+func (g *Game) switchBattler(i int) {
+	next := &g.creatures[i]
+	switch {
+	case next == g.battle.playerCreature:
+		g.battle.battleText = next.name + " is already out!"
+		g.battle.battleTextTimer = 40
+	case next.egg:
+		g.battle.battleText = "An egg can't fight!"
+		g.battle.battleTextTimer = 40
+	case next.hp <= 0:
+		g.battle.battleText = next.name + " has no energy left to fight!"
+		g.battle.battleTextTimer = 40
+	default:
+		g.battle.battleText = "Come back, " + g.battle.playerCreature.displayName() + "! Go, " + next.displayName() + "!"
+		g.battle.battleTextTimer = 60
+		g.battle.playerCreature = next
+		g.battle.leaveField(true)
+		g.battle.selectedAction = 0
+		g.battle.currentTurn = 1
+		g.audio.playSound("ball")
+	}
+	g.battle.party.open = false
+}
+
+// useBattleItem uses an item on a party creature, which takes the turn if the
+// item was used up
+func (g *Game) useBattleItem(name string, c *Creature) {
+	before := g.bag[name]
+	g.battle.battleText = g.useItem(name, c)
+	g.battle.battleTextTimer = 60
+	if g.bag[name] < before {
+		g.battle.currentTurn = 1
+	}
+	g.battle.party.open = false
+}
+
+// medicineContents returns the items in the bag that can be used on a
+// creature in battle
+func (g *Game) medicineContents() []string {
+	names := []string{}
+	for _, name := range g.bagContents() {
+		if item := findItem(name); item.category == ItemMedicine || item.category == ItemBerry {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// drawBattleParty draws the party list over the battle, with the choice for
+// the selected creature beside it
+func (g *Game) drawBattleParty(screen *ebiten.Image) {
+	p := &g.battle.party
+	g.drawPanel(screen, 10, 10, float32(screenWidth-20), float32(screenHeight-20), color.RGBA{50, 50, 100, 240})
+
+	titleOp := &text.DrawOptions{}
+	titleOp.GeoM.Translate(20, 30)
+	titleOp.ColorScale.ScaleWithColor(g.uiText(color.White))
+	text.Draw(screen, "Choose a creature", g.fontFace, titleOp)
+
+	g.drawPartyList(screen, p.selected)
+
+	switch {
+	case p.itemsOpen:
+		labels := make([]string, len(p.items))
+		for i, name := range p.items {
+			labels[i] = name + " x" + strconv.Itoa(g.bag[name])
+		}
+		g.drawChoiceBox(screen, labels, p.item)
+	case p.actionOpen:
+		g.drawChoiceBox(screen, battlePartyActions, p.action)
+	}
+
+	g.drawHint(screen, "Space to choose, ESC to go back")
+}
+
+// drawChoiceBox draws a list of choices in a box in the bottom right corner
+func (g *Game) drawChoiceBox(screen *ebiten.Image, choices []string, selected int) {
+	width := g.selectorWidth() + g.widestText(choices) + 20
+	height := 10 + len(choices)*g.rowHeight()
+	x := screenWidth - 20 - width
+	y := screenHeight - 40 - height
+	g.drawPanel(screen, float32(x), float32(y), float32(width), float32(height), color.RGBA{30, 30, 70, 250})
+
+	rects := listRects(x+10, y+5, width-20, g.rowHeight(), len(choices))
+	for i, choice := range choices {
+		op := &text.DrawOptions{}
+		op.GeoM.Translate(float64(rects[i].x+g.selectorWidth()), float64(rects[i].y))
+		clr := color.RGBA{255, 255, 255, 255}
+		if i == selected {
+			clr = color.RGBA{255, 255, 0, 255}
+			selectorOp := &text.DrawOptions{}
+			selectorOp.GeoM.Translate(float64(rects[i].x), float64(rects[i].y))
+			selectorOp.ColorScale.ScaleWithColor(g.uiText(clr))
+			text.Draw(screen, ">", g.fontFace, selectorOp)
+		}
+		op.ColorScale.ScaleWithColor(g.uiText(clr))
+		text.Draw(screen, choice, g.fontFace, op)
+	}
+}
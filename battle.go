@@ -4,101 +4,273 @@ import (
 	"image"
 	"image/color"
 	"math/rand"
+	"time"
 
+	"creaturegame-2/engine"
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
-	"github.com/hajimehoshi/ebiten/v2/text/v2"
 	"github.com/hajimehoshi/ebiten/v2/vector"
 )
 
 // Battle represents a battle state
 type Battle struct {
-	playerCreature  Creature
+	playerCreature  *Creature // Points into the player's party for the battle
 	enemyCreature   Creature
 	currentTurn     int
 	selectedAction  int
 	battleText      string
 	battleTextTimer int
+	// Fighting the roaming legendary, which can run and keeps its damage
+	roamer bool
+	// Fighting a static encounter, which is gone for good once beaten
+	static *StaticEncounter
+	// Fighting a boss, which changes phases as its HP drops
+	boss *BossBattle
+	// Fighting a giant creature out of a raid den, with an ally
+	raid *RaidBattle
+	// Fighting another player in a ranked battle the referee plays out
+	online *OnlineBattle
+	// Fighting a battle from a challenge code
+	challenge *ChallengeBattle
+	// Safari battles have throws instead of moves; bait and mud last a few turns
+	safari    bool
+	bait, mud int
+	// Scenery behind the battle, from where and when it started
+	backdrop Backdrop
+	// Intro phase played before the first turn, frames into it, and the line
+	// announcing the battle
+	intro        int
+	introFrame   int
+	announcement string
+	// Trainer fighting the player, if it's not a wild battle, and the next
+	// of their team to send out
+	trainer       string
+	trainerSprite *AnimatedSprite
+	opponent      Trainer
+	nextEnemy     int
+	// HP bars of both sides
+	enemyBar, playerBar HPBar
+	// Animations waiting to play, like damage popups
+	animations []BattleAnimation
+	// EXP handed out after winning
+	victory Victory
+	// Party list opened to switch creatures or use items
+	party BattleParty
+	// What the creatures out are in the middle of, like charging a move
+	playerVolatile, enemyVolatile engine.Volatile
 }
 
-// Start a battle with a random wild creature
-func (g *Game) startBattle() {
-	g.gameState = StateBattle
+// Start a battle with a wild creature
+func (g *Game) startBattle(enemy Creature) {
+	// Weak creatures keep away while a repel is working
+	if g.repelBlocks(enemy) {
+		return
+	}
+	g.setUpBattle(enemy)
+}
 
-	// Select a random creature as the enemy
-	enemyIndex := rand.Intn(len(g.creatures))
-	g.battle.enemyCreature = g.creatures[enemyIndex]
+// setUpBattle starts a battle against a creature, beginning with its intro
+func (g *Game) setUpBattle(enemy Creature) {
+	g.gameState = StateBattle
+	g.battle.enemyCreature = enemy
+	g.battle.roamer = false
+	g.battle.static = nil
+	g.battle.boss = nil
+	g.battle.raid = nil
+	g.battle.online = nil
+	g.battle.challenge = nil
+	g.battle.safari = g.worldMap.id == safariZoneID
+	g.battle.bait, g.battle.mud = 0, 0
+	g.battle.backdrop = g.newBackdrop(time.Now())
 
-	// Reset the creature's HP for the battle
-	g.battle.enemyCreature.hp = g.battle.enemyCreature.maxHP
+	// The active creature fights, or the next one able to if it has fainted;
+	// damage sticks to it after the battle
+	g.battle.playerCreature = &g.creatures[g.activeCreature]
+	if g.battle.playerCreature.hp <= 0 {
+		if next := g.nextHealthyCreature(); next != nil {
+			g.battle.playerCreature = next
+		}
+	}
 
 	// Set up the battle state
 	g.battle.currentTurn = 0
 	g.battle.selectedAction = 0
-	g.battle.battleText = "A wild " + g.battle.enemyCreature.name + " appeared!"
-	g.battle.battleTextTimer = 60 // Show text for 60 frames
+	g.battle.battleText = ""
+	g.battle.battleTextTimer = 0
+	g.battle.intro, g.battle.introFrame = IntroEnemyEnter, 0
+	g.battle.announcement = "A wild " + formName(enemy.name, enemy.form) + " appeared!"
+	if enemy.shiny {
+		g.battle.announcement = "A wild shiny " + formName(enemy.name, enemy.form) + " appeared!"
+	}
+	g.battle.trainer, g.battle.trainerSprite = "", nil
+	g.battle.opponent, g.battle.nextEnemy = Trainer{}, 0
+	g.battle.enemyBar, g.battle.playerBar = HPBar{}, HPBar{}
+	g.battle.animations = nil
+	g.battle.victory = Victory{}
+	g.battle.party = BattleParty{}
+	g.battle.playerVolatile, g.battle.enemyVolatile = engine.Volatile{}, engine.Volatile{}
+	g.updateHPBars()
+	g.events.publish(Event{kind: EventEncounter, species: enemy.name, form: enemy.form})
 }
 
 // updateBattle handles battle state updates
 func (g *Game) updateBattle() {
+	g.updateHPBars()
+	g.updateBattleAnimations()
+
+	if g.battle.intro != IntroDone {
+		g.updateBattleIntro()
+		return
+	}
+
+	if g.battle.victory.active {
+		g.updateVictory()
+		return
+	}
+
 	// Update battle text timer
 	if g.battle.battleTextTimer > 0 {
 		g.battle.battleTextTimer--
 		return
 	}
 
+	// Let animations finish before moving on
+	if len(g.battle.animations) > 0 {
+		return
+	}
+
+	if g.battle.party.open {
+		g.updateBattleParty()
+		return
+	}
+
+	// Ranked battles are played out by the referee
+	if g.battle.online != nil {
+		g.updateOnlineBattle()
+		return
+	}
+
+	// Safari battles have their own actions
+	if g.battle.safari {
+		g.updateSafariBattle()
+		return
+	}
+
 	// Handle player input during battle
 	if g.battle.currentTurn == 0 {
-		// Player's turn
-		if inpututil.IsKeyJustPressed(ebiten.KeyUp) {
+		// A two-turn move charged last turn strikes without waiting for a
+		// choice
+		if charging := g.battle.playerVolatile.Charging; charging != nil {
+			move := moveFromEngine(*charging)
+			g.battle.battleText = g.battle.playerCreature.displayName() + " used " + move.name + "!" + g.playerMove(move)
+			g.battle.battleTextTimer = 60
+			g.battle.currentTurn = 1
+			return
+		}
+
+		// Player's turn; the party can be opened instead of picking a move
+		if g.input.IsActionJustPressed(ebiten.KeyC) {
+			g.openBattleParty()
+			return
+		}
+
+		if g.input.IsActionJustPressed(ebiten.KeyUp) {
 			g.battle.selectedAction = (g.battle.selectedAction - 1 + len(g.battle.playerCreature.moves)) % len(g.battle.playerCreature.moves)
-		} else if inpututil.IsKeyJustPressed(ebiten.KeyDown) {
+		} else if g.input.IsActionJustPressed(ebiten.KeyDown) {
 			g.battle.selectedAction = (g.battle.selectedAction + 1) % len(g.battle.playerCreature.moves)
 		}
 
-		if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
-			// Execute selected move
-			selectedMove := g.battle.playerCreature.moves[g.battle.selectedAction]
-			damage := calculateDamage(g.battle.playerCreature, g.battle.enemyCreature, selectedMove)
+		clicked := g.mouseSelect(g.moveRects(), &g.battle.selectedAction)
 
-			g.battle.enemyCreature.hp -= damage
-			if g.battle.enemyCreature.hp < 0 {
-				g.battle.enemyCreature.hp = 0
+		if g.input.IsActionJustPressed(ebiten.KeySpace) || clicked {
+			// Execute selected move, falling back to Struggle once every move is out of PP
+			move := &g.battle.playerCreature.moves[g.battle.selectedAction]
+			selectedMove := *move
+
+			// A strong creature from elsewhere may do as it likes
+			disobeyed := ""
+			if move.pp > 0 && g.mightDisobey(g.battle.playerCreature) {
+				var other int
+				other, disobeyed = g.disobey(g.battle.playerCreature, g.battle.selectedAction)
+				if other < 0 {
+					g.battle.battleText = disobeyed
+					g.battle.battleTextTimer = 60
+					g.battle.currentTurn = 1
+					return
+				}
+				move = &g.battle.playerCreature.moves[other]
+				selectedMove = *move
 			}
 
-			g.battle.battleText = g.battle.playerCreature.name + " used " + selectedMove.name + "!"
+			if g.battle.playerCreature.outOfPP() {
+				selectedMove = struggle
+			} else if move.pp <= 0 {
+				g.battle.battleText = "There's no PP left for this move!"
+				g.battle.battleTextTimer = 40
+				return
+			} else {
+				move.pp--
+			}
+			g.battle.battleText = g.battle.playerCreature.displayName() + " used " + selectedMove.name + "!"
+			if disobeyed != "" {
+				g.battle.battleText = disobeyed + " " + g.battle.battleText
+			}
+			g.battle.battleText += g.playerMove(selectedMove)
 			g.battle.battleTextTimer = 60
 			g.battle.currentTurn = 1 // Switch to enemy turn
 		}
 	} else {
 		// Enemy's turn
 		if g.battle.battleTextTimer <= 0 {
-			if g.battle.enemyCreature.hp <= 0 {
-				g.battle.battleText = g.battle.enemyCreature.name + " fainted!"
+			if g.battle.enemyCreature.hp <= 0 && g.battle.victory.pending {
+				g.startVictory()
+			} else if g.battle.enemyCreature.hp <= 0 {
+				g.battle.battleText = g.battle.enemyCreature.displayName() + " fainted!"
 				g.battle.battleTextTimer = 60
-				g.gameState = StateOverworld
+				g.battle.victory.pending = true
+				g.events.publish(Event{kind: EventDefeat, species: g.battle.enemyCreature.name, form: g.battle.enemyCreature.form})
+			} else if g.battle.boss != nil && g.updateBoss() {
+				// The boss changed phase or played its script; it attacks next
+			} else if g.battle.raid != nil && g.updateRaid() {
+				// Shields went up, the ally attacked or the ally was attacked
+			} else if g.roamerFlees() {
+				g.endBattle()
+				g.showDialogue(g.battle.enemyCreature.displayName() + " fled!")
 			} else {
-				// Enemy attacks with a random move
-				enemyMoveIndex := rand.Intn(len(g.battle.enemyCreature.moves))
+				// Enemy attacks with a random move, or the one it charged
+				// last turn
+				enemyMoveIndex := battleRand.Intn(len(g.battle.enemyCreature.moves))
 				enemyMove := g.battle.enemyCreature.moves[enemyMoveIndex]
-
-				damage := calculateDamage(g.battle.enemyCreature, g.battle.playerCreature, enemyMove)
-
-				g.battle.playerCreature.hp -= damage
-				if g.battle.playerCreature.hp < 0 {
-					g.battle.playerCreature.hp = 0
+				if charging := g.battle.enemyVolatile.Charging; charging != nil {
+					enemyMove = moveFromEngine(*charging)
 				}
 
-				g.battle.battleText = g.battle.enemyCreature.name + " used " + enemyMove.name + "!"
+				g.battle.battleText = g.battle.enemyCreature.displayName() + " used " + enemyMove.name + "!" + g.enemyMove(enemyMove)
 				g.battle.battleTextTimer = 60
 
+				// A held berry is eaten once HP drops to half
+				if g.battle.playerCreature.eatHeldBerry() {
+					g.battle.battleText += " " + g.battle.playerCreature.displayName() + " ate its berry!"
+				}
+
 				if g.battle.playerCreature.hp <= 0 {
-					g.battle.battleText = g.battle.playerCreature.name + " fainted!"
+					g.battle.battleText = g.battle.playerCreature.displayName() + " fainted!"
 					g.battle.battleTextTimer = 60
-					g.gameState = StateOverworld
 
-					// Heal player's creature for the next battle
-					g.battle.playerCreature.hp = g.battle.playerCreature.maxHP
+					// Send out the next creature that can still fight
+					if next := g.nextHealthyCreature(); next != nil {
+						g.battle.playerCreature = next
+						g.battle.leaveField(true)
+						g.battle.battleText += " Go, " + next.displayName() + "!"
+						g.battle.selectedAction = 0
+						g.battle.currentTurn = 0
+					} else {
+						// Losing a challenge costs nothing, as the party is put back
+						challenge := g.battle.challenge != nil
+						g.endBattle()
+						if !challenge {
+							g.blackout()
+						}
+					}
 				} else {
 					g.battle.currentTurn = 0 // Switch back to player's turn
 				}
@@ -106,107 +278,241 @@ func (g *Game) updateBattle() {
 		}
 	}
 
-	// Check for escape
-	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+	// Check for escape; there's no running from trainers, or most bosses,
+	// though a challenge can be given up
+	if g.input.IsActionJustPressed(ebiten.KeyEscape) && g.battle.challenge != nil {
+		g.endBattle()
+	} else if g.input.IsActionJustPressed(ebiten.KeyEscape) && g.battle.trainer != "" {
+		g.battle.battleText = "There's no running from a trainer battle!"
+		g.battle.battleTextTimer = 40
+	} else if g.input.IsActionJustPressed(ebiten.KeyEscape) && g.battle.boss != nil && !g.battle.boss.boss.Escapable {
+		g.battle.battleText = "The " + g.battle.enemyCreature.name + " blocks the way! You can't get away!"
+		g.battle.battleTextTimer = 40
+	} else if g.input.IsActionJustPressed(ebiten.KeyEscape) {
 		g.battle.battleText = "Got away safely!"
 		g.battle.battleTextTimer = 60
-		g.gameState = StateOverworld
+		g.events.publish(Event{kind: EventFlee})
+		g.endBattle()
 	}
 }
 
-// calculateDamage calculates damage from an attack
-func calculateDamage(attacker, defender Creature, move Move) int {
-	// Basic damage formula similar to Pokémon
-	baseDamage := (2*attacker.level)/5 + 2
-	baseDamage = baseDamage * move.power * attacker.attack / defender.defense
-	baseDamage = baseDamage/50 + 2
+// heavyHitShare is the share of a creature's max HP a hit has to take to count as heavy
+const heavyHitShare = 0.25
+
+// publishHit announces a heavy hit, stronger the more of the creature's HP it took
+func (g *Game) publishHit(damage, maxHP int) {
+	share := float64(damage) / float64(maxHP)
+	if share < heavyHitShare {
+		return
+	}
+	g.events.publish(Event{kind: EventHeavyHit, strength: share * 2})
+}
 
-	// Random factor between 0.85 and 1.0
-	randomFactor := 0.85 + rand.Float32()*0.15
+// battleUITop returns the top of the battle UI along the bottom of the
+// screen, tall enough for the prompt and every move
+func (g *Game) battleUITop() int {
+	moves := len(g.battle.playerCreature.moves)
+	return screenHeight - max(70, 20+g.rowHeight()+moves*g.lineSpacing())
+}
 
-	return int(float32(baseDamage) * randomFactor)
+// moveRects lays out the player creature's moves in the battle UI, leaving
+// room after the longest name for the PP
+func (g *Game) moveRects() []Rect {
+	moves := g.battle.playerCreature.moves
+	names := make([]string, len(moves))
+	for i, move := range moves {
+		names[i] = move.name
+	}
+	width := max(135, g.selectorWidth()+g.widestText(names)+15)
+	return listRects(15, g.battleUITop()+20+g.rowHeight(), width, g.lineSpacing(), len(moves))
+}
+
+// endBattle returns to the overworld, leaving the roamer as the battle left
+// it, and paying out for a trainer beaten
+func (g *Game) endBattle() {
+	g.gameState = StateOverworld
+
+	if g.battle.challenge != nil {
+		g.finishChallenge(g.battle.enemyCreature.hp <= 0)
+	} else if g.battle.trainer != "" && g.battle.enemyCreature.hp <= 0 {
+		g.winTrainerBattle()
+	}
+
+	if g.battle.roamer {
+		g.roamer.creature = g.battle.enemyCreature
+		g.roamer.defeated = g.battle.enemyCreature.hp <= 0
+		g.battle.roamer = false
+	}
+
+	if g.battle.static != nil {
+		if g.battle.enemyCreature.hp <= 0 {
+			g.finishStaticEncounter(g.battle.static)
+		}
+		g.battle.static = nil
+	}
+
+	if g.battle.raid != nil {
+		if g.battle.enemyCreature.hp <= 0 {
+			g.finishRaid(g.battle.raid)
+		}
+		g.battle.raid = nil
+	}
+}
+
+// struggle is used once a creature has no PP left in any of its moves
+var struggle = Move{name: "Struggle", power: 30, accuracy: 100, type1: "Normal"}
+
+// nextHealthyCreature returns the first party creature that hasn't fainted,
+// or nil if the whole party has
+func (g *Game) nextHealthyCreature() *Creature {
+	for i := range g.creatures {
+		if g.creatures[i].hp > 0 {
+			return &g.creatures[i]
+		}
+	}
+	return nil
+}
+
+// outOfPP reports whether a creature has used up every move
+func (c *Creature) outOfPP() bool {
+	for _, move := range c.moves {
+		if move.pp > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// battleRand rolls the damage and effects of battles played out in this game
+var battleRand = rand.New(rand.NewSource(time.Now().UnixNano()))
+
+// calculateDamage calculates damage from an attack by the shared battle rules
+func calculateDamage(attacker, defender Creature, move Move) Hit {
+	hit := engine.Damage(attacker.fighter(), defender.fighter(), move.engineMove(), battleRand)
+	return Hit{damage: hit.Damage, effectiveness: hit.Effectiveness, critical: hit.Critical}
+}
+
+// fighter returns a creature as the battle rules see it
+func (c *Creature) fighter() engine.Fighter {
+	moves := make([]engine.Move, len(c.moves))
+	for i, move := range c.moves {
+		moves[i] = move.engineMove()
+	}
+	return engine.Fighter{
+		Name: c.name, Form: c.form, Level: c.level,
+		HP: c.hp, MaxHP: c.maxHP, Attack: c.attack, Defense: c.defense, Speed: c.speed,
+		Type1: c.type1, Type2: c.type2, Status: c.status, Moves: moves,
+		Held: c.heldItem,
+	}
+}
+
+// engineMove returns a move as the battle rules see it
+func (m Move) engineMove() engine.Move {
+	return engine.Move{Name: m.name, Power: m.power, Accuracy: m.accuracy, Type: m.type1, PP: m.pp, MaxPP: m.maxPP, Effect: m.effect, Chance: m.effectChance, Kind: m.kind}
 }
 
 // drawBattle draws the battle screen
 func (g *Game) drawBattle(screen *ebiten.Image) {
-	// Draw battle background
-	screen.Fill(color.RGBA{200, 200, 200, 255})
+	// Draw enemy creature on the scenery of where the battle started
+	enemy := g.battlerRect(true)
+	enemySize, enemyX, enemyY := enemy.width, enemy.x, enemy.y
+	uiTop := g.battleUITop()
+	player := g.battlerRect(false)
+	playerSize, playerX, playerY := player.width, player.x, player.y
+	g.drawBackdrop(screen, float32(enemyX+enemySize/2), float32(enemyY+enemySize-2), float32(playerX+playerSize/2), float32(playerY+playerSize-4))
+	enemyScale := g.introEnemyScale()
+	drawBattler(screen, float32(enemyX)+g.introSlide()+g.shakeOffset(true), float32(enemyY), float32(enemySize), enemyScale, g.battle.enemyCreature.color)
 
-	// Draw enemy creature
-	enemySize := 40
-	enemyX := screenWidth/2 - enemySize/2
-	enemyY := 50
-	vector.DrawFilledRect(screen, float32(enemyX), float32(enemyY), float32(enemySize), float32(enemySize), g.battle.enemyCreature.color, true)
-
-	// Draw player creature
-	playerSize := 40
-	playerX := 50
-	playerY := screenHeight - 100
-	vector.DrawFilledRect(screen, float32(playerX), float32(playerY), float32(playerSize), float32(playerSize), g.battle.playerCreature.color, true)
+	// Draw player creature just above the battle UI; nobody fights in the safari zone
+	playerScale := g.introCreatureScale(IntroPlayerThrow)
+	if !g.battle.safari {
+		drawBattler(screen, float32(playerX)+g.shakeOffset(false), float32(playerY), float32(playerSize), playerScale, g.battle.playerCreature.color)
+	}
+	g.drawBattleIntro(screen, float32(enemyX+enemySize/2), float32(enemyY+enemySize), float32(playerX+playerSize/2), float32(playerY+playerSize))
 
 	// Draw battle UI
-	uiRect := image.Rect(0, screenHeight-70, screenWidth, screenHeight)
-	vector.DrawFilledRect(screen, float32(uiRect.Min.X), float32(uiRect.Min.Y), float32(uiRect.Dx()), float32(uiRect.Dy()), color.RGBA{50, 50, 50, 240}, true)
+	uiRect := image.Rect(0, uiTop, screenWidth, screenHeight)
+	g.drawPanel(screen, float32(uiRect.Min.X), float32(uiRect.Min.Y), float32(uiRect.Dx()), float32(uiRect.Dy()), color.RGBA{50, 50, 50, 240})
 
 	// Draw battle text
-	if g.battle.battleTextTimer > 0 {
-		op := &text.DrawOptions{}
-		op.GeoM.Translate(10, float64(screenHeight-50))
-		op.ColorScale.ScaleWithColor(color.White)
-		text.Draw(screen, g.battle.battleText, g.fontFace, op)
+	if g.battle.battleTextTimer > 0 || g.battle.intro != IntroDone || g.battle.victory.active {
+		for i, line := range g.wrapCached(g.battle.battleText, screenWidth-20) {
+			g.drawText(screen, line, 10, float64(uiTop+20+i*g.lineSpacing()), color.White)
+		}
+	} else if g.battle.currentTurn == 0 && g.battle.safari {
+		g.drawSafariActions(screen)
+	} else if g.battle.online != nil && g.battle.online.waiting {
+		g.drawText(screen, g.cachedLabel(labelKey{prefix: "Waiting for ", name: g.battle.online.opponent}, func() string {
+			return "Waiting for " + g.battle.online.opponent + "..."
+		}), 10, float64(uiTop+20), color.White)
 	} else if g.battle.currentTurn == 0 {
-		op := &text.DrawOptions{}
-		op.GeoM.Translate(10, float64(screenHeight-50))
-		op.ColorScale.ScaleWithColor(color.White)
-		text.Draw(screen, "What will "+g.battle.playerCreature.name+" do?", g.fontFace, op)
+		prompt := g.cachedLabel(labelKey{prefix: "What will ", name: g.battle.playerCreature.displayName()}, func() string {
+			return "What will " + g.battle.playerCreature.displayName() + " do?"
+		})
+		g.drawText(screen, prompt, 10, float64(uiTop+20), color.White)
+		if g.battle.online == nil {
+			g.drawText(screen, "C: Party", float64(screenWidth-10-g.textWidth("C: Party")), float64(uiTop+20), color.RGBA{200, 200, 200, 255})
+		}
 
 		// Draw move options
+		rects := g.moveRects()
 		for i, move := range g.battle.playerCreature.moves {
-			op := &text.DrawOptions{}
-			op.GeoM.Translate(30, float64(screenHeight-30+i*15))
-			op.ColorScale.ScaleWithColor(color.White)
-			text.Draw(screen, move.name, g.fontFace, op)
-
-			op2 := &text.DrawOptions{}
-			op2.GeoM.Translate(15, float64(screenHeight-30+i*15))
-			op2.ColorScale.ScaleWithColor(color.White)
+			g.drawText(screen, move.name, float64(rects[i].x+g.selectorWidth()), float64(rects[i].y), color.White)
+			g.drawText(screen, g.fractionLabel("PP ", move.pp, move.maxPP), float64(rects[i].x+rects[i].width), float64(rects[i].y), color.RGBA{200, 200, 200, 255})
+
 			// Highlight selected move
 			if i == g.battle.selectedAction {
-				text.Draw(screen, ">", g.fontFace, op2)
+				g.drawText(screen, ">", float64(rects[i].x), float64(rects[i].y), color.White)
 			}
 		}
 	}
 
+	if g.battle.online != nil {
+		g.drawTurnTimer(screen, uiTop)
+	}
+
 	// Draw HP bars
 	// Enemy HP
-	vector.DrawFilledRect(screen, float32(enemyX), float32(enemyY-15), float32(enemySize), 5, color.RGBA{100, 100, 100, 255}, true)
-	hpRatio := float32(g.battle.enemyCreature.hp) / float32(g.battle.enemyCreature.maxHP)
-	hpColor := color.RGBA{0, 255, 0, 255}
-	if hpRatio < 0.5 {
-		hpColor = color.RGBA{255, 255, 0, 255}
+	g.drawBattleAnimations(screen)
+
+	// Each creature's name and HP show once it's out
+	if enemyScale < 1 || g.battle.intro == IntroEnemyEnter {
+		return
+	}
+	if g.battle.boss != nil {
+		g.drawBossBar(screen)
+	} else if g.battle.raid != nil {
+		g.drawRaidBar(screen)
+	} else {
+		g.drawHPBar(screen, float32(enemyX), float32(enemyY-15), float32(enemySize), &g.battle.enemyBar, false)
+		g.drawText(screen, g.creatureLabel("", &g.battle.enemyCreature, g.battle.enemyCreature.level), float64(enemyX), float64(enemyY-25), color.White)
 	}
-	if hpRatio < 0.2 {
-		hpColor = color.RGBA{255, 0, 0, 255}
+
+	if g.battle.safari || playerScale < 1 {
+		return
 	}
-	vector.DrawFilledRect(screen, float32(enemyX), float32(enemyY-15), float32(enemySize)*hpRatio, 5, hpColor, true)
-	op := &text.DrawOptions{}
-	op.GeoM.Translate(float64(enemyX), float64(enemyY-25))
-	op.ColorScale.ScaleWithColor(color.White)
-	text.Draw(screen, g.battle.enemyCreature.name+" Lv."+string(rune(g.battle.enemyCreature.level+'0')), g.fontFace, op)
 
 	// Player HP
-	vector.DrawFilledRect(screen, float32(playerX), float32(playerY-15), float32(playerSize), 5, color.RGBA{100, 100, 100, 255}, true)
-	hpRatio = float32(g.battle.playerCreature.hp) / float32(g.battle.playerCreature.maxHP)
-	hpColor = color.RGBA{0, 255, 0, 255}
-	if hpRatio < 0.5 {
-		hpColor = color.RGBA{255, 255, 0, 255}
-	}
-	if hpRatio < 0.2 {
-		hpColor = color.RGBA{255, 0, 0, 255}
-	}
-	vector.DrawFilledRect(screen, float32(playerX), float32(playerY-15), float32(playerSize)*hpRatio, 5, hpColor, true)
-	op2 := &text.DrawOptions{}
-	op2.GeoM.Translate(float64(playerX), float64(playerY-25))
-	op2.ColorScale.ScaleWithColor(color.White)
-	text.Draw(screen, g.battle.playerCreature.name+" Lv."+string(rune(g.battle.playerCreature.level+'0')), g.fontFace, op2)
+	g.drawHPBar(screen, float32(playerX), float32(playerY-15), float32(playerSize), &g.battle.playerBar, true)
+	g.drawExpBar(screen, float32(playerX), float32(playerY-9), float32(playerSize))
+	level := g.battle.playerCreature.level
+	if g.battle.victory.active {
+		level = g.battle.victory.shownLevel
+	}
+	g.drawText(screen, g.creatureLabel("", g.battle.playerCreature, level), float64(playerX), float64(playerY-25), color.White)
+	if g.battle.raid != nil {
+		g.drawRaidAlly(screen)
+	}
+
+	g.drawLevelUpPanel(screen)
+}
+
+// drawBattler draws a creature in battle in a box of the given size at x, y,
+// scaled by scale about the middle of its bottom edge
+func drawBattler(screen *ebiten.Image, x, y, size, scale float32, clr color.RGBA) {
+	if scale <= 0 {
+		return
+	}
+	scaled := size * scale
+	vector.DrawFilledRect(screen, x+(size-scaled)/2, y+size-scaled, scaled, scaled, clr, true)
 }
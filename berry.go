@@ -0,0 +1,104 @@
+package main
+
+import (
+	"image/color"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// berryTrees are the berries that grow on trees around the overworld
+var berryTrees = []string{"Oran Berry", "Oran Berry", "Sitrus Berry"}
+
+// Harvest records when a berry tree was last picked
+type Harvest struct {
+	Step int   `json:"step"` // Step count at harvest
+	Time int64 `json:"time"` // Unix time at harvest
+}
+
+// placeBerryTrees plants a berry tree on grass beside a path in some chunks,
+// whose top-left tile is originX, originY
+func (m *Map) placeBerryTrees(c *Chunk, rng *rand.Rand, originX, originY int) {
+	if rng.Float32() >= 0.3 {
+		return
+	}
+
+	for y := 1; y < chunkSize-1; y++ {
+		for x := 1; x < chunkSize-1; x++ {
+			if c.tiles[LayerBase][y][x] != TileGrass || c.collisionMap.get(y*chunkSize+x) {
+				continue
+			}
+
+			// Only beside paths, so trees are easy to find and revisit
+			besidePath := c.tiles[LayerBase][y-1][x] == TilePath || c.tiles[LayerBase][y+1][x] == TilePath ||
+				c.tiles[LayerBase][y][x-1] == TilePath || c.tiles[LayerBase][y][x+1] == TilePath
+			if !besidePath || rng.Float32() >= 0.2 {
+				continue
+			}
+
+			pos := Point{originX + x, originY + y}
+			if m.objects[pos] == nil {
+				m.objects[pos] = &MapObject{kind: ObjectBerryTree, item: berryTrees[rng.Intn(len(berryTrees))]}
+			}
+			return
+		}
+	}
+}
+
+// regrown reports whether the berry tree or gathering spot at pos has
+// something to pick; they refill after either enough steps or enough real
+// time, depending on the item
+func (g *Game) regrown(pos Point, object *MapObject) bool {
+	h, ok := g.worldMap.harvested[pos]
+	if !ok {
+		return true
+	}
+
+	item := findItem(object.item)
+	if g.steps-h.Step >= item.regrowSteps {
+		return true
+	}
+	return time.Since(time.Unix(h.Time, 0)) >= time.Duration(item.regrowMinutes)*time.Minute
+}
+
+// harvestBerry picks the berries off a ripe tree
+func (g *Game) harvestBerry(pos Point, object *MapObject) {
+	if !g.regrown(pos, object) {
+		g.showDialogue("The " + object.item + " tree's berries are still growing.")
+		return
+	}
+
+	count := 1 + rand.Intn(3)
+	g.addItem(object.item, count)
+	g.worldMap.harvested[pos] = Harvest{Step: g.steps, Time: time.Now().Unix()}
+	g.showDialogue("Picked " + strconv.Itoa(count) + " " + object.item + "!")
+}
+
+// eatHeldBerry has a creature eat its held berry once its HP drops to half,
+// reporting whether it did
+func (c *Creature) eatHeldBerry() bool {
+	berry := findItem(c.heldItem)
+	if berry == nil || berry.category != ItemBerry || c.hp <= 0 || c.hp > c.maxHP/2 {
+		return false
+	}
+
+	c.hp = min(c.hp+berry.heal, c.maxHP)
+	c.heldItem = ""
+	return true
+}
+
+// drawBerryTree draws a berry tree, with berries showing when it's ripe
+func (g *Game) drawBerryTree(screen *ebiten.Image, x, y float32, ripe bool) {
+	// Trunk and leaves
+	vector.DrawFilledRect(screen, x-3, y, 6, 12, color.RGBA{100, 60, 30, 255}, true)
+	vector.DrawFilledCircle(screen, x, y-3, 11, color.RGBA{30, 120, 40, 255}, true)
+
+	if ripe {
+		for _, offset := range [][2]float32{{-5, -6}, {4, -2}, {-1, 2}, {5, -9}} {
+			vector.DrawFilledCircle(screen, x+offset[0], y+offset[1], 2.5, color.RGBA{60, 90, 230, 255}, true)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// bicycle is the key item the player rides
+const bicycle = "Bicycle"
+
+// bikeSpeedFactor is how many times faster than walking the bike goes on paths
+// and bridges
+const bikeSpeedFactor = 2
+
+// onRideableGround reports whether the player is on ground the bike goes fast
+// on: a path, or a bridge
+func (g *Game) onRideableGround() bool {
+	x, y := g.player.tileX, g.player.tileY
+	tile := g.worldMap.Tile(LayerBase, x, y)
+	return tile == TilePath || tile == TileBridge || g.worldMap.IsBridge(x, y)
+}
+
+// bikeBlocked returns why the player can't ride where they are, or "" if they
+// can
+func (g *Game) bikeBlocked() string {
+	switch {
+	case g.worldMap.static:
+		return "There's no room to ride a bike indoors."
+	case g.player.surfing:
+		return "You can't ride a bike on the water."
+	}
+	switch g.worldMap.Tile(LayerBase, g.player.tileX, g.player.tileY) {
+	case TileTallGrass, TileRustlingGrass:
+		return "The grass is too tall to ride through."
+	}
+	return ""
+}
+
+// toggleBike gets the player on or off the bike, returning what happened
+func (g *Game) toggleBike() string {
+	if g.player.riding {
+		g.player.riding = false
+		return "You got off the " + bicycle + "."
+	}
+	if g.bag[bicycle] == 0 {
+		return "You don't have a bike to ride."
+	}
+	if reason := g.bikeBlocked(); reason != "" {
+		return reason
+	}
+	g.player.riding = true
+	return "You got on the " + bicycle + "!"
+}
+
+// updateBike gets the player off the bike wherever it can't be ridden, as on
+// going indoors, into tall grass or out onto the water
+func (g *Game) updateBike() {
+	if g.player.riding && g.bikeBlocked() != "" {
+		g.player.riding = false
+		g.showToast("You got off the " + bicycle + ".")
+	}
+}
+
+// updateBikeMusic plays the bike's tune while the player rides, and the
+// biome's while they walk outdoors, leaving the music to the battle theme
+// during battles and off on the title screen
+func (g *Game) updateBikeMusic() {
+	name := ""
+	switch {
+	case g.gameState == StateBattle || g.gameState == StateMainMenu:
+	case g.player.riding:
+		name = "bike"
+	case !g.worldMap.static:
+		// On foot outdoors, the biome has its own tune
+		name = biomes[g.currentBiome].music
+	}
+	g.audio.playMusic(name)
+}
+
+// talkToBikeClerk gives the player a bike the first time they ask
+func (g *Game) talkToBikeClerk() {
+	if g.bag[bicycle] > 0 {
+		g.showDialogue("How's the bike treating you? It's fastest on paths and bridges.")
+		return
+	}
+	g.showPrompt("We've got a spare "+bicycle+" out back. Want it?", func() {
+		g.addItem(bicycle, 1)
+		g.showToast("Got a " + bicycle + "!")
+		g.audio.playSound("fanfare")
+		g.showDialogue("Press V or use it from the bag to ride. It won't fit indoors or through tall grass!")
+	})
+}
+
+// drawBike draws the bike under the player while they ride, its wheels lined
+// up the way they face
+func (g *Game) drawBike(screen *ebiten.Image) {
+	if !g.player.riding {
+		return
+	}
+	frame := color.RGBA{200, 40, 50, 255}
+	tire := color.RGBA{30, 30, 30, 255}
+	x, y := g.player.visualX-g.camera.x+tileSize/2, g.player.visualY-g.camera.y+tileSize-5
+	dx, dy := directionDelta(g.player.direction)
+	if dx != 0 {
+		vector.StrokeLine(screen, x-9, y, x+9, y, 2, frame, true)
+		vector.StrokeCircle(screen, x-9, y, 4, 2, tire, true)
+		vector.StrokeCircle(screen, x+9, y, 4, 2, tire, true)
+		return
+	}
+	// Seen end on, the wheels line up behind each other
+	vector.StrokeLine(screen, x, y-6, x, y+4, 2, frame, true)
+	vector.DrawFilledRect(screen, x-2, y-7+float32(dy), 4, 12, tire, true)
+	vector.StrokeLine(screen, x-6, y-6, x+6, y-6, 1.5, frame, true)
+}
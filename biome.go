@@ -0,0 +1,227 @@
+package main
+
+import (
+	"image/color"
+	"math/rand"
+)
+
+// Biome constants
+const (
+	BiomeGrassland = iota
+	BiomeForest
+	BiomeDesert
+	BiomeSwamp
+	BiomeSnowfield
+	BiomeVolcanic
+	BiomeCount
+)
+
+// biomeScale is how many tiles one unit of biome noise spans
+const biomeScale = 96.0
+
+// WeatherChance is a weighted weather option for a biome
+type WeatherChance struct {
+	weather int
+	weight  int
+}
+
+// Biome describes the look, terrain mix and wildlife of an area
+type Biome struct {
+	name string
+	// Colors used to draw each tile type
+	palette [TileCount]color.RGBA
+	// Multiplier on the region's water density
+	waterScale float32
+	// Mountain clusters added on top of the region's count
+	extraMountains int
+	// Chance of a grass tile becoming a tree
+	treeDensity float32
+	// Wild creatures that appear in the biome's grass
+	encounters []EncounterEntry
+	// Weather the biome tends to have
+	weather []WeatherChance
+	// Music track that plays while in the biome
+	music string
+}
+
+// biomes holds the data for every biome, indexed by biome constant
+var biomes = [BiomeCount]Biome{
+	BiomeGrassland: {
+		name: "Grassland",
+		palette: [TileCount]color.RGBA{
+			TileGrass:    {34, 139, 34, 255},
+			TilePath:     {210, 180, 140, 255},
+			TileWater:    {30, 144, 255, 255},
+			TileBridge:   {139, 69, 19, 255},
+			TileMountain: {105, 105, 105, 255},
+			TileTree:     {20, 90, 20, 255},
+			TileSand:     {238, 214, 175, 255},
+		},
+		waterScale:  1,
+		treeDensity: 0.03,
+		encounters: []EncounterEntry{
+			{species: "Sparkitty", minLevel: 3, maxLevel: 6, weight: 30},
+			{species: "Zephyrd", minLevel: 3, maxLevel: 6, weight: 30},
+			{species: "Flamepup", minLevel: 3, maxLevel: 5, weight: 20},
+			{species: "Bubblefrog", minLevel: 3, maxLevel: 5, weight: 20},
+			{species: "Bubblefrog", minLevel: 4, maxLevel: 6, weight: 30, when: EncounterCondition{weather: []int{WeatherRain}}},
+			{species: "Frostfox", minLevel: 4, maxLevel: 6, weight: 10, when: EncounterCondition{time: TimeNight}},
+			{species: "Pebblit", minLevel: 5, maxLevel: 7, weight: 15, when: EncounterCondition{rustle: true}},
+		},
+		weather: []WeatherChance{{WeatherClear, 70}, {WeatherRain, 30}},
+		music:   "grassland",
+	},
+	BiomeForest: {
+		name: "Forest",
+		palette: [TileCount]color.RGBA{
+			TileGrass:    {24, 110, 40, 255},
+			TilePath:     {150, 115, 75, 255},
+			TileWater:    {30, 110, 160, 255},
+			TileBridge:   {110, 60, 20, 255},
+			TileMountain: {90, 95, 85, 255},
+			TileTree:     {10, 60, 20, 255},
+			TileSand:     {200, 185, 140, 255},
+		},
+		waterScale:  0.8,
+		treeDensity: 0.25,
+		encounters: []EncounterEntry{
+			{species: "Leafling", minLevel: 4, maxLevel: 8, weight: 40},
+			{species: "Zephyrd", minLevel: 4, maxLevel: 7, weight: 30},
+			{species: "Sparkitty", minLevel: 4, maxLevel: 7, weight: 30, when: EncounterCondition{time: TimeDay}},
+			{species: "Bogtoad", minLevel: 5, maxLevel: 8, weight: 25, when: EncounterCondition{time: TimeNight}},
+			{species: "Frostfox", minLevel: 6, maxLevel: 9, weight: 15, when: EncounterCondition{rustle: true}},
+		},
+		weather: []WeatherChance{{WeatherClear, 50}, {WeatherRain, 30}, {WeatherFog, 20}},
+		music:   "forest",
+	},
+	BiomeDesert: {
+		name: "Desert",
+		palette: [TileCount]color.RGBA{
+			TileGrass:    {230, 200, 130, 255},
+			TilePath:     {200, 160, 100, 255},
+			TileWater:    {60, 170, 200, 255},
+			TileBridge:   {139, 90, 40, 255},
+			TileMountain: {170, 110, 70, 255},
+			TileTree:     {90, 150, 60, 255},
+			TileSand:     {245, 225, 170, 255},
+		},
+		waterScale:     0.3,
+		extraMountains: 1,
+		treeDensity:    0.01,
+		encounters: []EncounterEntry{
+			{species: "Sandcrab", minLevel: 6, maxLevel: 10, weight: 50},
+			{species: "Flamepup", minLevel: 6, maxLevel: 9, weight: 30},
+			{species: "Pebblit", minLevel: 6, maxLevel: 9, weight: 20},
+			{species: "Magmite", minLevel: 9, maxLevel: 12, weight: 15, when: EncounterCondition{weather: []int{WeatherSandstorm}}},
+			{species: "Sparkitty", minLevel: 8, maxLevel: 11, weight: 15, when: EncounterCondition{rustle: true}},
+		},
+		weather: []WeatherChance{{WeatherClear, 60}, {WeatherSandstorm, 40}},
+		music:   "desert",
+	},
+	BiomeSwamp: {
+		name: "Swamp",
+		palette: [TileCount]color.RGBA{
+			TileGrass:    {85, 107, 47, 255},
+			TilePath:     {110, 90, 60, 255},
+			TileWater:    {60, 90, 70, 255},
+			TileBridge:   {90, 60, 30, 255},
+			TileMountain: {80, 80, 70, 255},
+			TileTree:     {50, 70, 30, 255},
+			TileSand:     {150, 140, 100, 255},
+		},
+		waterScale:  1.5,
+		treeDensity: 0.08,
+		encounters: []EncounterEntry{
+			{species: "Bogtoad", minLevel: 5, maxLevel: 9, weight: 45},
+			{species: "Bubblefrog", minLevel: 5, maxLevel: 8, weight: 40},
+			{species: "Leafling", minLevel: 5, maxLevel: 8, weight: 15},
+			{species: "Zephyrd", minLevel: 6, maxLevel: 9, weight: 20, when: EncounterCondition{weather: []int{WeatherClear}}},
+			{species: "Frostfox", minLevel: 7, maxLevel: 10, weight: 15, when: EncounterCondition{rustle: true}},
+		},
+		weather: []WeatherChance{{WeatherFog, 50}, {WeatherRain, 40}, {WeatherClear, 10}},
+		music:   "swamp",
+	},
+	BiomeSnowfield: {
+		name: "Snowfield",
+		palette: [TileCount]color.RGBA{
+			TileGrass:    {235, 240, 250, 255},
+			TilePath:     {190, 195, 210, 255},
+			TileWater:    {150, 200, 230, 255},
+			TileBridge:   {120, 80, 50, 255},
+			TileMountain: {140, 150, 170, 255},
+			TileTree:     {40, 90, 70, 255},
+			TileSand:     {210, 210, 200, 255},
+		},
+		waterScale:     0.7,
+		extraMountains: 1,
+		treeDensity:    0.06,
+		encounters: []EncounterEntry{
+			{species: "Frostfox", minLevel: 8, maxLevel: 12, weight: 60},
+			{species: "Bubblefrog", minLevel: 8, maxLevel: 11, weight: 20},
+			{species: "Pebblit", minLevel: 8, maxLevel: 11, weight: 20},
+			{species: "Zephyrd", minLevel: 10, maxLevel: 13, weight: 15, when: EncounterCondition{rustle: true}},
+		},
+		weather: []WeatherChance{{WeatherSnow, 60}, {WeatherClear, 30}, {WeatherFog, 10}},
+		music:   "snowfield",
+	},
+	BiomeVolcanic: {
+		name: "Volcanic",
+		palette: [TileCount]color.RGBA{
+			TileGrass:    {70, 60, 60, 255},
+			TilePath:     {110, 90, 80, 255},
+			TileWater:    {220, 80, 20, 255}, // Lava
+			TileBridge:   {60, 50, 50, 255},
+			TileMountain: {50, 40, 40, 255},
+			TileTree:     {40, 35, 30, 255},
+			TileSand:     {30, 25, 25, 255},
+		},
+		waterScale:     0.6,
+		extraMountains: 2,
+		treeDensity:    0.02,
+		encounters: []EncounterEntry{
+			{species: "Magmite", minLevel: 10, maxLevel: 14, weight: 50},
+			{species: "Flamepup", minLevel: 10, maxLevel: 13, weight: 30},
+			{species: "Pebblit", minLevel: 10, maxLevel: 13, weight: 20},
+			{species: "Magmite", minLevel: 16, maxLevel: 20, weight: 15, when: EncounterCondition{minBadges: 3}},
+			{species: "Sandcrab", minLevel: 12, maxLevel: 15, weight: 15, when: EncounterCondition{rustle: true}},
+		},
+		weather: []WeatherChance{{WeatherClear, 50}, {WeatherFog, 50}},
+		music:   "volcanic",
+	},
+}
+
+// biomeFromClimate picks a biome from temperature and moisture noise values
+func biomeFromClimate(temperature, moisture float64) int {
+	switch {
+	case temperature < -0.2:
+		return BiomeSnowfield
+	case temperature > 0.25 && moisture < 0:
+		return BiomeVolcanic
+	case temperature > 0.1 && moisture < -0.05:
+		return BiomeDesert
+	case moisture > 0.2:
+		return BiomeSwamp
+	case moisture > 0.05:
+		return BiomeForest
+	default:
+		return BiomeGrassland
+	}
+}
+
+// rollWeather picks a weather type according to the biome's tendencies
+func (b *Biome) rollWeather() int {
+	total := 0
+	for _, chance := range b.weather {
+		total += chance.weight
+	}
+
+	roll := rand.Intn(total)
+	for _, chance := range b.weather {
+		if roll < chance.weight {
+			return chance.weather
+		}
+		roll -= chance.weight
+	}
+
+	return WeatherClear
+}
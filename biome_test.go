@@ -0,0 +1,19 @@
+package main
+
+import "testing"
+
+func TestBiomeMusic(t *testing.T) {
+	for _, b := range biomes {
+		if _, ok := musicTracks[b.music]; !ok {
+			t.Errorf("%s plays %q, which isn't a music track", b.name, b.music)
+		}
+	}
+}
+
+func TestBiomeMusicPlays(t *testing.T) {
+	g := newTestGame(t)
+	g.step()
+	if want := biomes[g.currentBiome].music; g.audio.musicName != want {
+		t.Errorf("walking in the %s plays %q, want %q", biomes[g.currentBiome].name, g.audio.musicName, want)
+	}
+}
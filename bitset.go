@@ -0,0 +1,23 @@
+package main
+
+// bitset is a fixed-size set of boolean flags packed into 64-bit words
+type bitset []uint64
+
+// newBitset creates a bitset able to hold n flags
+func newBitset(n int) bitset {
+	return make(bitset, (n+63)/64)
+}
+
+// get reports whether flag i is set
+func (b bitset) get(i int) bool {
+	return b[i>>6]&(1<<(uint(i)&63)) != 0
+}
+
+// set sets or clears flag i
+func (b bitset) set(i int, value bool) {
+	if value {
+		b[i>>6] |= 1 << (uint(i) & 63)
+	} else {
+		b[i>>6] &^= 1 << (uint(i) & 63)
+	}
+}
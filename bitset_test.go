@@ -0,0 +1,36 @@
+package main
+
+import "testing"
+
+func TestBitset(t *testing.T) {
+	b := newBitset(130)
+	for _, i := range []int{0, 63, 64, 129} {
+		b.set(i, true)
+		if !b.get(i) {
+			t.Errorf("flag %d not set", i)
+		}
+	}
+	if b.get(1) || b.get(65) || b.get(128) {
+		t.Error("unset flag reads as set")
+	}
+	b.set(64, false)
+	if b.get(64) || !b.get(63) {
+		t.Error("clearing a flag touched its neighbours")
+	}
+}
+
+// BenchmarkMovementChecks measures the tile flag lookups made on each step
+func BenchmarkMovementChecks(b *testing.B) {
+	g := &Game{}
+	g.initMap(1, TerrainNoise)
+	m := g.worldMap
+	n := 0
+	b.ResetTimer()
+	for i := range b.N {
+		x, y := g.player.tileX-16+i%32, g.player.tileY-16+i/32%32
+		if !m.IsCollision(x, y) && m.IsGrass(x, y) || m.IsBridge(x, y) {
+			n++
+		}
+	}
+	_ = n
+}
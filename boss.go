@@ -0,0 +1,169 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"image/color"
+	"log"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+//go:embed bosses/bosses.json
+var bossFiles embed.FS
+
+// Boss is a boss encounter, as configured in bosses/bosses.json: a single
+// strong creature with a long HP bar that changes how it fights as its HP
+// runs down
+type Boss struct {
+	Species string `json:"species"`
+	Level   int    `json:"level"`
+	// Shown on the boss HP bar alongside the species
+	Title string `json:"title"`
+	// How many times a normal creature's HP the boss has
+	HPScale float64 `json:"hpScale"`
+	// Line announcing the battle, in place of the usual one
+	Intro string `json:"intro"`
+	// Bosses can't be caught or run from unless these are set
+	Catchable bool             `json:"catchable,omitempty"`
+	Escapable bool             `json:"escapable,omitempty"`
+	Phases    []BossPhase      `json:"phases"`
+	Script    []BossScriptStep `json:"script,omitempty"`
+}
+
+// BossPhase is a change in how a boss fights, once its HP drops to a share
+// of its max. Stats rise by a percentage, and the moves replace its own.
+type BossPhase struct {
+	HP      float64  `json:"hp"`
+	Text    string   `json:"text"`
+	Attack  int      `json:"attack,omitempty"`
+	Defense int      `json:"defense,omitempty"`
+	Speed   int      `json:"speed,omitempty"`
+	Moves   []string `json:"moves,omitempty"`
+}
+
+// BossScriptStep is something that happens at the start of one of the boss's
+// turns, counting from 1: a line of text, and a share of its max HP healed
+type BossScriptStep struct {
+	Turn int     `json:"turn"`
+	Text string  `json:"text"`
+	Heal float64 `json:"heal,omitempty"`
+}
+
+// BossBattle is the state of a boss battle in progress
+type BossBattle struct {
+	boss *Boss
+	// Next phase and script step waiting to happen, and turns the boss has had
+	phase, step int
+	turn        int
+}
+
+// bosses holds every boss by ID, loaded from the embedded file
+var bosses = loadBosses()
+
+// loadBosses reads the bosses from bosses/bosses.json
+func loadBosses() map[string]*Boss {
+	var file map[string]*Boss
+	data, err := bossFiles.ReadFile("bosses/bosses.json")
+	if err == nil {
+		err = json.Unmarshal(data, &file)
+	}
+	if err != nil {
+		log.Fatalf("loading bosses: %v", err)
+	}
+	return file
+}
+
+// findMove looks up a move by name among every species' moves and learnsets,
+// and the move tutor's
+func findMove(name string) (Move, bool) {
+	for _, species := range speciesList {
+		for _, move := range species.moves {
+			if move.name == name {
+				return move, true
+			}
+		}
+		for _, learned := range species.learnset {
+			if learned.move.name == name {
+				return learned.move, true
+			}
+		}
+	}
+	return findTutorMove(name)
+}
+
+// startBossBattle starts a battle against a boss, with its HP scaled up
+func (g *Game) startBossBattle(id string) {
+	boss := bosses[id]
+	enemy := newCreature(boss.Species, boss.Level)
+	enemy.maxHP = int(float64(enemy.maxHP) * boss.HPScale)
+	enemy.hp = enemy.maxHP
+
+	g.setUpBattle(enemy)
+	g.battle.boss = &BossBattle{boss: boss}
+	if boss.Intro != "" {
+		g.battle.announcement = boss.Intro
+	}
+}
+
+// updateBoss moves a boss on to its next phase, or plays its script for the
+// turn, at the start of its turn. It reports whether anything happened, in
+// which case the boss attacks once the text has been shown.
+func (g *Game) updateBoss() bool {
+	b := g.battle.boss
+	enemy := &g.battle.enemyCreature
+
+	if b.phase < len(b.boss.Phases) {
+		phase := b.boss.Phases[b.phase]
+		if float64(enemy.hp) <= phase.HP*float64(enemy.maxHP) {
+			b.phase++
+			enemy.attack += enemy.attack * phase.Attack / 100
+			enemy.defense += enemy.defense * phase.Defense / 100
+			enemy.speed += enemy.speed * phase.Speed / 100
+			if len(phase.Moves) > 0 {
+				enemy.moves = nil
+				for _, name := range phase.Moves {
+					if move, ok := findMove(name); ok {
+						move.pp = move.maxPP
+						enemy.moves = append(enemy.moves, move)
+					}
+				}
+			}
+			g.battle.battleText = phase.Text
+			g.battle.battleTextTimer = 80
+			g.events.publish(Event{kind: EventHeavyHit, strength: 0.5})
+			return true
+		}
+	}
+
+	if b.step < len(b.boss.Script) && b.boss.Script[b.step].Turn <= b.turn+1 {
+		step := b.boss.Script[b.step]
+		b.step++
+		enemy.hp = min(enemy.hp+int(step.Heal*float64(enemy.maxHP)), enemy.maxHP)
+		g.battle.battleText = step.Text
+		g.battle.battleTextTimer = 60
+		return true
+	}
+
+	b.turn++
+	return false
+}
+
+// drawBossBar draws a boss's HP bar across the top of the screen, with a
+// notch where each phase starts
+func (g *Game) drawBossBar(screen *ebiten.Image) {
+	b := g.battle.boss
+	x, y, width := float32(20), float32(8+g.lineHeight()), float32(screenWidth-40)
+
+	g.drawText(screen, g.creatureLabel(b.boss.Title+": ", &g.battle.enemyCreature, g.battle.enemyCreature.level), float64(x), 4, color.White)
+
+	ratio := g.battle.enemyBar.shown(g.hpBarLength()) / float32(g.battle.enemyCreature.maxHP)
+	vector.DrawFilledRect(screen, x-1, y-1, width+2, 10, color.RGBA{20, 20, 20, 255}, true)
+	vector.DrawFilledRect(screen, x, y, width, 8, color.RGBA{100, 100, 100, 255}, true)
+	vector.DrawFilledRect(screen, x, y, width*ratio, 8, hpColor(ratio), true)
+	for _, phase := range b.boss.Phases {
+		notch := x + width*float32(phase.HP)
+		vector.DrawFilledRect(screen, notch-1, y-2, 2, 12, color.White, true)
+	}
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+)
+
+// Calendar constants
+const (
+	// framesPerDay is how much play makes an in-game day go by: 20 minutes
+	// at 60 updates a second
+	framesPerDay = 20 * 60 * 60
+	daysPerWeek  = 7
+	// Weekday the shops hold their market
+	marketDay = 2
+	// Digits in a creature's ID number
+	idDigits = 5
+)
+
+// weekdayNames names the days of the in-game week, starting from the day a
+// new game starts on
+var weekdayNames = [daysPerWeek]string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday", "Sunday"}
+
+// marketDeals are sold at half price on market day, a different one each week
+var marketDeals = []string{"Super Potion", "Capture Ball", "Super Repel", "Sitrus Berry", "Revive"}
+
+// LotteryPrize is what the lottery pays out for an ID matching the winning
+// number in its last few digits
+type LotteryPrize struct {
+	digits int
+	item   string
+	count  int
+}
+
+// lotteryPrizes are the lottery's prizes, best first
+var lotteryPrizes = []LotteryPrize{
+	{5, "Capture Ball", 20},
+	{4, "Super Repel", 5},
+	{3, "Sitrus Berry", 3},
+	{2, "Super Potion", 2},
+}
+
+// visitorGift is what the weekend visitor hands out, once a weekend
+const visitorGift = "Sitrus Berry"
+
+// Calendar is the in-game date, which moves on with playtime, and the
+// recurring events the player has already had today or this week
+type Calendar struct {
+	day    int
+	frames int
+	// Day the lottery was last drawn, and week the visitor last gave a gift
+	lotteryDay  int
+	visitorWeek int
+}
+
+// newCalendar starts the calendar on the first day, with nothing done yet
+func newCalendar() Calendar {
+	return Calendar{lotteryDay: -1, visitorWeek: -1}
+}
+
+// weekday returns the day of the week it is
+func (c *Calendar) weekday() int {
+	return c.day % daysPerWeek
+}
+
+// week returns how many weeks have gone by
+func (c *Calendar) week() int {
+	return c.day / daysPerWeek
+}
+
+// weekend reports whether it's Saturday or Sunday
+func (c *Calendar) weekend() bool {
+	return c.weekday() >= 5
+}
+
+// dateText describes the date, for showing to the player
+func (c *Calendar) dateText() string {
+	return "Day " + strconv.Itoa(c.day+1) + ", " + weekdayNames[c.weekday()]
+}
+
+// updateCalendar moves the date on with playtime, announcing each new day
+func (g *Game) updateCalendar() {
+	if g.gameState == StateMainMenu || g.gameState == StateNameEntry || g.gameState == StateStarter {
+		return
+	}
+	c := &g.calendar
+	c.frames++
+	if c.frames < framesPerDay {
+		return
+	}
+	c.day++
+	c.frames = 0
+	g.updateVisitors()
+	g.deliverDailyMail()
+
+	message := "It's now " + weekdayNames[c.weekday()] + "."
+	switch {
+	case c.weekday() == marketDay:
+		message = "It's market day! The shops have a deal on."
+	case c.weekend():
+		message = "It's the weekend. There's a visitor at the heal centers."
+	}
+	g.showToast(message)
+}
+
+// updateVisitors puts the weekend visitor in every heal center at the
+// weekend, and takes them away during the week
+func (g *Game) updateVisitors() {
+	for _, m := range g.maps {
+		if m.visitorSpot == nil {
+			continue
+		}
+		if g.calendar.weekend() {
+			m.objects[*m.visitorSpot] = &MapObject{kind: ObjectVisitor}
+		} else {
+			delete(m.objects, *m.visitorSpot)
+		}
+	}
+}
+
+// talkToMarketClerk offers the week's deal at half price on market day
+func (g *Game) talkToMarketClerk() {
+	c := &g.calendar
+	deal := findItem(marketDeals[c.week()%len(marketDeals)])
+	price := deal.price / 2
+	if c.weekday() != marketDay {
+		g.showDialogue("Our market's on " + weekdayNames[marketDay] + "s. This week you'll find " + deal.name + " at half price!")
+		return
+	}
+	if g.money < price {
+		g.showDialogue("It's market day! " + deal.name + " is $" + strconv.Itoa(price) + " today, but you can't afford it.")
+		return
+	}
+	g.showPrompt("It's market day! "+deal.name+" is half price: $"+strconv.Itoa(price)+". Buy one?", func() {
+		g.money -= price
+		g.addItem(deal.name, 1)
+		g.showToast("Bought a " + deal.name + "!")
+	})
+}
+
+// lotteryNumber returns the day's winning lottery number, the same for
+// everyone playing the same world
+func (g *Game) lotteryNumber(day int) int {
+	rng := rand.New(rand.NewSource(g.maps[overworldID].seed + int64(day)))
+	return rng.Intn(100000)
+}
+
+// matchingDigits counts how many of the last digits of two ID numbers match
+func matchingDigits(a, b int) int {
+	count := 0
+	for count < idDigits && a%10 == b%10 {
+		a, b = a/10, b/10
+		count++
+	}
+	return count
+}
+
+// drawLottery draws the day's lottery number against every creature the
+// player has, once a day, and pays out for the best match
+func (g *Game) drawLottery() {
+	c := &g.calendar
+	if c.lotteryDay == c.day {
+		g.showDialogue("The lottery has already been drawn today. Come back tomorrow!")
+		return
+	}
+	c.lotteryDay = c.day
+
+	winning := g.lotteryNumber(c.day)
+	best, bestName := 0, ""
+	for _, creature := range append(append([]Creature(nil), g.creatures...), g.storage...) {
+		if digits := matchingDigits(creature.id, winning); digits > best {
+			best, bestName = digits, creature.name
+		}
+	}
+
+	message := fmt.Sprintf("Today's number is %05d!", winning)
+	for _, prize := range lotteryPrizes {
+		if best >= prize.digits {
+			g.addItem(prize.item, prize.count)
+			g.showDialogue(message + " " + bestName + "'s ID matches the last " + strconv.Itoa(best) + " digits! You win " +
+				strconv.Itoa(prize.count) + " " + prize.item + "!")
+			g.audio.playSound("fanfare")
+			return
+		}
+	}
+	g.showDialogue(message + " None of your creatures' IDs match. Better luck tomorrow!")
+}
+
+// talkToVisitor hands out the weekend visitor's gift, once a weekend
+func (g *Game) talkToVisitor() {
+	c := &g.calendar
+	if c.visitorWeek == c.week() {
+		g.showDialogue("I travel all week and only stop here at weekends. See you next weekend!")
+		return
+	}
+	c.visitorWeek = c.week()
+
+	// The first time they have room for it, the player gets an egg instead
+	if !g.flags[visitorEggFlag] && len(g.creatures) < partySize {
+		g.flags[visitorEggFlag] = true
+		g.giveEgg()
+		g.showDialogue("I'm only in town at weekends. I found this egg on my travels - will you look after it? Keep it in your party and walk with it, and it'll hatch before long.")
+		return
+	}
+	g.addItem(visitorGift, 1)
+	g.showDialogue("I'm only in town at weekends. Here, take this " + visitorGift + " from my travels!")
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"image/color"
+	"math"
+	"math/rand"
+	"strconv"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Card flip constants
+const (
+	// Tokens each card costs to play
+	cardFlipCost = 2
+	// Numbers on the cards of each suit
+	cardNumbers = 6
+	// Cards dealt before the deck is shuffled again
+	cardsPerShuffle = 12
+	// Frames a card takes to turn over
+	cardFlipFrames = 20
+	// Tokens paid for guessing the card exactly, its number or its suit
+	cardExactPayout  = 36
+	cardNumberPayout = 10
+	cardSuitPayout   = 6
+	// Size of a cell in the guessing grid
+	cardCellWidth = 22
+)
+
+// cardSuits are the types the cards come in, with their colors
+var cardSuits = []struct {
+	name  string
+	color color.RGBA
+}{
+	{"Fire", color.RGBA{230, 90, 40, 255}},
+	{"Water", color.RGBA{60, 120, 230, 255}},
+	{"Grass", color.RGBA{70, 170, 70, 255}},
+	{"Electric", color.RGBA{230, 200, 40, 255}},
+}
+
+// CardFlip is the card flip screen, one of the game corner's games. The
+// player guesses the next card off the deck, by its suit, its number or
+// both, on a grid with a row for each suit and a column for each number.
+// Cards already dealt since the last shuffle are marked, for players who
+// keep count.
+type CardFlip struct {
+	deck []int
+	// Cards dealt since the last shuffle
+	dealt map[int]bool
+	// Cell of the grid highlighted; row -1 is the numbers along the top and
+	// column -1 the suits down the side
+	row, column int
+	// Card being turned over, or -1, and how far it has turned
+	card   int
+	frames int
+	// What was guessed for it, and what it paid
+	guess   [2]int
+	message string
+}
+
+// openCardFlip switches to the card flip screen with a freshly shuffled deck
+func (g *Game) openCardFlip() {
+	g.gameState = StateCardFlip
+	g.cardFlip = CardFlip{card: -1}
+	g.cardFlip.shuffle()
+}
+
+// shuffle puts every card back in the deck in a random order
+func (c *CardFlip) shuffle() {
+	c.deck = rand.Perm(len(cardSuits) * cardNumbers)
+	c.dealt = make(map[int]bool)
+}
+
+// cardPayout returns what a guess of a suit and number pays for a card,
+// either of which is -1 if it's left out
+func cardPayout(suit, number, card int) int {
+	suitMatch, numberMatch := suit == card/cardNumbers, number == card%cardNumbers
+	switch {
+	case suit >= 0 && number >= 0:
+		if suitMatch && numberMatch {
+			return cardExactPayout
+		}
+	case suit >= 0:
+		if suitMatch {
+			return cardSuitPayout
+		}
+	case number >= 0:
+		if numberMatch {
+			return cardNumberPayout
+		}
+	}
+	return 0
+}
+
+// updateCardFlip moves around the guessing grid and deals a card for the
+// guess picked, paying out once it's turned over
+func (g *Game) updateCardFlip() {
+	c := &g.cardFlip
+	if c.card >= 0 && c.frames < cardFlipFrames {
+		c.frames++
+		if c.frames == cardFlipFrames {
+			g.settleCard()
+		}
+		return
+	}
+
+	if g.input.IsActionJustPressed(ebiten.KeyEscape) {
+		g.gameState = StateOverworld
+		return
+	}
+	if g.input.IsActionJustPressed(ebiten.KeyUp) {
+		c.row = max(c.row-1, -1)
+	} else if g.input.IsActionJustPressed(ebiten.KeyDown) {
+		c.row = min(c.row+1, len(cardSuits)-1)
+	}
+	if g.input.IsActionJustPressed(ebiten.KeyLeft) {
+		c.column = max(c.column-1, -1)
+	} else if g.input.IsActionJustPressed(ebiten.KeyRight) {
+		c.column = min(c.column+1, cardNumbers-1)
+	}
+
+	// The corner isn't a guess
+	if c.row < 0 && c.column < 0 || !g.input.IsActionJustPressed(ebiten.KeySpace) && !g.input.IsActionJustPressed(ebiten.KeyEnter) {
+		return
+	}
+	if g.tokens < cardFlipCost {
+		c.message = "You don't have enough tokens. Buy more at the prize counter."
+		return
+	}
+	g.tokens -= cardFlipCost
+	c.card, c.deck = c.deck[0], c.deck[1:]
+	c.frames, c.guess, c.message = 0, [2]int{c.row, c.column}, ""
+	g.audio.playSound("ball")
+}
+
+// settleCard pays out for the card just turned over, and marks it dealt
+func (g *Game) settleCard() {
+	c := &g.cardFlip
+	c.dealt[c.card] = true
+	won := cardPayout(c.guess[0], c.guess[1], c.card)
+	switch {
+	case won == 0:
+		c.message = "No match. Better luck with the next card!"
+	case won == cardExactPayout:
+		c.message = "Spot on! You won " + strconv.Itoa(won) + " tokens!"
+		g.audio.playSound("fanfare")
+	default:
+		c.message = "A match! You won " + strconv.Itoa(won) + " tokens."
+		g.audio.playSound("heal")
+	}
+	g.addTokens(won)
+	if len(c.dealt) >= cardsPerShuffle {
+		c.shuffle()
+		c.message += " The dealer shuffles the deck."
+	}
+}
+
+// drawCardFlip draws the guessing grid, with the cards dealt since the last
+// shuffle marked, and the card being turned over beside it
+func (g *Game) drawCardFlip(screen *ebiten.Image) {
+	c := &g.cardFlip
+	g.drawPanel(screen, 10, 10, float32(screenWidth-20), float32(screenHeight-20), color.RGBA{30, 90, 60, 240})
+	g.drawText(screen, "Card Flip", 20, 30, color.White)
+	tokens := g.tokensText()
+	g.drawText(screen, tokens, float64(screenWidth-20-g.textWidth(tokens)), 30, color.RGBA{255, 220, 120, 255})
+
+	highlight := color.RGBA{255, 255, 0, 255}
+	labelWidth := 0
+	for _, suit := range cardSuits {
+		labelWidth = max(labelWidth, g.textWidth(suit.name))
+	}
+	left, top := 20+labelWidth+6, g.listTop()
+	for number := range cardNumbers {
+		clr := color.Color(color.White)
+		if c.row < 0 && c.column == number {
+			clr = highlight
+		}
+		label := strconv.Itoa(number + 1)
+		g.drawText(screen, label, float64(left+number*cardCellWidth+(cardCellWidth-g.textWidth(label))/2), float64(top), clr)
+	}
+	for row, suit := range cardSuits {
+		y := top + (row+1)*g.rowHeight()
+		clr := color.Color(suit.color)
+		if c.column < 0 && c.row == row {
+			clr = highlight
+		}
+		g.drawText(screen, suit.name, 20, float64(y), clr)
+		for number := range cardNumbers {
+			x := float32(left + number*cardCellWidth)
+			card := row*cardNumbers + number
+			fill := suit.color
+			if c.dealt[card] {
+				fill = color.RGBA{70, 70, 70, 255}
+			}
+			vector.DrawFilledRect(screen, x+3, float32(y), cardCellWidth-6, float32(g.lineHeight()), fill, false)
+			// The whole row or column of a suit or number guess lights up
+			if (c.row == row || c.row < 0) && (c.column == number || c.column < 0) {
+				vector.StrokeRect(screen, x+2, float32(y)-1, cardCellWidth-4, float32(g.lineHeight()+2), 1, highlight, false)
+			}
+		}
+	}
+
+	payouts := "Pays " + strconv.Itoa(cardExactPayout) + " for the card, " + strconv.Itoa(cardNumberPayout) +
+		" for the number, " + strconv.Itoa(cardSuitPayout) + " for the suit"
+	y := top + (len(cardSuits)+1)*g.rowHeight() + 4
+	for i, line := range g.wrapCached(payouts, screenWidth-40) {
+		g.drawText(screen, line, 20, float64(y+i*g.lineSpacing()), color.RGBA{200, 230, 200, 255})
+	}
+
+	// The card being turned over narrows to its edge and opens out face up
+	if c.card >= 0 {
+		cx := float32(screenWidth - 60)
+		cy := float32(top + 40)
+		turn := float64(c.frames) / cardFlipFrames
+		if g.settings.Animation == AnimationOff {
+			turn = 1
+		}
+		width := float32(40 * math.Abs(math.Cos(turn*math.Pi)))
+		if turn < 0.5 {
+			vector.DrawFilledRect(screen, cx-width/2, cy-28, width, 56, color.RGBA{150, 40, 50, 255}, true)
+		} else {
+			suit := cardSuits[c.card/cardNumbers]
+			vector.DrawFilledRect(screen, cx-width/2, cy-28, width, 56, color.RGBA{250, 250, 245, 255}, true)
+			vector.DrawFilledCircle(screen, cx, cy-6, min32(width/3, 10), suit.color, true)
+			if width > 30 {
+				number := strconv.Itoa(c.card%cardNumbers + 1)
+				g.drawText(screen, number, float64(cx)-float64(g.textWidth(number))/2, float64(cy)+8, color.RGBA{30, 30, 30, 255})
+			}
+		}
+	}
+
+	hint := "Arrows to pick a suit, number or card, Space to deal, ESC to leave"
+	hintTop := g.drawHint(screen, hint)
+	lines := g.wrapText(c.message, screenWidth-40)
+	for i, line := range lines {
+		g.drawText(screen, line, 20, float64(hintTop-(len(lines)-i)*g.lineSpacing()), color.RGBA{255, 255, 180, 255})
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"image/color"
+	"math/rand"
+	"strconv"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Chain constants
+const (
+	// One in this many wild creatures is shiny with no chain going
+	shinyOdds = 1024
+	// Every this many of a species in a row adds the base shiny chance again
+	// and makes one more stat perfect, up to chainMaxBonus times
+	chainStep     = 10
+	chainMaxBonus = 4
+	// Highest individual value a stat can have
+	maxIV = 15
+)
+
+// Stats a creature has individual values for
+const (
+	StatHP = iota
+	StatAttack
+	StatDefense
+	StatSpeed
+	StatCount
+)
+
+// Chain is a run of wild creatures of one species beaten or caught in a row.
+// Beating other species leaves it be; catching one starts a new chain, and
+// running from a battle ends it.
+type Chain struct {
+	species string
+	count   int
+}
+
+// subscribeChain keeps the chain going as wild creatures are beaten and
+// caught, and breaks it when the player runs
+func (g *Game) subscribeChain() {
+	g.events.subscribe(EventDefeat, func(e Event) {
+		if g.battle.trainer == "" {
+			g.extendChain(e.species, false)
+		}
+	})
+	g.events.subscribe(EventCapture, func(e Event) { g.extendChain(e.species, true) })
+	g.events.subscribe(EventFlee, func(Event) {
+		if g.chain.count > 1 {
+			g.showToast("The " + g.chain.species + " chain was broken.")
+		}
+		g.chain = Chain{}
+	})
+}
+
+// extendChain counts a wild creature beaten or caught towards the chain
+func (g *Game) extendChain(species string, caught bool) {
+	switch {
+	case species == g.chain.species:
+		g.chain.count++
+	case g.chain.count == 0 || caught:
+		g.chain = Chain{species: species, count: 1}
+	}
+}
+
+// chainBonus returns how many steps of bonus the chain gives a species
+func (g *Game) chainBonus(species string) int {
+	if species != g.chain.species {
+		return 0
+	}
+	return min(g.chain.count/chainStep, chainMaxBonus)
+}
+
+// rollTraits rolls a wild creature's individual values, nature and whether
+// it's shiny, the values and shiny chance better the longer the chain of its
+// species
+func (g *Game) rollTraits(c *Creature) {
+	bonus := g.chainBonus(c.name)
+	for i := range c.ivs {
+		c.ivs[i] = rand.Intn(maxIV + 1)
+	}
+	for _, stat := range rand.Perm(StatCount)[:bonus] {
+		c.ivs[stat] = maxIV
+	}
+	c.nature = rand.Intn(len(natures))
+	c.applyTraits()
+
+	if rand.Intn(shinyOdds) <= bonus {
+		c.makeShiny()
+	}
+}
+
+// ivBonus returns how much an individual value adds to a stat at a level,
+// growing with the level as the stat does
+func ivBonus(iv, level int) int {
+	return iv * (level + 10) / 60
+}
+
+// makeShiny turns a creature into its rare shiny colors
+func (c *Creature) makeShiny() {
+	c.shiny = true
+	c.color = shinyColor(c.color)
+}
+
+// shinyColor returns a shiny creature's color, the regular one with its
+// channels turned around
+func shinyColor(clr color.RGBA) color.RGBA {
+	return color.RGBA{clr.B, clr.R, clr.G, clr.A}
+}
+
+// drawChain shows the chain going under the repel counter
+func (g *Game) drawChain(screen *ebiten.Image) {
+	if g.chain.count == 0 {
+		return
+	}
+	label := g.cachedLabel(labelKey{prefix: "Chain ", name: g.chain.species, a: g.chain.count}, func() string {
+		return g.chain.species + " chain " + strconv.Itoa(g.chain.count)
+	})
+	g.drawText(screen, label, float64(screenWidth-5-g.textWidth(label)), float64(g.hudHeight()+5+g.lineSpacing()), color.RGBA{255, 230, 120, 220})
+}
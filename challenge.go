@@ -0,0 +1,319 @@
+package main
+
+import (
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image/color"
+	"log"
+	"math/rand"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"creaturegame-2/engine"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Challenge rules, which combine as flags
+const (
+	RuleNoItems = 1 << iota
+	RuleNoSwitching
+)
+
+// challengeRulesets name each combination of challenge rules, indexed by
+// their flags
+var challengeRulesets = []string{"Standard", "No items", "No switching", "No items or switching"}
+
+// Challenge code constants
+const (
+	challengeVersion = 2
+	// Longest code that can be typed in, dashes and all
+	challengeCodeMaxLen = 72
+	// Letters and digits codes are written in, leaving out ones easily
+	// mistaken for each other
+	challengeAlphabet = "23456789ABCDEFGHJKLMNPQRSTUVWXYZ"
+)
+
+// challengeEncoding writes challenge codes
+var challengeEncoding = base32.NewEncoding(challengeAlphabet).WithPadding(base32.NoPadding)
+
+// challengeMenuOptions are the choices on the challenge screen; the first
+// shows the ruleset codes are made with
+var challengeMenuOptions = []string{"Rules", "Ruleset", "Make a code", "Enter a code", "Back"}
+
+// Challenge is a battle setup shared as a code: the team to fight, the
+// highest level allowed on the player's side, the rules and the ruleset the
+// player's side is held to, and the seed every roll in the battle comes
+// from, so everyone taking it fights the same battle
+type Challenge struct {
+	seed     uint32
+	levelCap int
+	rules    int
+	// Index into engine.Rulesets
+	ruleset int
+	team    []ChallengeMember
+}
+
+// ChallengeMember is one creature of a challenge's team, which knows the
+// moves its species starts with
+type ChallengeMember struct {
+	species, form string
+	level         int
+}
+
+// ChallengeBattle is a challenge being fought. The party is put back as it
+// was afterwards, win or lose.
+type ChallengeBattle struct {
+	rules   int
+	ruleset engine.Ruleset
+	party   []Creature
+}
+
+// makeChallenge makes a challenge of the player's party, capped at the level
+// of its strongest creature. Eggs and fused creatures, which a code can't
+// describe, are left out. The team has to keep to the ruleset it's made
+// with, as whoever takes it will.
+func (g *Game) makeChallenge(rules, ruleset int) (Challenge, error) {
+	c := Challenge{seed: rand.Uint32(), rules: rules, ruleset: ruleset}
+	var team []engine.Fighter
+	for _, creature := range g.creatures {
+		species := findSpecies(creature.name)
+		if creature.egg || species == nil || species.fusedFrom[0] != "" {
+			continue
+		}
+		c.team = append(c.team, ChallengeMember{species: creature.name, form: creature.form, level: creature.level})
+		c.levelCap = max(c.levelCap, creature.level)
+		team = append(team, creature.fighter())
+	}
+	if len(c.team) == 0 {
+		return Challenge{}, errors.New("there's no one in the party to share")
+	}
+	if err := engine.Rulesets[ruleset].Check(team); err != nil {
+		return Challenge{}, err
+	}
+	return c, nil
+}
+
+// code writes a challenge as a code to pass around: the version, seed, level
+// cap, rules, ruleset, then each creature's species, form and level, and a
+// check byte to catch typing mistakes, split into groups of four
+func (c Challenge) code() string {
+	data := []byte{challengeVersion}
+	data = binary.BigEndian.AppendUint32(data, c.seed)
+	data = append(data, byte(c.levelCap), byte(c.rules), byte(c.ruleset), byte(len(c.team)))
+	for _, m := range c.team {
+		species := slices.IndexFunc(speciesList, func(s Species) bool { return s.name == m.species })
+		form := slices.IndexFunc(speciesList[species].forms, func(f SpeciesForm) bool { return f.name == m.form }) + 1
+		data = append(data, byte(species), byte(form), byte(m.level))
+	}
+	data = append(data, byte(crc32.ChecksumIEEE(data)))
+
+	encoded := challengeEncoding.EncodeToString(data)
+	var groups []string
+	for len(encoded) > 4 {
+		groups = append(groups, encoded[:4])
+		encoded = encoded[4:]
+	}
+	return strings.Join(append(groups, encoded), "-")
+}
+
+// parseChallenge reads a challenge code, ignoring case, spaces and dashes
+func parseChallenge(code string) (Challenge, error) {
+	code = strings.NewReplacer("-", "", " ", "").Replace(strings.ToUpper(code))
+	data, err := challengeEncoding.DecodeString(code)
+	if err != nil || len(data) < 10 {
+		return Challenge{}, errors.New("that isn't a challenge code")
+	}
+	body, check := data[:len(data)-1], data[len(data)-1]
+	if byte(crc32.ChecksumIEEE(body)) != check {
+		return Challenge{}, errors.New("the code has a typo in it")
+	}
+	if body[0] != challengeVersion {
+		return Challenge{}, errors.New("the code is from another version of the game")
+	}
+
+	c := Challenge{seed: binary.BigEndian.Uint32(body[1:5]), levelCap: int(body[5]), rules: int(body[6]), ruleset: int(body[7])}
+	count := int(body[8])
+	members := body[9:]
+	if count == 0 || count > partySize || len(members) != count*3 || c.rules >= len(challengeRulesets) || c.ruleset >= len(engine.Rulesets) {
+		return Challenge{}, errors.New("the code doesn't describe a battle")
+	}
+	for i := 0; i < len(members); i += 3 {
+		species, form, level := int(members[i]), int(members[i+1]), int(members[i+2])
+		if species >= len(speciesList) || form > len(speciesList[species].forms) || level < 1 || level > c.levelCap {
+			return Challenge{}, errors.New("the code doesn't describe a battle")
+		}
+		m := ChallengeMember{species: speciesList[species].name, level: level}
+		if form > 0 {
+			m.form = speciesList[species].forms[form-1].name
+		}
+		c.team = append(c.team, m)
+	}
+	return c, nil
+}
+
+// startChallenge starts the battle a challenge describes, as long as the
+// party is within its level cap and keeps to its ruleset
+func (g *Game) startChallenge(c Challenge) error {
+	ruleset := engine.Rulesets[c.ruleset]
+	var team []engine.Fighter
+	for _, creature := range g.creatures {
+		if creature.egg {
+			continue
+		}
+		if creature.level > c.levelCap {
+			return errors.New(creature.name + " is over the level cap of " + strconv.Itoa(c.levelCap) + ".")
+		}
+		team = append(team, creature.fighter())
+	}
+	if err := ruleset.Check(team); err != nil {
+		return errors.New(err.Error() + ".")
+	}
+	if g.nextHealthyCreature() == nil {
+		return errors.New("none of your creatures can battle")
+	}
+
+	// Every creature is copied with its moves, whose PP the battle uses up
+	party := slices.Clone(g.creatures)
+	for i := range party {
+		party[i].moves = slices.Clone(party[i].moves)
+	}
+
+	trainer := Trainer{name: "Challenger", clothes: color.RGBA{200, 60, 160, 255}}
+	for _, m := range c.team {
+		creature := newCreatureForm(m.species, m.form, m.level)
+		creature.trainer = trainer.name
+		trainer.team = append(trainer.team, creature)
+	}
+
+	battleRand.Seed(int64(c.seed))
+	g.startTrainerBattle(trainer)
+	g.battle.challenge = &ChallengeBattle{rules: c.rules, ruleset: ruleset, party: party}
+	g.battle.announcement = "The challenge begins! " + challengeRulesets[c.rules] + " rules, under the " + ruleset.Name + " ruleset."
+	return nil
+}
+
+// challengeForbids reports whether the challenge being fought has a rule,
+// saying so if it has
+func (g *Game) challengeForbids(rule int, message string) bool {
+	if g.battle.challenge == nil || g.battle.challenge.rules&rule == 0 {
+		return false
+	}
+	g.battle.battleText = message
+	g.battle.battleTextTimer = 40
+	return true
+}
+
+// partyMoveEffect rolls the effect of the enemy's move on a party creature,
+// unless the sleep clause of the challenge's ruleset stops it as another of
+// the party already has a status
+func (g *Game) partyMoveEffect(move Move, target *Creature) string {
+	if c := g.battle.challenge; c != nil {
+		team, index := make([]engine.Fighter, len(g.creatures)), -1
+		for i := range g.creatures {
+			team[i] = g.creatures[i].fighter()
+			if &g.creatures[i] == target {
+				index = i
+			}
+		}
+		if c.ruleset.BlocksStatus(team, index) {
+			return ""
+		}
+	}
+	return applyMoveEffect(move, target)
+}
+
+// finishChallenge puts the party back as it was before the challenge and
+// says how it went
+func (g *Game) finishChallenge(won bool) {
+	g.creatures = g.battle.challenge.party
+	g.battle.challenge = nil
+	battleRand.Seed(time.Now().UnixNano())
+	if won {
+		g.showDialogue("You won the challenge!")
+	} else {
+		g.showDialogue("The challenge was too much this time.")
+	}
+}
+
+// updateChallengeMenu handles the challenge screen, reached from the trade
+// screen, for making a code of the party or typing one in
+func (g *Game) updateChallengeMenu() {
+	t := &g.trade
+	if g.input.IsActionJustPressed(ebiten.KeyUp) {
+		t.selected = (t.selected - 1 + len(challengeMenuOptions)) % len(challengeMenuOptions)
+	} else if g.input.IsActionJustPressed(ebiten.KeyDown) {
+		t.selected = (t.selected + 1) % len(challengeMenuOptions)
+	}
+	if g.input.IsActionJustPressed(ebiten.KeyEscape) {
+		t.stage, t.selected, t.status = TradeMenu, 0, ""
+		return
+	}
+	if !g.input.IsActionJustPressed(ebiten.KeySpace) && !g.input.IsActionJustPressed(ebiten.KeyEnter) {
+		return
+	}
+
+	switch challengeMenuOptions[t.selected] {
+	case "Rules":
+		t.rules = (t.rules + 1) % len(challengeRulesets)
+	case "Ruleset":
+		t.ruleset = (t.ruleset + 1) % len(engine.Rulesets)
+		t.status = engine.Rulesets[t.ruleset].Name + ": " + engine.Rulesets[t.ruleset].Describe() + "."
+	case "Make a code":
+		c, err := g.makeChallenge(t.rules, t.ruleset)
+		if err != nil {
+			t.status = "Couldn't make a code: " + err.Error()
+			return
+		}
+		code := c.code()
+		log.Println("Challenge code:", code)
+		t.status = "Your code: " + code
+	case "Enter a code":
+		t.code, t.status = "", ""
+		t.stage = TradeCode
+	case "Back":
+		t.stage, t.selected, t.status = TradeMenu, 0, ""
+	}
+}
+
+// updateChallengeCode handles typing in a challenge code, starting the
+// battle once a good one is entered
+func (g *Game) updateChallengeCode() {
+	t := &g.trade
+	g.typeText(&t.code, challengeCodeMaxLen)
+	if g.input.IsKeyJustPressed(ebiten.KeyEscape) {
+		t.stage = TradeChallenge
+		return
+	}
+	if !g.input.IsKeyJustPressed(ebiten.KeyEnter) {
+		return
+	}
+
+	c, err := parseChallenge(t.code)
+	if err != nil {
+		t.status = "Couldn't read the code: " + err.Error()
+		return
+	}
+	g.closeTrade("")
+	if err := g.startChallenge(c); err != nil {
+		g.showDialogue("You can't take this challenge: " + err.Error())
+	}
+}
+
+// drawChallengeMenu draws the challenge screen's options, with the rules and
+// ruleset codes are made with
+func (g *Game) drawChallengeMenu(screen *ebiten.Image) {
+	t := &g.trade
+	for i, option := range challengeMenuOptions {
+		switch option {
+		case "Rules":
+			option = "Rules: " + challengeRulesets[t.rules]
+		case "Ruleset":
+			option = "Ruleset: " + engine.Rulesets[t.ruleset].Name
+		}
+		g.drawTradeLine(screen, option, 40, 50+i*20, i == t.selected)
+	}
+}
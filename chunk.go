@@ -0,0 +1,214 @@
+package main
+
+import "math/rand"
+
+// Chunk streaming constants
+const (
+	// chunkSize is the width and height of a chunk in tiles
+	chunkSize = 16
+	// chunkLoadMargin is how many chunks beyond the visible area are kept generated
+	chunkLoadMargin = 1
+	// chunkUnloadMargin is how many chunks beyond the visible area may stay in memory
+	chunkUnloadMargin = 3
+)
+
+// Chunk is a fixed-size square of tiles that is generated independently
+type Chunk struct {
+	tiles [LayerCount][chunkSize][chunkSize]int
+	// Tile property flags, indexed by y*chunkSize+x in chunk-local coordinates
+	grassTiles   bitset
+	bridgeTiles  bitset
+	collisionMap bitset
+	// Biome of each tile
+	biomes [chunkSize][chunkSize]uint8
+	// Tiles drawn to images, made the first time the chunk is on screen
+	cache *TileCache
+	// Logs floating across gaps in the chunk's water
+	platforms []*Platform
+}
+
+// newChunk creates an empty all-grass chunk
+func newChunk() *Chunk {
+	c := &Chunk{
+		grassTiles:   newBitset(chunkSize * chunkSize),
+		bridgeTiles:  newBitset(chunkSize * chunkSize),
+		collisionMap: newBitset(chunkSize * chunkSize),
+	}
+
+	// Every tile starts as grass, so mark it for encounter checks
+	for y := range chunkSize {
+		for x := range chunkSize {
+			c.setGrass(x, y, true)
+		}
+	}
+
+	return c
+}
+
+// generateChunk builds the chunk at chunk coordinate cx, cy from the map seed
+func (m *Map) generateChunk(cx, cy int) *Chunk {
+	// Mix the chunk coordinate into the seed so every chunk is reproducible
+	rng := rand.New(rand.NewSource(m.seed ^ int64(cx)*73856093 ^ int64(cy)*19349663))
+
+	c := newChunk()
+
+	// Assign every tile a biome from the climate noise
+	for y := range chunkSize {
+		for x := range chunkSize {
+			c.biomes[y][x] = uint8(m.climateBiome(cx*chunkSize+x, cy*chunkSize+y))
+		}
+	}
+
+	// Terrain ratios come from the region and biome the chunk's center falls in
+	centerX, centerY := cx*chunkSize+chunkSize/2, cy*chunkSize+chunkSize/2
+	region := m.plan.regionAt(centerX, centerY)
+	biome := &biomes[c.biomes[chunkSize/2][chunkSize/2]]
+
+	switch m.config.terrain {
+	case TerrainNoise:
+		// Shape water, beaches, forests and mountains from elevation and moisture
+		m.generateNoiseTerrain(c, cx*chunkSize, cy*chunkSize, region.waterDensity*biome.waterScale)
+
+		// Generate paths connecting different areas
+		c.generatePaths(rng, chunkSize, chunkSize)
+
+	default:
+		// Generate realistic water bodies using cellular automata
+		c.generateWaterBodies(rng, chunkSize, chunkSize, region.waterDensity*biome.waterScale)
+
+		// Generate paths connecting different areas
+		c.generatePaths(rng, chunkSize, chunkSize)
+
+		// Place mountains in clusters away from water
+		c.generateMountains(rng, chunkSize, chunkSize, region.mountainClusters+biome.extraMountains)
+	}
+
+	// Add bridges at strategic locations
+	c.placeBridges(rng, chunkSize, chunkSize)
+
+	// Scatter trees according to each tile's biome
+	c.plantTrees(rng)
+	c.plantTallGrass(rng)
+
+	// Carve towns and routes last so the world stays connected
+	m.plan.carveChunk(c, cx*chunkSize, cy*chunkSize)
+
+	// Hide item balls in hard-to-reach spots
+	m.placeItemBalls(c, rng, cx*chunkSize, cy*chunkSize)
+
+	// Plant berry trees along the paths
+	m.placeBerryTrees(c, rng, cx*chunkSize, cy*chunkSize)
+
+	// Junk washes up along sandy shores
+	m.placeJunk(c, rng, cx*chunkSize, cy*chunkSize)
+
+	// Giant creatures gather in dens beside some paths
+	m.placeRaidDens(c, rng, cx*chunkSize, cy*chunkSize)
+
+	// Items are buried here and there, for a following creature to sniff out
+	m.placeHiddenItem(c, rng, cx*chunkSize, cy*chunkSize)
+
+	// Snowfield beaches freeze over, once junk has washed up on them
+	c.freezeShores()
+
+	// Currents run through the water, and logs float across it
+	m.placeWaterFeatures(c, cx, cy)
+
+	// Tiles past the edge of the world are never reachable
+	for y := range chunkSize {
+		for x := range chunkSize {
+			if cx*chunkSize+x >= m.width || cy*chunkSize+y >= m.height {
+				c.setCollision(x, y, true)
+			}
+		}
+	}
+
+	return c
+}
+
+// plantTrees turns some grass tiles into impassable trees, more of them in
+// densely wooded biomes
+func (c *Chunk) plantTrees(rng *rand.Rand) {
+	for y := range chunkSize {
+		for x := range chunkSize {
+			if c.tiles[LayerBase][y][x] != TileGrass || rng.Float32() >= biomes[c.biomes[y][x]].treeDensity {
+				continue
+			}
+			c.tiles[LayerBase][y][x] = TileTree
+			c.setCollision(x, y, true)
+			c.setGrass(x, y, false)
+		}
+	}
+}
+
+// chunkAt returns the chunk holding tile x, y along with the chunk-local
+// coordinates, generating the chunk if it isn't loaded; nil if off the map
+func (m *Map) chunkAt(x, y int) (*Chunk, int, int) {
+	if x < 0 || x >= m.width || y < 0 || y >= m.height {
+		return nil, 0, 0
+	}
+
+	key := Point{x / chunkSize, y / chunkSize}
+	c, ok := m.chunks[key]
+	if !ok && m.static {
+		return nil, 0, 0
+	}
+	if !ok {
+		c = m.generateChunk(key.x, key.y)
+		m.chunks[key] = c
+	}
+
+	return c, x % chunkSize, y % chunkSize
+}
+
+// streamChunks makes sure the chunks around tile x, y are generated and
+// drops chunks that have fallen far outside the view
+func (m *Map) streamChunks(x, y int) {
+	if m.static {
+		return
+	}
+
+	centerX, centerY := x/chunkSize, y/chunkSize
+	viewX := (screenWidth/tileSize)/(2*chunkSize) + 1
+	viewY := (screenHeight/tileSize)/(2*chunkSize) + 1
+
+	// Load chunks covering the view plus a margin
+	for cy := centerY - viewY - chunkLoadMargin; cy <= centerY+viewY+chunkLoadMargin; cy++ {
+		for cx := centerX - viewX - chunkLoadMargin; cx <= centerX+viewX+chunkLoadMargin; cx++ {
+			m.chunkAt(cx*chunkSize, cy*chunkSize)
+		}
+	}
+
+	// Unload chunks well outside the view; they regenerate identically later.
+	// Drawn tiles are let go of sooner, as they take far more memory.
+	for key, c := range m.chunks {
+		dx, dy := abs(key.x-centerX), abs(key.y-centerY)
+		if dx > viewX+chunkLoadMargin || dy > viewY+chunkLoadMargin {
+			c.invalidate()
+		}
+		if dx > viewX+chunkUnloadMargin || dy > viewY+chunkUnloadMargin {
+			delete(m.chunks, key)
+		}
+	}
+}
+
+// setCollision marks the chunk-local tile x, y as passable or impassable
+func (c *Chunk) setCollision(x, y int, value bool) {
+	if x >= 0 && x < chunkSize && y >= 0 && y < chunkSize {
+		c.collisionMap.set(y*chunkSize+x, value)
+	}
+}
+
+// setGrass marks whether the chunk-local tile x, y can trigger encounters
+func (c *Chunk) setGrass(x, y int, value bool) {
+	if x >= 0 && x < chunkSize && y >= 0 && y < chunkSize {
+		c.grassTiles.set(y*chunkSize+x, value)
+	}
+}
+
+// setBridge marks whether the chunk-local tile x, y is covered by a bridge
+func (c *Chunk) setBridge(x, y int, value bool) {
+	if x >= 0 && x < chunkSize && y >= 0 && y < chunkSize {
+		c.bridgeTiles.set(y*chunkSize+x, value)
+	}
+}
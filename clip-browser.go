@@ -0,0 +1,27 @@
+//go:build js
+
+package main
+
+import (
+	"path"
+	"syscall/js"
+)
+
+// writeClip offers a clip as a download, as localStorage is too small to keep
+// clips in
+func writeClip(name string, data []byte) (string, error) {
+	array := js.Global().Get("Uint8Array").New(len(data))
+	js.CopyBytesToJS(array, data)
+	blob := js.Global().Get("Blob").New([]any{array}, map[string]any{"type": "image/gif"})
+	url := js.Global().Get("URL").Call("createObjectURL", blob)
+
+	document := js.Global().Get("document")
+	link := document.Call("createElement", "a")
+	link.Set("href", url)
+	link.Set("download", path.Base(name))
+	document.Get("body").Call("appendChild", link)
+	link.Call("click")
+	link.Call("remove")
+	js.Global().Get("URL").Call("revokeObjectURL", url)
+	return "your downloads", nil
+}
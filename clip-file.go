@@ -0,0 +1,12 @@
+//go:build !js
+
+package main
+
+// writeClip saves a clip in the user's config directory, returning its path
+func writeClip(name string, data []byte) (string, error) {
+	store := FileStore{}
+	if err := store.Write(name, data); err != nil {
+		return "", err
+	}
+	return store.path(name)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Simulation timing constants
+const (
+	// Steps of game logic run per second, whatever rate Ebiten calls Update at
+	simulationRate = 60
+	// Most steps run in one Update, so a long stall doesn't freeze the game
+	// catching up
+	maxStepsPerUpdate = 8
+)
+
+// Clock runs game logic in fixed steps, so walking and animations keep the
+// same speed if the tick rate changes. Draw shows the player and camera
+// partway between the last two steps.
+type Clock struct {
+	// Steps due but not yet run, the fraction left over being how far Draw
+	// is between the last step and the next
+	pending float64
+	// How many times faster than normal the game runs, for fast-forward
+	speed float64
+	// Which step of this Update is running, and how many Updates have passed
+	// since the last step ran, counting this one
+	substep int
+	idle    int
+	// Where the player, follower and camera were before the last step
+	previous Positions
+}
+
+// Positions are the smoothly moving things Draw interpolates between steps
+type Positions struct {
+	playerX, playerY     float32
+	followerX, followerY float32
+	cameraX, cameraY     float32
+}
+
+// positions returns where the player, follower and camera are now
+func (g *Game) positions() Positions {
+	return Positions{
+		playerX: g.player.visualX, playerY: g.player.visualY,
+		followerX: g.follower.visualX, followerY: g.follower.visualY,
+		cameraX: g.camera.x, cameraY: g.camera.y,
+	}
+}
+
+// setPositions moves the player, follower and camera
+func (g *Game) setPositions(p Positions) {
+	g.player.visualX, g.player.visualY = p.playerX, p.playerY
+	g.follower.visualX, g.follower.visualY = p.followerX, p.followerY
+	g.camera.x, g.camera.y = p.cameraX, p.cameraY
+}
+
+// lerpPositions returns the positions a share t of the way from a to b.
+// Anything that jumped further than a tile, like on a warp, is shown where
+// it ended up.
+func lerpPositions(a, b Positions, t float32) Positions {
+	lerp := func(from, to float32) float32 {
+		if abs32(to-from) > tileSize {
+			return to
+		}
+		return from + (to-from)*t
+	}
+	return Positions{
+		playerX: lerp(a.playerX, b.playerX), playerY: lerp(a.playerY, b.playerY),
+		followerX: lerp(a.followerX, b.followerX), followerY: lerp(a.followerY, b.followerY),
+		cameraX: lerp(a.cameraX, b.cameraX), cameraY: lerp(a.cameraY, b.cameraY),
+	}
+}
+
+// stepsDue adds the steps owed for one Update to the clock, at the current
+// tick rate and speed. Ebiten running in step with the display has no fixed
+// rate, so it gets a step every Update.
+func (c *Clock) stepsDue() {
+	if c.speed <= 0 {
+		c.speed = 1
+	}
+	tps := ebiten.TPS()
+	if tps <= 0 {
+		tps = simulationRate
+	}
+	c.pending += c.speed * simulationRate / float64(tps)
+	c.idle++
+}
+
+// alpha is how far Draw is between the last step and the next
+func (c *Clock) alpha() float32 {
+	return min32(float32(c.pending), 1)
+}
+
+// justPressed reports whether an input held down for a number of ticks was
+// pressed since the last step. Only the first step of an Update sees it, so
+// fast-forward doesn't act on one press twice.
+func (c *Clock) justPressed(ticks int) bool {
+	return c.substep == 0 && ticks >= 1 && ticks <= c.idle
+}
@@ -0,0 +1,251 @@
+// Command matchmaker is a minimal reference matchmaking server for ranked
+// battles. Players queue with their rating and are paired with whoever's
+// closest, allowing a wider gap the longer they wait. Once both accept, they
+// are sent to a referee with a match code so it pairs them with each other.
+// Everything is kept in memory.
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"creaturegame-2/engine"
+)
+
+// Matchmaking constants
+const (
+	// Widest rating gap paired straight away, and how much wider it grows
+	// each second a player waits
+	baseRatingGap   = 100
+	ratingGapGrowth = 10
+	// How long a ticket lasts without being checked on, as the player has
+	// likely gone
+	ticketTimeout = 30 * time.Second
+	// How long a pair has for both players to accept before both go back in
+	// the queue
+	acceptTimeout = 20 * time.Second
+)
+
+// Ticket statuses, as the game knows them
+const (
+	statusWaiting  = "waiting"
+	statusPaired   = "paired"
+	statusReady    = "ready"
+	statusDeclined = "declined"
+)
+
+// ticket is a player's place in the queue
+type ticket struct {
+	id      string
+	trainer string
+	rating  int
+	status  string
+	// When they joined the queue, last checked on their ticket, and were
+	// last paired
+	queued, seen, paired time.Time
+	// Who they're paired with, whether they've accepted, and the match
+	// code once both have
+	partner  *ticket
+	accepted bool
+	match    string
+}
+
+// pairing is a ticket as the game sees it
+type pairing struct {
+	Status   string `json:"status"`
+	Opponent string `json:"opponent,omitempty"`
+	Rating   int    `json:"rating,omitempty"`
+	Referee  string `json:"referee,omitempty"`
+	Match    string `json:"match,omitempty"`
+}
+
+// server is the queue and everyone in it
+type server struct {
+	mu      sync.Mutex
+	tickets map[string]*ticket
+	referee string
+	token   string
+}
+
+func main() {
+	addr := flag.String("addr", ":7779", "address to listen on")
+	referee := flag.String("referee", "localhost:"+engine.RefereePort, "referee address players are sent to")
+	token := flag.String("token", "", "bearer token players must send, if any")
+	flag.Parse()
+
+	s := &server{tickets: make(map[string]*ticket), referee: *referee, token: *token}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /tickets", s.authorized(s.join))
+	mux.HandleFunc("GET /tickets/{id}", s.authorized(s.check))
+	mux.HandleFunc("POST /tickets/{id}/accept", s.authorized(s.accept))
+	mux.HandleFunc("DELETE /tickets/{id}", s.authorized(s.leave))
+
+	log.Println("Matchmaking on", *addr, "for the referee at", *referee)
+	log.Fatal(http.ListenAndServe(*addr, mux))
+}
+
+// authorized turns away requests without the token, if there is one, and
+// holds the lock for the rest. Every request tidies up the queue and pairs
+// whoever can be, as the gaps allowed grow while players wait.
+func (s *server) authorized(handle func(http.ResponseWriter, *http.Request)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.token != "" && r.Header.Get("Authorization") != "Bearer "+s.token {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.expire()
+		s.pair()
+		handle(w, r)
+	}
+}
+
+// join queues a player and returns their ticket
+func (s *server) join(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Trainer string `json:"trainer"`
+		Rating  int    `json:"rating"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Trainer == "" {
+		http.Error(w, "bad ticket", http.StatusBadRequest)
+		return
+	}
+	now := time.Now()
+	t := &ticket{id: newCode(), trainer: req.Trainer, rating: req.Rating, status: statusWaiting, queued: now, seen: now}
+	s.tickets[t.id] = t
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{"ticket": t.id})
+}
+
+// check reports how a ticket stands
+func (s *server) check(w http.ResponseWriter, r *http.Request) {
+	t, ok := s.tickets[r.PathValue("id")]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	t.seen = time.Now()
+	p := pairing{Status: t.status}
+	if t.partner != nil {
+		p.Opponent, p.Rating = t.partner.trainer, t.partner.rating
+	}
+	if t.status == statusReady {
+		p.Referee, p.Match = s.referee, t.match
+	}
+	json.NewEncoder(w).Encode(p)
+}
+
+// accept accepts the pairing a ticket has, and readies the match once both
+// players have
+func (s *server) accept(w http.ResponseWriter, r *http.Request) {
+	t, ok := s.tickets[r.PathValue("id")]
+	if !ok || t.partner == nil {
+		http.Error(w, "not paired", http.StatusConflict)
+		return
+	}
+	t.accepted = true
+	if t.partner.accepted {
+		match := newCode()
+		for _, u := range []*ticket{t, t.partner} {
+			u.status, u.match = statusReady, match
+		}
+		log.Println("Match:", t.trainer, "vs", t.partner.trainer)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// leave takes a ticket out of the queue, putting whoever it was paired with
+// back in to be paired on the next request
+func (s *server) leave(w http.ResponseWriter, r *http.Request) {
+	if t, ok := s.tickets[r.PathValue("id")]; ok {
+		s.remove(t)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// remove drops a ticket, and its partner back into the queue unless their
+// match is already on
+func (s *server) remove(t *ticket) {
+	delete(s.tickets, t.id)
+	if p := t.partner; p != nil && p.status != statusReady {
+		p.status, p.partner, p.accepted = statusDeclined, nil, false
+	}
+}
+
+// expire drops tickets nobody has checked on for a while, and puts pairs
+// that weren't both accepted in time back into the queue
+func (s *server) expire() {
+	for _, t := range s.tickets {
+		switch {
+		case time.Since(t.seen) > ticketTimeout:
+			s.remove(t)
+		case t.status == statusPaired && time.Since(t.paired) > acceptTimeout:
+			for _, u := range []*ticket{t, t.partner} {
+				u.status, u.partner, u.accepted = statusDeclined, nil, false
+			}
+		}
+	}
+}
+
+// pair pairs up queued players, closest ratings first, as long as the gap
+// is one both have waited long enough to accept
+func (s *server) pair() {
+	for {
+		var best [2]*ticket
+		bestGap := -1
+		for _, a := range s.tickets {
+			for _, b := range s.tickets {
+				if a.id >= b.id || !queued(a) || !queued(b) {
+					continue
+				}
+				gap := abs(a.rating - b.rating)
+				if gap > allowedGap(a) || gap > allowedGap(b) {
+					continue
+				}
+				if bestGap < 0 || gap < bestGap {
+					best, bestGap = [2]*ticket{a, b}, gap
+				}
+			}
+		}
+		if bestGap < 0 {
+			return
+		}
+		a, b := best[0], best[1]
+		now := time.Now()
+		a.status, a.partner, a.paired = statusPaired, b, now
+		b.status, b.partner, b.paired = statusPaired, a, now
+	}
+}
+
+// queued reports whether a ticket is waiting to be paired
+func queued(t *ticket) bool {
+	return t.status == statusWaiting || t.status == statusDeclined
+}
+
+// allowedGap is the widest rating gap a player will be paired across, which
+// grows the longer they wait
+func allowedGap(t *ticket) int {
+	return baseRatingGap + ratingGapGrowth*int(time.Since(t.queued)/time.Second)
+}
+
+// newCode makes a random ticket or match code
+func newCode() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
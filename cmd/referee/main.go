@@ -0,0 +1,242 @@
+// Command referee pairs players for ranked battles and plays out every turn
+// itself with the game's battle rules, under the ruleset named by -rules.
+// Players send only their team and their choice of move, so no player's
+// game can fix a damage roll.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"log"
+	"net"
+	"time"
+
+	"creaturegame-2/engine"
+)
+
+// Referee timing constants
+const (
+	// How long a player has to send their team after connecting
+	joinTimeout = 30 * time.Second
+	// How long a player has to choose a move before they lose the match,
+	// when the ruleset has no timer
+	choiceTimeout = 5 * time.Minute
+	// Time allowed past a ruleset's timer for a player's choice to arrive,
+	// as their game sends its own default when the timer runs out
+	timerGrace = 3 * time.Second
+)
+
+// maxMessageSize is the most a player can send in one message, well over
+// the size of a full team
+const maxMessageSize = 64 << 10
+
+// player is someone connected to the referee
+type player struct {
+	conn    net.Conn
+	encoder *json.Encoder
+	name    string
+	rating  int
+	match   string
+	team    []engine.Fighter
+	// Messages from the player, closed once they disconnect
+	messages chan engine.Message
+}
+
+func main() {
+	addr := flag.String("addr", ":"+engine.RefereePort, "address to listen on")
+	rulesName := flag.String("rules", engine.Rulesets[0].Name, "ruleset every match is fought under")
+	flag.Parse()
+	rules, ok := engine.FindRuleset(*rulesName)
+	if !ok {
+		log.Fatalf("No ruleset called %q", *rulesName)
+	}
+
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Println("Refereeing", rules.Name, "rules on", listener.Addr())
+
+	waiting, left := make(chan *player), make(chan *player)
+	go matchmake(waiting, left, rules)
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			log.Println("Failed to accept:", err)
+			continue
+		}
+		go greet(conn, waiting, left, rules)
+	}
+}
+
+// greet reads a new player's team and, if it's a fair one within the rules,
+// queues them for a match. Once they disconnect they're sent on left.
+func greet(conn net.Conn, waiting, left chan<- *player, rules engine.Ruleset) {
+	// No message may run past the size limit, counted afresh for each
+	limited := &io.LimitedReader{R: conn, N: maxMessageSize}
+	decoder := json.NewDecoder(limited)
+	p := &player{conn: conn, encoder: json.NewEncoder(conn), messages: make(chan engine.Message, 4)}
+
+	conn.SetReadDeadline(time.Now().Add(joinTimeout))
+	var join engine.Message
+	if err := decoder.Decode(&join); err != nil || join.Type != engine.MessageJoin {
+		conn.Close()
+		return
+	}
+	conn.SetReadDeadline(time.Time{})
+	err := engine.ValidTeam(join.Team)
+	if err == nil {
+		err = rules.Check(join.Team)
+	}
+	if err != nil {
+		p.send(engine.Message{Type: engine.MessageError, Reason: err.Error()})
+		conn.Close()
+		return
+	}
+	p.name, p.rating, p.match, p.team = join.Trainer, join.Rating, join.Match, join.Team
+
+	// The player is queued before anything is read, so they can't leave
+	// before they've joined
+	waiting <- p
+	go func() {
+		defer func() {
+			close(p.messages)
+			left <- p
+		}()
+		for {
+			limited.N = maxMessageSize
+			var msg engine.Message
+			if err := decoder.Decode(&msg); err != nil {
+				return
+			}
+			p.messages <- msg
+		}
+	}()
+}
+
+// matchmake pairs players in the order they joined, except that players
+// with a match code from a matchmaker are only paired with each other. A
+// player who leaves while waiting is taken out of the queue.
+func matchmake(waiting, left <-chan *player, rules engine.Ruleset) {
+	queued := make(map[string]*player)
+	for {
+		select {
+		case p := <-waiting:
+			first, ok := queued[p.match]
+			if !ok {
+				queued[p.match] = p
+				continue
+			}
+			delete(queued, p.match)
+			go referee([2]*player{first, p}, rules)
+		case p := <-left:
+			if queued[p.match] == p {
+				delete(queued, p.match)
+				p.conn.Close()
+				log.Println(p.name, "left the queue")
+			}
+		}
+	}
+}
+
+// send sends a message to a player, who is dropped at the end of the match
+// if it doesn't arrive
+func (p *player) send(msg engine.Message) {
+	if err := p.encoder.Encode(msg); err != nil {
+		log.Println("Failed to send to", p.name+":", err)
+	}
+}
+
+// referee plays out a match between two players under a ruleset, taking a
+// move from each every turn and sending both how it went
+func referee(players [2]*player, rules engine.Ruleset) {
+	log.Println("Match:", players[0].name, "vs", players[1].name)
+	m := engine.NewMatch([2][]engine.Fighter{players[0].team, players[1].team}, rules, time.Now().UnixNano())
+	for side, p := range players {
+		other := players[1-side]
+		p.send(engine.Message{Type: engine.MessageStart, Side: side, Trainer: other.name, Rating: other.rating, Team: other.team, Winner: -1, Rules: &rules, Seconds: rules.TurnLimit(1)})
+	}
+
+	for {
+		choices, winner, reason := waitForChoices(m, players)
+		if reason != "" {
+			finish(players, winner, reason)
+			return
+		}
+
+		events := m.Resolve(choices)
+		for _, p := range players {
+			p.send(engine.Message{Type: engine.MessageTurn, Events: events, Winner: -1, Seconds: m.Rules.TurnLimit(m.Turn + 1)})
+		}
+		if winner := m.Winner(); winner >= 0 {
+			finish(players, winner, "")
+			return
+		}
+	}
+}
+
+// waitForChoices collects both players' moves for a turn. Under a ruleset
+// with a timer, a player who runs out of time uses their first move. If the
+// turn can't be played, because a player left, gave up or, without a timer,
+// took too long, it returns the winner, or -1 for neither, and why.
+func waitForChoices(m *engine.Match, players [2]*player) (choices [2]int, winner int, reason string) {
+	var chosen [2]bool
+	limit := m.Rules.TurnLimit(m.Turn + 1)
+	timeout := time.After(choiceTimeout)
+	if limit > 0 {
+		timeout = time.After(time.Duration(limit)*time.Second + timerGrace)
+	}
+	for !chosen[0] || !chosen[1] {
+		var side int
+		var msg engine.Message
+		var ok bool
+		select {
+		case msg, ok = <-players[0].messages:
+			side = 0
+		case msg, ok = <-players[1].messages:
+			side = 1
+		case <-timeout:
+			if limit > 0 {
+				for side := range chosen {
+					if !chosen[side] {
+						choices[side] = m.DefaultChoice(side)
+					}
+				}
+				return choices, -1, ""
+			}
+			switch {
+			case chosen[0]:
+				return choices, 0, players[1].name + " took too long."
+			case chosen[1]:
+				return choices, 1, players[0].name + " took too long."
+			}
+			return choices, -1, "Both players took too long."
+		}
+
+		switch {
+		case !ok:
+			return choices, 1 - side, players[side].name + " left the battle."
+		case msg.Type == engine.MessageForfeit:
+			return choices, 1 - side, players[side].name + " gave up."
+		case msg.Type != engine.MessageChoose || chosen[side]:
+			continue
+		case !m.ValidChoice(side, msg.Move):
+			players[side].send(engine.Message{Type: engine.MessageError, Reason: "That move can't be used."})
+		default:
+			choices[side], chosen[side] = msg.Move, true
+		}
+	}
+	return choices, -1, ""
+}
+
+// finish tells both players how the match ended and hangs up
+func finish(players [2]*player, winner int, reason string) {
+	for _, p := range players {
+		p.send(engine.Message{Type: engine.MessageEnd, Winner: winner, Reason: reason})
+		p.conn.Close()
+	}
+	if winner >= 0 {
+		log.Println("Match won by", players[winner].name)
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"image/color"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// defaultConfigFile is where the launch configuration is read from, unless
+// -config says otherwise
+const defaultConfigFile = "creaturegame.json"
+
+// maxWindowScale is the largest window scale the config may ask for
+const maxWindowScale = 6
+
+// Config is how the game is launched, read from a file beside it and
+// overridden by command-line flags. Unlike the options, it's for testers and
+// developers rather than players, and the game never writes it.
+type Config struct {
+	// Window size as a multiple of the screen
+	WindowScale int  `json:"windowScale"`
+	VSync       bool `json:"vsync"`
+	// Seed the world of a new game is made from, or 0 for a random one
+	Seed int64 `json:"seed"`
+	// Terrain generator the world of a new game is made with: "noise", the
+	// default, or "cellular"
+	Terrain string `json:"terrain"`
+	// Shows the debug overlay
+	Dev bool `json:"dev"`
+	// Folder saves and settings are kept in, in place of the user's config
+	// folder; browsers always use localStorage
+	DataDir string `json:"dataDir"`
+	// Start a new game straight away, on this map if one is given. These
+	// only come from flags.
+	SkipMenu bool   `json:"-"`
+	Map      string `json:"-"`
+}
+
+// defaultConfig returns the configuration used when there's no file
+func defaultConfig() Config {
+	return Config{WindowScale: 2, VSync: true}
+}
+
+// loadConfig reads the launch configuration from a file. A missing file
+// isn't an error, and leaves everything at the defaults.
+func loadConfig(path string) (Config, error) {
+	config := defaultConfig()
+	encoded, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return config, nil
+	}
+	if err != nil {
+		return config, err
+	}
+	if err := json.Unmarshal(encoded, &config); err != nil {
+		return defaultConfig(), err
+	}
+	if config.WindowScale < 1 || config.WindowScale > maxWindowScale {
+		config.WindowScale = 2
+	}
+	if _, ok := parseTerrain(config.Terrain); !ok {
+		config.Terrain = ""
+	}
+	return config, nil
+}
+
+// quickStart skips the title screen, naming and the starter scene for
+// testing. The player starts with the first starter, on the configured map
+// if there is one, walking in through its door.
+func (g *Game) quickStart() {
+	c := newCreature(starterSpecies[0], starterLevel)
+	c.trainer = g.playerName
+	g.recordMet(&c)
+	g.creatures = []Creature{c}
+	g.activeCreature = 0
+	g.rivalStarter = rivalStarter(c.name)
+	g.gameState = StateOverworld
+
+	id := g.config.Map
+	if id == "" || id == g.worldMap.id {
+		return
+	}
+	for _, m := range g.maps {
+		for door, warp := range m.warps {
+			if warp.mapID != id || warp.back {
+				continue
+			}
+			g.placePlayer(m.id, door.x, door.y+1, DirectionUp)
+			g.warpTo(warp)
+			return
+		}
+	}
+	log.Println("No map with the ID", id)
+}
+
+// drawDebug shows the tick rate, the map and tile the player is on, and
+// the world seed in dev mode
+func (g *Game) drawDebug(screen *ebiten.Image) {
+	if !g.config.Dev {
+		return
+	}
+	lines := []string{
+		"FPS " + strconv.Itoa(int(ebiten.ActualFPS())) + " TPS " + strconv.Itoa(int(ebiten.ActualTPS())),
+		g.worldMap.id + " " + strconv.Itoa(g.player.tileX) + "," + strconv.Itoa(g.player.tileY) + " layer " + strconv.Itoa(g.player.currentLayer),
+		"Seed " + strconv.FormatInt(g.maps[overworldID].seed, 10) + " " + terrainNames[g.maps[overworldID].config.terrain],
+	}
+	for i, line := range lines {
+		g.drawText(screen, line, 4, float64(screenHeight-4-(len(lines)-i)*g.lineSpacing()), color.RGBA{255, 255, 120, 255})
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Control presets
+const (
+	// Arrow keys to move, Space to confirm and Escape to cancel
+	ControlsArrows = iota
+	// WASD to move, J to confirm and K to cancel
+	ControlsWASD
+	// A gamepad, with the arrow keys as a fallback
+	ControlsGamepad
+	ControlsCount
+)
+
+// controlsNames label the control presets
+var controlsNames = [ControlsCount]string{"Arrows", "WASD", "Gamepad"}
+
+// controlPresets rebind actions, named by their key in the arrows preset, to
+// other keys. Actions a preset doesn't list keep their own key.
+var controlPresets = [ControlsCount]map[ebiten.Key]ebiten.Key{
+	ControlsWASD: {
+		ebiten.KeyUp:     ebiten.KeyW,
+		ebiten.KeyDown:   ebiten.KeyS,
+		ebiten.KeyLeft:   ebiten.KeyA,
+		ebiten.KeyRight:  ebiten.KeyD,
+		ebiten.KeySpace:  ebiten.KeyJ,
+		ebiten.KeyEscape: ebiten.KeyK,
+	},
+}
+
+// boundKey returns the keyboard key that performs an action under the
+// current control preset
+func (g *Game) boundKey(action ebiten.Key) ebiten.Key {
+	if key, ok := controlPresets[g.settings.Controls][action]; ok {
+		return key
+	}
+	return action
+}
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"image/color"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// caveMinerals are dug out of the walls of caves, and junkItems fished out
+// of the water along shores
+var (
+	caveMinerals = []string{"Iron Ore", "Iron Ore", "Glimmer Stone"}
+	junkItems    = []string{"Tin Can", "Old Boot", "Driftwood"}
+)
+
+// CraftMenu is the crafting screen, opened from the pause menu
+type CraftMenu struct {
+	selected int
+	// Result of the last thing crafted
+	message string
+}
+
+// craftableItems returns every item with a recipe, in item list order
+func craftableItems() []string {
+	names := []string{}
+	for _, item := range itemList {
+		if len(item.recipe) > 0 {
+			names = append(names, item.name)
+		}
+	}
+	return names
+}
+
+// knownRecipes returns the items the player has learned to craft, in item
+// list order
+func (g *Game) knownRecipes() []string {
+	names := []string{}
+	for _, name := range craftableItems() {
+		if g.recipes[name] {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// canCraft reports whether the bag holds everything an item's recipe needs
+func (g *Game) canCraft(name string) bool {
+	for _, ingredient := range findItem(name).recipe {
+		if g.bag[ingredient.item] < ingredient.count {
+			return false
+		}
+	}
+	return true
+}
+
+// recipeText lists what goes into an item, like "2 Oran Berry"
+func recipeText(name string) string {
+	line := ""
+	for i, ingredient := range findItem(name).recipe {
+		if i > 0 {
+			line += " and "
+		}
+		line += strconv.Itoa(ingredient.count) + " " + ingredient.item
+	}
+	return line
+}
+
+// addCrafter puts someone in a heal center who teaches one recipe
+func (m *Map) addCrafter(recipe string) {
+	m.objects[Point{m.width - 2, 4}] = &MapObject{kind: ObjectCrafter, item: recipe}
+}
+
+// talkToCrafter teaches the player the crafter's recipe
+func (g *Game) talkToCrafter(recipe string) {
+	if g.recipes[recipe] {
+		g.showDialogue("Remember: " + recipeText(recipe) + " make a " + recipe + ". Open Craft from the menu whenever you have them.")
+		return
+	}
+	g.recipes[recipe] = true
+	g.audio.playSound("fanfare")
+	g.showToast("Learned to craft a " + recipe + "!")
+	g.showDialogue("I make all my own supplies. Here's how to make a " + recipe + ": combine " + recipeText(recipe) + ". Open Craft from the menu to try it.")
+}
+
+// placeMinerals sets mineral deposits into the rock walls of a cave, where
+// they face open floor and never get in the way
+func (m *Map) placeMinerals(rng *rand.Rand) {
+	for placed, tries := 0, 0; placed < 3 && tries < 500; tries++ {
+		x, y := 1+rng.Intn(m.width-2), 5+rng.Intn(m.height-6)
+		pos := Point{x, y}
+		if m.Tile(LayerBase, x, y) != TileRock || m.objects[pos] != nil || !m.facesFloor(x, y, TileCaveFloor) {
+			continue
+		}
+		m.objects[pos] = &MapObject{kind: ObjectMineral, item: caveMinerals[rng.Intn(len(caveMinerals))]}
+		placed++
+	}
+}
+
+// facesFloor reports whether a tile is next to a tile of the given floor
+func (m *Map) facesFloor(x, y, floor int) bool {
+	for _, d := range [][2]int{{0, -1}, {0, 1}, {-1, 0}, {1, 0}} {
+		if m.Tile(LayerBase, x+d[0], y+d[1]) == floor && !m.IsCollision(x+d[0], y+d[1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// placeJunk leaves junk floating in the water beside a sandy shore in some
+// chunks, whose top-left tile is originX, originY
+func (m *Map) placeJunk(c *Chunk, rng *rand.Rand, originX, originY int) {
+	if rng.Float32() >= 0.25 {
+		return
+	}
+
+	for y := 1; y < chunkSize-1; y++ {
+		for x := 1; x < chunkSize-1; x++ {
+			if c.tiles[LayerBase][y][x] != TileWater || c.bridgeTiles.get(y*chunkSize+x) {
+				continue
+			}
+			shore := c.tiles[LayerBase][y-1][x] == TileSand || c.tiles[LayerBase][y+1][x] == TileSand ||
+				c.tiles[LayerBase][y][x-1] == TileSand || c.tiles[LayerBase][y][x+1] == TileSand
+			if !shore || rng.Float32() >= 0.2 {
+				continue
+			}
+
+			pos := Point{originX + x, originY + y}
+			if m.objects[pos] == nil {
+				m.objects[pos] = &MapObject{kind: ObjectJunk, item: junkItems[rng.Intn(len(junkItems))]}
+			}
+			return
+		}
+	}
+}
+
+// gatherMaterial takes a crafting material from a deposit or the water, if
+// it has refilled since it was last taken from
+func (g *Game) gatherMaterial(pos Point, object *MapObject) {
+	if !g.regrown(pos, object) {
+		if object.kind == ObjectMineral {
+			g.showDialogue("The rock here has been dug out. Maybe more will turn up later.")
+		} else {
+			g.showDialogue("There's nothing floating here right now.")
+		}
+		return
+	}
+
+	g.addItem(object.item, 1)
+	g.worldMap.harvested[pos] = Harvest{Step: g.steps, Time: time.Now().Unix()}
+	if object.kind == ObjectMineral {
+		g.showDialogue("Dug a " + object.item + " out of the rock!")
+	} else {
+		g.showDialogue("Fished a " + object.item + " out of the water!")
+	}
+}
+
+// drawMineral draws a mineral deposit in a rock wall, glinting while there's
+// something to dig out
+func (g *Game) drawMineral(screen *ebiten.Image, x, y float32, full bool) {
+	vector.DrawFilledCircle(screen, x, y, 9, color.RGBA{95, 90, 85, 255}, true)
+	if !full {
+		return
+	}
+	for _, offset := range [][2]float32{{-4, -3}, {3, -5}, {2, 3}} {
+		vector.DrawFilledRect(screen, x+offset[0]-1.5, y+offset[1]-1.5, 3, 3, color.RGBA{200, 220, 255, 255}, true)
+	}
+}
+
+// drawJunk draws junk bobbing in the water, which is gone once fished out
+func (g *Game) drawJunk(screen *ebiten.Image, x, y float32, full bool) {
+	if !full {
+		return
+	}
+	bob := float32(g.ticks/30%2) * 1.5
+	vector.DrawFilledRect(screen, x-8, y-2+bob, 16, 5, color.RGBA{120, 90, 60, 255}, true)
+	vector.DrawFilledCircle(screen, x+3, y-3+bob, 3, color.RGBA{170, 170, 180, 255}, true)
+}
+
+// openCraftMenu switches to the crafting screen
+func (g *Game) openCraftMenu() {
+	g.gameState = StateCraft
+	g.craft = CraftMenu{}
+}
+
+// craftRects lays out the list of known recipes
+func (g *Game) craftRects(names []string) []Rect {
+	return listRects(20, g.listTop(), g.selectorWidth()+g.widestText(names)+10, g.rowHeight(), len(names))
+}
+
+// updateCraftMenu moves through the known recipes and crafts the selected
+// one
+func (g *Game) updateCraftMenu() {
+	if g.input.IsActionJustPressed(ebiten.KeyEscape) {
+		g.gameState = StateMenu
+		return
+	}
+
+	names := g.knownRecipes()
+	if len(names) == 0 {
+		return
+	}
+	c := &g.craft
+	if g.input.IsActionJustPressed(ebiten.KeyUp) {
+		c.selected = (c.selected - 1 + len(names)) % len(names)
+		c.message = ""
+	} else if g.input.IsActionJustPressed(ebiten.KeyDown) {
+		c.selected = (c.selected + 1) % len(names)
+		c.message = ""
+	}
+	clicked := g.mouseSelect(g.craftRects(names), &c.selected)
+
+	if !g.input.IsActionJustPressed(ebiten.KeySpace) && !g.input.IsActionJustPressed(ebiten.KeyEnter) && !clicked {
+		return
+	}
+	name := names[c.selected]
+	if !g.canCraft(name) {
+		c.message = "You need " + recipeText(name) + " to make a " + name + "."
+		return
+	}
+	for _, ingredient := range findItem(name).recipe {
+		for range ingredient.count {
+			g.removeItem(ingredient.item)
+		}
+	}
+	g.addItem(name, 1)
+	g.audio.playSound("heal")
+	c.message = "Made a " + name + "! You have " + strconv.Itoa(g.bag[name]) + " now."
+}
+
+// drawCraftMenu draws the known recipes, with what the selected one needs and
+// how much of it the bag holds
+func (g *Game) drawCraftMenu(screen *ebiten.Image) {
+	g.drawPanel(screen, 10, 10, float32(screenWidth-20), float32(screenHeight-20), color.RGBA{70, 80, 50, 240})
+
+	drawText := func(x, y int, line string, clr color.Color) {
+		op := &text.DrawOptions{}
+		op.GeoM.Translate(float64(x), float64(y))
+		op.ColorScale.ScaleWithColor(g.uiText(clr))
+		text.Draw(screen, line, g.fontFace, op)
+	}
+	drawText(20, 30, "Crafting", color.White)
+
+	names := g.knownRecipes()
+	if len(names) == 0 {
+		for i, line := range g.wrapText("You don't know any recipes yet. People in heal centers know how to make things.", screenWidth-60) {
+			drawText(30, g.listTop()+i*g.lineSpacing(), line, color.RGBA{200, 200, 200, 255})
+		}
+		g.drawHint(screen, "ESC to go back")
+		return
+	}
+
+	rects := g.craftRects(names)
+	for i, name := range names {
+		clr := color.Color(color.White)
+		if !g.canCraft(name) {
+			clr = color.RGBA{150, 150, 150, 255}
+		}
+		if i == g.craft.selected {
+			drawText(rects[i].x, rects[i].y, ">", color.RGBA{255, 255, 0, 255})
+			clr = color.RGBA{255, 255, 0, 255}
+		}
+		drawText(rects[i].x+g.selectorWidth(), rects[i].y, name, clr)
+	}
+
+	// What the selected recipe takes, and how much of each the bag holds
+	x, y := max(160, rects[0].x+rects[0].width+10), g.listTop()
+	drawText(x, y, "Needs:", color.RGBA{200, 220, 170, 255})
+	y += g.rowHeight()
+	for _, ingredient := range findItem(names[g.craft.selected]).recipe {
+		have := g.bag[ingredient.item]
+		clr := color.RGBA{255, 160, 160, 255}
+		if have >= ingredient.count {
+			clr = color.RGBA{160, 255, 160, 255}
+		}
+		drawText(x, y, ingredient.item, color.White)
+		y += g.lineSpacing()
+		drawText(x+10, y, strconv.Itoa(have)+"/"+strconv.Itoa(ingredient.count), clr)
+		y += g.rowHeight()
+	}
+
+	hintTop := g.drawHint(screen, "Space to craft, ESC to go back")
+	lines := g.wrapText(g.craft.message, screenWidth-40)
+	for i, line := range lines {
+		drawText(20, hintTop-(len(lines)-i)*g.lineSpacing(), line, color.RGBA{255, 255, 180, 255})
+	}
+}
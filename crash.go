@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// stateNames name the game states in crash reports
+var stateNames = map[int]string{
+	StateMainMenu:     "main menu",
+	StateOverworld:    "overworld",
+	StateBattle:       "battle",
+	StateMenu:         "pause menu",
+	StateCreatureMenu: "creature menu",
+	StateBag:          "bag",
+	StateTownMap:      "town map",
+	StateTrade:        "trade",
+	StateOptions:      "options",
+	StateEvolution:    "evolution",
+	StateDex:          "dex",
+	StateNameEntry:    "name entry",
+	StateStarter:      "starter scene",
+	StateFusion:       "fusion lab",
+	StateCraft:        "crafting",
+	StateStorage:      "storage",
+	StateMail:         "mail",
+	StateSlots:        "slots",
+	StateCardFlip:     "card flip",
+}
+
+// recoverCrash is deferred at the top of Update and Draw. If the game
+// panics, it saves the game as it was to the emergency save and writes a
+// crash report before letting the panic carry on and end the game.
+func (g *Game) recoverCrash() {
+	r := recover()
+	if r == nil {
+		return
+	}
+	report := g.crashReport(r, debug.Stack())
+	log.Print(report)
+	if err := saveStore.Write(crashReportFile, []byte(report)); err != nil {
+		log.Println("Failed to write the crash report:", err)
+	}
+	if err := g.emergencySave(); err != nil {
+		log.Println("Failed to write the emergency save:", err)
+	}
+	panic(r)
+}
+
+// emergencySave writes the game in progress, if there is one, to the
+// emergency save. The game's state may be what caused the crash, so a
+// panic while gathering it is an error rather than a second crash.
+func (g *Game) emergencySave() (err error) {
+	if len(g.creatures) == 0 {
+		return nil
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("gathering the save: %v", r)
+		}
+	}()
+	return writeSave(emergencySaveFile, g.saveData())
+}
+
+// crashReport describes a crash: what went wrong, where the game was, and
+// the stack trace
+func (g *Game) crashReport(r any, stack []byte) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Crashed at %s: %v\n\n", time.Now().Format(time.RFC3339), r)
+
+	fmt.Fprintf(&b, "State: %s\n", stateNames[g.gameState])
+	if m := g.maps[overworldID]; m != nil {
+		fmt.Fprintf(&b, "Seed: %d\n", m.seed)
+	}
+	if g.worldMap != nil {
+		fmt.Fprintf(&b, "Map: %s at %d,%d, layer %d, facing %d\n", g.worldMap.id, g.player.tileX, g.player.tileY, g.player.currentLayer, g.player.direction)
+	}
+	fmt.Fprintf(&b, "Day %d, steps %d, money %d, badges %d\n", g.calendar.day, g.steps, g.money, g.badges)
+	for i, c := range g.creatures {
+		fmt.Fprintf(&b, "Party %d: %s Lv.%d, HP %d/%d, status %d\n", i, c.name, c.level, c.hp, c.maxHP, c.status)
+	}
+	if g.gameState == StateBattle {
+		enemy := g.battle.enemyCreature
+		fmt.Fprintf(&b, "Battle: against %s Lv.%d, HP %d/%d, trainer %q, turn %d\n", enemy.name, enemy.level, enemy.hp, enemy.maxHP, g.battle.trainer, g.battle.currentTurn)
+	}
+
+	fmt.Fprintf(&b, "\n%s", stack)
+	return b.String()
+}
+
+// hasEmergencySave reports whether the last game crashed and left a save
+// to restore
+func hasEmergencySave() bool {
+	return saveStore.Exists(emergencySaveFile)
+}
+
+// restoreEmergencySave loads the game saved when the last one crashed. It's
+// kept until the player next saves, so a crash straight after restoring
+// doesn't lose it.
+func (g *Game) restoreEmergencySave() error {
+	if err := g.loadGameFrom(emergencySaveFile); err != nil {
+		return err
+	}
+	g.unsaved = true
+	g.showToast("Recovered the game from before the crash.")
+	return nil
+}
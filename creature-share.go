@@ -0,0 +1,229 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Creature sharing constants
+const (
+	shareVersion = 1
+	// Files in the save store a creature is exported to, and imported from
+	shareExportFile = "creature-export.txt"
+	shareImportFile = "creature-import.txt"
+)
+
+// shareKey signs exported creatures. Like saveKey, it's in the source, so it
+// stops creatures being edited in passing; the legitimacy check catches the
+// rest.
+var shareKey = []byte("creaturegame creature v1")
+
+// SharedCreature is a creature exported to be imported into another save,
+// signed so it can't be changed on the way
+type SharedCreature struct {
+	Version   int          `json:"version"`
+	From      string       `json:"from"`
+	Creature  CreatureSave `json:"creature"`
+	Signature string       `json:"signature,omitempty"`
+}
+
+// sign returns the signature of a shared creature's contents
+func (s SharedCreature) sign() string {
+	s.Signature = ""
+	body, _ := json.Marshal(s)
+	mac := hmac.New(sha256.New, shareKey)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// encodeCreature writes a creature as a signed blob of text to pass around
+func encodeCreature(c Creature, from string) string {
+	s := SharedCreature{Version: shareVersion, From: from, Creature: saveCreature(c)}
+	s.Signature = s.sign()
+	body, _ := json.Marshal(s)
+	return base64.StdEncoding.EncodeToString(body)
+}
+
+// decodeCreature reads a shared creature's blob, checking it wasn't changed
+// since it was exported and that it's a creature the game could have made
+func decodeCreature(blob string) (SharedCreature, error) {
+	body, err := base64.StdEncoding.DecodeString(strings.TrimSpace(blob))
+	if err != nil {
+		return SharedCreature{}, errors.New("that isn't an exported creature")
+	}
+	var s SharedCreature
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&s); err != nil {
+		return SharedCreature{}, errors.New("that isn't an exported creature")
+	}
+	if s.Version != shareVersion {
+		return SharedCreature{}, errors.New("it was exported from another version of the game")
+	}
+	if !hmac.Equal([]byte(s.Signature), []byte(s.sign())) {
+		return SharedCreature{}, errors.New("it was changed after it was exported")
+	}
+	if err := legitimate(s.Creature); err != nil {
+		return SharedCreature{}, err
+	}
+	return s, nil
+}
+
+// legitimate checks that a saved creature is one the game could have made:
+// a known species at a level it can reach, with stats no higher than the
+// best it could have, and moves as the game teaches them. Fused creatures'
+// parts are checked too.
+func legitimate(cs CreatureSave) error {
+	if !receivable(&cs) {
+		return errors.New("it isn't a creature this game knows")
+	}
+	if cs.Level > maxLevel || cs.MetLevel > cs.Level || cs.Friendship < 0 || cs.Friendship > maxFriendship {
+		return errors.New("its level or friendship is out of range")
+	}
+	if len(cs.IVs) > StatCount || cs.Nature < 0 || cs.Nature >= len(natures) {
+		return errors.New("its traits are out of range")
+	}
+	for _, iv := range cs.IVs {
+		if iv < 0 || iv > maxIV {
+			return errors.New("its traits are out of range")
+		}
+	}
+	if item := findItem(cs.Held); cs.Held != "" && (item == nil || item.category == ItemKey) {
+		return errors.New("it's holding something it couldn't be")
+	}
+	if cs.Mail != nil && (cs.Held != mailItem || len(cs.Mail.Text) > maxLetterLength || cs.Mail.Gift != "") {
+		return errors.New("it's carrying a letter it couldn't be")
+	}
+
+	// The species is worked out without registering a fused form, as the
+	// creature may yet be turned away
+	var species *Species
+	switch len(cs.Parts) {
+	case 0:
+		species = findBaseSpecies(cs.Name)
+		if species == nil {
+			return errors.New("it isn't a creature this game knows")
+		}
+		if cs.Form != "" && !slices.ContainsFunc(species.forms, func(f SpeciesForm) bool { return f.name == cs.Form }) {
+			return errors.New("it's in a form its species doesn't have")
+		}
+		species = species.withForm(cs.Form)
+	case 2:
+		a, b := cs.Parts[0], cs.Parts[1]
+		if len(a.Parts) != 0 || len(b.Parts) != 0 || !fusionAllowed(a.Name, b.Name) {
+			return errors.New("it was fused from creatures that can't be fused")
+		}
+		if cs.Name != fusionName(a.Name, b.Name) || cs.Form != "" {
+			return errors.New("it isn't the fused form of the creatures it was fused from")
+		}
+		for _, part := range cs.Parts {
+			if err := legitimate(part); err != nil {
+				return errors.New("one of the creatures it was fused from isn't right: " + err.Error())
+			}
+		}
+		fused := newFusionSpecies(a.Name, b.Name)
+		species = &fused
+	default:
+		return errors.New("it was fused from creatures that can't be fused")
+	}
+
+	// The best a stat could be: perfect individual values and a nature
+	// raising it, with room for fusion and each level's smallest HP gain
+	best := Creature{level: cs.Level, ivs: [StatCount]int{maxIV, maxIV, maxIV, maxIV}}
+	ceiling := func(stat, base int) int {
+		return best.statAt(stat, base)*11/10*(100+fusionBonus)/100 + cs.Level
+	}
+	if cs.HP < 0 || cs.HP > cs.MaxHP || cs.MaxHP > ceiling(StatHP, species.hp) ||
+		cs.Attack > ceiling(StatAttack, species.attack) ||
+		cs.Defense > ceiling(StatDefense, species.defense) ||
+		cs.Speed > ceiling(StatSpeed, species.speed) {
+		return errors.New("its stats are higher than it could have")
+	}
+
+	for _, m := range cs.Moves {
+		known, ok := knownMove(m.Name)
+		if !ok || m.Power != known.power || m.Accuracy != known.accuracy || m.Type != known.type1 ||
+			m.MaxPP != known.maxPP || m.Effect != known.effect || m.Chance != known.effectChance ||
+			m.Kind != known.kind || m.PP < 0 || m.PP > m.MaxPP {
+			return errors.New("it knows a move it couldn't have learned: " + m.Name)
+		}
+	}
+	return nil
+}
+
+// knownMove looks up a move any species starts with or learns, or the move
+// tutor teaches
+func knownMove(name string) (Move, bool) {
+	for _, species := range speciesList {
+		moves := slices.Clone(species.moves)
+		for _, form := range species.forms {
+			moves = append(moves, form.moves...)
+		}
+		for _, learned := range species.learnset {
+			moves = append(moves, learned.move)
+		}
+		for _, m := range moves {
+			if m.name == name {
+				return m, true
+			}
+		}
+	}
+	return findTutorMove(name)
+}
+
+// exportCreature writes a creature to the export file, and to the log, to
+// be imported into another save
+func (g *Game) exportCreature(c *Creature) string {
+	if c.egg {
+		return "Eggs can't be exported."
+	}
+	blob := encodeCreature(*c, g.playerName)
+	log.Println("Exported " + c.name + ": " + blob)
+	if err := saveStore.Write(shareExportFile, []byte(blob+"\n")); err != nil {
+		log.Println("Failed to write the export:", err)
+		return "Couldn't export " + c.name + "."
+	}
+	return "Exported " + c.name + " to " + shareExportFile + " beside the save."
+}
+
+// importCreature brings in the creature in the import file, as long as it
+// checks out and isn't already here
+func (g *Game) importCreature() string {
+	blob, err := saveStore.Read(shareImportFile)
+	if err != nil {
+		return "Put a creature's export in " + shareImportFile + " beside the save to import it."
+	}
+	s, err := decodeCreature(string(blob))
+	if err != nil {
+		return "Couldn't import the creature: " + err.Error() + "."
+	}
+
+	c := loadCreature(s.Creature)
+	for _, list := range [][]Creature{g.creatures, g.storage} {
+		for _, have := range list {
+			if have.id == c.id && have.trainer == c.trainer && have.metLevel == c.metLevel {
+				return c.name + " is already here."
+			}
+		}
+	}
+	if c.trainer == "" {
+		c.trainer = s.From
+	}
+	g.filterNickname(&c)
+	g.events.publish(Event{kind: EventReceive, species: c.name, form: c.form})
+	if len(g.creatures) < partySize {
+		g.creatures = append(g.creatures, c)
+		return "Imported " + c.displayName() + " Lv." + strconv.Itoa(c.level) + " from " + s.From + ". It joined the party."
+	}
+	g.storage = append(g.storage, c)
+	return "Imported " + c.displayName() + " Lv." + strconv.Itoa(c.level) + " from " + s.From + ". It was sent to storage."
+}
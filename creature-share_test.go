@@ -0,0 +1,53 @@
+package main
+
+import "testing"
+
+func TestLegitimateFusion(t *testing.T) {
+	defer resetFusions()
+	var a, b string
+	for _, x := range speciesList {
+		for _, y := range speciesList {
+			if a == "" && fusionAllowed(x.name, y.name) {
+				a, b = x.name, y.name
+			}
+		}
+	}
+	fused := saveCreature(fuseCreatures(newCreature(a, 10), newCreature(b, 10)))
+	resetFusions()
+
+	if err := legitimate(fused); err != nil {
+		t.Fatalf("a %s fused from a %s and a %s isn't legitimate: %v", fused.Name, a, b, err)
+	}
+	if len(fusedOrder) != 0 {
+		t.Errorf("checking a fused creature registered %d fused forms", len(fusedOrder))
+	}
+
+	renamed := fused
+	renamed.Name = b
+	same := fused
+	same.Parts = []CreatureSave{fused.Parts[0], fused.Parts[0]}
+	nested := fused
+	nested.Parts = []CreatureSave{fused, fused.Parts[1]}
+	single := fused
+	single.Parts = fused.Parts[:1]
+	for name, cs := range map[string]CreatureSave{"renamed": renamed, "same parts": same, "fused part": nested, "one part": single} {
+		if legitimate(cs) == nil {
+			t.Errorf("a fused creature with %s passed as legitimate", name)
+		}
+	}
+}
+
+func TestLegitimateMoves(t *testing.T) {
+	cs := saveCreature(newCreature(speciesList[0].name, 10))
+	if err := legitimate(cs); err != nil {
+		t.Fatalf("a new %s isn't legitimate: %v", cs.Name, err)
+	}
+
+	// A move given a sure poisoning is one the game never teaches
+	poison := cs
+	poison.Moves = append([]MoveSave(nil), cs.Moves...)
+	poison.Moves[0].Effect, poison.Moves[0].Chance = EffectPoison, 100
+	if legitimate(poison) == nil {
+		t.Errorf("%s passed as legitimate with a move that always poisons", cs.Name)
+	}
+}
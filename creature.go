@@ -5,20 +5,59 @@ import (
 	"image/color"
 )
 
+// Status condition constants
+const (
+	StatusNone = iota
+	StatusPoison
+)
+
 // Creature represents a creature in the game
 type Creature struct {
-	name     string
-	hp       int
-	maxHP    int
-	attack   int
-	defense  int
-	speed    int
-	type1    string
-	moves    []Move
-	level    int
+	name    string
+	hp      int
+	maxHP   int
+	attack  int
+	defense int
+	speed   int
+	type1   string
+	type2   string
+	moves   []Move
+	level   int
+	// EXP earned towards the next level
+	exp      int
 	inBattle bool
 	position image.Point
 	color    color.RGBA
+	// Item the creature is holding, if any, and the letter written on it if
+	// it's mail
+	heldItem string
+	mail     *Letter
+	// Status condition, which lasts until healed
+	status int
+	// Name of the trainer who first caught the creature, and the nickname
+	// they gave it, if any
+	trainer  string
+	nickname string
+	// Where and at what level it joined its trainer
+	metLocation string
+	metLevel    int
+	// Five-digit ID number, drawn against in the lottery
+	id int
+	// The two creatures fused to make this one, given back if it's split
+	fusedFrom []Creature
+	// Regional form, if it isn't the regular one
+	form string
+	// Whether it's still an egg, and the steps left before it hatches
+	egg      bool
+	eggSteps int
+	// How attached it is to its trainer, from 0 to maxFriendship
+	friendship int
+	// Stat bonuses it was born with, from 0 to maxIV, and whether it's in
+	// its rare shiny colors
+	ivs   [StatCount]int
+	shiny bool
+	// Index into natures
+	nature int
 }
 
 // Move represents a move/attack
@@ -27,4 +66,34 @@ type Move struct {
 	power    int
 	accuracy int
 	type1    string
+	// Uses left and the most the move can have
+	pp    int
+	maxPP int
+	// Secondary effect and its percent chance of taking hold
+	effect       int
+	effectChance int
+	// What else it does, like recoil or charging up first, as the battle
+	// rules number it
+	kind int
+}
+
+// displayName returns what a creature is called: its nickname, or its
+// species if it hasn't got one
+func (c *Creature) displayName() string {
+	if c.nickname != "" {
+		return c.nickname
+	}
+	return c.name
+}
+
+// heal fully restores a creature's HP and PP and cures its status
+func (c *Creature) heal() {
+	if c.egg {
+		return
+	}
+	c.hp = c.maxHP
+	c.status = StatusNone
+	for i := range c.moves {
+		c.moves[i].pp = c.moves[i].maxPP
+	}
 }
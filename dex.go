@@ -0,0 +1,282 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Dex records which species the player has seen and caught, and what the
+// dex screen is showing
+type Dex struct {
+	seen, caught map[string]bool
+	// Forms seen of each species, the regular one under an empty name
+	forms map[string]map[string]bool
+	// Species highlighted, and the first one in view when the list scrolls
+	selected int
+	top      int
+	// Which of the highlighted species' discovered forms is shown
+	form int
+	// Sprite of the highlighted species, and the color it was made for
+	sprite      *AnimatedSprite
+	spriteColor color.RGBA
+	// Area map of the highlighted species open, and the biome of each of its
+	// cells, worked out for the world with the seed kept
+	areaOpen  bool
+	areaCells [areaMapCells][areaMapCells]uint8
+	areaReady bool
+	areaSeed  int64
+}
+
+// markSeen records a species as seen
+func (d *Dex) markSeen(name string) {
+	if d.seen == nil {
+		d.seen = make(map[string]bool)
+	}
+	d.seen[name] = true
+}
+
+// markForm records a species as seen in a form, the regular one being
+// named by an empty string
+func (d *Dex) markForm(name, form string) {
+	d.markSeen(name)
+	if d.forms == nil {
+		d.forms = make(map[string]map[string]bool)
+	}
+	if d.forms[name] == nil {
+		d.forms[name] = make(map[string]bool)
+	}
+	d.forms[name][form] = true
+}
+
+// discoveredForms returns the forms of a species seen so far, regular first
+// and then in the order the species lists them. Species seen before forms
+// were recorded count as seen in their regular form.
+func (d *Dex) discoveredForms(species *Species) []string {
+	seen := d.forms[species.name]
+	if len(seen) == 0 {
+		return []string{""}
+	}
+	forms := []string{}
+	if seen[""] {
+		forms = append(forms, "")
+	}
+	for _, f := range species.forms {
+		if seen[f.name] {
+			forms = append(forms, f.name)
+		}
+	}
+	return forms
+}
+
+// markCaught records a species as caught, which means it has been seen too,
+// and reports whether it's new to the dex
+func (d *Dex) markCaught(name string) bool {
+	d.markSeen(name)
+	if d.caught == nil {
+		d.caught = make(map[string]bool)
+	}
+	added := !d.caught[name]
+	d.caught[name] = true
+	return added
+}
+
+// list returns the species in a set in dex order, for saving
+func (d *Dex) list(set map[string]bool) []string {
+	names := []string{}
+	for i := range speciesCount() {
+		if name := speciesAt(i).name; set[name] {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// completion returns the share of all species caught, as a whole percentage
+func (d *Dex) completion() int {
+	return len(d.caught) * 100 / speciesCount()
+}
+
+// subscribeDex fills in the dex as creatures are met in battle, caught or
+// received, with a notice for each new species or form once the game is
+// under way
+func (g *Game) subscribeDex() {
+	g.events.subscribe(EventEncounter, func(e Event) { g.dex.markForm(e.species, e.form) })
+	register := func(e Event) {
+		newForm := !g.dex.forms[e.species][e.form]
+		g.dex.markForm(e.species, e.form)
+		if (g.dex.markCaught(e.species) || newForm) && g.gameInitialized {
+			g.showToast(formName(e.species, e.form) + " was added to the dex!")
+		}
+	}
+	g.events.subscribe(EventCapture, register)
+	g.events.subscribe(EventReceive, register)
+}
+
+// openDex switches to the dex screen
+func (g *Game) openDex() {
+	g.gameState = StateDex
+	g.dex.selected, g.dex.top, g.dex.areaOpen = 0, 0, false
+}
+
+// dexRows returns how many species fit in the list at once
+func (g *Game) dexRows() int {
+	return (screenHeight - 35 - g.listTop()) / g.rowHeight()
+}
+
+// dexRects lays out the rows of the dex list in view
+func (g *Game) dexRects() []Rect {
+	rows := min(g.dexRows(), speciesCount()-g.dex.top)
+	return listRects(20, g.listTop(), 130, g.rowHeight(), rows)
+}
+
+// updateDex moves through the species list, scrolling to keep the
+// highlighted one in view
+func (g *Game) updateDex() {
+	d := &g.dex
+	if d.areaOpen {
+		g.updateAreaDex()
+		return
+	}
+
+	previous := d.selected
+	if g.input.IsActionJustPressed(ebiten.KeyUp) {
+		d.selected = (d.selected - 1 + speciesCount()) % speciesCount()
+	} else if g.input.IsActionJustPressed(ebiten.KeyDown) {
+		d.selected = (d.selected + 1) % speciesCount()
+	}
+
+	row := d.selected - d.top
+	clicked := g.mouseSelect(g.dexRects(), &row)
+	d.selected = d.top + row
+
+	// Left and right flip through the discovered forms of the species
+	forms := len(d.discoveredForms(speciesAt(d.selected)))
+	if d.selected != previous {
+		d.form = 0
+	} else if g.input.IsActionJustPressed(ebiten.KeyLeft) {
+		d.form = (d.form - 1 + forms) % forms
+	} else if g.input.IsActionJustPressed(ebiten.KeyRight) {
+		d.form = (d.form + 1) % forms
+	}
+
+	// Seen species have an area map of where they're found
+	if g.input.IsActionJustPressed(ebiten.KeySpace) || g.input.IsActionJustPressed(ebiten.KeyEnter) || clicked {
+		d.areaOpen = d.seen[speciesAt(d.selected).name]
+		return
+	}
+
+	rows := g.dexRows()
+	if d.selected < d.top {
+		d.top = d.selected
+	} else if d.selected >= d.top+rows {
+		d.top = d.selected - rows + 1
+	}
+
+	if g.input.IsActionJustPressed(ebiten.KeyEscape) || g.input.IsActionJustPressed(ebiten.KeyB) {
+		g.gameState = StateOverworld
+	}
+}
+
+// drawDex draws the species list with the highlighted species' entry beside
+// it. Species not seen yet are left blank, and only caught ones have their
+// full entry.
+func (g *Game) drawDex(screen *ebiten.Image) {
+	d := &g.dex
+	if d.areaOpen {
+		g.drawAreaDex(screen)
+		return
+	}
+	g.drawPanel(screen, 10, 10, float32(screenWidth-20), float32(screenHeight-20), color.RGBA{150, 40, 40, 240})
+
+	drawText := func(x, y int, line string, clr color.Color) {
+		op := &text.DrawOptions{}
+		op.GeoM.Translate(float64(x), float64(y))
+		op.ColorScale.ScaleWithColor(g.uiText(clr))
+		text.Draw(screen, line, g.fontFace, op)
+	}
+
+	drawText(20, 30, "Dex", color.White)
+	progress := "Seen " + strconv.Itoa(len(d.seen)) + "  Caught " + strconv.Itoa(len(d.caught)) + " (" + strconv.Itoa(d.completion()) + "%)"
+	drawText(screenWidth-20-g.textWidth(progress), 30, progress, color.White)
+
+	// Species list, with a ball by the ones caught
+	rects := g.dexRects()
+	for i, r := range rects {
+		species := speciesAt(d.top + i)
+		label := fmt.Sprintf("%03d ", d.top+i+1)
+		if d.seen[species.name] {
+			label += species.name
+		} else {
+			label += "???"
+		}
+
+		clr := color.RGBA{255, 255, 255, 255}
+		if d.top+i == d.selected {
+			clr = color.RGBA{255, 255, 0, 255}
+			drawText(r.x, r.y, ">", clr)
+		}
+		drawText(r.x+g.selectorWidth(), r.y, label, clr)
+		if d.caught[species.name] {
+			drawBall(screen, float32(r.x+r.width), float32(r.y+g.lineHeight()/2), 4)
+		}
+	}
+
+	// The highlighted species' entry
+	species := speciesAt(d.selected)
+	x, y := 165, g.listTop()
+	if !d.seen[species.name] {
+		drawText(x, y, "No data yet.", color.RGBA{200, 200, 200, 255})
+		g.drawHint(screen, "ESC to go back")
+		return
+	}
+
+	// The form shown, with a dot for each one discovered
+	forms := d.discoveredForms(species)
+	form := forms[min(d.form, len(forms)-1)]
+	shown := species.withForm(form)
+	if d.sprite == nil || d.spriteColor != shown.color {
+		d.sprite, d.spriteColor = newCreatureSprite(shown.color), shown.color
+	}
+	d.sprite.draw(screen, float32(x), float32(y), g.ticks)
+	if len(forms) > 1 {
+		formX := x + tileSize + 8
+		label := form
+		if label == "" {
+			label = "Regular"
+		}
+		drawText(formX, y, "< "+label+" >", color.White)
+		for i := range forms {
+			clr := color.RGBA{150, 150, 150, 255}
+			if forms[i] == form {
+				clr = color.RGBA{255, 255, 0, 255}
+			}
+			vector.DrawFilledCircle(screen, float32(formX+4+i*10), float32(y+g.lineSpacing()+4), 3, clr, true)
+		}
+	}
+	y += tileSize + 4
+
+	drawLine := func(line string, clr color.Color) {
+		drawText(x, y, line, clr)
+		y += g.lineSpacing()
+	}
+	drawLine(species.name+"  "+typeNames(shown.type1, shown.type2), color.White)
+	if !d.caught[species.name] {
+		drawLine("Not caught yet.", color.RGBA{200, 200, 200, 255})
+	} else {
+		drawLine(fmt.Sprintf("HT %.1fm  WT %.1fkg", species.height, species.weight), color.White)
+		for _, line := range g.wrapText(species.entry, screenWidth-20-x) {
+			drawLine(line, color.RGBA{230, 220, 200, 255})
+		}
+	}
+
+	if len(forms) > 1 {
+		g.drawHint(screen, "Left/Right for forms, Space for area")
+	} else {
+		g.drawHint(screen, "Space for area, ESC to go back")
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+)
+
+//go:embed dialogues/dialogues.json
+var dialogueFiles embed.FS
+
+// maxBranchSteps bounds how many branches in a row a conversation follows
+// before showing anything, so a loop in a file can't hang the game
+const maxBranchSteps = 32
+
+// DialogueTree is a conversation, as configured in dialogues/dialogues.json:
+// nodes of text joined by choices and branches, starting from Start
+type DialogueTree struct {
+	Start string                   `json:"start"`
+	Nodes map[string]*DialogueNode `json:"nodes"`
+}
+
+// DialogueNode is one step of a conversation. A node with If is a branch,
+// going on to Then if the condition holds and Else if not, without showing
+// anything. Any other node shows its text, then offers its choices, or goes
+// on to Next, or ends the conversation.
+//
+// Text can include {player}, {rival}, {money} and {badges}. Conditions
+// name a flag, which must be set, or with a leading ! must not be. Emotes
+// are named as in emoteNames.
+type DialogueNode struct {
+	If   string `json:"if,omitempty"`
+	Then string `json:"then,omitempty"`
+	Else string `json:"else,omitempty"`
+
+	Text    string           `json:"text,omitempty"`
+	Choices []DialogueChoice `json:"choices,omitempty"`
+	Next    string           `json:"next,omitempty"`
+	// Flags set and an item given when the node is shown, and an emote
+	// shown over the speaker
+	Set   []string `json:"set,omitempty"`
+	Give  string   `json:"give,omitempty"`
+	Emote string   `json:"emote,omitempty"`
+}
+
+// DialogueChoice is an answer the player can pick, only offered if its
+// condition holds
+type DialogueChoice struct {
+	Text string `json:"text"`
+	Next string `json:"next,omitempty"`
+	If   string `json:"if,omitempty"`
+}
+
+// dialogueTrees holds every conversation by ID, loaded from the embedded file
+var dialogueTrees = loadDialogueTrees()
+
+// loadDialogueTrees reads the conversations from dialogues/dialogues.json,
+// checking every node they lead to is there
+func loadDialogueTrees() map[string]*DialogueTree {
+	var file map[string]*DialogueTree
+	data, err := dialogueFiles.ReadFile("dialogues/dialogues.json")
+	if err == nil {
+		err = json.Unmarshal(data, &file)
+	}
+	for id, tree := range file {
+		if err == nil {
+			err = tree.check(id)
+		}
+	}
+	if err != nil {
+		log.Fatalf("loading dialogues: %v", err)
+	}
+	return file
+}
+
+// check reports a node a tree leads to that it doesn't have, or an item or
+// emote it uses that doesn't exist
+func (t *DialogueTree) check(id string) error {
+	targets := []string{t.Start}
+	for name, node := range t.Nodes {
+		if node.Give != "" && findItem(node.Give) == nil {
+			return fmt.Errorf("%s: node %s gives unknown item %s", id, name, node.Give)
+		}
+		if _, ok := findEmote(node.Emote); node.Emote != "" && !ok {
+			return fmt.Errorf("%s: node %s has unknown emote %s", id, name, node.Emote)
+		}
+		targets = append(targets, node.Then, node.Else, node.Next)
+		for _, choice := range node.Choices {
+			targets = append(targets, choice.Next)
+		}
+	}
+	for _, target := range targets {
+		if _, ok := t.Nodes[target]; target != "" && !ok {
+			return fmt.Errorf("%s: no node %s", id, target)
+		}
+	}
+	return nil
+}
+
+// holds reports whether a dialogue condition holds: a flag that must be
+// set, or with a leading ! must not be. No condition always holds.
+func (g *Game) holds(condition string) bool {
+	if flag, ok := strings.CutPrefix(condition, "!"); ok {
+		return !g.flags[flag]
+	}
+	return condition == "" || g.flags[condition]
+}
+
+// fillVariables fills in the money and badge placeholders in a line of
+// dialogue; showDialogue fills in the names
+func (g *Game) fillVariables(message string) string {
+	return strings.NewReplacer("{money}", "$"+strconv.Itoa(g.money), "{badges}", strconv.Itoa(g.badges)).Replace(message)
+}
+
+// startConversation starts the conversation with an ID from the top, with
+// whoever's speaking it
+func (g *Game) startConversation(id string, speaker Entity) {
+	tree, ok := dialogueTrees[id]
+	if !ok {
+		log.Println("No dialogue with the ID", id)
+		return
+	}
+	g.continueConversation(tree, tree.Start, speaker)
+}
+
+// continueConversation shows a node of a conversation, following branches
+// until it reaches one with something to say
+func (g *Game) continueConversation(tree *DialogueTree, id string, speaker Entity) {
+	for range maxBranchSteps {
+		node := tree.Nodes[id]
+		if node == nil {
+			return
+		}
+		if node.If != "" {
+			id = node.Else
+			if g.holds(node.If) {
+				id = node.Then
+			}
+			continue
+		}
+
+		for _, flag := range node.Set {
+			g.flags[flag] = true
+		}
+		if node.Give != "" {
+			g.addItem(node.Give, 1)
+		}
+		if emote, ok := findEmote(node.Emote); ok {
+			g.showEmote(speaker, emote, 0)
+		}
+		message := g.fillVariables(node.Text)
+
+		var labels, next []string
+		for _, choice := range node.Choices {
+			if g.holds(choice.If) {
+				labels = append(labels, g.fillNames(g.fillVariables(choice.Text)))
+				next = append(next, choice.Next)
+			}
+		}
+		switch {
+		case len(labels) > 0:
+			g.showChoice(message, labels, func(i int) {
+				g.continueConversation(tree, next[i], speaker)
+			})
+		case node.Next != "":
+			g.showDialogueThen(message, func() {
+				g.continueConversation(tree, node.Next, speaker)
+			})
+		default:
+			g.showDialogue(message)
+		}
+		return
+	}
+	log.Println("Dialogue branches too deep at node", id)
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// dialogueLines is how many lines of text each page of dialogue shows
+const dialogueLines = 3
+
+// Dialogue is a box of text shown over the overworld, one page at a time
+type Dialogue struct {
+	active bool
+	pages  [][]string
+	page   int
+	// Prompts and choices end with a list of answers; choose runs with the
+	// one the player picks, unless they back out
+	options  []string
+	selected int
+	choose   func(int)
+	// Runs once the dialogue box closes, if set
+	after func()
+}
+
+// showDialogue opens the dialogue box with the given text, wrapping it into
+// pages. Name placeholders in the text are filled in.
+func (g *Game) showDialogue(message string) {
+	lines := g.wrapText(g.fillNames(message), screenWidth-24)
+
+	pages := [][]string{}
+	for i := 0; i < len(lines); i += dialogueLines {
+		pages = append(pages, lines[i:min(i+dialogueLines, len(lines))])
+	}
+
+	g.dialogue = Dialogue{active: true, pages: pages}
+}
+
+// showPrompt opens the dialogue box with a yes/no question, running confirm
+// if the player answers yes
+func (g *Game) showPrompt(message string, confirm func()) {
+	g.showChoice(message, []string{"YES", "NO"}, func(i int) {
+		if i == 0 {
+			confirm()
+		}
+	})
+}
+
+// showChoice opens the dialogue box with a question and a list of answers,
+// running choose with the index of the one picked. Backing out picks none.
+func (g *Game) showChoice(message string, options []string, choose func(int)) {
+	g.showDialogue(message)
+	g.dialogue.options = options
+	g.dialogue.choose = choose
+}
+
+// showDialogueThen opens the dialogue box, running after once it's closed
+func (g *Game) showDialogueThen(message string, after func()) {
+	g.showDialogue(message)
+	g.dialogue.after = after
+}
+
+// updateDialogue advances or closes the dialogue box
+func (g *Game) updateDialogue() {
+	lastPage := g.dialogue.page == len(g.dialogue.pages)-1
+
+	// Move between the answers on the last page of a prompt
+	if count := len(g.dialogue.options); lastPage && count > 0 {
+		if g.input.IsActionJustPressed(ebiten.KeyUp) {
+			g.dialogue.selected = (g.dialogue.selected - 1 + count) % count
+		} else if g.input.IsActionJustPressed(ebiten.KeyDown) {
+			g.dialogue.selected = (g.dialogue.selected + 1) % count
+		}
+		if g.input.IsActionJustPressed(ebiten.KeyEscape) {
+			g.dialogue = Dialogue{}
+			return
+		}
+	}
+
+	if g.input.IsActionJustPressed(ebiten.KeySpace) || g.input.IsActionJustPressed(ebiten.KeyEnter) {
+		g.dialogue.page++
+		if g.dialogue.page >= len(g.dialogue.pages) {
+			choose, selected, after := g.dialogue.choose, g.dialogue.selected, g.dialogue.after
+			g.dialogue = Dialogue{}
+			if choose != nil {
+				choose(selected)
+			}
+			if after != nil {
+				after()
+			}
+		}
+	}
+}
+
+// drawDialogue draws the dialogue box at the bottom of the screen
+func (g *Game) drawDialogue(screen *ebiten.Image) {
+	if !g.dialogue.active {
+		return
+	}
+
+	dialogueHeight := float32(dialogueLines*g.lineSpacing() + 11)
+	y := screenHeight - dialogueHeight - 4
+	g.drawPanel(screen, 4, y, screenWidth-8, dialogueHeight, color.RGBA{250, 250, 250, 240})
+	vector.StrokeRect(screen, 4, y, screenWidth-8, dialogueHeight, 2, color.RGBA{40, 40, 60, 255}, true)
+
+	for i, line := range g.dialogue.pages[g.dialogue.page] {
+		g.drawText(screen, line, 12, float64(y)+6+float64(i*g.lineSpacing()), color.RGBA{30, 30, 30, 255})
+	}
+
+	// Show the answers on the last page of a prompt
+	if options := g.dialogue.options; len(options) > 0 && g.dialogue.page == len(g.dialogue.pages)-1 {
+		boxWidth := float32(g.textWidth("> ") + g.widestText(options) + 17)
+		boxHeight := float32(len(options)*g.lineSpacing() + 10)
+		boxX := screenWidth - 8 - boxWidth
+		boxY := y - boxHeight - 4
+		g.drawPanel(screen, boxX, boxY, boxWidth, boxHeight, color.RGBA{250, 250, 250, 240})
+		vector.StrokeRect(screen, boxX, boxY, boxWidth, boxHeight, 2, color.RGBA{40, 40, 60, 255}, true)
+		for i, option := range options {
+			label := "  " + option
+			if i == g.dialogue.selected {
+				label = "> " + option
+			}
+			g.drawText(screen, label, float64(boxX)+6, float64(boxY)+5+float64(i*g.lineSpacing()), color.RGBA{30, 30, 30, 255})
+		}
+	}
+
+	// Show an arrow when there's another page to read
+	if g.dialogue.page < len(g.dialogue.pages)-1 && g.ticks/20%2 == 0 {
+		g.drawText(screen, "v", screenWidth-20, float64(y+dialogueHeight)-float64(g.lineHeight())-3, color.RGBA{30, 30, 30, 255})
+	}
+}
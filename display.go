@@ -0,0 +1,93 @@
+package main
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// uiScales are the UI scale choices, where 0 fits the window automatically
+var uiScales = []int{0, 1, 2, 3, 4}
+
+// Display draws the game at its logical resolution and scales it up to fill
+// the window at a whole number of pixels, so text stays sharp at any window
+// size and on high-DPI screens. Whatever the game doesn't cover is
+// letterboxed.
+type Display struct {
+	canvas *ebiten.Image
+	// Window size and pixels per window unit, in device pixels
+	width, height float64
+	deviceScale   float64
+}
+
+// layout sizes the screen to the window's device pixels
+func (d *Display) layout(outsideWidth, outsideHeight float64) (float64, float64) {
+	d.deviceScale = ebiten.Monitor().DeviceScaleFactor()
+	d.width = math.Ceil(outsideWidth * d.deviceScale)
+	d.height = math.Ceil(outsideHeight * d.deviceScale)
+	return d.width, d.height
+}
+
+// scale returns how many device pixels each logical pixel covers. Automatic
+// scaling fills as much of the window as it can; a fixed UI scale is kept the
+// same size on high-DPI screens, but shrinks if the window is too small.
+func (d *Display) scale(uiScale int) float64 {
+	if d.width == 0 {
+		return 1
+	}
+	fit := math.Min(d.width/screenWidth, d.height/screenHeight)
+	if fit < 1 {
+		// Too small for even one pixel each, so squeeze it in
+		return fit
+	}
+	whole := math.Floor(fit)
+	if uiScale > 0 {
+		return math.Max(1, math.Min(math.Round(float64(uiScale)*d.deviceScale), whole))
+	}
+	return whole
+}
+
+// origin returns where the top-left corner of the game goes on the screen
+func (d *Display) origin(scale float64) (float64, float64) {
+	return math.Floor((d.width - screenWidth*scale) / 2), math.Floor((d.height - screenHeight*scale) / 2)
+}
+
+// logicalScreen returns the image the game draws onto
+func (d *Display) logicalScreen() *ebiten.Image {
+	if d.canvas == nil {
+		d.canvas = ebiten.NewImage(screenWidth, screenHeight)
+	}
+	return d.canvas
+}
+
+// present scales the finished frame onto the window
+func (d *Display) present(screen *ebiten.Image, uiScale int) {
+	screen.Fill(color.Black)
+
+	scale := d.scale(uiScale)
+	x, y := d.origin(scale)
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(scale, scale)
+	op.GeoM.Translate(x, y)
+	if scale < 1 {
+		op.Filter = ebiten.FilterLinear
+	}
+	screen.DrawImage(d.canvas, op)
+}
+
+// toLogical converts a position on the window, as reported for the mouse and
+// touches, to the game's logical pixels
+func (g *Game) toLogical(x, y int) (int, int) {
+	scale := g.display.scale(g.settings.UIScale)
+	originX, originY := g.display.origin(scale)
+	return int(math.Floor((float64(x) - originX) / scale)), int(math.Floor((float64(y) - originY) / scale))
+}
+
+// uiScaleLabel names a UI scale choice for the options screen
+func uiScaleLabel(uiScale int) string {
+	if uiScale == 0 {
+		return "Auto"
+	}
+	return string(rune('0'+uiScale)) + "x"
+}
@@ -0,0 +1,67 @@
+package main
+
+import "math/rand"
+
+// Egg constants
+const (
+	// Steps an egg has to be carried in the party before it hatches
+	eggHatchSteps = 1000
+	// Level a creature hatches at
+	eggLevel = 5
+	// Flag set once the weekend visitor has handed over their egg
+	visitorEggFlag = "visitor-egg"
+)
+
+// eggSpecies are the creatures that can hatch from a gift egg
+var eggSpecies = []string{"Sparkitty", "Zephyrd", "Leafling"}
+
+// newEgg makes an egg of a species, which has no HP until it hatches so it
+// never goes into battle
+func newEgg(species string) Creature {
+	c := newCreature(species, eggLevel)
+	c.egg = true
+	c.eggSteps = eggHatchSteps
+	c.hp = 0
+	return c
+}
+
+// giveEgg adds an egg of a random species to the party, which needs room
+// for it
+func (g *Game) giveEgg() {
+	egg := newEgg(eggSpecies[rand.Intn(len(eggSpecies))])
+	egg.trainer = g.playerName
+	g.creatures = append(g.creatures, egg)
+	g.audio.playSound("fanfare")
+}
+
+// checkHatch hatches the first egg in the party that's been carried far
+// enough. It reports whether it interrupted the step to show it.
+func (g *Game) checkHatch() bool {
+	for i := range g.creatures {
+		c := &g.creatures[i]
+		if !c.egg || c.eggSteps > 0 {
+			continue
+		}
+		c.egg = false
+		c.hp = c.maxHP
+		c.friendship = hatchFriendship
+		g.recordMet(c)
+		g.events.publish(Event{kind: EventReceive, species: c.name, form: c.form})
+		g.audio.playCry(c.name)
+		g.showDialogue("Oh? The egg is hatching... " + c.name + " hatched from the egg!")
+		return true
+	}
+	return false
+}
+
+// eggText says how close an egg is to hatching
+func eggText(c *Creature) string {
+	switch {
+	case c.eggSteps <= eggHatchSteps/10:
+		return "Sounds are coming from inside. It will hatch soon!"
+	case c.eggSteps <= eggHatchSteps/2:
+		return "It moves around inside sometimes."
+	default:
+		return "It looks like it will take a long time to hatch."
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Emote constants
+const (
+	// How long an emote is up for unless asked otherwise
+	emoteFrames = simulationRate
+	// Frames an emote takes to pop up
+	emotePopFrames = 6
+	// Size of the bubble an emote is drawn in
+	emoteSize = 14
+)
+
+// Emote kinds
+const (
+	EmoteExclaim = iota
+	EmoteQuestion
+	EmoteHeart
+	EmoteSweat
+	EmoteCount
+)
+
+// emoteNames are what emotes are called in data files
+var emoteNames = [EmoteCount]string{"exclaim", "question", "heart", "sweat"}
+
+// Kinds of entity an emote can be shown over
+const (
+	EntityPlayer = iota
+	EntityFollower
+	EntityRival
+	// A person standing on the map, found by their tile
+	EntityObject
+)
+
+// Entity is something on the overworld an emote is shown over
+type Entity struct {
+	kind int
+	tile Point
+}
+
+// objectEntity returns the entity standing on a tile of the map
+func objectEntity(tile Point) Entity {
+	return Entity{kind: EntityObject, tile: tile}
+}
+
+// Emote is a bubble shown over an entity's head for a while
+type Emote struct {
+	entity Entity
+	kind   int
+	// Frames left and how many it started with
+	frames, total int
+}
+
+// findEmote looks up an emote kind by name, as used in data files
+func findEmote(name string) (int, bool) {
+	for kind, emoteName := range emoteNames {
+		if emoteName == name {
+			return kind, true
+		}
+	}
+	return 0, false
+}
+
+// showEmote shows an emote over an entity for a number of frames, or for
+// the usual time if it's 0, replacing any it already has
+func (g *Game) showEmote(entity Entity, kind, frames int) {
+	if frames <= 0 {
+		frames = emoteFrames
+	}
+	emote := Emote{entity: entity, kind: kind, frames: frames, total: frames}
+	for i := range g.emotes {
+		if g.emotes[i].entity == entity {
+			g.emotes[i] = emote
+			return
+		}
+	}
+	g.emotes = append(g.emotes, emote)
+}
+
+// updateEmotes runs down the emotes showing, dropping the ones that are done
+func (g *Game) updateEmotes() {
+	kept := g.emotes[:0]
+	for _, e := range g.emotes {
+		if e.frames--; e.frames > 0 {
+			kept = append(kept, e)
+		}
+	}
+	g.emotes = kept
+}
+
+// entityPosition returns where an entity is drawn on the overworld, as the
+// top-left corner of its tile, and whether it's there to be drawn
+func (g *Game) entityPosition(entity Entity) (float32, float32, bool) {
+	switch entity.kind {
+	case EntityPlayer:
+		return g.player.visualX, g.player.visualY, true
+	case EntityFollower:
+		f := &g.follower
+		return f.visualX, f.visualY, len(g.creatures) > 0 && (f.tileX != g.player.tileX || f.tileY != g.player.tileY)
+	case EntityRival:
+		return float32(g.rival.x * tileSize), float32(g.rival.y * tileSize), g.rival.active
+	default:
+		_, ok := g.worldMap.objects[entity.tile]
+		return float32(entity.tile.x * tileSize), float32(entity.tile.y * tileSize), ok
+	}
+}
+
+// drawEmotes draws each emote in a bubble over its entity's head, popping
+// up as it appears
+func (g *Game) drawEmotes(screen *ebiten.Image) {
+	for _, e := range g.emotes {
+		x, y, ok := g.entityPosition(e.entity)
+		if !ok {
+			continue
+		}
+		rise := float32(0)
+		if shown := e.total - e.frames; shown < emotePopFrames && g.settings.Animation != AnimationOff {
+			rise = float32(emotePopFrames-shown) * 2
+		}
+		left := x - g.camera.x + (tileSize-emoteSize)/2
+		top := y - g.camera.y - emoteSize - 2 + rise
+		g.drawEmote(screen, left, top, e.kind)
+	}
+}
+
+// drawEmote draws an emote's bubble with its top-left corner at x, y: a box
+// with a tail pointing down, and the symbol inside
+func (g *Game) drawEmote(screen *ebiten.Image, x, y float32, kind int) {
+	g.drawPanel(screen, x, y, emoteSize, emoteSize, color.RGBA{250, 250, 250, 245})
+	vector.StrokeRect(screen, x, y, emoteSize, emoteSize, 1, color.RGBA{40, 40, 60, 255}, true)
+	cx, cy := x+emoteSize/2, y+emoteSize/2
+	fillTriangle(screen, cx, y+emoteSize, 3, 4, color.RGBA{250, 250, 250, 245})
+
+	switch kind {
+	case EmoteExclaim:
+		red := color.RGBA{210, 40, 40, 255}
+		vector.DrawFilledRect(screen, cx-1, cy-5, 2, 6, red, true)
+		vector.DrawFilledRect(screen, cx-1, cy+3, 2, 2, red, true)
+	case EmoteQuestion:
+		g.drawText(screen, "?", float64(cx)-float64(g.textWidth("?"))/2, float64(y)+1, color.RGBA{40, 60, 200, 255})
+	case EmoteHeart:
+		pink := color.RGBA{230, 60, 110, 255}
+		vector.DrawFilledCircle(screen, cx-2, cy-1, 2.5, pink, true)
+		vector.DrawFilledCircle(screen, cx+2, cy-1, 2.5, pink, true)
+		fillTriangle(screen, cx, cy, 4.5, 5, pink)
+	case EmoteSweat:
+		blue := color.RGBA{80, 150, 240, 255}
+		vector.DrawFilledCircle(screen, cx, cy+2, 3, blue, true)
+		fillTriangle(screen, cx, cy+1, 2.8, -6, blue)
+	}
+}
+
+// fillTriangle fills a triangle standing on a flat side centered on cx at
+// y, with its point height below, or above if height is negative, a row of
+// pixels at a time
+func fillTriangle(screen *ebiten.Image, cx, y, halfWidth, height float32, clr color.Color) {
+	rows := int(abs32(height))
+	for i := range rows {
+		w := halfWidth * float32(rows-i) / float32(rows)
+		row := y + float32(i)
+		if height < 0 {
+			row = y - float32(i) - 1
+		}
+		vector.DrawFilledRect(screen, cx-w, row, 2*w, 1, clr, false)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import "time"
+
+// Field abilities a party's lead creature has that change how often wild
+// creatures appear
+const (
+	// Wild creatures keep clear of a stealthy lead
+	AbilityStealth = "Stealth"
+	// Wild creatures are drawn to a lead with a lure
+	AbilityLure = "Lure"
+)
+
+// Encounter rate modifier constants, each multiplying the chance of an
+// encounter on a step
+const (
+	// While a repel is working, on top of it scaring off weak creatures
+	repelRateFactor = 0.5
+	// With a stealthy lead, or one with a lure
+	stealthRateFactor = 0.5
+	lureRateFactor    = 1.5
+	// At night, outdoors, when more creatures are out and about
+	nightRateFactor = 1.25
+	// For each step of bonus the chain gives, as word of it gets around
+	chainRateFactor = 0.1
+	// While riding the bike, which is past most creatures before they notice
+	bikeRateFactor = 0.6
+)
+
+// EncounterModifier scales the chance of a wild encounter on a step through
+// a zone; 1 leaves it as it is
+type EncounterModifier func(g *Game, zone int) float32
+
+// encounterModifiers are applied in turn to the zone's rate on every step.
+// New ones go on the end.
+var encounterModifiers = []EncounterModifier{
+	repelRateModifier,
+	leadAbilityRateModifier,
+	timeOfDayRateModifier,
+	chainRateModifier,
+	bikeRateModifier,
+}
+
+// encounterRate returns the chance of meeting a wild creature on a step
+// through a zone: its base rate, scaled by every modifier
+func (g *Game) encounterRate(zone int) float32 {
+	rate := encounterZones[zone].rate
+	for _, modify := range encounterModifiers {
+		rate *= modify(g, zone)
+	}
+	return min32(rate, 1)
+}
+
+// repelRateModifier thins out encounters while a repel is working
+func repelRateModifier(g *Game, zone int) float32 {
+	if g.repelLeft() > 0 {
+		return repelRateFactor
+	}
+	return 1
+}
+
+// leadAbilityRateModifier applies the ability of the creature leading the
+// party, if it's one that changes the rate
+func leadAbilityRateModifier(g *Game, zone int) float32 {
+	if len(g.creatures) == 0 {
+		return 1
+	}
+	lead := &g.creatures[g.activeCreature]
+	species := findSpecies(lead.name)
+	if lead.egg || species == nil {
+		return 1
+	}
+	switch species.ability {
+	case AbilityStealth:
+		return stealthRateFactor
+	case AbilityLure:
+		return lureRateFactor
+	}
+	return 1
+}
+
+// timeOfDayRateModifier brings out more creatures at night, except in caves,
+// which are as dark by day
+func timeOfDayRateModifier(g *Game, zone int) float32 {
+	if !g.worldMap.static && isNight(time.Now()) {
+		return nightRateFactor
+	}
+	return 1
+}
+
+// chainRateModifier brings out more creatures the longer the chain runs
+func chainRateModifier(g *Game, zone int) float32 {
+	return 1 + chainRateFactor*float32(g.chainBonus(g.chain.species))
+}
+
+// bikeRateModifier lets the player ride past creatures while on the bike
+func bikeRateModifier(g *Game, zone int) float32 {
+	if g.player.riding {
+		return bikeRateFactor
+	}
+	return 1
+}
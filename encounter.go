@@ -0,0 +1,219 @@
+package main
+
+import (
+	"image/color"
+	"math/rand"
+	"slices"
+	"time"
+)
+
+// Encounter zone constants: the kinds of ground wild creatures appear on
+const (
+	ZoneNone = iota
+	ZoneShortGrass
+	ZoneTallGrass
+	ZoneRustlingGrass
+	ZoneCave
+	ZoneWater
+	ZoneCount
+)
+
+// EncounterZone configures how often wild creatures appear on a kind of ground
+type EncounterZone struct {
+	name string
+	// Chance of an encounter on each step
+	rate float32
+}
+
+// encounterZones holds the settings for every zone, indexed by zone constant
+var encounterZones = [ZoneCount]EncounterZone{
+	ZoneNone:          {name: "None"},
+	ZoneShortGrass:    {name: "Short grass", rate: 0.02},
+	ZoneTallGrass:     {name: "Tall grass", rate: 0.06},
+	ZoneRustlingGrass: {name: "Rustling grass", rate: 0.5},
+	ZoneCave:          {name: "Cave", rate: 0.03},
+	ZoneWater:         {name: "Water", rate: 0.04}, // While surfing
+}
+
+// caveEncounters are the wild creatures found on cave floors
+var caveEncounters = []EncounterEntry{
+	{species: "Pebblit", minLevel: 6, maxLevel: 10, weight: 50},
+	{species: "Zephyrd", minLevel: 6, maxLevel: 9, weight: 30},
+	{species: "Magmite", minLevel: 8, maxLevel: 11, weight: 20},
+	{species: "Frostfox", minLevel: 14, maxLevel: 18, weight: 10, when: EncounterCondition{minBadges: 3}},
+}
+
+// waterEncounters are the wild creatures found while surfing
+var waterEncounters = []EncounterEntry{
+	{species: "Bubblefrog", minLevel: 5, maxLevel: 10, weight: 70},
+	{species: "Bogtoad", minLevel: 6, maxLevel: 10, weight: 30},
+}
+
+// Time of day constants for encounter conditions
+const (
+	TimeAny = iota
+	TimeDay
+	TimeNight
+)
+
+// EncounterCondition limits an encounter entry to some states of the world
+type EncounterCondition struct {
+	// Time of day the entry appears at
+	time int
+	// Weathers the entry appears in; any weather if empty
+	weather []int
+	// Gym badges the player needs before the entry appears
+	minBadges int
+	// Whether the entry only hides in rustling grass the player steps into
+	rustle bool
+}
+
+// WorldState is what encounter conditions are checked against
+type WorldState struct {
+	night   bool
+	weather int
+	badges  int
+	// The player is stepping into rustling grass
+	rustle bool
+}
+
+// worldState captures the current state of the world for encounter rolls
+func (g *Game) worldState() WorldState {
+	return WorldState{
+		night:   isNight(time.Now()),
+		weather: g.weather,
+		badges:  g.badges,
+		rustle:  g.rustlingAt(g.player.tileX, g.player.tileY),
+	}
+}
+
+// isNight reports whether a local time falls at night
+func isNight(t time.Time) bool {
+	return t.Hour() < 6 || t.Hour() >= 20
+}
+
+// met reports whether the world is in a state the condition allows
+func (c EncounterCondition) met(state WorldState) bool {
+	switch {
+	case c.time == TimeDay && state.night, c.time == TimeNight && !state.night:
+		return false
+	case len(c.weather) > 0 && !slices.Contains(c.weather, state.weather):
+		return false
+	case c.rustle && !state.rustle:
+		return false
+	}
+	return state.badges >= c.minBadges
+}
+
+// Tall grass generation constants
+const (
+	// Most tall grass patches per chunk
+	maxTallGrassPatches = 3
+	// Chance of a tall grass tile rustling instead
+	rustlingGrassChance = 0.01
+	// Levels added to creatures found in rustling grass
+	rustlingLevelBonus = 5
+)
+
+// EncounterZone returns which encounter zone the tile at x, y is, for a
+// player on the given layer
+func (m *Map) EncounterZone(x, y, layer int) int {
+	if m.noEncounters || layer != LayerBase {
+		return ZoneNone
+	}
+
+	switch m.Tile(LayerBase, x, y) {
+	case TileTallGrass:
+		return ZoneTallGrass
+	case TileRustlingGrass:
+		return ZoneRustlingGrass
+	case TileCaveFloor:
+		return ZoneCave
+	case TileWater, TileCurrentUp, TileCurrentDown, TileCurrentLeft, TileCurrentRight:
+		return ZoneWater
+	}
+
+	if m.IsGrass(x, y) {
+		return ZoneShortGrass
+	}
+	return ZoneNone
+}
+
+// rollWildCreature picks the wild creature met in a zone at tile x, y
+func (g *Game) rollWildCreature(zone, x, y int) Creature {
+	state := g.worldState()
+	if g.worldMap.encounters != nil {
+		return rollEncounter(g.worldMap.encounters, state)
+	}
+
+	switch zone {
+	case ZoneCave:
+		return rollEncounter(caveEncounters, state)
+	case ZoneWater:
+		return rollEncounter(waterEncounters, state)
+	}
+
+	// Creatures in a biome's grass come in the biome's regional form
+	biome := g.worldMap.BiomeAt(x, y)
+	table := biomes[biome].encounters
+	if zone == ZoneRustlingGrass {
+		table = rareEncounters(table)
+	}
+	wild := rollEncounter(table, state)
+	if form := regionalForm(wild.name, biome); form != "" {
+		wild = newCreatureForm(wild.name, form, wild.level)
+	}
+	return wild
+}
+
+// rareEncounters turns an encounter table around so its rarest entries
+// become the most common, at higher levels
+func rareEncounters(table []EncounterEntry) []EncounterEntry {
+	lowest, highest := table[0].weight, table[0].weight
+	for _, entry := range table {
+		lowest = min(lowest, entry.weight)
+		highest = max(highest, entry.weight)
+	}
+
+	rare := make([]EncounterEntry, len(table))
+	for i, entry := range table {
+		rare[i] = entry
+		rare[i].minLevel += rustlingLevelBonus
+		rare[i].maxLevel += rustlingLevelBonus
+		rare[i].weight = highest + lowest - entry.weight
+	}
+	return rare
+}
+
+// plantTallGrass grows a few patches of tall grass over the chunk's grass,
+// with the odd rustling tile among them
+func (c *Chunk) plantTallGrass(rng *rand.Rand) {
+	for range rng.Intn(maxTallGrassPatches + 1) {
+		centerX, centerY := rng.Intn(chunkSize), rng.Intn(chunkSize)
+		radius := 1 + rng.Intn(3)
+
+		for y := max(centerY-radius, 0); y <= min(centerY+radius, chunkSize-1); y++ {
+			for x := max(centerX-radius, 0); x <= min(centerX+radius, chunkSize-1); x++ {
+				if c.tiles[LayerBase][y][x] != TileGrass || abs(x-centerX)+abs(y-centerY) > radius {
+					continue
+				}
+				c.tiles[LayerBase][y][x] = TileTallGrass
+				if rng.Float32() < rustlingGrassChance {
+					c.tiles[LayerBase][y][x] = TileRustlingGrass
+				}
+			}
+		}
+	}
+}
+
+// shade darkens or lightens a color by a factor
+func shade(clr color.RGBA, factor float32) color.RGBA {
+	scale := func(v uint8) uint8 {
+		scaled := float32(v) * factor
+		if scaled > 255 {
+			return 255
+		}
+		return uint8(scaled)
+	}
+	return color.RGBA{scale(clr.R), scale(clr.G), scale(clr.B), clr.A}
+}
@@ -0,0 +1,95 @@
+package engine
+
+import "math/rand"
+
+// criticalChance is the chance of an attack landing a critical hit, and
+// criticalMultiplier how much more damage one does
+const (
+	criticalChance     = 1.0 / 16
+	criticalMultiplier = 1.5
+)
+
+// Fighter is what the rules need to know about a creature in battle
+type Fighter struct {
+	Name    string `json:"name"`
+	Form    string `json:"form,omitempty"`
+	Level   int    `json:"level"`
+	HP      int    `json:"hp"`
+	MaxHP   int    `json:"maxHP"`
+	Attack  int    `json:"attack"`
+	Defense int    `json:"defense"`
+	Speed   int    `json:"speed"`
+	Type1   string `json:"type1"`
+	Type2   string `json:"type2,omitempty"`
+	// Status condition, as the game numbers them; 0 is none
+	Status int    `json:"status,omitempty"`
+	Moves  []Move `json:"moves"`
+	// Item it's holding, if any
+	Held string `json:"held,omitempty"`
+}
+
+// Move is a move as the rules see it. A move's effect inflicts the status
+// numbered the same, and its kind says what else it does, like recoil or
+// charging up first.
+type Move struct {
+	Name     string `json:"name"`
+	Power    int    `json:"power"`
+	Accuracy int    `json:"accuracy"`
+	Type     string `json:"type"`
+	PP       int    `json:"pp"`
+	MaxPP    int    `json:"maxPP"`
+	Effect   int    `json:"effect,omitempty"`
+	Chance   int    `json:"chance,omitempty"`
+	Kind     int    `json:"kind,omitempty"`
+}
+
+// Struggle is used once a creature has no PP left in any of its moves
+var Struggle = Move{Name: "Struggle", Power: 30, Accuracy: 100, Type: "Normal"}
+
+// Hit is the result of one attack
+type Hit struct {
+	Damage int `json:"damage"`
+	// Damage multiplier from the move's type against the defender's
+	Effectiveness float32 `json:"effectiveness"`
+	Critical      bool    `json:"critical,omitempty"`
+}
+
+// Damage rolls the damage an attack does
+func Damage(attacker, defender Fighter, move Move, rng *rand.Rand) Hit {
+	// Basic damage formula similar to Pokémon
+	baseDamage := (2*attacker.Level)/5 + 2
+	baseDamage = baseDamage * move.Power * attacker.Attack / defender.Defense
+	baseDamage = baseDamage/50 + 2
+
+	// Random factor between 0.85 and 1.0
+	randomFactor := 0.85 + rng.Float32()*0.15
+
+	// Some types hit others harder, and the odd hit lands critically
+	hit := Hit{Effectiveness: Effectiveness(move.Type, defender.Type1)}
+	if defender.Type2 != "" {
+		hit.Effectiveness *= Effectiveness(move.Type, defender.Type2)
+	}
+	hit.Critical = hit.Effectiveness > 0 && rng.Float32() < criticalChance
+	multiplier := randomFactor * hit.Effectiveness
+	if hit.Critical {
+		multiplier *= criticalMultiplier
+	}
+	hit.Damage = int(float32(baseDamage) * multiplier)
+	return hit
+}
+
+// EffectTakes rolls whether a move's secondary effect takes hold of its
+// target, which it can't once the target has fainted or has a status already
+func EffectTakes(move Move, target Fighter, rng *rand.Rand) bool {
+	return move.Effect != 0 && target.HP > 0 && target.Status == 0 && rng.Intn(100) < move.Chance
+}
+
+// OutOfPP reports whether a fighter has used up every move
+func (f Fighter) OutOfPP() bool {
+	for _, move := range f.Moves {
+		if move.PP > 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,80 @@
+package engine
+
+import "math/rand"
+
+// Move kinds, for moves that do more than hit once
+const (
+	KindPlain = iota
+	// Hurts the attacker by a share of the damage it does
+	KindRecoil
+	// Heals the attacker by a share of the damage it does
+	KindDrain
+	// Charges up for a turn, then strikes on the next
+	KindCharge
+	// Like a charging move, but the attacker is out of reach while it
+	// charges, so moves used on it miss
+	KindVanish
+	// Traps the defender, which is hurt each time the attacker acts for a
+	// few turns
+	KindBind
+	KindCount
+)
+
+// Move kind constants
+const (
+	// Share of the damage done, as divisors, that recoil costs the attacker
+	// and a draining move heals it
+	recoilDivisor = 4
+	drainDivisor  = 2
+	// Share of max HP, as a divisor, that a bound creature loses each time,
+	// and how many times it's hurt before it gets free
+	bindDivisor  = 8
+	minBindTurns = 2
+	maxBindTurns = 5
+)
+
+// Volatile is what a creature in battle is in the middle of from one turn to
+// the next, which it leaves behind when it leaves the field
+type Volatile struct {
+	// Two-turn move being charged, to strike with next turn
+	Charging *Move `json:"charging,omitempty"`
+	// Out of reach while charging a vanishing move
+	Vanished bool `json:"vanished,omitempty"`
+	// Times left it's hurt by a binding move, and the move's name
+	Bound   int    `json:"bound,omitempty"`
+	BoundBy string `json:"boundBy,omitempty"`
+}
+
+// TwoTurn reports whether a move charges for a turn before it strikes
+func TwoTurn(move Move) bool {
+	return move.Kind == KindCharge || move.Kind == KindVanish
+}
+
+// Recoil returns the HP a move's recoil costs an attacker that did damage
+// with it. Recoil never knocks the attacker out; it's left with at least 1
+// HP.
+func Recoil(move Move, attacker Fighter, damage int) int {
+	if move.Kind != KindRecoil || damage <= 0 {
+		return 0
+	}
+	return min(max(damage/recoilDivisor, 1), attacker.HP-1)
+}
+
+// Drain returns the HP a draining move heals an attacker that did damage
+// with it, up to its max HP
+func Drain(move Move, attacker Fighter, damage int) int {
+	if move.Kind != KindDrain || damage <= 0 {
+		return 0
+	}
+	return min(max(damage/drainDivisor, 1), attacker.MaxHP-attacker.HP)
+}
+
+// BindTurns rolls how many times a binding move hurts the creature it traps
+func BindTurns(rng *rand.Rand) int {
+	return minBindTurns + rng.Intn(maxBindTurns-minBindTurns+1)
+}
+
+// BindDamage returns the HP a bound creature loses each time it's hurt
+func BindDamage(f Fighter) int {
+	return max(f.MaxHP/bindDivisor, 1)
+}
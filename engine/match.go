@@ -0,0 +1,224 @@
+package engine
+
+import (
+	"errors"
+	"math/rand"
+)
+
+// Team limits a referee holds players to
+const (
+	MaxTeam     = 6
+	MaxMoves    = 4
+	MaxLevel    = 100
+	maxStat     = 999
+	maxNameSize = 32
+)
+
+// TurnEvent is one attack in a turn, as the referee resolved it
+type TurnEvent struct {
+	// Side attacking, and the move it used
+	Side int    `json:"side"`
+	Move string `json:"move"`
+	Hit  Hit    `json:"hit"`
+	// Defender's HP after the hit, and the status the move gave it, if any
+	HP     int `json:"hp"`
+	Status int `json:"status,omitempty"`
+	// Team index of the defender's next creature when it fainted, or -1
+	SentOut int `json:"sentOut"`
+	// Set if the move only charged this turn, or missed as the defender
+	// was out of reach
+	Charged bool `json:"charged,omitempty"`
+	Missed  bool `json:"missed,omitempty"`
+	// Attacker's HP after recoil or draining
+	AttackerHP int `json:"attackerHP,omitempty"`
+	// Times the move bound the defender for, and the HP the defender lost to
+	// being bound already
+	Bound      int `json:"bound,omitempty"`
+	BindDamage int `json:"bindDamage,omitempty"`
+}
+
+// Match is a battle between two teams, each with its front creature out,
+// fought under a ruleset
+type Match struct {
+	Teams  [2][]Fighter
+	Active [2]int
+	Rules  Ruleset
+	// What each side's creature out is in the middle of
+	Volatile [2]Volatile
+	// Turns played so far
+	Turn int
+	rng  *rand.Rand
+}
+
+// NewMatch starts a match between two teams under a ruleset, rolling from
+// seed
+func NewMatch(teams [2][]Fighter, rules Ruleset, seed int64) *Match {
+	return &Match{Teams: teams, Rules: rules, rng: rand.New(rand.NewSource(seed))}
+}
+
+// ValidTeam checks that a team is one the game could have made: a handful of
+// healthy creatures with stats, moves and types in range
+func ValidTeam(team []Fighter) error {
+	if len(team) == 0 || len(team) > MaxTeam {
+		return errors.New("a team has one to six creatures")
+	}
+	for _, f := range team {
+		switch {
+		case f.Name == "" || len(f.Name) > maxNameSize:
+			return errors.New("creature with a bad name")
+		case f.Level < 1 || f.Level > MaxLevel:
+			return errors.New(f.Name + " has a bad level")
+		case f.MaxHP < 1 || f.MaxHP > maxStat || f.HP < 1 || f.HP > f.MaxHP:
+			return errors.New(f.Name + " has bad HP")
+		case f.Attack < 1 || f.Attack > maxStat || f.Defense < 1 || f.Defense > maxStat || f.Speed < 0 || f.Speed > maxStat:
+			return errors.New(f.Name + " has bad stats")
+		case !KnownType(f.Type1) || f.Type2 != "" && !KnownType(f.Type2):
+			return errors.New(f.Name + " has an unknown type")
+		case len(f.Moves) == 0 || len(f.Moves) > MaxMoves:
+			return errors.New(f.Name + " needs one to four moves")
+		}
+		// Every move must be a real one as it stands, with no more PP than
+		// it can hold
+		for _, m := range f.Moves {
+			known, ok := Moves[m.Name]
+			known.PP = m.PP
+			if !ok || m != known || m.PP < 0 || m.PP > m.MaxPP {
+				return errors.New(f.Name + " has a bad move")
+			}
+		}
+	}
+	return nil
+}
+
+// ValidChoice reports whether a side can use the move at index move: one
+// with PP left, or any move once all are out, which becomes Struggle
+func (m *Match) ValidChoice(side, move int) bool {
+	f := m.Teams[side][m.Active[side]]
+	if move < 0 || move >= len(f.Moves) {
+		return false
+	}
+	return f.Moves[move].PP > 0 || f.OutOfPP()
+}
+
+// DefaultChoice returns the move a side uses when it runs out of time: its
+// first with PP left, or its first if it has none, which becomes Struggle
+func (m *Match) DefaultChoice(side int) int {
+	for i, move := range m.Teams[side][m.Active[side]].Moves {
+		if move.PP > 0 {
+			return i
+		}
+	}
+	return 0
+}
+
+// Resolve plays out a turn from both sides' choices of move, the faster
+// creature attacking first. Ties are settled by a coin toss.
+func (m *Match) Resolve(choices [2]int) []TurnEvent {
+	m.Turn++
+	first := 0
+	speeds := [2]int{m.Teams[0][m.Active[0]].Speed, m.Teams[1][m.Active[1]].Speed}
+	if speeds[1] > speeds[0] || speeds[1] == speeds[0] && m.rng.Intn(2) == 1 {
+		first = 1
+	}
+
+	var events []TurnEvent
+	for _, side := range [2]int{first, 1 - first} {
+		if m.Winner() >= 0 {
+			break
+		}
+		// A creature that fainted before it could attack was replaced,
+		// and the one sent out doesn't get a move this turn
+		if len(events) > 0 && events[0].SentOut >= 0 {
+			break
+		}
+		events = append(events, m.attack(side, choices[side]))
+	}
+	return events
+}
+
+// attack resolves one side's move against the other. A two-turn move
+// charges on the turn it's chosen and strikes on the next whatever the
+// choice then, and a creature the attacker has bound is hurt after its move.
+func (m *Match) attack(side, choice int) TurnEvent {
+	attacker := &m.Teams[side][m.Active[side]]
+	defender := &m.Teams[1-side][m.Active[1-side]]
+	volatile, target := &m.Volatile[side], &m.Volatile[1-side]
+
+	var move Move
+	charged := false
+	switch {
+	case volatile.Charging != nil:
+		move = *volatile.Charging
+		volatile.Charging, volatile.Vanished = nil, false
+	case attacker.OutOfPP():
+		move = Struggle
+	default:
+		attacker.Moves[choice].PP--
+		move = attacker.Moves[choice]
+		if TwoTurn(move) {
+			volatile.Charging, volatile.Vanished = &move, move.Kind == KindVanish
+			charged = true
+		}
+	}
+
+	event := TurnEvent{Side: side, Move: move.Name, Charged: charged, SentOut: -1}
+	wasBound := target.Bound > 0
+	switch {
+	case charged:
+	case target.Vanished:
+		event.Missed = true
+	default:
+		event.Hit = Damage(*attacker, *defender, move, m.rng)
+		defender.HP = max(defender.HP-event.Hit.Damage, 0)
+		if !m.Rules.BlocksStatus(m.Teams[1-side], m.Active[1-side]) && EffectTakes(move, *defender, m.rng) {
+			defender.Status = move.Effect
+			event.Status = move.Effect
+		}
+		attacker.HP += Drain(move, *attacker, event.Hit.Damage) - Recoil(move, *attacker, event.Hit.Damage)
+		if move.Kind == KindBind && defender.HP > 0 && !wasBound {
+			target.Bound, target.BoundBy = BindTurns(m.rng), move.Name
+			event.Bound = target.Bound
+		}
+	}
+	if wasBound && defender.HP > 0 {
+		event.BindDamage = min(BindDamage(*defender), defender.HP)
+		defender.HP -= event.BindDamage
+		if target.Bound--; target.Bound == 0 {
+			target.BoundBy = ""
+		}
+	}
+	event.HP, event.AttackerHP = defender.HP, attacker.HP
+
+	if defender.HP <= 0 {
+		// Fainting ends whatever the defender was in the middle of, and
+		// frees the attacker if the defender had bound it
+		*target = Volatile{}
+		volatile.Bound, volatile.BoundBy = 0, ""
+		if next := m.nextHealthy(1 - side); next >= 0 {
+			m.Active[1-side] = next
+			event.SentOut = next
+		}
+	}
+	return event
+}
+
+// nextHealthy returns the index of a side's first creature that hasn't
+// fainted, or -1 if they all have
+func (m *Match) nextHealthy(side int) int {
+	for i, f := range m.Teams[side] {
+		if f.HP > 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// Winner returns the side that has won, or -1 while both can still fight
+func (m *Match) Winner() int {
+	for side := range 2 {
+		if m.nextHealthy(side) < 0 {
+			return 1 - side
+		}
+	}
+	return -1
+}
@@ -0,0 +1,39 @@
+package engine
+
+// effectPoison is the move effect that poisons, as the game numbers them
+const effectPoison = 1
+
+// Moves holds every move a creature can know, by name, so a team can be
+// checked against the real ones. PP is left at zero, as it's whatever a
+// creature has left.
+var Moves = map[string]Move{
+	"Body Slam":     {Name: "Body Slam", Power: 85, Accuracy: 100, Type: "Normal", MaxPP: 15},
+	"Bubble":        {Name: "Bubble", Power: 50, Accuracy: 90, Type: "Water", MaxPP: 25},
+	"Dig":           {Name: "Dig", Power: 80, Accuracy: 100, Type: "Ground", MaxPP: 10, Kind: KindVanish},
+	"Double-Edge":   {Name: "Double-Edge", Power: 120, Accuracy: 100, Type: "Normal", MaxPP: 15, Kind: KindRecoil},
+	"Ember":         {Name: "Ember", Power: 50, Accuracy: 90, Type: "Fire", MaxPP: 25},
+	"Fire Punch":    {Name: "Fire Punch", Power: 75, Accuracy: 100, Type: "Fire", MaxPP: 15},
+	"Fire Spin":     {Name: "Fire Spin", Power: 35, Accuracy: 85, Type: "Fire", MaxPP: 15, Kind: KindBind},
+	"Fly":           {Name: "Fly", Power: 90, Accuracy: 95, Type: "Flying", MaxPP: 15, Kind: KindVanish},
+	"Gust":          {Name: "Gust", Power: 45, Accuracy: 95, Type: "Flying", MaxPP: 25},
+	"Hydro Pump":    {Name: "Hydro Pump", Power: 110, Accuracy: 80, Type: "Water", MaxPP: 5},
+	"Ice Punch":     {Name: "Ice Punch", Power: 75, Accuracy: 100, Type: "Ice", MaxPP: 15},
+	"Ice Shard":     {Name: "Ice Shard", Power: 50, Accuracy: 90, Type: "Ice", MaxPP: 25},
+	"Mega Drain":    {Name: "Mega Drain", Power: 40, Accuracy: 100, Type: "Grass", MaxPP: 15, Kind: KindDrain},
+	"Mud Shot":      {Name: "Mud Shot", Power: 50, Accuracy: 90, Type: "Ground", MaxPP: 25},
+	"Peck":          {Name: "Peck", Power: 35, Accuracy: 100, Type: "Flying", MaxPP: 35},
+	"Quick Attack":  {Name: "Quick Attack", Power: 40, Accuracy: 100, Type: "Normal", MaxPP: 35},
+	"Rock Slide":    {Name: "Rock Slide", Power: 75, Accuracy: 90, Type: "Rock", MaxPP: 10},
+	"Rock Throw":    {Name: "Rock Throw", Power: 50, Accuracy: 90, Type: "Rock", MaxPP: 25},
+	"Scratch":       {Name: "Scratch", Power: 40, Accuracy: 100, Type: "Normal", MaxPP: 35},
+	"Sludge":        {Name: "Sludge", Power: 55, Accuracy: 85, Type: "Poison", MaxPP: 15, Effect: effectPoison, Chance: 30},
+	"Sludge Bomb":   {Name: "Sludge Bomb", Power: 90, Accuracy: 100, Type: "Poison", MaxPP: 10, Effect: effectPoison, Chance: 30},
+	"Solar Beam":    {Name: "Solar Beam", Power: 120, Accuracy: 100, Type: "Grass", MaxPP: 10, Kind: KindCharge},
+	"Spark":         {Name: "Spark", Power: 50, Accuracy: 90, Type: "Electric", MaxPP: 25},
+	"Tackle":        {Name: "Tackle", Power: 40, Accuracy: 100, Type: "Normal", MaxPP: 35},
+	"Take Down":     {Name: "Take Down", Power: 90, Accuracy: 85, Type: "Normal", MaxPP: 20, Kind: KindRecoil},
+	"Thunder Punch": {Name: "Thunder Punch", Power: 75, Accuracy: 100, Type: "Electric", MaxPP: 15},
+	"Thunderclap":   {Name: "Thunderclap", Power: 80, Accuracy: 85, Type: "Electric", MaxPP: 10},
+	"Vine Whip":     {Name: "Vine Whip", Power: 45, Accuracy: 100, Type: "Grass", MaxPP: 25},
+	"Whirlpool":     {Name: "Whirlpool", Power: 35, Accuracy: 85, Type: "Water", MaxPP: 15, Kind: KindBind},
+}
@@ -0,0 +1,48 @@
+package engine
+
+// RefereePort is the port the referee server listens on unless told otherwise
+const RefereePort = "7778"
+
+// Referee message types
+const (
+	// Player to referee: the player's name, rating and team, to be matched
+	// with another player, or with the one a matchmaker paired them with
+	MessageJoin = "join"
+	// Referee to player: the match has begun; which side the player is, the
+	// opponent's name, rating and team, and the ruleset it's fought under
+	MessageStart = "start"
+	// Player to referee: the move chosen for this turn
+	MessageChoose = "choose"
+	// Referee to both: how the turn played out
+	MessageTurn = "turn"
+	// Player to referee: giving up
+	MessageForfeit = "forfeit"
+	// Referee to both: who won, and why if it wasn't by battling it out
+	MessageEnd = "end"
+	// Referee to player: something the player sent was refused
+	MessageError = "error"
+)
+
+// Message is one line of the referee protocol: newline-delimited JSON over
+// TCP. Players only ever send their team and their choices; every roll is
+// made by the referee, so neither player can decide how much damage a move
+// does.
+type Message struct {
+	Type    string      `json:"type"`
+	Trainer string      `json:"trainer,omitempty"`
+	Rating  int         `json:"rating,omitempty"`
+	Team    []Fighter   `json:"team,omitempty"`
+	Side    int         `json:"side"`
+	Move    int         `json:"move"`
+	Events  []TurnEvent `json:"events,omitempty"`
+	Winner  int         `json:"winner"`
+	Reason  string      `json:"reason,omitempty"`
+	// Match code a matchmaker gave both players, so the referee pairs them
+	// with each other rather than whoever's next
+	Match string `json:"match,omitempty"`
+	// Ruleset the referee holds both players to
+	Rules *Ruleset `json:"rules,omitempty"`
+	// Seconds the players have to choose their next move, sent with the
+	// start of the match and each turn, or 0 if there's no timer
+	Seconds int `json:"seconds,omitempty"`
+}
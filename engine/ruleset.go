@@ -0,0 +1,146 @@
+package engine
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// minTurnSeconds is the shortest a turn's time limit gets in sudden death
+const minTurnSeconds = 5
+
+// Ruleset is a set of rules a battle is fought under, checked against each
+// team before the battle starts and enforced while it's fought
+type Ruleset struct {
+	Name string `json:"name"`
+	// Highest level a creature can be, or 0 for no cap
+	LevelCap int `json:"levelCap,omitempty"`
+	// Species clause: a team can't have two of the same species. Item
+	// clause: no two creatures on a team can hold the same item.
+	SpeciesClause bool `json:"speciesClause,omitempty"`
+	ItemClause    bool `json:"itemClause,omitempty"`
+	// Sleep clause, which here covers every status: a move can't give a
+	// creature a status while another on its team has one
+	SleepClause bool `json:"sleepClause,omitempty"`
+	// Seconds each player has to choose a move in a networked battle, or 0
+	// for no timer, just the referee's own limit. A player who runs out of
+	// time uses their first move.
+	TurnSeconds int `json:"turnSeconds,omitempty"`
+	// Turn sudden death starts on, or 0 for never. From then on each turn
+	// has half the time of the one before.
+	SuddenDeath int `json:"suddenDeath,omitempty"`
+}
+
+// Rulesets are the rulesets battles can be fought under, the first with no
+// rules at all
+var Rulesets = []Ruleset{
+	{Name: "Open"},
+	{Name: "Standard", LevelCap: 50, SpeciesClause: true, ItemClause: true, SleepClause: true, TurnSeconds: 90, SuddenDeath: 30},
+	{Name: "Little Cup", LevelCap: 10, SpeciesClause: true, ItemClause: true, SleepClause: true, TurnSeconds: 60, SuddenDeath: 20},
+	{Name: "Speed", SpeciesClause: true, TurnSeconds: 20, SuddenDeath: 10},
+}
+
+// FindRuleset looks up a ruleset by name
+func FindRuleset(name string) (Ruleset, bool) {
+	for _, r := range Rulesets {
+		if strings.EqualFold(r.Name, name) {
+			return r, true
+		}
+	}
+	return Ruleset{}, false
+}
+
+// TurnLimit returns how many seconds each player has to choose a move on a
+// turn, counting from 1, or 0 if the ruleset has no timer. In sudden death
+// the limit halves every turn, down to minTurnSeconds.
+func (r Ruleset) TurnLimit(turn int) int {
+	if r.TurnSeconds <= 0 {
+		return 0
+	}
+	limit := r.TurnSeconds
+	if r.SuddenDeath > 0 {
+		for range max(turn-r.SuddenDeath+1, 0) {
+			if limit/2 < minTurnSeconds {
+				break
+			}
+			limit /= 2
+		}
+	}
+	return limit
+}
+
+// InSuddenDeath reports whether a turn, counting from 1, is in sudden death
+func (r Ruleset) InSuddenDeath(turn int) bool {
+	return r.TurnSeconds > 0 && r.SuddenDeath > 0 && turn >= r.SuddenDeath
+}
+
+// Describe lists a ruleset's rules, like "Lv.50 cap, species and item
+// clauses, 90s turns"
+func (r Ruleset) Describe() string {
+	var rules, clauses []string
+	if r.LevelCap > 0 {
+		rules = append(rules, "Lv."+strconv.Itoa(r.LevelCap)+" cap")
+	}
+	for _, clause := range []struct {
+		on   bool
+		name string
+	}{{r.SpeciesClause, "species"}, {r.ItemClause, "item"}, {r.SleepClause, "sleep"}} {
+		if clause.on {
+			clauses = append(clauses, clause.name)
+		}
+	}
+	switch len(clauses) {
+	case 0:
+	case 1:
+		rules = append(rules, clauses[0]+" clause")
+	default:
+		rules = append(rules, strings.Join(clauses[:len(clauses)-1], ", ")+" and "+clauses[len(clauses)-1]+" clauses")
+	}
+	if r.TurnSeconds > 0 {
+		rules = append(rules, strconv.Itoa(r.TurnSeconds)+"s turns")
+	}
+	if r.InSuddenDeath(r.SuddenDeath) {
+		rules = append(rules, "sudden death from turn "+strconv.Itoa(r.SuddenDeath))
+	}
+	if len(rules) == 0 {
+		return "no rules"
+	}
+	return strings.Join(rules, ", ")
+}
+
+// Check reports the first rule a team breaks: a creature over the level
+// cap, or a species or held item it has twice when a clause forbids that
+func (r Ruleset) Check(team []Fighter) error {
+	species := make(map[string]bool)
+	held := make(map[string]bool)
+	for _, f := range team {
+		switch {
+		case r.LevelCap > 0 && f.Level > r.LevelCap:
+			return errors.New(f.Name + " is over the level cap of " + strconv.Itoa(r.LevelCap))
+		case r.SpeciesClause && species[f.Name]:
+			return errors.New("the species clause allows only one " + f.Name)
+		case r.ItemClause && f.Held != "" && held[f.Held]:
+			return errors.New("the item clause allows only one " + f.Held)
+		}
+		species[f.Name] = true
+		if f.Held != "" {
+			held[f.Held] = true
+		}
+	}
+	return nil
+}
+
+// BlocksStatus reports whether the sleep clause stops a move giving a status
+// to the creature at index target of a team, as another creature on the
+// team still standing already has one
+func (r Ruleset) BlocksStatus(team []Fighter, target int) bool {
+	if !r.SleepClause {
+		return false
+	}
+	for i, f := range team {
+		if i != target && f.HP > 0 && f.Status != 0 {
+			return true
+		}
+	}
+	return false
+}
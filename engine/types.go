@@ -0,0 +1,33 @@
+// Package engine holds the battle rules the game shares with the referee
+// server, so a ranked battle plays out the same whichever side resolves it.
+package engine
+
+// typeChart holds how well each move type hits each creature type; pairs
+// that aren't listed hit normally
+var typeChart = map[string]map[string]float32{
+	"Normal":   {"Rock": 0.5},
+	"Fire":     {"Grass": 2, "Ice": 2, "Fire": 0.5, "Water": 0.5, "Rock": 0.5},
+	"Water":    {"Fire": 2, "Ground": 2, "Rock": 2, "Water": 0.5, "Grass": 0.5},
+	"Grass":    {"Water": 2, "Ground": 2, "Rock": 2, "Fire": 0.5, "Grass": 0.5, "Flying": 0.5, "Poison": 0.5},
+	"Electric": {"Water": 2, "Flying": 2, "Electric": 0.5, "Grass": 0.5, "Ground": 0},
+	"Ice":      {"Grass": 2, "Ground": 2, "Flying": 2, "Fire": 0.5, "Water": 0.5, "Ice": 0.5},
+	"Flying":   {"Grass": 2, "Electric": 0.5, "Rock": 0.5},
+	"Ground":   {"Fire": 2, "Electric": 2, "Poison": 2, "Rock": 2, "Grass": 0.5, "Flying": 0},
+	"Rock":     {"Fire": 2, "Ice": 2, "Flying": 2, "Ground": 0.5},
+	"Poison":   {"Grass": 2, "Poison": 0.5, "Ground": 0.5, "Rock": 0.5},
+}
+
+// Effectiveness returns the damage multiplier of a move type against a
+// creature type
+func Effectiveness(moveType, defenderType string) float32 {
+	if multiplier, ok := typeChart[moveType][defenderType]; ok {
+		return multiplier
+	}
+	return 1
+}
+
+// KnownType reports whether a type is in the type chart
+func KnownType(name string) bool {
+	_, ok := typeChart[name]
+	return ok
+}
@@ -0,0 +1,52 @@
+package main
+
+// Event kinds published on the event bus
+const (
+	// A hit took a big share of a creature's HP
+	EventHeavyHit = iota
+	EventCapture
+	// The player walked into something solid
+	EventBump
+	// A wild creature or trainer's creature appeared in battle
+	EventEncounter
+	// A creature joined the player other than by being caught, like in a
+	// trade, or a party creature evolved
+	EventReceive
+	// The player finished a step onto a new tile
+	EventStep
+	// An opposing creature fainted in battle
+	EventDefeat
+	// The player ran from a battle
+	EventFlee
+)
+
+// Event is something that happened in play that other systems can react to
+type Event struct {
+	kind int
+	// How strong the event was, from 0 to 1
+	strength float64
+	// Species the event is about, if any, and its regional form
+	species string
+	form    string
+}
+
+// EventBus passes gameplay events to whatever is listening for them, so
+// gameplay code doesn't need to know about feedback like rumble
+type EventBus struct {
+	handlers map[int][]func(Event)
+}
+
+// subscribe calls handler for every event of a kind
+func (b *EventBus) subscribe(kind int, handler func(Event)) {
+	if b.handlers == nil {
+		b.handlers = make(map[int][]func(Event))
+	}
+	b.handlers[kind] = append(b.handlers[kind], handler)
+}
+
+// publish passes an event to everything subscribed to its kind
+func (b *EventBus) publish(e Event) {
+	for _, handler := range b.handlers[e.kind] {
+		handler(e)
+	}
+}
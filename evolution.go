@@ -0,0 +1,178 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// maxMoves is the most moves a creature can know
+const maxMoves = 4
+
+// evolutionFrames is how long a creature flashes between forms before it
+// evolves, which is the window for stopping it
+const evolutionFrames = 240
+
+// Evolution stage constants
+const (
+	EvolutionStart    = iota // Announcing the evolution
+	EvolutionMorphing        // Flashing between forms; can be stopped
+	EvolutionDone            // Evolved or stopped, with the result to read
+)
+
+// Evolution is the scene of a party creature evolving into another species
+type Evolution struct {
+	creature *Creature
+	// Species before and after, with the colors they're drawn in
+	from, into           string
+	fromColor, intoColor color.RGBA
+	stage                int
+	frames               int
+	announced            bool
+	cancelled            bool
+}
+
+// checkEvolution starts the evolution of a creature that has reached the
+// level its species evolves at
+func (g *Game) checkEvolution(c *Creature) {
+	species := findSpecies(c.name)
+	if species != nil && species.evolution != "" && c.level >= species.evolveLevel {
+		g.startEvolution(c, species.evolution)
+	}
+}
+
+// startEvolution switches to the evolution scene for a party creature
+func (g *Game) startEvolution(c *Creature, into string) {
+	g.evolution = Evolution{
+		creature:  c,
+		from:      c.displayName(),
+		into:      into,
+		fromColor: c.color,
+		intoColor: findSpecies(into).withForm(c.form).color,
+	}
+	g.gameState = StateEvolution
+}
+
+// updateEvolution plays the evolution scene, reading out each step in the
+// dialogue box
+func (g *Game) updateEvolution() {
+	e := &g.evolution
+	e.frames++
+
+	if g.dialogue.active {
+		g.updateDialogue()
+		return
+	}
+
+	switch e.stage {
+	case EvolutionStart:
+		if !e.announced {
+			e.announced = true
+			g.showDialogue("What? " + e.from + " is evolving!")
+			return
+		}
+		e.stage, e.frames = EvolutionMorphing, 0
+
+	case EvolutionMorphing:
+		if g.input.IsActionJustPressed(ebiten.KeyEscape) {
+			e.stage, e.frames, e.cancelled = EvolutionDone, 0, true
+			g.showDialogue("Huh? " + e.from + " stopped evolving!")
+			return
+		}
+		if e.frames >= evolutionFrames {
+			e.creature.evolve(e.into)
+			g.events.publish(Event{kind: EventReceive, species: e.into, form: e.creature.form})
+			e.stage, e.frames = EvolutionDone, 0
+			g.audio.playSound("fanfare")
+			g.audio.playCry(e.into)
+			g.showDialogue("Congratulations! Your " + e.from + " evolved into " + e.into + "!" + e.creature.learnNewMoves())
+		}
+
+	case EvolutionDone:
+		g.evolution = Evolution{}
+		g.gameState = StateOverworld
+	}
+}
+
+// learnNewMoves teaches a creature the moves its species learns up to its
+// level that it doesn't know yet, returning what happened to read out
+func (c *Creature) learnNewMoves() string {
+	message := ""
+	for _, learned := range findSpecies(c.name).learnset {
+		if learned.level > c.level || c.knowsMove(learned.move.name) {
+			continue
+		}
+		if len(c.moves) >= maxMoves {
+			message += " " + c.name + " wanted to learn " + learned.move.name + ", but it already knows four moves."
+			continue
+		}
+		move := learned.move
+		move.pp = move.maxPP
+		c.moves = append(c.moves, move)
+		message += " " + c.name + " learned " + move.name + "!"
+	}
+	return message
+}
+
+// knowsMove reports whether a creature knows a move
+func (c *Creature) knowsMove(name string) bool {
+	for _, move := range c.moves {
+		if move.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// drawEvolution draws the evolving creature as a silhouette flashing faster
+// and faster between its two forms, then revealed in its new colors
+func (g *Game) drawEvolution(screen *ebiten.Image) {
+	e := &g.evolution
+	screen.Fill(color.RGBA{20, 20, 40, 255})
+
+	cx, bottom := float32(screenWidth/2), float32(screenHeight/2+30)
+	draw := func(size float32, clr color.RGBA) {
+		vector.DrawFilledRect(screen, cx-size/2, bottom-size, size, size, clr, true)
+	}
+
+	switch e.stage {
+	case EvolutionStart:
+		draw(40, e.fromColor)
+
+	case EvolutionMorphing:
+		// Swapping shapes flash faster as the evolution nears, and hold still
+		// when animations are off
+		period := max(3, 30-e.frames/8)
+		newForm := e.frames/period%2 == 1
+		if g.settings.Animation == AnimationOff {
+			newForm = false
+		}
+		if newForm {
+			draw(52, color.RGBA{240, 240, 255, 255})
+		} else {
+			draw(40, color.RGBA{240, 240, 255, 255})
+		}
+
+		op := &text.DrawOptions{}
+		op.GeoM.Translate(10, 10)
+		op.ColorScale.ScaleWithColor(g.uiText(color.RGBA{200, 200, 220, 255}))
+		text.Draw(screen, "B: Stop evolving", g.fontFace, op)
+
+	case EvolutionDone:
+		if e.cancelled {
+			draw(40, e.fromColor)
+		} else {
+			draw(52, e.intoColor)
+		}
+
+		// The new form is revealed in a flash
+		if !e.cancelled && e.frames < 30 && !g.settings.ReduceFlashes {
+			fade := uint8(255 * (30 - e.frames) / 30)
+			vector.DrawFilledRect(screen, 0, 0, screenWidth, screenHeight, color.RGBA{fade, fade, fade, fade}, false)
+		}
+	}
+
+	g.drawDialogue(screen)
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"image/color"
+	"strconv"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// maxLevel is the highest level a creature can reach
+const maxLevel = 100
+
+// expBarFrames is how long the EXP bar takes to fill from empty to full
+const expBarFrames = 40
+
+// LevelUp records a level gained and how much each stat grew
+type LevelUp struct {
+	level                         int
+	maxHP, attack, defense, speed int
+}
+
+// Victory is the end of a won battle: the EXP bar filling and a panel for
+// each level gained
+type Victory struct {
+	// Set once the enemy's fainting has been shown, and while EXP is handed out
+	pending  bool
+	active   bool
+	creature *Creature
+	// Level and EXP the bar shows as it fills
+	shownLevel int
+	shownExp   float32
+	// Levels gained, and the one whose panel is open, if any
+	levelUps []LevelUp
+	panel    int
+	// Frames left before the battle ends once the bar is full
+	hold int
+}
+
+// expToNextLevel returns the EXP a creature needs to go from a level to the next
+func expToNextLevel(level int) int {
+	return 3*level*level/2 + 20
+}
+
+// expYield returns the EXP given for beating a creature
+func expYield(defeated Creature) int {
+	return defeated.level * 12
+}
+
+// gainExp adds EXP to a creature, raising its level and stats as it fills
+// up, and returns the levels gained
+func (c *Creature) gainExp(amount int) []LevelUp {
+	var levelUps []LevelUp
+	c.exp += amount
+	for c.level < maxLevel && c.exp >= expToNextLevel(c.level) {
+		c.exp -= expToNextLevel(c.level)
+		levelUps = append(levelUps, c.levelUp())
+	}
+	if c.level >= maxLevel {
+		c.exp = 0
+	}
+	return levelUps
+}
+
+// levelUp raises a creature's level by one, growing its stats to match the
+// new level; it gains as much HP as its max HP grew
+func (c *Creature) levelUp() LevelUp {
+	species := findSpecies(c.name).withForm(c.form)
+	c.level++
+	up := LevelUp{
+		level:   c.level,
+		maxHP:   max(c.statAt(StatHP, species.hp)-c.maxHP, 1),
+		attack:  max(c.statAt(StatAttack, species.attack)-c.attack, 0),
+		defense: max(c.statAt(StatDefense, species.defense)-c.defense, 0),
+		speed:   max(c.statAt(StatSpeed, species.speed)-c.speed, 0),
+	}
+	c.maxHP += up.maxHP
+	c.attack += up.attack
+	c.defense += up.defense
+	c.speed += up.speed
+	if c.hp > 0 {
+		c.hp += up.maxHP
+	}
+	return up
+}
+
+// startVictory plays the fanfare and hands out EXP once the enemy faints
+func (g *Game) startVictory() {
+	// Challenges give no EXP, as the party is put back as it was afterwards
+	if g.battle.challenge != nil {
+		if !g.sendNextEnemy() {
+			g.endBattle()
+		}
+		return
+	}
+
+	c := g.battle.playerCreature
+	gained := expYield(g.battle.enemyCreature)
+
+	g.battle.victory = Victory{active: true, creature: c, shownLevel: c.level, shownExp: float32(c.exp), panel: -1, hold: 40}
+	g.battle.victory.levelUps = c.gainExp(gained)
+	g.battle.battleText = c.displayName() + " gained " + strconv.Itoa(gained) + " EXP!"
+	g.audio.playSound("fanfare")
+}
+
+// updateVictory fills the EXP bar, stopping to show the stat panel of each
+// level gained, then ends the battle unless a trainer has more creatures
+func (g *Game) updateVictory() {
+	v := &g.battle.victory
+	c := v.creature
+
+	// A level up panel waits for the confirm button
+	if v.panel >= 0 {
+		if g.input.IsActionJustPressed(ebiten.KeySpace) {
+			v.panel = -1
+		}
+		return
+	}
+
+	if v.shownLevel < c.level {
+		// Fill to the top, then start on the next level
+		need := float32(expToNextLevel(v.shownLevel))
+		v.shownExp += g.expBarStep(need)
+		if v.shownExp >= need {
+			v.panel = v.shownLevel - v.levelUps[0].level + 1
+			v.shownLevel++
+			v.shownExp = 0
+			g.battle.battleText = c.displayName() + " grew to Lv. " + strconv.Itoa(v.shownLevel) + "!"
+			g.audio.playSound("heal")
+		}
+		return
+	}
+
+	if v.shownExp < float32(c.exp) {
+		v.shownExp = min32(v.shownExp+g.expBarStep(float32(expToNextLevel(c.level))), float32(c.exp))
+		return
+	}
+
+	// Leave the full bar up for a moment, or until the player moves on
+	v.hold--
+	if v.hold <= 0 || g.input.IsActionJustPressed(ebiten.KeySpace) {
+		v.active = false
+		if g.sendNextEnemy() {
+			return
+		}
+		g.endBattle()
+		g.checkEvolution(c)
+	}
+}
+
+// expBarStep returns how much EXP the bar fills by in a frame, for a level
+// needing need EXP; it fills at once when animations are off
+func (g *Game) expBarStep(need float32) float32 {
+	if g.settings.Animation == AnimationOff {
+		return need
+	}
+	return need / expBarFrames
+}
+
+// drawExpBar draws the EXP bar of the player's creature below its HP bar
+func (g *Game) drawExpBar(screen *ebiten.Image, x, y, width float32) {
+	v := &g.battle.victory
+	c := g.battle.playerCreature
+	level, exp := c.level, float32(c.exp)
+	if v.active {
+		level, exp = v.shownLevel, v.shownExp
+	}
+
+	ratio := float32(0)
+	if level < maxLevel {
+		ratio = min32(exp/float32(expToNextLevel(level)), 1)
+	}
+	vector.DrawFilledRect(screen, x, y, width, 3, color.RGBA{60, 60, 60, 255}, true)
+	vector.DrawFilledRect(screen, x, y, width*ratio, 3, color.RGBA{70, 160, 255, 255}, true)
+}
+
+// drawLevelUpPanel shows how much each stat grew for the level up being shown
+func (g *Game) drawLevelUpPanel(screen *ebiten.Image) {
+	v := &g.battle.victory
+	if !v.active || v.panel < 0 {
+		return
+	}
+	up := v.levelUps[v.panel]
+	lines := []string{
+		"Lv. " + strconv.Itoa(up.level),
+		"Max HP  +" + strconv.Itoa(up.maxHP),
+		"Attack  +" + strconv.Itoa(up.attack),
+		"Defense +" + strconv.Itoa(up.defense),
+		"Speed   +" + strconv.Itoa(up.speed),
+	}
+
+	width := g.widestText(lines) + 20
+	height := len(lines)*g.lineSpacing() + 12
+	x := screenWidth - width - 10
+	y := g.battleUITop() - height - 6
+	g.drawPanel(screen, float32(x), float32(y), float32(width), float32(height), color.RGBA{40, 60, 110, 240})
+	for i, line := range lines {
+		op := &text.DrawOptions{}
+		op.GeoM.Translate(float64(x+10), float64(y+6+i*g.lineSpacing()))
+		op.ColorScale.ScaleWithColor(g.uiText(color.White))
+		text.Draw(screen, line, g.fontFace, op)
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"image/color"
+	"strconv"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// FastForwardMode is a fast-forward option: how many times faster the game
+// runs, and whether it always does, which is only for debugging
+type FastForwardMode struct {
+	name   string
+	speed  float64
+	always bool
+}
+
+// fastForwardModes are the fast-forward options, in the order the options
+// screen cycles through them
+var fastForwardModes = []FastForwardMode{
+	{name: "Hold Tab, 2x", speed: 2},
+	{name: "Hold Tab, 4x", speed: 4},
+	{name: "Hold Tab, 8x", speed: 8},
+	{name: "Always 4x (debug)", speed: 4, always: true},
+}
+
+// fastForwarding reports whether the game is running fast this Update
+func (g *Game) fastForwarding() bool {
+	mode := fastForwardModes[g.settings.FastForward]
+	if mode.always {
+		return true
+	}
+	// There's nothing to speed through while typing or on the title screen
+	typing := g.gameState == StateNameEntry || g.gameState == StateTrade && g.trade.stage == TradeAddress ||
+		g.gameState == StateMail && g.mailScreen.stage == MailWriting
+	return g.input.IsActionPressed(ebiten.KeyTab) && !typing && g.gameState != StateMainMenu
+}
+
+// updateFastForward sets how many steps the clock runs this Update, muting
+// sounds while it runs fast
+func (g *Game) updateFastForward() {
+	g.clock.speed = 1
+	if g.fastForwarding() {
+		g.clock.speed = fastForwardModes[g.settings.FastForward].speed
+	}
+	g.audio.muted = g.clock.speed > 1
+}
+
+// drawFastForward marks the corner of the screen while the game runs fast
+func (g *Game) drawFastForward(screen *ebiten.Image) {
+	if g.clock.speed <= 1 {
+		return
+	}
+	label := g.cachedLabel(labelKey{prefix: ">> ", a: int(g.clock.speed * 100)}, func() string {
+		return ">> " + strconv.FormatFloat(g.clock.speed, 'f', -1, 64) + "x"
+	})
+	g.drawText(screen, label, float64(screenWidth-8-g.textWidth(label)), 4, color.RGBA{255, 220, 80, 255})
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Follower reaction constants
+const (
+	// Frames the follower spends reacting to being talked to
+	reactionFrames = simulationRate
+	// Furthest a hidden item can be, in tiles, for the follower to sniff it
+	// out
+	sniffRange = 3
+	// Chance of a chunk having an item buried in it
+	hiddenItemChance = 0.5
+)
+
+// Ways the follower reacts when the player talks to it
+const (
+	ReactionNone = iota
+	// Hops up and down
+	ReactionJump
+	// Trembles on the spot
+	ReactionShiver
+	// Dips its nose to the ground
+	ReactionSniff
+)
+
+// reactionEmotes are the emotes shown over the follower as it reacts
+var reactionEmotes = map[int]int{
+	ReactionJump:   EmoteHeart,
+	ReactionShiver: EmoteSweat,
+	ReactionSniff:  EmoteQuestion,
+}
+
+// talkToFollower has the lead creature react to the player, by how it feels
+// about them and what's around it
+func (g *Game) talkToFollower() {
+	f := &g.follower
+	lead := &g.creatures[g.activeCreature]
+	f.direction = directionTo(g.player.tileX-f.tileX, g.player.tileY-f.tileY)
+	reaction, message := g.followerReaction(lead)
+	f.reaction, f.reactionFrames = reaction, reactionFrames
+	if emote, ok := reactionEmotes[reaction]; ok {
+		g.showEmote(Entity{kind: EntityFollower}, emote, reactionFrames)
+	}
+	g.showDialogue(message)
+}
+
+// followerReaction picks how the lead creature reacts: anything buried
+// nearby catches its nose first, then the cold or the rain, and otherwise
+// it shows how friendly it is
+func (g *Game) followerReaction(lead *Creature) (int, string) {
+	outdoors := !g.worldMap.static
+	cold := outdoors && (g.weather == WeatherSnow || g.currentBiome == BiomeSnowfield)
+	switch {
+	case lead.egg:
+		return ReactionNone, "The egg sits still. Something is moving inside."
+	case lead.hp <= 0:
+		return ReactionNone, lead.displayName() + " is too worn out to do much."
+	case g.hiddenItemNear(g.follower.tileX, g.follower.tileY):
+		return ReactionSniff, lead.displayName() + " is sniffing at the ground. Something might be buried nearby!"
+	case cold && (lead.type1 == "Ice" || lead.type2 == "Ice"):
+		return ReactionJump, lead.displayName() + " is loving the cold!"
+	case cold:
+		return ReactionShiver, lead.displayName() + " is shivering in the cold."
+	case outdoors && g.weather == WeatherRain && lead.type1 != "Water" && lead.type2 != "Water":
+		return ReactionShiver, lead.displayName() + " shakes the rain off."
+	case lead.friendship >= 200:
+		return ReactionJump, lead.displayName() + " jumps for joy!"
+	case lead.friendship >= 150:
+		return ReactionJump, lead.displayName() + " hops happily around you."
+	case lead.friendship >= 100:
+		return ReactionNone, lead.displayName() + " looks up at you."
+	default:
+		return ReactionNone, lead.displayName() + " turns away."
+	}
+}
+
+// hiddenItemNear reports whether an item is buried within sniffing range of
+// a tile
+func (g *Game) hiddenItemNear(x, y int) bool {
+	for dy := -sniffRange; dy <= sniffRange; dy++ {
+		for dx := -sniffRange; dx <= sniffRange; dx++ {
+			if object, ok := g.worldMap.objects[Point{x + dx, y + dy}]; ok && object.kind == ObjectHiddenItem {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// updateFollowerReaction runs down the follower's reaction
+func (g *Game) updateFollowerReaction() {
+	if g.follower.reactionFrames > 0 {
+		g.follower.reactionFrames--
+	}
+}
+
+// reactionOffset returns how far the follower is moved from its tile by the
+// reaction it's playing
+func (g *Game) reactionOffset() (float32, float32) {
+	f := &g.follower
+	if f.reactionFrames <= 0 || g.settings.Animation == AnimationOff {
+		return 0, 0
+	}
+	progress := 1 - float64(f.reactionFrames)/reactionFrames
+	switch f.reaction {
+	case ReactionJump:
+		// Two hops
+		return 0, -float32(math.Abs(math.Sin(progress*2*math.Pi))) * 8
+	case ReactionShiver:
+		return float32(math.Sin(float64(g.ticks)*2.5)) * 1.5, 0
+	case ReactionSniff:
+		return 0, float32(f.reactionFrames / 8 % 2 * 2)
+	}
+	return 0, 0
+}
+
+// placeHiddenItem buries an item in an open grass or sand tile of the chunk
+// whose top-left tile is originX, originY, now and then. Nothing shows where
+// it is, but a following creature can smell it. Items already dug up stay
+// gone when the chunk is regenerated.
+func (m *Map) placeHiddenItem(c *Chunk, rng *rand.Rand, originX, originY int) {
+	if rng.Float32() >= hiddenItemChance {
+		return
+	}
+	x, y := 1+rng.Intn(chunkSize-2), 1+rng.Intn(chunkSize-2)
+	tile := c.tiles[LayerBase][y][x]
+	if c.collisionMap.get(y*chunkSize+x) || (tile != TileGrass && tile != TileSand) {
+		return
+	}
+	pos := Point{originX + x, originY + y}
+	if !m.pickedUp[pos] && m.objects[pos] == nil {
+		m.objects[pos] = &MapObject{kind: ObjectHiddenItem, item: itemRewards[rng.Intn(len(itemRewards))]}
+	}
+}
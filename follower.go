@@ -0,0 +1,94 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Follower is the lead creature walking a tile behind the player
+type Follower struct {
+	tileX, tileY     int
+	visualX, visualY float32
+	direction        int
+	moving           bool
+	sprite           *AnimatedSprite
+	// Color of the creature the sprite was made for
+	color color.RGBA
+	// How it's reacting to the player talking to it, and for how much longer
+	reaction       int
+	reactionFrames int
+}
+
+// follow sends the follower to a tile, the one the player just stepped off
+func (g *Game) follow(x, y int) {
+	f := &g.follower
+	if dx, dy := x-f.tileX, y-f.tileY; dx != 0 || dy != 0 {
+		f.direction = directionTo(dx, dy)
+	}
+	f.tileX, f.tileY = x, y
+	f.moving = true
+}
+
+// directionTo returns the direction of a one-tile step
+func directionTo(dx, dy int) int {
+	switch {
+	case dy < 0:
+		return DirectionUp
+	case dy > 0:
+		return DirectionDown
+	case dx < 0:
+		return DirectionLeft
+	default:
+		return DirectionRight
+	}
+}
+
+// updateFollower moves the follower toward its tile at the player's speed
+func (g *Game) updateFollower(speed float32) {
+	f := &g.follower
+	targetX, targetY := float32(f.tileX*tileSize), float32(f.tileY*tileSize)
+	f.visualX = approach(f.visualX, targetX, speed)
+	f.visualY = approach(f.visualY, targetY, speed)
+	if f.visualX == targetX && f.visualY == targetY {
+		f.moving = false
+	}
+}
+
+// approach moves value toward target by at most step
+func approach(value, target, step float32) float32 {
+	if value < target {
+		return min32(value+step, target)
+	}
+	return max(value-step, target)
+}
+
+// min32 returns the smaller of two float32s
+func min32(a, b float32) float32 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// drawFollower draws the lead creature behind the player, unless it's
+// tucked in under the player after a warp
+func (g *Game) drawFollower(screen *ebiten.Image) {
+	f := &g.follower
+	if len(g.creatures) == 0 || f.tileX == g.player.tileX && f.tileY == g.player.tileY {
+		return
+	}
+
+	lead := g.creatures[g.activeCreature]
+	if f.sprite == nil || f.color != lead.color {
+		f.sprite, f.color = newCreatureSprite(lead.color), lead.color
+	}
+	f.sprite.direction = f.direction
+	if f.moving {
+		f.sprite.play("walk", g.ticks)
+	} else {
+		f.sprite.play("idle", g.ticks)
+	}
+	dx, dy := g.reactionOffset()
+	f.sprite.draw(screen, f.visualX-g.camera.x+dx, f.visualY-g.camera.y+dy, g.ticks)
+}
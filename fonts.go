@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"image/color"
+	"log"
+	"math"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+// Font choices
+const (
+	// The 7x13 bitmap font, which only comes in one size
+	FontPixel = iota
+	// The bundled Go font, which can be scaled
+	FontGo
+	FontCount
+)
+
+// fontNames label the font choices
+var fontNames = [FontCount]string{"Pixel", "Go"}
+
+// textSizes are the sizes the scalable font can be drawn at
+var textSizes = []float64{1, 1.5, 2}
+
+// textSizeNames label the text sizes
+var textSizeNames = []string{"1x", "1.5x", "2x"}
+
+// goFontSize is the Go font's size at 1x, matching the bitmap font's height
+const goFontSize = 11
+
+// goFontSource is the parsed Go font, loaded the first time it's needed
+var goFontSource *text.GoTextFaceSource
+
+// applyFont switches to the font and text size chosen in the options
+func (g *Game) applyFont() {
+	g.clearLabels()
+	g.fontFace = text.NewGoXFace(basicfont.Face7x13)
+	if g.settings.Font != FontGo {
+		return
+	}
+
+	if goFontSource == nil {
+		source, err := text.NewGoTextFaceSource(bytes.NewReader(goregular.TTF))
+		if err != nil {
+			log.Println("Failed to load font:", err)
+			return
+		}
+		goFontSource = source
+	}
+	g.fontFace = &text.GoTextFace{Source: goFontSource, Size: goFontSize * textSizes[g.settings.TextSize]}
+}
+
+// lineHeight returns the height of a line of text in the current font
+func (g *Game) lineHeight() int {
+	m := g.fontFace.Metrics()
+	return int(math.Ceil(m.HAscent + m.HDescent))
+}
+
+// lineSpacing returns the distance between lines of running text
+func (g *Game) lineSpacing() int {
+	return g.lineHeight() + 2
+}
+
+// rowHeight returns the height of a row in a menu
+func (g *Game) rowHeight() int {
+	return g.lineHeight() + 7
+}
+
+// textWidth measures how wide a string is drawn in the current font
+func (g *Game) textWidth(s string) int {
+	return int(math.Ceil(text.Advance(s, g.fontFace)))
+}
+
+// widestText returns the width of the widest of some strings
+func (g *Game) widestText(labels []string) int {
+	widest := 0
+	for _, label := range labels {
+		widest = max(widest, g.textWidth(label))
+	}
+	return widest
+}
+
+// selectorWidth returns how far menu labels sit right of their selector arrow
+func (g *Game) selectorWidth() int {
+	return g.textWidth(">") + 8
+}
+
+// listTop returns where the first row of a full-screen menu goes, below its title
+func (g *Game) listTop() int {
+	return 30 + g.rowHeight() + 10
+}
+
+// wrapText splits text into lines no wider than width pixels, breaking on spaces
+func (g *Game) wrapText(message string, width int) []string {
+	lines := []string{}
+	line := ""
+	for _, word := range strings.Fields(message) {
+		if line != "" && g.textWidth(line+" "+word) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// drawHint draws the controls hint along the bottom of a full-screen menu,
+// wrapping it if it doesn't fit, and returns the y of its first line
+func (g *Game) drawHint(screen *ebiten.Image, hint string) int {
+	lines := g.wrapCached(hint, screenWidth-40)
+	top := screenHeight - 15 - len(lines)*g.lineSpacing()
+	for i, line := range lines {
+		g.drawText(screen, line, 20, float64(top+i*g.lineSpacing()), color.RGBA{200, 200, 200, 255})
+	}
+	return top
+}
+
+// drawText draws a line of text at x, y in a color, reusing one set of draw
+// options rather than making new ones for every line drawn each frame
+func (g *Game) drawText(screen *ebiten.Image, line string, x, y float64, clr color.Color) {
+	op := &g.textOptions
+	op.GeoM.Reset()
+	op.GeoM.Translate(x, y)
+	op.ColorScale.Reset()
+	op.ColorScale.ScaleWithColor(g.uiText(clr))
+	text.Draw(screen, line, g.fontFace, op)
+}
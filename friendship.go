@@ -0,0 +1,29 @@
+package main
+
+// Friendship constants
+const (
+	maxFriendship = 255
+	// Friendship a creature starts with, or starts with if it hatched from an
+	// egg the player carried
+	baseFriendship  = 70
+	hatchFriendship = 120
+	// Steps walked together for each point of friendship a party creature
+	// gains
+	friendshipSteps = 128
+)
+
+// friendshipText describes how a creature feels about its trainer
+func friendshipText(friendship int) string {
+	switch {
+	case friendship >= maxFriendship:
+		return "Adores you"
+	case friendship >= 200:
+		return "Very friendly"
+	case friendship >= 150:
+		return "Friendly"
+	case friendship >= 100:
+		return "Warming up to you"
+	default:
+		return "Wary"
+	}
+}
@@ -0,0 +1,366 @@
+package main
+
+import (
+	"image/color"
+	"strconv"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Fusion constants
+const (
+	// Index of the region whose town has the fusion lab
+	fusionLabRegion = 1
+	// Percent a fused creature's stats come out above the average of the two
+	// it was made from
+	fusionBonus = 10
+	// Item that splits a fused creature apart again
+	splitterItem = "Splitter"
+)
+
+// fusedForms holds the fused forms made so far, by the two species fused in
+// order, and fusedOrder lists them in the order they were made, for the dex.
+// Each form is allocated on its own, so pointers to it stay good.
+var (
+	fusedForms = make(map[[2]string]*Species)
+	fusedOrder []*Species
+)
+
+// fusionNameUses counts the ordered pairs of different base species that
+// give each fused name
+var fusionNameUses = countFusionNames()
+
+// FusionLab is the screen at the fusion lab for picking two party creatures
+// to fuse
+type FusionLab struct {
+	selected int
+	// Party index of the first creature picked, or -1 while picking it
+	first int
+}
+
+// fusionName makes up a fused form's name from the front of one species'
+// name and the back of the other's
+func fusionName(a, b string) string {
+	return a[:(len(a)+1)/2] + b[len(b)/2:]
+}
+
+// countFusionNames counts how many pairs of base species would give each
+// fused name
+func countFusionNames() map[string]int {
+	uses := make(map[string]int)
+	for _, a := range speciesList {
+		for _, b := range speciesList {
+			if a.name != b.name {
+				uses[fusionName(a.name, b.name)]++
+			}
+		}
+	}
+	return uses
+}
+
+// fusionAllowed reports whether two species can be fused: they have to be
+// different base species, and the fused form's name has to be theirs alone,
+// not another pair's or a base species'
+func fusionAllowed(a, b string) bool {
+	name := fusionName(a, b)
+	return a != b && findBaseSpecies(a) != nil && findBaseSpecies(b) != nil &&
+		fusionNameUses[name] == 1 && findBaseSpecies(name) == nil
+}
+
+// fusionSpecies returns the fused form of two species, registering it the
+// first time it's made so it has a dex entry, or nil if they can't be fused
+func fusionSpecies(a, b string) *Species {
+	if !fusionAllowed(a, b) {
+		return nil
+	}
+	pair := [2]string{a, b}
+	if species, ok := fusedForms[pair]; ok {
+		return species
+	}
+	species := newFusionSpecies(a, b)
+	fusedForms[pair] = &species
+	fusedOrder = append(fusedOrder, &species)
+	return &species
+}
+
+// newFusionSpecies works out the fused form of two species: the first's
+// type and the second's, and the average of their base stats and looks
+func newFusionSpecies(a, b string) Species {
+	sa, sb := findSpecies(a), findSpecies(b)
+	fused := Species{
+		name:      fusionName(a, b),
+		type1:     sa.type1,
+		hp:        (sa.hp + sb.hp) / 2,
+		attack:    (sa.attack + sb.attack) / 2,
+		defense:   (sa.defense + sb.defense) / 2,
+		speed:     (sa.speed + sb.speed) / 2,
+		color:     mixColor(sa.color, sb.color, 0.5),
+		ability:   sa.ability,
+		entry:     "Made by fusing a " + a + " with a " + b + ". It has the looks of both, and answers to neither name.",
+		height:    (sa.height + sb.height) / 2,
+		weight:    sa.weight + sb.weight,
+		fusedFrom: [2]string{a, b},
+	}
+	if sb.type1 != sa.type1 {
+		fused.type2 = sb.type1
+	}
+	if fused.ability == "" {
+		fused.ability = sb.ability
+	}
+	// It knows the strongest move from each side
+	for _, moves := range [][]Move{sa.moves, sb.moves} {
+		move := moves[len(moves)-1]
+		if len(fused.moves) == 0 || fused.moves[0].name != move.name {
+			fused.moves = append(fused.moves, move)
+		}
+	}
+	return fused
+}
+
+// resetFusions forgets every fused form made, for a new game or before
+// loading one
+func resetFusions() {
+	fusedForms = make(map[[2]string]*Species)
+	fusedOrder = nil
+}
+
+// canFuse reports whether two creatures can be fused: they have to be
+// species that can be, and neither can be fused already or be an egg
+func canFuse(a, b *Creature) bool {
+	return fusable(a) && fusable(b) && fusionAllowed(a.name, b.name)
+}
+
+// fusable reports whether a creature could be fused with another
+func fusable(c *Creature) bool {
+	return len(c.fusedFrom) == 0 && !c.egg
+}
+
+// fuseCreatures makes a new creature out of two: at the higher of their
+// levels, with the average of their stats plus a bonus. Its HP is made
+// the same way, so fusing doesn't heal.
+func fuseCreatures(a, b Creature) Creature {
+	species := fusionSpecies(a.name, b.name)
+	c := newCreature(species.name, max(a.level, b.level))
+	combine := func(x, y int) int {
+		return (x + y) * (100 + fusionBonus) / 200
+	}
+	c.maxHP = combine(a.maxHP, b.maxHP)
+	c.hp = combine(a.hp, b.hp)
+	c.attack = combine(a.attack, b.attack)
+	c.defense = combine(a.defense, b.defense)
+	c.speed = combine(a.speed, b.speed)
+	c.trainer = a.trainer
+	c.id = a.id
+	c.fusedFrom = []Creature{a, b}
+	return c
+}
+
+// loadableCreature reports whether a saved creature is of a base species
+// this game knows, or made from two unfused ones that can be fused
+func loadableCreature(cs CreatureSave) bool {
+	switch len(cs.Parts) {
+	case 0:
+		return findBaseSpecies(cs.Name) != nil
+	case 2:
+		a, b := cs.Parts[0], cs.Parts[1]
+		return len(a.Parts) == 0 && len(b.Parts) == 0 && fusionAllowed(a.Name, b.Name)
+	}
+	return false
+}
+
+// talkToFusionScientist offers to fuse two of the player's creatures
+func (g *Game) talkToFusionScientist() {
+	count := 0
+	for i := range g.creatures {
+		if fusable(&g.creatures[i]) {
+			count++
+		}
+	}
+	if count < 2 {
+		g.showDialogue("Welcome to the Fusion Lab! Bring me two different creatures and I'll fuse them into one. Fused creatures can't be fused again, and eggs can't be fused at all.")
+		return
+	}
+	g.showPrompt("Welcome to the Fusion Lab! I can fuse two of your creatures into one. Shall we begin?", func() {
+		g.fusion = FusionLab{first: -1}
+		g.gameState = StateFusion
+	})
+}
+
+// fusionRects lays out the party list on the left of the lab screen
+func (g *Game) fusionRects() []Rect {
+	return listRects(20, g.listTop(), 130, g.rowHeight(), len(g.creatures))
+}
+
+// updateFusionLab picks the first creature, then the second, then asks before
+// fusing them
+func (g *Game) updateFusionLab() {
+	f := &g.fusion
+	if g.dialogue.active {
+		g.updateDialogue()
+		return
+	}
+
+	if g.input.IsActionJustPressed(ebiten.KeyUp) {
+		f.selected = (f.selected - 1 + len(g.creatures)) % len(g.creatures)
+	} else if g.input.IsActionJustPressed(ebiten.KeyDown) {
+		f.selected = (f.selected + 1) % len(g.creatures)
+	}
+	clicked := g.mouseSelect(g.fusionRects(), &f.selected)
+
+	if g.input.IsActionJustPressed(ebiten.KeyEscape) {
+		if f.first >= 0 {
+			f.first = -1
+		} else {
+			g.gameState = StateOverworld
+		}
+		return
+	}
+	if !g.input.IsActionJustPressed(ebiten.KeySpace) && !g.input.IsActionJustPressed(ebiten.KeyEnter) && !clicked {
+		return
+	}
+
+	picked := &g.creatures[f.selected]
+	if f.first < 0 {
+		if picked.egg {
+			g.showDialogue("An egg can't be fused. Let it hatch first.")
+			return
+		}
+		if len(picked.fusedFrom) > 0 {
+			g.showDialogue(picked.name + " is fused already. It can't be fused again.")
+			return
+		}
+		f.first = f.selected
+		return
+	}
+
+	first := &g.creatures[f.first]
+	if f.selected == f.first || !canFuse(first, picked) {
+		g.showDialogue("Those two can't be fused. Pick a creature of a different species that isn't fused already.")
+		return
+	}
+	second := f.selected
+	name := fusionName(first.name, picked.name)
+	g.showPrompt("Fuse "+first.name+" and "+picked.name+" into "+name+"? Only a "+splitterItem+" can separate them again.", func() {
+		g.fuse(f.first, second)
+	})
+}
+
+// fuse replaces two party creatures with their fusion, in the place of the
+// first, and hands over a splitter in case the player changes their mind
+func (g *Game) fuse(i, j int) {
+	// Held items go back in the bag
+	for _, k := range []int{i, j} {
+		if item := g.creatures[k].heldItem; item != "" {
+			g.addItem(item, 1)
+			g.creatures[k].heldItem = ""
+		}
+	}
+	fused := fuseCreatures(g.creatures[i], g.creatures[j])
+	g.recordMet(&fused)
+	g.creatures[i] = fused
+	g.creatures = append(g.creatures[:j], g.creatures[j+1:]...)
+
+	// The active creature stays the same one, or becomes the fusion
+	fusedAt := i
+	if j < i {
+		fusedAt--
+	}
+	switch {
+	case g.activeCreature == i || g.activeCreature == j:
+		g.activeCreature = fusedAt
+	case g.activeCreature > j:
+		g.activeCreature--
+	}
+
+	g.events.publish(Event{kind: EventReceive, species: fused.name})
+	g.addItem(splitterItem, 1)
+	g.audio.playSound("fanfare")
+	g.gameState = StateOverworld
+	g.showDialogue("The machine hums and flashes... Say hello to " + fused.name + "! Here, take a " + splitterItem + " in case you want them back the way they were.")
+}
+
+// splitCreature uses a splitter on a fused creature, giving back the two it
+// was made from
+func (g *Game) splitCreature(name string, c *Creature) string {
+	if len(c.fusedFrom) == 0 {
+		return "It won't have any effect."
+	}
+	g.removeItem(name)
+	if c.heldItem != "" {
+		g.addItem(c.heldItem, 1)
+	}
+
+	fused, parts := c.name, c.fusedFrom
+	*c = parts[0]
+	message := fused + " split back into " + parts[0].name + " and " + parts[1].name + "!"
+	if len(g.creatures) < partySize {
+		g.creatures = append(g.creatures, parts[1])
+		return message
+	}
+	g.storage = append(g.storage, parts[1])
+	return message + " " + parts[1].name + " was sent to storage."
+}
+
+// drawFusionLab draws the party on the left and, once the first creature is
+// picked, what fusing it with the highlighted one would make
+func (g *Game) drawFusionLab(screen *ebiten.Image) {
+	f := &g.fusion
+	g.drawPanel(screen, 10, 10, float32(screenWidth-20), float32(screenHeight-20), color.RGBA{40, 60, 80, 240})
+
+	drawText := func(x, y int, line string, clr color.Color) {
+		op := &text.DrawOptions{}
+		op.GeoM.Translate(float64(x), float64(y))
+		op.ColorScale.ScaleWithColor(g.uiText(clr))
+		text.Draw(screen, line, g.fontFace, op)
+	}
+	title := "Fusion Lab - Pick the first creature"
+	if f.first >= 0 {
+		title = "Fusion Lab - Pick the second creature"
+	}
+	drawText(20, 30, title, color.White)
+
+	rects, labels := g.fusionRects(), g.partyLabels()
+	for i, c := range g.creatures {
+		r := rects[i]
+		clr := color.Color(color.White)
+		switch {
+		case i == f.first:
+			clr = color.RGBA{120, 220, 255, 255}
+		case !fusable(&c) || f.first >= 0 && !canFuse(&g.creatures[f.first], &g.creatures[i]):
+			clr = color.RGBA{130, 130, 130, 255}
+		}
+		if i == f.selected {
+			drawText(r.x, r.y, ">", color.RGBA{255, 255, 0, 255})
+		}
+		drawText(r.x+g.selectorWidth(), r.y, labels[i], clr)
+	}
+
+	// What the fusion would come out as
+	if f.first >= 0 && f.selected != f.first && canFuse(&g.creatures[f.first], &g.creatures[f.selected]) {
+		a, b := g.creatures[f.first], g.creatures[f.selected]
+		species := newFusionSpecies(a.name, b.name)
+		x, y := 165, g.listTop()
+		vector.DrawFilledRect(screen, float32(x), float32(y), tileSize, tileSize, species.color, false)
+		vector.StrokeRect(screen, float32(x), float32(y), tileSize, tileSize, 1, color.White, false)
+		y += tileSize + 6
+		combine := func(x, y int) string {
+			return strconv.Itoa((x + y) * (100 + fusionBonus) / 200)
+		}
+		for _, line := range []string{
+			species.name + " Lv." + strconv.Itoa(max(a.level, b.level)),
+			"Type: " + typeNames(species.type1, species.type2),
+			"HP " + combine(a.maxHP, b.maxHP) + "  ATK " + combine(a.attack, b.attack),
+			"DEF " + combine(a.defense, b.defense) + "  SPD " + combine(a.speed, b.speed),
+		} {
+			drawText(x, y, line, color.White)
+			y += g.lineSpacing()
+		}
+	}
+
+	if !g.dialogue.active {
+		g.drawHint(screen, "Space to pick, ESC to go back")
+	}
+	g.drawDialogue(screen)
+}
@@ -0,0 +1,36 @@
+package main
+
+import "testing"
+
+func TestFusionSpecies(t *testing.T) {
+	defer resetFusions()
+	a, b := speciesList[0].name, speciesList[1].name
+	first := fusionSpecies(a, b)
+	if first == nil || first.name != fusionName(a, b) {
+		t.Fatalf("fusing %s with %s gave %v", a, b, first)
+	}
+
+	// Making every other fusion mustn't move the first
+	for _, x := range speciesList {
+		for _, y := range speciesList {
+			fusionSpecies(x.name, y.name)
+		}
+	}
+	if fusionSpecies(a, b) != first || findSpecies(first.name) != first {
+		t.Error("the fused form moved once more were made")
+	}
+
+	// Nor should starting again overwrite it
+	resetFusions()
+	fusionSpecies(b, a)
+	if first.name != fusionName(a, b) || first.fusedFrom != [2]string{a, b} {
+		t.Error("the fused form changed after the fusions were reset")
+	}
+	if findSpecies(first.name) != nil {
+		t.Error("a fused form outlived the reset")
+	}
+
+	if fusionSpecies(a, a) != nil || fusionSpecies(a, first.name) != nil {
+		t.Error("fused a species with itself or with a fused form")
+	}
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"image/color"
+	"strconv"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Game corner constants
+const (
+	// Index of the region whose town has the game corner
+	gameCornerRegion = 2
+	// Tokens sold at a time at the prize counter, and what they cost
+	tokenBundle      = 50
+	tokenBundlePrice = 1000
+	// Most tokens the player can hold
+	maxTokens = 9999
+)
+
+// Prize is something the prize counter exchanges tokens for: an item, or a
+// creature at a level
+type Prize struct {
+	item    string
+	species string
+	level   int
+	tokens  int
+}
+
+// name returns what a prize is listed as
+func (p Prize) name() string {
+	if p.species != "" {
+		return p.species + " Lv." + strconv.Itoa(p.level)
+	}
+	return p.item
+}
+
+// prizes are what the prize counter offers, cheapest first
+var prizes = []Prize{
+	{item: "Super Repel", tokens: 150},
+	{item: "Glimmer Stone", tokens: 200},
+	{item: "Max Revive", tokens: 600},
+	{item: splitterItem, tokens: 800},
+	{species: "Zephyrd", level: 12, tokens: 1200},
+	{species: "Frostfox", level: 15, tokens: 2500},
+	{species: "Magmite", level: 18, tokens: 4000},
+}
+
+// newGameCorner builds the inside of the game corner: a row of slot machines
+// along the back, the prize counter in the corner and the card table in the
+// middle, with the move tutor off to the side
+func newGameCorner(id string) *Map {
+	m := newStaticMap(id, 11, 8)
+	for x := 1; x <= 4; x++ {
+		m.objects[Point{x, 1}] = &MapObject{kind: ObjectSlotMachine}
+	}
+	for x := 7; x < m.width-1; x++ {
+		m.stamp(x, 2, TileCounter, true)
+	}
+	m.objects[Point{8, 1}] = &MapObject{kind: ObjectPrizeClerk}
+	for x := 2; x <= 4; x++ {
+		m.stamp(x, 5, TileCounter, true)
+	}
+	m.objects[Point{3, 4}] = &MapObject{kind: ObjectCardDealer}
+	m.objects[Point{m.width - 2, m.height - 3}] = &MapObject{kind: ObjectMoveTutor}
+	return m
+}
+
+// tokensText describes how many tokens the player has
+func (g *Game) tokensText() string {
+	return g.countLabel("Tokens: ", g.tokens)
+}
+
+// addTokens pays out tokens, up to the most the player can hold
+func (g *Game) addTokens(count int) {
+	g.tokens = min(g.tokens+count, maxTokens)
+}
+
+// talkToPrizeClerk sells tokens and exchanges them for prizes
+func (g *Game) talkToPrizeClerk() {
+	g.showChoice("Welcome to the game corner! You have "+strconv.Itoa(g.tokens)+" tokens. What can I do for you?",
+		[]string{"Buy tokens", "Prizes", "Nothing"}, func(i int) {
+			switch i {
+			case 0:
+				g.buyTokens()
+			case 1:
+				g.offerPrizes()
+			}
+		})
+}
+
+// buyTokens sells a bundle of tokens
+func (g *Game) buyTokens() {
+	bundle := strconv.Itoa(tokenBundle) + " tokens"
+	if g.money < tokenBundlePrice {
+		g.showDialogue(bundle + " are $" + strconv.Itoa(tokenBundlePrice) + ", but you can't afford them.")
+		return
+	}
+	if g.tokens+tokenBundle > maxTokens {
+		g.showDialogue("Your token case is full!")
+		return
+	}
+	g.showPrompt(bundle+" for $"+strconv.Itoa(tokenBundlePrice)+"?", func() {
+		g.money -= tokenBundlePrice
+		g.addTokens(tokenBundle)
+		g.audio.playSound("heal")
+		g.showDialogue("Here you go! You have " + strconv.Itoa(g.tokens) + " tokens now.")
+	})
+}
+
+// offerPrizes lists the prizes and exchanges tokens for the one picked
+func (g *Game) offerPrizes() {
+	options := []string{}
+	for _, prize := range prizes {
+		options = append(options, prize.name()+" "+strconv.Itoa(prize.tokens))
+	}
+	options = append(options, "Back")
+	g.showChoice("Which prize would you like? You have "+strconv.Itoa(g.tokens)+" tokens.", options, func(i int) {
+		if i >= len(prizes) {
+			return
+		}
+		prize := prizes[i]
+		if g.tokens < prize.tokens {
+			g.showDialogue("The " + prize.name() + " is " + strconv.Itoa(prize.tokens) + " tokens. You'll need more!")
+			return
+		}
+		g.tokens -= prize.tokens
+		g.audio.playSound("fanfare")
+		if prize.species == "" {
+			g.addItem(prize.item, 1)
+			g.showDialogue("Here's your " + prize.item + "! Thanks for playing!")
+			return
+		}
+		c := newCreature(prize.species, prize.level)
+		c.trainer = g.playerName
+		g.recordMet(&c)
+		g.events.publish(Event{kind: EventReceive, species: c.name})
+		where := "It joined the party."
+		if len(g.creatures) < partySize {
+			g.creatures = append(g.creatures, c)
+		} else {
+			g.storage = append(g.storage, c)
+			where = "It was sent to storage."
+		}
+		g.showDialogue("Here's your " + c.name + "! Look after it. " + where)
+	})
+}
+
+// talkToCardDealer offers a game of card flip
+func (g *Game) talkToCardDealer() {
+	g.showPrompt("Care for a game of card flip? It's "+strconv.Itoa(cardFlipCost)+" tokens a card.", g.openCardFlip)
+}
+
+// drawSlotMachine draws a slot machine, its screen flashing now and then
+func (g *Game) drawSlotMachine(screen *ebiten.Image, x, y float32) {
+	vector.DrawFilledRect(screen, x-11, y-14, 22, 28, color.RGBA{170, 40, 60, 255}, true)
+	lit := color.RGBA{250, 240, 200, 255}
+	if (g.ticks/40+int(x))%5 == 0 {
+		lit = color.RGBA{255, 220, 80, 255}
+	}
+	vector.DrawFilledRect(screen, x-8, y-10, 16, 9, lit, true)
+	for i := range slotReels {
+		vector.DrawFilledRect(screen, x-7+float32(i)*5, y-8, 4, 5, color.RGBA{60, 60, 80, 255}, true)
+	}
+	vector.DrawFilledRect(screen, x+11, y-8, 2, 8, color.RGBA{180, 180, 190, 255}, true)
+	vector.DrawFilledCircle(screen, x+12, y-9, 2, color.RGBA{220, 40, 40, 255}, true)
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"image/color"
+	"math/rand"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/text/v2"
@@ -15,17 +16,39 @@ const (
 	StateBattle
 	StateMenu
 	StateCreatureMenu
+	StateBag
+	StateTownMap
+	StateTrade
+	StateOptions
+	StateEvolution
+	StateDex
+	StateNameEntry
+	StateStarter
+	StateFusion
+	StateCraft
+	StateStorage
+	StateMail
+	// The game corner's games
+	StateSlots
+	StateCardFlip
 )
 
 // Game is the main game struct
 type Game struct {
-	player              Player
-	gameState           int
-	worldMap            Map
-	battle              Battle
-	encounterRate       float32
-	creatures           []Creature
-	fontFace            text.Face
+	player         Player
+	gameState      int
+	worldMap       *Map
+	battle         Battle
+	creatures      []Creature
+	activeCreature int // Index of the creature sent out first in battle
+	fontFace       text.Face
+	// Draw options reused by drawText, and text cached by cachedLabel and
+	// wrapCached
+	textOptions text.DrawOptions
+	labels      map[labelKey]string
+	wrapped     map[wrapKey][]string
+	// Draw options reused by drawMapLayer
+	tileOptions         ebiten.DrawImageOptions
 	camera              Camera
 	menuOptions         []string
 	selectedOption      int
@@ -34,10 +57,157 @@ type Game struct {
 	selectedCreature    int
 	menuSection         int // 0 for creature list, 1 for creature details
 	detailMenuOptions   []string
+	summaryPage         int // Page of the creature summary open
+	// Biome the player is currently in and the weather there
+	currentBiome int
+	weather      int
+	// Every map in the game keyed by ID; worldMap is the one the player is on
+	maps map[string]*Map
+	// Where to put the player back when leaving the interior they're in
+	returnPoints []ReturnPoint
+	// Fade played while moving between maps
+	transition Transition
+	// Text box shown when reading signs
+	dialogue Dialogue
+	audio    *AudioManager
+	// Items carried by the player, by name, and the one highlighted in the bag
+	bag          map[string]int
+	selectedItem int
+	// Use/Give choice open on the selected item, and the result of the last one
+	bagActionOpen     bool
+	selectedBagAction int
+	bagMessage        string
+	// Pause menu opened from the overworld
+	pauseOptions  []string
+	selectedPause int
+	// Tiles walked since the game started, which berry regrowth, repels,
+	// eggs and friendship go by
+	steps int
+	// Where the player wakes up if their whole party faints
+	respawn Respawn
+	// Money carried by the player
+	money int
+	// Frames left of the flash shown when poison hurts the party
+	poisonFlash int
+	// Gym badges earned
+	badges int
+	// Story flags, set as the player makes progress
+	flags map[string]bool
+	// Legendary creature roaming the routes
+	roamer Roamer
+	// Current visit to the safari zone, if any
+	safari Safari
+	// Caught creatures that didn't fit in the party
+	storage []Creature
+	// What the storage screen is showing, and the teams saved to call up
+	// from it
+	storageMenu StorageMenu
+	presets     []TeamPreset
+	// The player's name, recorded on the creatures they catch, and their
+	// rival's
+	playerName string
+	rivalName  string
+	// Name being typed in at the start of a new game, and the starter scene
+	// that follows it
+	nameEntry NameEntry
+	starter   StarterScene
+	// Starter the rival took, which their team is built around, and the
+	// rival stepping out to battle, if they are
+	rivalStarter string
+	rival        RivalAmbush
+	// Trade with another player in progress
+	trade TradeSession
+	// The lead creature walking behind the player
+	follower Follower
+	// On-screen controls for touch screens, and the mouse pointer
+	display  Display
+	touch    TouchControls
+	mouse    Mouse
+	gamepads []ebiten.GamepadID
+	// Directions the gamepad sticks are pushed this frame and last frame
+	stickHeld, stickPrev map[ebiten.Key]bool
+	// Gameplay events, for feedback like rumble to react to
+	events EventBus
+	// The player's options, the selected line of the options screen, the
+	// first one in view and the state it was opened from
+	settings        Settings
+	selectedSetting int
+	optionsTop      int
+	optionsReturn   int
+	// Keeps the save in sync with a server, if set up, and the save revisions
+	// written and last agreed with it
+	sync           *SyncClient
+	saveRevision   int
+	syncedRevision int
+	// Finds ranked battles through a matchmaking server, if set up, and how
+	// the player has done in them
+	matchmaker *Matchmaker
+	ranked     RankedRecord
+	// Step count the active repel wears off at, and which kind it was
+	repelEnd  int
+	repelItem string
+	// Grass rustling with a creature hiding in it, if any
+	rustle RustleMarker
+	// Frames of ambient animation played, which runs slower or stops with
+	// the animation setting
+	ticks        int
+	ambientClock float64
+	// Evolution playing, if any
+	evolution Evolution
+	// Species the player has seen and caught, and the dex screen
+	dex Dex
+	// Notices waiting to be shown at the top of the screen, the first one
+	// showing now
+	toasts []Toast
+	// The overworld HUD strip and the area banner under it, and the areas
+	// the player has been to
+	hud        HUD
+	discovered map[string]bool
+	// Bubbles showing over heads on the overworld
+	emotes []Emote
+	// In-game date, and the daily and weekly events already had
+	calendar Calendar
+	// Creatures being picked at the fusion lab
+	fusion FusionLab
+	// The last few seconds of frames, kept to save as a clip
+	recorder Recorder
+	// How the game was launched
+	config Config
+	// Whether anything has happened since the game was saved or loaded, and
+	// the prompt asking about it on quitting
+	unsaved bool
+	quit    QuitPrompt
+	// Berries planted in the garden, by soil tile
+	garden map[Point]*Plot
+	// Items the player has learned to craft, and the crafting screen
+	recipes map[string]bool
+	craft   CraftMenu
+	// Letters in the mailbox at home, and the screen reading and writing them
+	mail       []Letter
+	mailScreen MailScreen
+	// Tokens for the game corner, and its games
+	tokens   int
+	slots    SlotMachine
+	cardFlip CardFlip
+	// Day each raid den was last beaten, and the creature a friend lent to
+	// fight alongside the player in raids, with whose it is
+	raidsBeaten     map[Point]int
+	raidAlly        *Creature
+	raidAllyTrainer string
+	// Task taken from a bulletin board, and the day's tasks taken already
+	quests QuestLog
+	// Which of the day's trades on the trade boards have been made
+	tradeBoard TradeBoard
+	// Wild creatures of one species beaten or caught in a row
+	chain Chain
+	// Runs game logic in fixed steps apart from drawing
+	clock Clock
+	// Where game logic reads the player's input from
+	input InputProvider
 }
 
 // NewGame creates a new game instance
-func NewGame() *Game {
+func NewGame(config Config) *Game {
 	game := &Game{
 		player: Player{
 			tileX:         5,
@@ -47,25 +217,57 @@ func NewGame() *Game {
 			movementState: MovementIdle,
 			direction:     DirectionDown,
 			currentLayer:  LayerBase,
+			buffered:      DirectionNone,
+			sprite:        newPersonSprite(color.RGBA{220, 40, 40, 255}),
 		},
-		gameState:     StateMainMenu, // Start with main menu
-		encounterRate: 0.02,
-		fontFace:      text.NewGoXFace(basicfont.Face7x13),
+		gameState: StateMainMenu, // Start with main menu
+		fontFace:  text.NewGoXFace(basicfont.Face7x13),
 		camera: Camera{
 			x: 0,
 			y: 0,
 		},
 		menuOptions:         []string{"New Game", "Options", "Exit"},
+		pauseOptions:        []string{"Creatures", "Dex", "Bag", "Craft", "Map", "Trade", "Options", "Save", "Close"},
 		selectedOption:      0,
 		gameInitialized:     false,
 		creatureMenuOptions: []string{"View Stats", "Switch Order", "Back to Game"},
 		selectedCreature:    0,
 		menuSection:         0,
 		detailMenuOptions:   []string{"Summary", "Moves", "Back"},
+		audio:               newAudioManager(),
+		settings:            loadSettings(),
 	}
+	game.input = EbitenInput{game}
+	game.config = config
+	game.subscribeRumble()
+	game.subscribeDex()
+	game.subscribeSteps()
+	game.subscribeQuests()
+	game.subscribeUnsaved()
+	game.subscribeChain()
+	game.applyFont()
+
+	// Offer to pick up where the player left off
+	if hasSave() {
+		game.menuOptions = []string{"Continue", "New Game", "Options", "Exit"}
+	}
+	// Offer back the game the last run was playing when it crashed
+	if hasEmergencySave() {
+		game.menuOptions = append([]string{"Recover Save"}, game.menuOptions...)
+	}
+
+	// Fetch any newer save from another machine
+	game.sync = newSyncClient()
+	game.sync.start(game.saveRevision)
+	game.matchmaker = newMatchmaker()
 
 	game.initGame()
 
+	// Testers can go straight into the game
+	if config.SkipMenu || config.Map != "" {
+		game.quickStart()
+	}
+
 	return game
 }
 
@@ -75,73 +277,109 @@ func (g *Game) initGame() {
 		return
 	}
 
-	// Create creatures
-	// Create some creatures
-	g.creatures = []Creature{
-		{
-			name:     "Sparkitty",
-			hp:       50,
-			maxHP:    50,
-			attack:   12,
-			defense:  10,
-			speed:    15,
-			type1:    "Electric",
-			level:    5,
-			inBattle: false,
-			color:    color.RGBA{255, 255, 0, 255},
-			moves: []Move{
-				{name: "Tackle", power: 40, accuracy: 100, type1: "Normal"},
-				{name: "Spark", power: 50, accuracy: 90, type1: "Electric"},
-			},
-		},
-		{
-			name:     "Flamepup",
-			hp:       45,
-			maxHP:    45,
-			attack:   15,
-			defense:  8,
-			speed:    12,
-			type1:    "Fire",
-			level:    5,
-			inBattle: false,
-			color:    color.RGBA{255, 100, 0, 255},
-			moves: []Move{
-				{name: "Tackle", power: 40, accuracy: 100, type1: "Normal"},
-				{name: "Ember", power: 50, accuracy: 90, type1: "Fire"},
-			},
-		},
-		{
-			name:     "Bubblefrog",
-			hp:       55,
-			maxHP:    55,
-			attack:   10,
-			defense:  12,
-			speed:    10,
-			type1:    "Water",
-			level:    5,
-			inBattle: false,
-			color:    color.RGBA{0, 100, 255, 255},
-			moves: []Move{
-				{name: "Tackle", power: 40, accuracy: 100, type1: "Normal"},
-				{name: "Bubble", power: 50, accuracy: 90, type1: "Water"},
-			},
-		},
-	}
+	g.playerName, g.rivalName = defaultPlayerName, defaultRivalName
+
+	// The player picks their first creature in the starter scene
+	g.creatures = nil
+	g.activeCreature = 0
 
-	// Initialize the player's starter creature
-	g.battle.playerCreature = g.creatures[0]
+	// Start with an empty bag and some pocket money
+	g.bag = make(map[string]int)
+	g.money = 3000
+	g.flags = make(map[string]bool)
+	g.recipes = make(map[string]bool)
+	g.garden = make(map[Point]*Plot)
+	g.raidsBeaten = make(map[Point]int)
+	g.discovered = make(map[string]bool)
+	g.mail = []Letter{welcomeLetter}
+	g.tokens = 0
+	g.raidAlly, g.raidAllyTrainer = nil, ""
+	g.chain = Chain{}
+	g.quests = QuestLog{}
+	g.tradeBoard = TradeBoard{}
 
-	// Create the map with layers
-	g.initMap()
+	// Nothing has been seen yet, and nothing fused
+	g.dex = Dex{}
+	resetFusions()
+
+	// Start on the first day of the calendar
+	g.calendar = newCalendar()
+
+	// Create the map with layers, from the configured seed and terrain
+	// generator if there are any
+	seed := g.config.Seed
+	if seed == 0 {
+		seed = rand.Int63()
+	}
+	terrain, _ := parseTerrain(g.config.Terrain)
+	g.initMap(seed, terrain)
+
+	// Set the legendary loose on the routes
+	g.roamer = newRoamer(g.worldMap.plan)
+	g.updateVisitors()
 
 	// Initialize camera to center on player
 	g.updateCamera()
 
+	// Roll the starting weather for the biome the player spawns in
+	g.currentBiome = g.worldMap.BiomeAt(g.player.tileX, g.player.tileY)
+	g.weather = biomes[g.currentBiome].rollWeather()
+
 	g.gameInitialized = true
 }
 
-// Update updates the game state
+// Update reads input, then runs as many fixed steps of game logic as are due
 func (g *Game) Update() error {
+	defer g.recoverCrash()
+
+	if ebiten.IsWindowBeingClosed() {
+		g.requestQuit(true)
+	}
+
+	g.updateTouch()
+	g.updateMouse()
+	g.updateGamepads()
+
+	g.updateFastForward()
+	c := &g.clock
+	c.stepsDue()
+	for c.substep = 0; c.pending >= 1 && c.substep < maxStepsPerUpdate; c.substep++ {
+		c.previous = g.positions()
+		g.step()
+		c.pending--
+		c.idle = 0
+	}
+	// Steps still owed after a long stall are dropped rather than caught up
+	if c.pending >= 1 {
+		c.pending = 0
+	}
+
+	// Ending RunGame this way, rather than exiting, lets main clean up
+	if g.quit.confirmed {
+		return ebiten.Termination
+	}
+	return nil
+}
+
+// step runs one fixed step of game logic
+func (g *Game) step() {
+	g.updateAmbientClock()
+	g.updateToasts()
+	g.updateCalendar()
+	g.updateRecorder()
+	g.updateMusic()
+
+	// Pick up the result of a background save sync
+	if result, ok := g.sync.poll(); ok {
+		g.applySync(result)
+	}
+
+	// The quit prompt sits over everything else until it's answered
+	if g.quit.open {
+		g.updateQuitPrompt()
+		return
+	}
+
 	switch g.gameState {
 	case StateMainMenu:
 		g.updateMainMenu()
@@ -151,12 +389,50 @@ func (g *Game) Update() error {
 		g.updateBattle()
 	case StateCreatureMenu:
 		g.updateCreatureMenu()
+	case StateBag:
+		g.updateBagMenu()
+	case StateMenu:
+		g.updatePauseMenu()
+	case StateTownMap:
+		g.updateTownMap()
+	case StateTrade:
+		g.updateTrade()
+	case StateOptions:
+		g.updateOptionsMenu()
+	case StateEvolution:
+		g.updateEvolution()
+	case StateDex:
+		g.updateDex()
+	case StateNameEntry:
+		g.updateNameEntry()
+	case StateStarter:
+		g.updateStarterScene()
+	case StateFusion:
+		g.updateFusionLab()
+	case StateCraft:
+		g.updateCraftMenu()
+	case StateStorage:
+		g.updateStorage()
+	case StateMail:
+		g.updateMail()
+	case StateSlots:
+		g.updateSlots()
+	case StateCardFlip:
+		g.updateCardFlip()
 	}
-	return nil
 }
 
-// Draw draws the game
-func (g *Game) Draw(screen *ebiten.Image) {
+// Draw draws the game at its logical resolution, then scales it to the window
+func (g *Game) Draw(window *ebiten.Image) {
+	defer g.recoverCrash()
+
+	screen := g.display.logicalScreen()
+
+	// Show the player and camera partway between the last two steps
+	current := g.positions()
+	g.setPositions(lerpPositions(g.clock.previous, current, g.clock.alpha()))
+	defer g.setPositions(current)
+
 	// Clear the screen
 	screen.Fill(color.RGBA{135, 206, 235, 255})
 
@@ -167,12 +443,64 @@ func (g *Game) Draw(screen *ebiten.Image) {
 		g.drawOverworld(screen)
 	case StateBattle:
 		g.drawBattle(screen)
+		if g.battle.party.open {
+			g.drawBattleParty(screen)
+		}
 	case StateCreatureMenu:
 		g.drawCreatureMenu(screen)
+	case StateBag:
+		g.drawBagMenu(screen)
+	case StateMenu:
+		g.drawOverworld(screen)
+		g.drawPauseMenu(screen)
+	case StateTownMap:
+		g.drawTownMap(screen)
+	case StateTrade:
+		g.drawTrade(screen)
+	case StateOptions:
+		g.drawOptionsMenu(screen)
+	case StateEvolution:
+		g.drawEvolution(screen)
+	case StateDex:
+		g.drawDex(screen)
+	case StateNameEntry:
+		g.drawNameEntry(screen)
+	case StateStarter:
+		g.drawStarterScene(screen)
+	case StateFusion:
+		g.drawFusionLab(screen)
+	case StateCraft:
+		g.drawCraftMenu(screen)
+	case StateStorage:
+		g.drawStorage(screen)
+	case StateMail:
+		g.drawMail(screen)
+	case StateSlots:
+		g.drawSlots(screen)
+	case StateCardFlip:
+		g.drawCardFlip(screen)
+	}
+
+	// Clips leave out notices, the fast-forward mark and on-screen controls
+	if g.settings.Recording {
+		g.recorder.capture(screen)
 	}
+	g.drawFastForward(screen)
+	g.drawToasts(screen)
+	g.drawDebug(screen)
+	g.drawQuitPrompt(screen)
+	g.drawTouchControls(screen)
+	g.display.present(window, g.settings.UIScale)
 }
 
-// Layout implements ebiten.Game's Layout
+// Layout implements ebiten.Game's Layout; LayoutF is used in its place
 func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
-	return screenWidth, screenHeight
+	width, height := g.LayoutF(float64(outsideWidth), float64(outsideHeight))
+	return int(width), int(height)
+}
+
+// LayoutF sizes the screen to the window in device pixels, so the game can be
+// scaled up sharply rather than stretched by Ebiten
+func (g *Game) LayoutF(outsideWidth, outsideHeight float64) (float64, float64) {
+	return g.display.layout(outsideWidth, outsideHeight)
 }
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// BenchmarkDrawMapLayer measures drawing one layer of the tiles on screen
+func BenchmarkDrawMapLayer(b *testing.B) {
+	g := newTestGame(b)
+	screen := ebiten.NewImage(screenWidth, screenHeight)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		g.drawMapLayer(screen, LayerBase)
+	}
+}
+
+// BenchmarkDrawBattle measures drawing a wild battle waiting for the
+// player's move
+func BenchmarkDrawBattle(b *testing.B) {
+	g := newTestGame(b)
+	g.setUpBattle(newCreature(g.creatures[0].name, 5))
+	g.battle.intro = IntroDone
+	screen := ebiten.NewImage(screenWidth, screenHeight)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		g.drawBattle(screen)
+	}
+}
+
+// BenchmarkUpdateOverworld measures a step of the overworld with the player
+// standing still
+func BenchmarkUpdateOverworld(b *testing.B) {
+	g := newTestGame(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		g.updateOverworld()
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"math"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// gamepadButtons maps keys to the standard gamepad buttons that press them
+var gamepadButtons = map[ebiten.Key]ebiten.StandardGamepadButton{
+	ebiten.KeyUp:     ebiten.StandardGamepadButtonLeftTop,
+	ebiten.KeyDown:   ebiten.StandardGamepadButtonLeftBottom,
+	ebiten.KeyLeft:   ebiten.StandardGamepadButtonLeftLeft,
+	ebiten.KeyRight:  ebiten.StandardGamepadButtonLeftRight,
+	ebiten.KeySpace:  ebiten.StandardGamepadButtonRightBottom,
+	ebiten.KeyEscape: ebiten.StandardGamepadButtonRightRight,
+	ebiten.KeyEnter:  ebiten.StandardGamepadButtonCenterRight,
+	ebiten.KeyV:      ebiten.StandardGamepadButtonRightTop,
+}
+
+// stickDeadZone is how far the left stick has to be pushed to count as a direction
+const stickDeadZone = 0.5
+
+// updateGamepads notes which gamepads are connected this frame, and which
+// directions their left sticks are pushed
+func (g *Game) updateGamepads() {
+	g.gamepads = ebiten.AppendGamepadIDs(g.gamepads[:0])
+
+	g.stickPrev, g.stickHeld = g.stickHeld, make(map[ebiten.Key]bool)
+	if g.settings.Controls != ControlsGamepad {
+		return
+	}
+	for _, id := range g.gamepads {
+		if !ebiten.IsStandardGamepadLayoutAvailable(id) {
+			continue
+		}
+		x := ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxisLeftStickHorizontal)
+		y := ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxisLeftStickVertical)
+		g.stickHeld[ebiten.KeyLeft] = g.stickHeld[ebiten.KeyLeft] || x < -stickDeadZone
+		g.stickHeld[ebiten.KeyRight] = g.stickHeld[ebiten.KeyRight] || x > stickDeadZone
+		g.stickHeld[ebiten.KeyUp] = g.stickHeld[ebiten.KeyUp] || y < -stickDeadZone
+		g.stickHeld[ebiten.KeyDown] = g.stickHeld[ebiten.KeyDown] || y > stickDeadZone
+	}
+}
+
+// gamepadPressed reports whether a gamepad button or stick direction standing
+// in for a key is held. Gamepads are only read with the gamepad preset.
+func (g *Game) gamepadPressed(key ebiten.Key) bool {
+	button, ok := gamepadButtons[key]
+	if !ok || g.settings.Controls != ControlsGamepad {
+		return false
+	}
+	if g.stickHeld[key] {
+		return true
+	}
+	for _, id := range g.gamepads {
+		if ebiten.IsStandardGamepadLayoutAvailable(id) && ebiten.IsStandardGamepadButtonPressed(id, button) {
+			return true
+		}
+	}
+	return false
+}
+
+// gamepadJustPressed reports whether a gamepad button or stick direction
+// standing in for a key was pressed since the last step
+func (g *Game) gamepadJustPressed(key ebiten.Key) bool {
+	button, ok := gamepadButtons[key]
+	if !ok || g.settings.Controls != ControlsGamepad {
+		return false
+	}
+	if g.clock.substep == 0 && g.stickHeld[key] && !g.stickPrev[key] {
+		return true
+	}
+	for _, id := range g.gamepads {
+		if ebiten.IsStandardGamepadLayoutAvailable(id) && g.clock.justPressed(inpututil.StandardGamepadButtonPressDuration(id, button)) {
+			return true
+		}
+	}
+	return false
+}
+
+// subscribeRumble shakes the gamepads on hits, captures and bumps
+func (g *Game) subscribeRumble() {
+	for _, kind := range []int{EventHeavyHit, EventCapture, EventBump} {
+		g.events.subscribe(kind, g.rumble)
+	}
+}
+
+// rumble vibrates every connected gamepad, harder and longer for stronger
+// events, unless vibration is switched off. Gamepads that can't vibrate
+// ignore it.
+func (g *Game) rumble(e Event) {
+	if !g.settings.Vibration {
+		return
+	}
+
+	strength := math.Max(0, math.Min(1, e.strength))
+	options := &ebiten.VibrateGamepadOptions{
+		Duration:        time.Duration(80+220*strength) * time.Millisecond,
+		StrongMagnitude: strength,
+		WeakMagnitude:   math.Min(1, strength+0.2),
+	}
+	for _, id := range g.gamepads {
+		ebiten.VibrateGamepad(id, options)
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"image/color"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Garden constants
+const (
+	// Index of the region whose town has the garden
+	gardenRegion = 0
+	// Key item needed to water planted berries
+	wateringCan = "Watering Can"
+)
+
+// Growth stage constants for a planted berry
+const (
+	StageSeed = iota
+	StageSprout
+	StageFlower
+	StageRipe
+)
+
+// stageTexts describe a planted berry at each stage
+var stageTexts = [...]string{
+	StageSeed:   "A seed is buried in the soil.",
+	StageSprout: "A sprout has pushed up out of the soil.",
+	StageFlower: "The plant is in flower.",
+	StageRipe:   "The plant is heavy with berries!",
+}
+
+// berryColors are the colors ripe berries are drawn in
+var berryColors = map[string]color.RGBA{
+	"Oran Berry":   {60, 90, 230, 255},
+	"Sitrus Berry": {240, 210, 60, 255},
+}
+
+// Plot is a berry planted in the garden. It only grows while watered: each
+// watering moves it on one stage once the berry's growing time has passed.
+type Plot struct {
+	Berry string `json:"berry"`
+	Stage int    `json:"stage"`
+	// Unix time it was watered at in this stage, or 0 if it's dry
+	Watered int64 `json:"watered,omitempty"`
+}
+
+// stageTime is how long a berry takes to grow each stage once watered
+func stageTime(berry string) time.Duration {
+	return time.Duration(findItem(berry).regrowMinutes) * time.Minute / 2
+}
+
+// growth returns the stage a plot is at by now, and whether it's been
+// watered in that stage
+func (p *Plot) growth(now time.Time) (stage int, watered bool) {
+	if p.Watered == 0 {
+		return p.Stage, false
+	}
+	if now.Sub(time.Unix(p.Watered, 0)) >= stageTime(p.Berry) {
+		return p.Stage + 1, false
+	}
+	return p.Stage, true
+}
+
+// settle records any growth since the plot was last looked at
+func (p *Plot) settle(now time.Time) {
+	if stage, _ := p.growth(now); stage != p.Stage {
+		p.Stage, p.Watered = stage, 0
+	}
+}
+
+// gardenPlots returns the soil tiles of the garden: two rows of four where
+// the safari gate stands in its town
+func (p *WorldPlan) gardenPlots() []Point {
+	town := p.regions[gardenRegion].town
+	minX, minY := town.x-townWidth/2, town.y-townHeight/2
+	plots := []Point{}
+	for y := minY + 9; y <= minY+10; y++ {
+		for x := minX + 7; x <= minX+10; x++ {
+			plots = append(plots, Point{x, y})
+		}
+	}
+	return plots
+}
+
+// tendPlot plants, waters or harvests the soil at pos, depending on what's
+// growing there
+func (g *Game) tendPlot(pos Point) {
+	plot := g.garden[pos]
+	if plot == nil {
+		seeds := []string{}
+		for _, item := range itemList {
+			if item.category == ItemBerry && g.bag[item.name] > 0 {
+				seeds = append(seeds, item.name)
+			}
+		}
+		if len(seeds) == 0 {
+			g.showDialogue("The soil here is soft and rich. A berry planted here would grow.")
+			return
+		}
+		g.offerSeeds(pos, seeds)
+		return
+	}
+
+	now := time.Now()
+	plot.settle(now)
+	switch {
+	case plot.Stage >= StageRipe:
+		count := 2 + rand.Intn(3)
+		g.addItem(plot.Berry, count)
+		delete(g.garden, pos)
+		g.showDialogue("Picked " + strconv.Itoa(count) + " " + plot.Berry + "! The soil is ready for planting again.")
+	case plot.Watered != 0:
+		g.showDialogue(stageTexts[plot.Stage] + " The soil is still damp.")
+	case g.bag[wateringCan] == 0:
+		g.showDialogue(stageTexts[plot.Stage] + " The soil is dry. Something to water it with would help it grow.")
+	default:
+		plot.Watered = now.Unix()
+		g.showDialogue(stageTexts[plot.Stage] + " Watered the " + plot.Berry + " plant.")
+	}
+}
+
+// offerSeeds asks to plant each kind of berry in the bag in turn, until the
+// player picks one
+func (g *Game) offerSeeds(pos Point, seeds []string) {
+	g.showPrompt("Plant a "+seeds[0]+" here?", func() {
+		g.removeItem(seeds[0])
+		g.garden[pos] = &Plot{Berry: seeds[0]}
+		g.showDialogue("Planted a " + seeds[0] + ". Water it to help it grow.")
+	})
+	if len(seeds) > 1 {
+		g.dialogue.after = func() {
+			if g.garden[pos] == nil {
+				g.offerSeeds(pos, seeds[1:])
+			}
+		}
+	}
+}
+
+// drawGarden draws what's growing in each garden plot, with damp soil where
+// it's been watered
+func (g *Game) drawGarden(screen *ebiten.Image) {
+	if g.worldMap.id != overworldID {
+		return
+	}
+	now := time.Now()
+	for pos, plot := range g.garden {
+		x := float32(pos.x*tileSize) - g.camera.x + tileSize/2
+		y := float32(pos.y*tileSize) - g.camera.y + tileSize/2
+		if x < -tileSize || y < -tileSize || x > screenWidth+tileSize || y > screenHeight+tileSize {
+			continue
+		}
+
+		stage, watered := plot.growth(now)
+		if watered {
+			vector.DrawFilledRect(screen, x-tileSize/2, y-tileSize/2, tileSize, tileSize, color.RGBA{40, 25, 15, 110}, true)
+		}
+
+		leaf := color.RGBA{50, 150, 50, 255}
+		switch stage {
+		case StageSeed:
+			vector.DrawFilledCircle(screen, x, y+4, 4, color.RGBA{80, 55, 35, 255}, true)
+		case StageSprout:
+			vector.StrokeLine(screen, x, y+6, x, y-2, 2, leaf, true)
+			vector.DrawFilledCircle(screen, x-3, y-3, 3, leaf, true)
+			vector.DrawFilledCircle(screen, x+3, y-3, 3, leaf, true)
+		case StageFlower:
+			vector.StrokeLine(screen, x, y+8, x, y-6, 2, leaf, true)
+			vector.DrawFilledCircle(screen, x-4, y+1, 4, leaf, true)
+			vector.DrawFilledCircle(screen, x+4, y-1, 4, leaf, true)
+			vector.DrawFilledCircle(screen, x, y-8, 3, color.RGBA{250, 170, 200, 255}, true)
+		default:
+			vector.DrawFilledCircle(screen, x, y-2, 10, leaf, true)
+			for _, offset := range [][2]float32{{-5, -5}, {4, -1}, {-1, 3}, {5, -8}} {
+				vector.DrawFilledCircle(screen, x+offset[0], y+offset[1], 2.5, berryColors[plot.Berry], true)
+			}
+		}
+	}
+}
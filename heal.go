@@ -0,0 +1,88 @@
+package main
+
+import (
+	"image/color"
+	"strconv"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// blackoutPenalty is the share of money lost on blacking out, as a divisor
+const blackoutPenalty = 2
+
+// Respawn is where the player wakes up after their whole party faints: the
+// last heal center they used, along with the doors they came in through so
+// they can walk back out
+type Respawn struct {
+	mapID        string
+	x, y         int
+	direction    int
+	returnPoints []ReturnPoint
+}
+
+// addHealer puts the heal center attendant behind the counter of a heal
+// center interior
+func (m *Map) addHealer() {
+	m.objects[Point{m.width / 2, 1}] = &MapObject{kind: ObjectHealer}
+}
+
+// healParty fully restores the party at a heal center, fainted creatures
+// too, and makes it the player's respawn point. Out of battle, it's the only
+// place a party is restored for free.
+func (g *Game) healParty() {
+	revived := 0
+	for i := range g.creatures {
+		if c := &g.creatures[i]; c.hp <= 0 && !c.egg {
+			revived++
+		}
+		g.creatures[i].heal()
+	}
+
+	g.respawn = Respawn{
+		mapID:        g.worldMap.id,
+		x:            g.player.tileX,
+		y:            g.player.tileY,
+		direction:    g.player.direction,
+		returnPoints: append([]ReturnPoint(nil), g.returnPoints...),
+	}
+
+	g.audio.playSound("heal")
+	if revived > 0 {
+		g.showDialogue("Welcome to the Heal Center! Your creatures are fully rested, and the ones that fainted are back on their feet. We hope to see you again!")
+		return
+	}
+	g.showDialogue("Welcome to the Heal Center! Your creatures are fully rested. We hope to see you again!")
+}
+
+// blackout fades out after the whole party faints; the player drops some of
+// their money and wakes up at their respawn point
+func (g *Game) blackout() {
+	lost := g.money / blackoutPenalty
+	g.money -= lost
+
+	g.gameState = StateOverworld
+	g.transition = Transition{
+		active:   true,
+		blackout: true,
+		message:  "You have no creatures left that can fight! You dropped $" + strconv.Itoa(lost) + " in the panic and hurried back...",
+	}
+}
+
+// respawnPlayer moves the player to their respawn point with the party
+// restored, as if they'd just walked out of the heal center door
+func (g *Game) respawnPlayer() {
+	for i := range g.creatures {
+		g.creatures[i].heal()
+	}
+
+	g.returnPoints = append([]ReturnPoint(nil), g.respawn.returnPoints...)
+	g.placePlayer(g.respawn.mapID, g.respawn.x, g.respawn.y, g.respawn.direction)
+}
+
+// drawNPC draws a person standing on the map, centered on x, y
+func (g *Game) drawNPC(screen *ebiten.Image, object *MapObject, x, y float32, clr color.RGBA) {
+	if object.sprite == nil {
+		object.sprite = newPersonSprite(clr)
+	}
+	object.sprite.draw(screen, x-spriteFrameSize/2, y-spriteFrameSize/2, g.ticks)
+}
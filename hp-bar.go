@@ -0,0 +1,98 @@
+package main
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// hpBarFrames is how long an HP bar takes to slide to a creature's new HP
+const hpBarFrames = 40
+
+// Colors an HP bar fades between as it empties
+var (
+	hpFull = color.RGBA{0, 255, 0, 255}
+	hpHalf = color.RGBA{255, 255, 0, 255}
+	hpLow  = color.RGBA{255, 0, 0, 255}
+)
+
+// HPBar shows a creature's HP in battle, sliding to each new value rather
+// than jumping to it
+type HPBar struct {
+	creature *Creature
+	// HP the bar is sliding from and to, and frames into the slide
+	from, to float32
+	frame    int
+}
+
+// track follows the HP of a creature, starting a slide whenever it changes;
+// a different creature's HP is shown straight away
+func (b *HPBar) track(c *Creature, frames int) {
+	hp := float32(c.hp)
+	if b.creature != c {
+		*b = HPBar{creature: c, from: hp, to: hp, frame: frames}
+	}
+	if hp != b.to {
+		b.from, b.to, b.frame = b.shown(frames), hp, 0
+	}
+	if b.frame < frames {
+		b.frame++
+	}
+}
+
+// shown returns the HP the bar shows now, easing out towards its target
+func (b *HPBar) shown(frames int) float32 {
+	if b.frame >= frames {
+		return b.to
+	}
+	t := float64(b.frame) / float64(frames)
+	eased := float32(1 - math.Pow(1-t, 3))
+	return b.from + (b.to-b.from)*eased
+}
+
+// hpBarLength returns how many frames HP bars slide for; they jump straight
+// to the new HP when animations are off
+func (g *Game) hpBarLength() int {
+	if g.settings.Animation == AnimationOff {
+		return 0
+	}
+	return hpBarFrames
+}
+
+// updateHPBars moves both battle HP bars towards their creatures' HP, once
+// any move animation playing has landed
+func (g *Game) updateHPBars() {
+	if len(g.battle.animations) > 0 && g.battle.animations[0].holdsBars {
+		return
+	}
+	g.battle.enemyBar.track(&g.battle.enemyCreature, g.hpBarLength())
+	g.battle.playerBar.track(g.battle.playerCreature, g.hpBarLength())
+}
+
+// hpColor returns the color of an HP bar filled to ratio, fading from green
+// through yellow to red as it empties
+func hpColor(ratio float32) color.RGBA {
+	if ratio >= 0.5 {
+		return mixColor(hpHalf, hpFull, (ratio-0.5)*2)
+	}
+	return mixColor(hpLow, hpHalf, max(0, ratio-0.2)/0.3)
+}
+
+// drawHPBar draws an HP bar at x, y, with the HP as numbers after it if
+// numbers is set
+func (g *Game) drawHPBar(screen *ebiten.Image, x, y, width float32, bar *HPBar, numbers bool) {
+	maxHP := float32(bar.creature.maxHP)
+	hp := bar.shown(g.hpBarLength())
+	ratio := hp / maxHP
+
+	vector.DrawFilledRect(screen, x, y, width, 5, color.RGBA{100, 100, 100, 255}, true)
+	vector.DrawFilledRect(screen, x, y, width*ratio, 5, hpColor(ratio), true)
+
+	// The numbers count down along with the bar
+	if numbers {
+		label := g.fractionLabel("", int(math.Ceil(float64(hp))), bar.creature.maxHP)
+		g.drawText(screen, label, float64(x+width+6), float64(y)-float64(g.lineHeight())/2, color.White)
+	}
+}
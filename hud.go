@@ -0,0 +1,166 @@
+package main
+
+import (
+	"image/color"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// HUD constants
+const (
+	// Width of the lead creature's HP bar
+	hudBarWidth = 36
+	// Frames the area banner takes to slide in or out, and stays up between
+	hudBannerSlideFrames = 15
+	hudBannerShowFrames  = 150
+)
+
+// HUD is what the overworld HUD strip keeps track of: the area the player
+// is in, and how far along the banner naming it is after they cross into a
+// new one
+type HUD struct {
+	area   string
+	banner int
+	// The banner is for an area the player hasn't been to before, which is
+	// shown even with the HUD off
+	discovered bool
+}
+
+// hudHeight returns the height of the HUD strip, or 0 when it's off, for
+// the corner indicators to sit below
+func (g *Game) hudHeight() int {
+	if !g.settings.HUD {
+		return 0
+	}
+	return g.lineHeight() + 6
+}
+
+// bannerLength returns how many frames the area banner is up for, which is
+// shorter when animations are off as it doesn't slide
+func (g *Game) bannerLength() int {
+	if g.settings.Animation == AnimationOff {
+		return hudBannerShowFrames
+	}
+	return hudBannerShowFrames + 2*hudBannerSlideFrames
+}
+
+// areaName names the area the player is in: the town, route or region of
+// the overworld they're in or went indoors from
+func (g *Game) areaName() string {
+	if g.worldMap.id == safariZoneID {
+		return "Safari Zone"
+	}
+	overworld, ok := g.maps[overworldID]
+	if !ok || overworld.plan == nil {
+		return ""
+	}
+	p := g.overworldTile()
+	return overworld.plan.areaAt(p.x, p.y)
+}
+
+// discoveredAreas lists the areas the player has been to, in order
+func (g *Game) discoveredAreas() []string {
+	areas := slices.Collect(maps.Keys(g.discovered))
+	slices.Sort(areas)
+	return areas
+}
+
+// updateHUD notices the player crossing into another area, bringing up the
+// banner naming it, and records areas they haven't been to before as
+// discovered for the town map
+func (g *Game) updateHUD() {
+	if g.hud.banner > 0 {
+		g.hud.banner--
+	}
+	area := g.areaName()
+	if area == g.hud.area {
+		return
+	}
+	switch {
+	case !g.discovered[area]:
+		g.discovered[area] = true
+		g.hud.banner, g.hud.discovered = g.bannerLength(), true
+	case g.hud.area != "":
+		g.hud.banner, g.hud.discovered = g.bannerLength(), false
+	}
+	g.hud.area = area
+}
+
+// drawHUD draws the HUD strip along the top of the overworld, when it's on:
+// the lead creature's HP, where the player is, the repel's steps left and
+// the time of day
+func (g *Game) drawHUD(screen *ebiten.Image) {
+	if !g.settings.HUD {
+		return
+	}
+	height := float32(g.hudHeight())
+	g.drawPanel(screen, 0, 0, screenWidth, height, color.RGBA{0, 0, 0, 150})
+	y := 3.0
+
+	left := 4
+	if len(g.creatures) > 0 && !g.creatures[g.activeCreature].egg {
+		lead := &g.creatures[g.activeCreature]
+		g.drawText(screen, lead.displayName(), float64(left), y, color.White)
+		left += g.textWidth(lead.displayName()) + 4
+		ratio := float32(lead.hp) / float32(lead.maxHP)
+		barY := height/2 - 2
+		vector.DrawFilledRect(screen, float32(left), barY, hudBarWidth, 4, color.RGBA{100, 100, 100, 255}, true)
+		vector.DrawFilledRect(screen, float32(left), barY, hudBarWidth*ratio, 4, hpColor(ratio), true)
+		left += hudBarWidth + 8
+	}
+
+	// The clock, with a sun or moon, then the repel's steps before it
+	now := time.Now()
+	clock := g.cachedLabel(labelKey{prefix: "Clock", a: now.Hour(), b: now.Minute()}, func() string {
+		return now.Format("15:04")
+	})
+	right := screenWidth - 4 - g.textWidth(clock)
+	g.drawText(screen, clock, float64(right), y, color.White)
+	right -= 10
+	if isNight(now) {
+		vector.DrawFilledCircle(screen, float32(right), height/2, 4, color.RGBA{220, 220, 255, 255}, true)
+		vector.DrawFilledCircle(screen, float32(right)+2, height/2-1, 3.5, color.RGBA{0, 0, 0, 150}, true)
+	} else {
+		vector.DrawFilledCircle(screen, float32(right), height/2, 4, color.RGBA{255, 210, 80, 255}, true)
+	}
+	right -= 10
+	if steps := g.repelLeft(); steps > 0 {
+		label := g.countLabel("Repel ", steps)
+		right -= g.textWidth(label)
+		g.drawText(screen, label, float64(right), y, color.RGBA{200, 220, 255, 255})
+		right -= 8
+	}
+
+	// The area goes in the middle of what's left, if it fits
+	if width := g.textWidth(g.hud.area); width <= right-left {
+		g.drawText(screen, g.hud.area, float64(left+(right-left-width)/2), y, color.RGBA{255, 240, 200, 255})
+	}
+}
+
+// drawAreaBanner draws the banner naming the area the player just crossed
+// into, sliding in from the left below the HUD strip and back out when it's
+// done. Without the HUD, only newly discovered areas get one.
+func (g *Game) drawAreaBanner(screen *ebiten.Image) {
+	if g.hud.banner <= 0 || !g.settings.HUD && !g.hud.discovered {
+		return
+	}
+	label := g.hud.area
+	if g.hud.discovered {
+		label = "New area: " + label
+	}
+	width := float32(g.textWidth(label) + 24)
+	height := float32(g.lineHeight() + 10)
+	x, y := float32(0), float32(g.hudHeight()+6)
+	if g.settings.Animation != AnimationOff {
+		shown := min(min(g.hud.banner, g.bannerLength()-g.hud.banner), hudBannerSlideFrames)
+		x = -width + width*float32(shown)/hudBannerSlideFrames
+	}
+
+	g.drawPanel(screen, x, y, width, height, color.RGBA{20, 40, 30, 230})
+	vector.DrawFilledRect(screen, x+width-3, y, 3, height, color.RGBA{120, 220, 140, 255}, false)
+	g.drawText(screen, label, float64(x+10), float64(y+5), color.White)
+}
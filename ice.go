@@ -0,0 +1,36 @@
+package main
+
+// slide carries the player on over ice the way they were going, reporting
+// whether they slid. The slide ends on a tile that isn't ice, or against
+// anything in the way.
+func (g *Game) slide() bool {
+	x, y := g.player.tileX, g.player.tileY
+	if g.player.currentLayer != LayerBase || g.worldMap.Tile(LayerBase, x, y) != TileIce || !g.canStep(g.player.direction) {
+		return false
+	}
+	g.carryPlayer(directionDelta(g.player.direction))
+	return true
+}
+
+// freezeShores turns the beaches of snowfields to ice
+func (c *Chunk) freezeShores() {
+	for y := range chunkSize {
+		for x := range chunkSize {
+			if c.tiles[LayerBase][y][x] == TileSand && c.biomes[y][x] == BiomeSnowfield {
+				c.tiles[LayerBase][y][x] = TileIce
+			}
+		}
+	}
+}
+
+// freezeGymFloor ices over a gym's floor between its back wall and the
+// entrance, so reaching the back means sliding around its staggered walls
+func (m *Map) freezeGymFloor() {
+	for y := 5; y < m.entrance.y; y++ {
+		for x := 1; x < m.width-1; x++ {
+			if !m.IsCollision(x, y) {
+				m.stamp(x, y, TileIce, false)
+			}
+		}
+	}
+}
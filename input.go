@@ -0,0 +1,162 @@
+package main
+
+import (
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// InputProvider is where game logic reads input from. Actions are named by
+// their key in the arrows preset, like ebiten.KeySpace for confirm; raw keys
+// are keyboard keys read as they are, for typing and shortcuts.
+type InputProvider interface {
+	IsActionPressed(action ebiten.Key) bool
+	IsActionJustPressed(action ebiten.Key) bool
+	IsKeyJustPressed(key ebiten.Key) bool
+	// Characters typed since the last step
+	TypedChars() []rune
+	// Where the mouse pointer is on the logical screen, and whether the
+	// left button was clicked since the last step
+	CursorPosition() (int, int)
+	IsClickJustPressed() bool
+}
+
+// EbitenInput reads the keyboard, mouse, touch screen and gamepads through
+// Ebiten, applying the control preset. Presses only count on the first step
+// of an Update, so fast-forward doesn't act on one twice.
+type EbitenInput struct {
+	g *Game
+}
+
+// IsActionPressed reports whether an action's key is held down on the
+// keyboard, by touch or on a gamepad
+func (in EbitenInput) IsActionPressed(action ebiten.Key) bool {
+	g := in.g
+	return ebiten.IsKeyPressed(g.boundKey(action)) || g.touch.held[action] || g.gamepadPressed(action)
+}
+
+// IsActionJustPressed reports whether an action's key was pressed since the
+// last step on the keyboard, by touch or on a gamepad
+func (in EbitenInput) IsActionJustPressed(action ebiten.Key) bool {
+	g := in.g
+	return in.IsKeyJustPressed(g.boundKey(action)) || g.clock.substep == 0 && g.touch.held[action] && !g.touch.prev[action] ||
+		g.gamepadJustPressed(action)
+}
+
+// IsKeyJustPressed reports whether a keyboard key was pressed since the last
+// step, whatever the control preset
+func (in EbitenInput) IsKeyJustPressed(key ebiten.Key) bool {
+	return in.g.clock.justPressed(inpututil.KeyPressDuration(key))
+}
+
+// TypedChars returns the characters typed since the last step
+func (in EbitenInput) TypedChars() []rune {
+	if in.g.clock.substep > 0 {
+		return nil
+	}
+	return ebiten.AppendInputChars(nil)
+}
+
+// CursorPosition returns where the mouse pointer is on the logical screen
+func (in EbitenInput) CursorPosition() (int, int) {
+	return in.g.toLogical(ebiten.CursorPosition())
+}
+
+// IsClickJustPressed reports whether the left mouse button was clicked since
+// the last step
+func (in EbitenInput) IsClickJustPressed() bool {
+	return in.g.clock.justPressed(inpututil.MouseButtonPressDuration(ebiten.MouseButtonLeft))
+}
+
+// InputFrame is one step of scripted input: the actions and raw keys held,
+// text typed, and the mouse
+type InputFrame struct {
+	Actions []ebiten.Key
+	Keys    []ebiten.Key
+	Chars   string
+	X, Y    int
+	Click   bool
+}
+
+// ScriptedInput plays back input frames one step at a time, so tests can
+// drive menus, walking and battles without a keyboard
+type ScriptedInput struct {
+	frames []InputFrame
+	// Frame being played, and the one before it, which decides what was just
+	// pressed
+	current, previous InputFrame
+}
+
+// newScriptedInput returns input that plays the frames in order
+func newScriptedInput(frames ...InputFrame) *ScriptedInput {
+	return &ScriptedInput{frames: frames}
+}
+
+// press returns a frame with actions pressed, followed by a frame with them
+// let go, so pressing the same action again counts as a new press
+func press(actions ...ebiten.Key) []InputFrame {
+	return []InputFrame{{Actions: actions}, {}}
+}
+
+// hold returns frames holding an action down for a number of steps
+func hold(action ebiten.Key, steps int) []InputFrame {
+	frames := make([]InputFrame, steps)
+	for i := range frames {
+		frames[i].Actions = []ebiten.Key{action}
+	}
+	return frames
+}
+
+// Queue adds frames to play after the ones already queued
+func (s *ScriptedInput) Queue(frames ...InputFrame) {
+	s.frames = append(s.frames, frames...)
+}
+
+// Advance moves on to the next frame, to call before each step. Once the
+// script runs out nothing is held.
+func (s *ScriptedInput) Advance() {
+	s.previous = s.current
+	s.current = InputFrame{X: s.current.X, Y: s.current.Y}
+	if len(s.frames) > 0 {
+		s.current, s.frames = s.frames[0], s.frames[1:]
+	}
+}
+
+// Done reports whether every frame has been played
+func (s *ScriptedInput) Done() bool {
+	return len(s.frames) == 0
+}
+
+// IsActionPressed reports whether the frame holds an action
+func (s *ScriptedInput) IsActionPressed(action ebiten.Key) bool {
+	return slices.Contains(s.current.Actions, action)
+}
+
+// IsActionJustPressed reports whether the frame holds an action the frame
+// before didn't
+func (s *ScriptedInput) IsActionJustPressed(action ebiten.Key) bool {
+	return slices.Contains(s.current.Actions, action) && !slices.Contains(s.previous.Actions, action)
+}
+
+// IsKeyJustPressed reports whether the frame holds a raw key the frame
+// before didn't
+func (s *ScriptedInput) IsKeyJustPressed(key ebiten.Key) bool {
+	return slices.Contains(s.current.Keys, key) && !slices.Contains(s.previous.Keys, key)
+}
+
+// TypedChars returns the text typed in the frame
+func (s *ScriptedInput) TypedChars() []rune {
+	return []rune(s.current.Chars)
+}
+
+// CursorPosition returns where the frame puts the mouse pointer
+func (s *ScriptedInput) CursorPosition() (int, int) {
+	return s.current.X, s.current.Y
+}
+
+// IsClickJustPressed reports whether the frame clicks and the frame before
+// didn't
+func (s *ScriptedInput) IsClickJustPressed() bool {
+	return s.current.Click && !s.previous.Click
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// newTestGame starts a game on the world made from seed 1, past the title
+// screen with the first starter, keeping its files in a temporary folder
+func newTestGame(tb testing.TB) *Game {
+	tb.Helper()
+	saveStore = dataDirStore(tb.TempDir())
+	return NewGame(Config{Seed: 1, SkipMenu: true})
+}
+
+// play steps the game through every queued frame of its scripted input,
+// then a number of steps with nothing held
+func play(g *Game, in *ScriptedInput, idle int) {
+	for !in.Done() {
+		in.Advance()
+		g.step()
+	}
+	for range idle {
+		in.Advance()
+		g.step()
+	}
+}
+
+func TestScriptedMovement(t *testing.T) {
+	g := newTestGame(t)
+	direction := -1
+	for _, k := range directionKeys {
+		if g.canStep(k.direction) {
+			direction = k.direction
+			break
+		}
+	}
+	if direction < 0 {
+		t.Fatal("the player can't step anywhere from the spawn point")
+	}
+
+	in := newScriptedInput(hold(directionKeys[direction].key, 30)...)
+	g.input = in
+	x, y := g.player.tileX, g.player.tileY
+	play(g, in, 30)
+
+	// The player walks at least a tile that way, and no other way
+	dx, dy := directionDelta(direction)
+	mx, my := g.player.tileX-x, g.player.tileY-y
+	if mx*dx+my*dy <= 0 || mx*dy != 0 || my*dx != 0 {
+		t.Fatalf("player at (%d, %d) after walking from (%d, %d), want a step toward (%d, %d)", g.player.tileX, g.player.tileY, x, y, dx, dy)
+	}
+}
+
+func TestScriptedMenu(t *testing.T) {
+	saveStore = dataDirStore(t.TempDir())
+	g := NewGame(Config{Seed: 1})
+	if g.gameState != StateMainMenu {
+		t.Fatalf("game starts in state %v, want the main menu", g.gameState)
+	}
+
+	// Wander down the menu and back up to New Game, then pick it
+	in := newScriptedInput(press(ebiten.KeyDown)...)
+	in.Queue(press(ebiten.KeyUp)...)
+	in.Queue(press(ebiten.KeySpace)...)
+	g.input = in
+	play(g, in, 0)
+	if g.gameState != StateNameEntry {
+		t.Fatalf("picking New Game leaves state %v, want name entry", g.gameState)
+	}
+
+	// The pause menu opens from the overworld
+	g = newTestGame(t)
+	in = newScriptedInput(press(ebiten.KeyEnter)...)
+	g.input = in
+	play(g, in, 0)
+	if g.gameState != StateMenu {
+		t.Fatalf("Enter in the overworld leaves state %v, want the pause menu", g.gameState)
+	}
+}
+
+func TestScriptedBattle(t *testing.T) {
+	g := newTestGame(t)
+	in := newScriptedInput()
+	g.input = in
+	g.setUpBattle(newCreature(g.creatures[0].name, 5))
+
+	// Skip the announcement as soon as it shows, and wait out the rest
+	for range 600 {
+		if g.battle.intro == IntroAnnounce {
+			in.Queue(press(ebiten.KeySpace)...)
+		}
+		play(g, in, 1)
+		if g.battle.intro == IntroDone {
+			break
+		}
+	}
+	if g.battle.intro != IntroDone {
+		t.Fatalf("battle intro stuck at %v", g.battle.intro)
+	}
+
+	move := g.battle.playerCreature.moves[0]
+	in.Queue(press(ebiten.KeySpace)...)
+	play(g, in, 0)
+	if got := g.battle.playerCreature.moves[0].pp; got != move.pp-1 {
+		t.Errorf("%s has %d PP after using it, want %d", move.name, got, move.pp-1)
+	}
+	if !strings.Contains(g.battle.battleText, " used "+move.name+"!") {
+		t.Errorf("battle text %q doesn't tell of %s", g.battle.battleText, move.name)
+	}
+}
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+	"math/rand"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// transitionFrames is how long each half of a door fade lasts
+const transitionFrames = 15
+
+// Warp moves the player to a tile on another map when stepped on
+type Warp struct {
+	mapID string
+	x, y  int
+	// Exit warps send the player back to where they entered from instead
+	back bool
+}
+
+// ReturnPoint remembers where the player was standing before going through a door
+type ReturnPoint struct {
+	mapID     string
+	x, y      int
+	direction int
+}
+
+// Transition is a fade to black and back while moving between maps
+type Transition struct {
+	active bool
+	frames int
+	warp   Warp
+	// Blackouts send the player to their respawn point instead of through a warp
+	blackout bool
+	// Shown once the screen has faded back in
+	message string
+}
+
+// interiorID returns the map ID of a building interior
+func interiorID(regionIndex, buildingIndex int) string {
+	return fmt.Sprintf("town%d-building%d", regionIndex, buildingIndex)
+}
+
+// newStaticMap creates a fixed-size map filled with floor and surrounded by
+// walls, with every chunk built up front
+func newStaticMap(id string, width, height int) *Map {
+	m := &Map{
+		id:           id,
+		width:        width,
+		height:       height,
+		chunks:       make(map[Point]*Chunk),
+		static:       true,
+		noEncounters: true,
+		warps:        make(map[Point]Warp),
+		objects:      make(map[Point]*MapObject),
+		pickedUp:     make(map[Point]bool),
+		harvested:    make(map[Point]Harvest),
+		obstacles:    make(map[Point]ObstacleState),
+	}
+
+	for cy := 0; cy*chunkSize < height; cy++ {
+		for cx := 0; cx*chunkSize < width; cx++ {
+			m.chunks[Point{cx, cy}] = newChunk()
+		}
+	}
+
+	for y := range height {
+		for x := range width {
+			if x == 0 || y == 0 || x == width-1 || y == height-1 {
+				m.stamp(x, y, TileInnerWall, true)
+			} else {
+				m.stamp(x, y, TileFloor, false)
+			}
+		}
+	}
+
+	return m
+}
+
+// stamp sets the tile at x, y to a non-encounter tile
+func (m *Map) stamp(x, y, tile int, solid bool) {
+	if c, lx, ly := m.chunkAt(x, y); c != nil {
+		c.stamp(lx, ly, tile, solid)
+	}
+}
+
+// buildCavePuzzles walls off two optional rooms in a cave, each holding an
+// item: one along the back behind a hole that needs a boulder pushed into it,
+// and one along the east side behind a gate opened by a boulder on a switch
+func buildCavePuzzles(m *Map, rng *rand.Rand) {
+	// Back room, sealed by a wall with a hole for its only opening
+	gapX := 3 + rng.Intn(m.width-9)
+	for x := 1; x < m.width-1; x++ {
+		m.stamp(x, 4, TileRock, true)
+	}
+	m.stamp(gapX, 4, TileHole, true)
+	for y := 1; y < 4; y++ {
+		for x := 1; x < m.width-4; x++ {
+			m.stamp(x, y, TileCaveFloor, false)
+		}
+	}
+	m.objects[Point{m.width - 6, 2}] = &MapObject{kind: ObjectItem, item: itemRewards[rng.Intn(len(itemRewards))]}
+
+	// A clear run up from the exit row to push the boulder along
+	for y := 5; y < m.height-1; y++ {
+		m.stamp(gapX, y, TileCaveFloor, false)
+	}
+	m.addBoulder(gapX, 6)
+
+	// East room, sealed by a wall with a gate in it
+	wallX := m.width - 4
+	for y := 5; y < m.height-1; y++ {
+		m.stamp(wallX, y, TileRock, true)
+	}
+	m.stamp(wallX, 8, TileGate, true)
+	m.stamp(wallX-1, 8, TileCaveFloor, false)
+	m.addCaveGuardian(wallX+1, 8)
+	m.objects[Point{m.width - 2, 6}] = &MapObject{kind: ObjectItem, item: itemRewards[rng.Intn(len(itemRewards))]}
+
+	// A switch in the southwest, with a boulder to push west onto it
+	for x := 1; x < 6; x++ {
+		m.stamp(x, m.height-3, TileCaveFloor, false)
+	}
+	m.stamp(1, m.height-3, TileSwitch, false)
+	m.addBoulder(3, m.height-3)
+}
+
+// newInteriorMap builds the interior map behind a building's door
+func newInteriorMap(b Building) *Map {
+	// Hand-made interiors come from map files
+	if b.layout != "" {
+		m, err := loadMapFile(b.layout, b.interior)
+		if err != nil {
+			log.Fatalf("loading map %s: %v", b.layout, err)
+		}
+		return m
+	}
+
+	var m *Map
+
+	switch b.kind {
+	case BuildingHealCenter:
+		m = newStaticMap(b.interior, 9, 7)
+		// A counter across the back of the room, with the attendant behind it
+		for x := 2; x < m.width-2; x++ {
+			m.stamp(x, 2, TileCounter, true)
+		}
+		m.addHealer()
+		m.visitorSpot = &Point{1, 4}
+
+	case BuildingShop:
+		m = newStaticMap(b.interior, 8, 6)
+		for x := 2; x < m.width-2; x++ {
+			m.stamp(x, 2, TileCounter, true)
+		}
+		m.objects[Point{m.width / 2, 1}] = &MapObject{kind: ObjectMarketClerk}
+		m.objects[Point{2, 1}] = &MapObject{kind: ObjectLotteryClerk}
+		m.objects[Point{m.width - 3, 1}] = &MapObject{kind: ObjectBikeClerk}
+
+	case BuildingGym:
+		m = newStaticMap(b.interior, 11, 12)
+		// Staggered walls the player has to weave between to reach the leader
+		for x := 1; x < m.width-3; x++ {
+			m.stamp(x, 4, TileInnerWall, true)
+		}
+		for x := 3; x < m.width-1; x++ {
+			m.stamp(x, 7, TileInnerWall, true)
+		}
+
+	case BuildingCave:
+		m = newStaticMap(b.interior, 20, 14)
+		// Wild creatures live on the cave floor
+		m.noEncounters = false
+		for y := range m.height {
+			for x := range m.width {
+				if m.IsCollision(x, y) {
+					m.stamp(x, y, TileRock, true)
+				} else {
+					m.stamp(x, y, TileCaveFloor, false)
+				}
+			}
+		}
+		// Scatter rock pillars, keeping the area around the exit clear
+		rng := rand.New(rand.NewSource(int64(b.x)*7919 + int64(b.y)))
+		for range 24 {
+			x, y := 1+rng.Intn(m.width-5), 6+rng.Intn(m.height-9)
+			m.stamp(x, y, TileRock, true)
+		}
+		buildCavePuzzles(m, rng)
+		m.placeMinerals(rng)
+
+	case BuildingSafari:
+		m = newSafariGate(b.interior)
+
+	case BuildingGameCorner:
+		m = newGameCorner(b.interior)
+
+	case BuildingHouse:
+		m = newStaticMap(b.interior, 7, 6)
+		m.addNameRater()
+
+	case BuildingLab:
+		m = newStaticMap(b.interior, 9, 7)
+		// The fusion machine's two pods along the back, with the scientist
+		// behind the console between them
+		for x := 2; x < m.width-2; x++ {
+			m.stamp(x, 2, TileCounter, true)
+		}
+		m.stamp(1, 1, TileRoofLab, true)
+		m.stamp(m.width-2, 1, TileRoofLab, true)
+		m.objects[Point{m.width / 2, 1}] = &MapObject{kind: ObjectFusionScientist}
+
+	default:
+		m = newStaticMap(b.interior, 7, 6)
+	}
+
+	// The way out is in the middle of the bottom wall
+	exit := Point{m.width / 2, m.height - 1}
+	m.stamp(exit.x, exit.y, TileDoor, false)
+	m.warps[exit] = Warp{back: true}
+	m.entrance = Point{exit.x, exit.y - 1}
+
+	return m
+}
+
+// checkWarp starts a door transition if the player is standing on a door
+func (g *Game) checkWarp() bool {
+	warp, ok := g.worldMap.warps[Point{g.player.tileX, g.player.tileY}]
+	if !ok {
+		return false
+	}
+
+	g.transition = Transition{active: true, warp: warp}
+	g.audio.playSound("door")
+
+	return true
+}
+
+// updateTransition advances a door fade, moving the player once the screen is black
+func (g *Game) updateTransition() {
+	g.transition.frames++
+
+	if g.transition.frames == transitionFrames {
+		if g.transition.blackout {
+			g.respawnPlayer()
+		} else {
+			g.warpTo(g.transition.warp)
+		}
+	}
+
+	if g.transition.frames >= transitionFrames*2 {
+		if g.transition.message != "" {
+			g.showDialogue(g.transition.message)
+		}
+		g.transition = Transition{}
+	}
+}
+
+// warpTo moves the player through a warp, remembering where to come back to
+func (g *Game) warpTo(warp Warp) {
+	mapID, x, y, direction := warp.mapID, warp.x, warp.y, g.player.direction
+
+	if warp.back {
+		// Return to the tile the player stepped onto the door from, facing away
+		if len(g.returnPoints) == 0 {
+			return
+		}
+		back := g.returnPoints[len(g.returnPoints)-1]
+		g.returnPoints = g.returnPoints[:len(g.returnPoints)-1]
+		mapID, x, y, direction = back.mapID, back.x, back.y, back.direction
+	} else {
+		// Remember the tile the player came from and the way they'll face leaving
+		dx, dy := directionDelta(g.player.direction)
+		g.returnPoints = append(g.returnPoints, ReturnPoint{
+			mapID:     g.worldMap.id,
+			x:         g.player.tileX - dx,
+			y:         g.player.tileY - dy,
+			direction: oppositeDirection(g.player.direction),
+		})
+	}
+
+	g.placePlayer(mapID, x, y, direction)
+
+	// The roamer moves on whenever the player changes maps
+	g.moveRoamer()
+
+	// Leaving the safari zone ends the visit
+	if g.worldMap.id != safariZoneID {
+		g.safari = Safari{}
+	}
+}
+
+// placePlayer puts the player straight onto a tile of a map, standing still
+func (g *Game) placePlayer(mapID string, x, y, direction int) {
+	if m, ok := g.maps[mapID]; ok {
+		g.worldMap = m
+	}
+	g.player.tileX, g.player.tileY = x, y
+	g.player.visualX = float32(x * tileSize)
+	g.player.visualY = float32(y * tileSize)
+	g.player.direction = direction
+	g.player.movementState = MovementIdle
+	g.player.surfing = g.worldMap.IsWater(x, y)
+	g.updateBike()
+	g.rustle = RustleMarker{}
+	g.emotes = nil
+	g.player.currentLayer = LayerBase
+	if g.worldMap.IsBridge(x, y) {
+		g.player.currentLayer = LayerOverlay
+	}
+	g.snapCamera()
+	g.worldMap.streamChunks(g.player.tileX, g.player.tileY)
+
+	// The lead creature catches up, tucked in behind the player
+	g.follower = Follower{tileX: x, tileY: y, visualX: g.player.visualX, visualY: g.player.visualY}
+}
+
+// drawTransition darkens the screen during a door fade
+func (g *Game) drawTransition(screen *ebiten.Image) {
+	if !g.transition.active {
+		return
+	}
+
+	// Fade out to black, then back in
+	progress := float32(g.transition.frames) / transitionFrames
+	if progress > 1 {
+		progress = 2 - progress
+	}
+
+	vector.DrawFilledRect(screen, 0, 0, screenWidth, screenHeight, color.RGBA{0, 0, 0, uint8(255 * progress)}, true)
+}
@@ -0,0 +1,166 @@
+package main
+
+import "strconv"
+
+// Item category constants
+const (
+	ItemMedicine = iota
+	ItemBall
+	ItemKey
+	ItemBerry
+	ItemRepel
+	ItemSplitter
+	ItemMaterial
+	ItemMail
+)
+
+// Item describes a kind of item the player can carry
+type Item struct {
+	name        string
+	description string
+	category    int
+	price       int
+	// HP restored by medicine and berries
+	heal int
+	// Share of max HP, as a divisor, that medicine for fainted creatures
+	// brings one back with; other medicine does nothing for them
+	revive int
+	// How long a berry tree, or a spot materials are gathered from, takes to
+	// refill after picking, whichever comes first
+	regrowSteps   int
+	regrowMinutes int
+	// Steps a repel keeps weak wild creatures away for
+	repelSteps int
+	// What it takes to craft one, if it can be crafted
+	recipe []Ingredient
+}
+
+// Ingredient is an item a recipe uses, and how many of it
+type Ingredient struct {
+	item  string
+	count int
+}
+
+// itemList holds every item in the game, in the order they're listed in the bag
+var itemList = []Item{
+	{name: "Potion", description: "Restores 20 HP to one creature.", category: ItemMedicine, price: 200, heal: 20,
+		recipe: []Ingredient{{"Oran Berry", 2}}},
+	{name: "Super Potion", description: "Restores 50 HP to one creature.", category: ItemMedicine, price: 600, heal: 50,
+		recipe: []Ingredient{{"Potion", 1}, {"Sitrus Berry", 2}}},
+	{name: "Revive", description: "Brings a fainted creature back with half its HP.", category: ItemMedicine, price: 1500, revive: 2,
+		recipe: []Ingredient{{"Super Potion", 1}, {"Glimmer Stone", 1}}},
+	{name: "Max Revive", description: "Brings a fainted creature back with all its HP.", category: ItemMedicine, price: 4000, revive: 1},
+	{name: "Capture Ball", description: "A ball for catching wild creatures.", category: ItemBall, price: 200,
+		recipe: []Ingredient{{"Iron Ore", 1}, {"Tin Can", 1}}},
+	{name: "Repel", description: "Keeps weak wild creatures away for 100 steps.", category: ItemRepel, price: 350, repelSteps: 100,
+		recipe: []Ingredient{{"Old Boot", 1}, {"Driftwood", 1}}},
+	{name: "Super Repel", description: "Keeps weak wild creatures away for 200 steps.", category: ItemRepel, price: 500, repelSteps: 200,
+		recipe: []Ingredient{{"Repel", 1}, {"Glimmer Stone", 1}}},
+	{name: "Oran Berry", description: "Restores 10 HP. Eaten automatically when held at half HP.", category: ItemBerry, price: 20, heal: 10, regrowSteps: 300, regrowMinutes: 20},
+	{name: "Sitrus Berry", description: "Restores 30 HP. Eaten automatically when held at half HP.", category: ItemBerry, price: 60, heal: 30, regrowSteps: 600, regrowMinutes: 45},
+	{name: "Watering Can", description: "Waters berries planted in soil. Face a planted berry to water it.", category: ItemKey},
+	{name: "Bicycle", description: "A folding bike that goes twice as fast on paths and bridges. Press V to ride.", category: ItemKey},
+	{name: "Splitter", description: "Splits a fused creature back into the two it was made from.", category: ItemSplitter, price: 1000},
+	{name: "Iron Ore", description: "A lump of ore dug out of a cave wall. Used in crafting.", category: ItemMaterial, price: 40, regrowSteps: 400, regrowMinutes: 30},
+	{name: "Glimmer Stone", description: "A stone that glitters in the dark of caves. Used in crafting.", category: ItemMaterial, price: 120, regrowSteps: 800, regrowMinutes: 60},
+	{name: "Tin Can", description: "An empty can fished out of the water. Used in crafting.", category: ItemMaterial, price: 10, regrowSteps: 300, regrowMinutes: 20},
+	{name: "Old Boot", description: "A soggy boot fished out of the water. It smells awful. Used in crafting.", category: ItemMaterial, price: 10, regrowSteps: 300, regrowMinutes: 20},
+	{name: "Driftwood", description: "Wood worn smooth by the water. Used in crafting.", category: ItemMaterial, price: 10, regrowSteps: 300, regrowMinutes: 20},
+	{name: mailItem, description: "Stationery for a short letter. Give it to a creature to write one for it to carry in a trade.", category: ItemMail, price: 50},
+}
+
+// findItem looks up an item by name, returning nil if it doesn't exist
+func findItem(name string) *Item {
+	for i := range itemList {
+		if itemList[i].name == name {
+			return &itemList[i]
+		}
+	}
+	return nil
+}
+
+// addItem puts count of an item in the bag
+func (g *Game) addItem(name string, count int) {
+	g.bag[name] += count
+}
+
+// removeItem takes one of an item out of the bag, reporting whether there was one
+func (g *Game) removeItem(name string) bool {
+	if g.bag[name] <= 0 {
+		return false
+	}
+	g.bag[name]--
+	if g.bag[name] == 0 {
+		delete(g.bag, name)
+	}
+	return true
+}
+
+// bagContents returns the names of the items in the bag, in item list order
+func (g *Game) bagContents() []string {
+	names := []string{}
+	for _, item := range itemList {
+		if g.bag[item.name] > 0 {
+			names = append(names, item.name)
+		}
+	}
+	return names
+}
+
+// useItem uses an item from the bag on a creature, returning a message
+// describing what happened
+func (g *Game) useItem(name string, c *Creature) string {
+	item := findItem(name)
+	if item != nil && item.category == ItemRepel {
+		return g.useRepel(name)
+	}
+	if item != nil && item.category == ItemSplitter && g.gameState != StateBattle {
+		return g.splitCreature(name, c)
+	}
+	if name == bicycle && g.gameState != StateBattle {
+		return g.toggleBike()
+	}
+	if item == nil || (item.category != ItemMedicine && item.category != ItemBerry) {
+		return "That can't be used here."
+	}
+	if c.egg {
+		return "It won't have any effect."
+	}
+	// Fainted creatures stay that way until revived or taken to a heal
+	// center
+	if item.revive > 0 {
+		if c.hp > 0 {
+			return "It won't have any effect."
+		}
+		g.removeItem(name)
+		c.hp = max(c.maxHP/item.revive, 1)
+		return c.displayName() + " was revived!"
+	}
+	if c.hp <= 0 {
+		return c.displayName() + " has fainted! Only a Revive or a heal center can help it."
+	}
+	if c.hp >= c.maxHP {
+		return "It won't have any effect."
+	}
+
+	g.removeItem(name)
+	healed := min(item.heal, c.maxHP-c.hp)
+	c.hp += healed
+	return c.displayName() + " recovered " + strconv.Itoa(healed) + " HP."
+}
+
+// giveItem has a creature hold an item from the bag, swapping out anything it
+// was already holding
+func (g *Game) giveItem(name string, c *Creature) string {
+	if c.egg {
+		return "An egg can't hold items."
+	}
+	g.removeItem(name)
+	message := c.displayName() + " is now holding the " + name + "."
+	if c.heldItem != "" {
+		g.addItem(c.heldItem, 1)
+		message = c.displayName() + " swapped its " + c.heldItem + " for the " + name + "."
+	}
+	c.heldItem = name
+	return message + g.fileLetter(c)
+}
@@ -0,0 +1,78 @@
+package main
+
+import "strconv"
+
+// maxCachedLabels is how many labels are kept before the cache is emptied
+// and starts over, so it can't grow without bound over a long session
+const maxCachedLabels = 512
+
+// labelKey is what a cached label is built from
+type labelKey struct {
+	prefix, name string
+	a, b         int
+}
+
+// cachedLabel returns the label for a key, only building it if the key
+// hasn't been seen since the cache was last emptied. Labels drawn every
+// frame go through here so they aren't put together again each time.
+func (g *Game) cachedLabel(key labelKey, build func() string) string {
+	if label, ok := g.labels[key]; ok {
+		return label
+	}
+	if g.labels == nil || len(g.labels) >= maxCachedLabels {
+		g.labels = make(map[labelKey]string)
+	}
+	label := build()
+	g.labels[key] = label
+	return label
+}
+
+// creatureLabel returns a creature's name, level and status as shown above
+// it in battle, after a prefix like a boss's title
+func (g *Game) creatureLabel(prefix string, c *Creature, level int) string {
+	return g.cachedLabel(labelKey{prefix, c.displayName(), level, c.status}, func() string {
+		return prefix + c.displayName() + " Lv." + strconv.Itoa(level) + " " + statusNames[c.status]
+	})
+}
+
+// fractionLabel returns two numbers as a fraction after a prefix, like
+// "PP 10/15"
+func (g *Game) fractionLabel(prefix string, n, of int) string {
+	return g.cachedLabel(labelKey{prefix, "/", n, of}, func() string {
+		return prefix + strconv.Itoa(n) + "/" + strconv.Itoa(of)
+	})
+}
+
+// countLabel returns a number after a prefix, like "Repel 42"
+func (g *Game) countLabel(prefix string, n int) string {
+	return g.cachedLabel(labelKey{prefix, "", n, 0}, func() string {
+		return prefix + strconv.Itoa(n)
+	})
+}
+
+// wrapKey is a message wrapped to a width
+type wrapKey struct {
+	message string
+	width   int
+}
+
+// wrapCached returns a message wrapped to a width like wrapText does, only
+// wrapping it again when it hasn't been seen since the cache was emptied
+func (g *Game) wrapCached(message string, width int) []string {
+	key := wrapKey{message, width}
+	if lines, ok := g.wrapped[key]; ok {
+		return lines
+	}
+	if g.wrapped == nil || len(g.wrapped) >= maxCachedLabels {
+		g.wrapped = make(map[wrapKey][]string)
+	}
+	lines := g.wrapText(message, width)
+	g.wrapped[key] = lines
+	return lines
+}
+
+// clearLabels empties the label caches, as text is measured differently
+// once the font changes
+func (g *Game) clearLabels() {
+	g.labels, g.wrapped = nil, nil
+}
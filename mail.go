@@ -0,0 +1,353 @@
+package main
+
+import (
+	"image/color"
+	"math/rand"
+	"strconv"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Mail constants
+const (
+	// Stationery a creature holds to carry a letter
+	mailItem = "Mail"
+	// Most characters a letter written by the player can have
+	maxLetterLength = 60
+	// Most letters the mailbox keeps; the oldest read ones go first
+	maxLetters = 20
+	// Chance of a tip or a gift arriving on a day with no news
+	letterChance = 0.5
+)
+
+// Mail screen stages
+const (
+	MailList = iota
+	MailReading
+	MailWriting
+)
+
+// Letter is a piece of mail, either sent to the mailbox at home or written
+// by a player and carried by a creature
+type Letter struct {
+	From    string `json:"from"`
+	Subject string `json:"subject,omitempty"`
+	Text    string `json:"text"`
+	// Item sent along with it, until it's taken out
+	Gift string `json:"gift,omitempty"`
+	Day  int    `json:"day"`
+	Read bool   `json:"read,omitempty"`
+}
+
+// title returns what a letter is listed as in the mailbox
+func (l *Letter) title() string {
+	if l.Subject != "" {
+		return l.Subject
+	}
+	return "Letter from " + l.From
+}
+
+// MailScreen is the mailbox at home, or the letter being written for a
+// creature to carry
+type MailScreen struct {
+	stage int
+	// Letter highlighted, and the first one in view when the list scrolls
+	selected int
+	top      int
+	// Party creature the letter is being written for, and what's written
+	creature int
+	draft    string
+	// Result of the last thing done
+	message string
+}
+
+// welcomeLetter is waiting in the mailbox when a new game starts
+var welcomeLetter = Letter{
+	From:    "Mom",
+	Subject: "Welcome to the mailbox",
+	Text:    "Letters will turn up here every so often, so check back whenever you're home. I've put in some stationery: give it to a creature and write a few words, and whoever you trade it to can read them.",
+	Gift:    mailItem,
+}
+
+// mailTips are sent on quiet days
+var mailTips = []string{
+	"Creatures caught in a chain of the same species are more likely to have good traits, and now and then a rare color.",
+	"A berry held by a creature gets eaten by itself once its HP drops to half. Plant spares in the garden by the house!",
+	"Creatures that follow you around grow fond of you. Talk to yours now and then, and see what it's sniffing at.",
+	"A Repel keeps weak wild creatures away, but not ones as strong as your lead creature.",
+	"The town map marks every town and route you've been to. There's more out there than you think!",
+}
+
+// mailGifts are sent now and then with a note
+var mailGifts = []string{"Potion", "Oran Berry", "Capture Ball", "Super Potion", mailItem}
+
+// deliverLetter puts a letter in the mailbox, making room by throwing out the
+// oldest read one, or the oldest of all if every one is unread
+func (g *Game) deliverLetter(letter Letter) {
+	letter.Day, letter.Read = g.calendar.day, false
+	if len(g.mail) >= maxLetters {
+		oldest := 0
+		for i := range g.mail {
+			if g.mail[i].Read {
+				oldest = i
+				break
+			}
+		}
+		g.mail = append(g.mail[:oldest], g.mail[oldest+1:]...)
+	}
+	g.mail = append(g.mail, letter)
+}
+
+// unreadLetters counts the letters in the mailbox that haven't been read
+func (g *Game) unreadLetters() int {
+	count := 0
+	for _, letter := range g.mail {
+		if !letter.Read {
+			count++
+		}
+	}
+	return count
+}
+
+// deliverDailyMail sends the day's letter, if there is one: news the day
+// before the market or the visitor, and otherwise now and then a tip or a
+// gift
+func (g *Game) deliverDailyMail() {
+	c := &g.calendar
+	var letter Letter
+	switch {
+	case (c.weekday()+1)%daysPerWeek == marketDay:
+		deal := marketDeals[(c.day+1)/daysPerWeek%len(marketDeals)]
+		letter = Letter{From: "Market Clerk", Subject: "Market day tomorrow",
+			Text: "It's market day tomorrow! Come by any heal center for " + deal + " at half price."}
+	case c.weekday() == 4:
+		letter = Letter{From: "The Visitor", Subject: "See you this weekend",
+			Text: "I'm coming back through town this weekend, and I'll be at the heal centers. Stop by - I might have something for you!"}
+	case rand.Float32() >= letterChance:
+		return
+	case rand.Intn(3) == 0:
+		gift := mailGifts[rand.Intn(len(mailGifts))]
+		letter = Letter{From: "Mom", Subject: "A little something",
+			Text: "I hope your journey's going well, {player}. I saw this and thought of you, so here's a " + gift + ". Look after yourself!", Gift: gift}
+	default:
+		letter = Letter{From: "Creature Fan Club", Subject: "Trainer tip",
+			Text: mailTips[rand.Intn(len(mailTips))]}
+	}
+	g.deliverLetter(letter)
+	g.showToast("You've got mail! Check the mailbox at home.")
+}
+
+// fileLetter takes the letter off a creature carrying one and puts it in the
+// mailbox, returning a note to add to a message about it. The stationery
+// stays with the creature.
+func (g *Game) fileLetter(c *Creature) string {
+	if c.mail == nil {
+		return ""
+	}
+	from := c.mail.From
+	g.deliverLetter(*c.mail)
+	c.mail = nil
+	return " It was carrying a letter from " + from + ", now in your mailbox."
+}
+
+// openMailbox switches to the mailbox screen
+func (g *Game) openMailbox() {
+	g.gameState = StateMail
+	g.mailScreen = MailScreen{}
+	if len(g.mail) > 0 {
+		// The newest letter first
+		g.mailScreen.selected = len(g.mail) - 1
+		g.mailScreen.top = max(len(g.mail)-g.mailRows(), 0)
+	}
+}
+
+// openMailWriter switches to writing a letter for a party creature to carry,
+// from the bag
+func (g *Game) openMailWriter(creature int) {
+	g.gameState = StateMail
+	g.mailScreen = MailScreen{stage: MailWriting, creature: creature}
+}
+
+// mailRows returns how many letters fit in the list at once, leaving room
+// for a message above the hint
+func (g *Game) mailRows() int {
+	return (screenHeight - 35 - g.lineSpacing() - g.listTop()) / g.rowHeight()
+}
+
+// mailRects lays out the letters in view
+func (g *Game) mailRects() []Rect {
+	rows := min(g.mailRows(), len(g.mail)-g.mailScreen.top)
+	return listRects(20, g.listTop(), screenWidth-40, g.rowHeight(), rows)
+}
+
+// updateMail handles the mailbox, reading a letter and writing one
+func (g *Game) updateMail() {
+	m := &g.mailScreen
+	switch m.stage {
+	case MailWriting:
+		g.updateMailWriter()
+		return
+	case MailReading:
+		if g.input.IsActionJustPressed(ebiten.KeyEscape) || g.input.IsActionJustPressed(ebiten.KeySpace) || g.input.IsActionJustPressed(ebiten.KeyEnter) {
+			m.stage, m.message = MailList, ""
+		}
+		return
+	}
+
+	if g.input.IsActionJustPressed(ebiten.KeyEscape) {
+		g.gameState = StateOverworld
+		return
+	}
+	if len(g.mail) == 0 {
+		return
+	}
+	if g.input.IsActionJustPressed(ebiten.KeyUp) {
+		m.selected = (m.selected - 1 + len(g.mail)) % len(g.mail)
+		m.message = ""
+	} else if g.input.IsActionJustPressed(ebiten.KeyDown) {
+		m.selected = (m.selected + 1) % len(g.mail)
+		m.message = ""
+	}
+	row := m.selected - m.top
+	clicked := g.mouseSelect(g.mailRects(), &row)
+	m.selected = m.top + row
+	// Keep the highlighted letter in view, and the list filled
+	defer func() {
+		m.top = max(min(min(m.top, m.selected), len(g.mail)-g.mailRows()), m.selected-g.mailRows()+1, 0)
+	}()
+
+	if g.input.IsKeyJustPressed(ebiten.KeyX) {
+		title := g.mail[m.selected].title()
+		g.mail = append(g.mail[:m.selected], g.mail[m.selected+1:]...)
+		m.selected = max(min(m.selected, len(g.mail)-1), 0)
+		m.message = "Threw away \"" + title + "\"."
+		return
+	}
+	if g.input.IsActionJustPressed(ebiten.KeySpace) || g.input.IsActionJustPressed(ebiten.KeyEnter) || clicked {
+		g.readLetter(&g.mail[m.selected])
+	}
+}
+
+// readLetter opens a letter, taking out any gift sent with it
+func (g *Game) readLetter(letter *Letter) {
+	m := &g.mailScreen
+	m.stage, m.message = MailReading, ""
+	letter.Read = true
+	if letter.Gift != "" {
+		g.addItem(letter.Gift, 1)
+		g.audio.playSound("heal")
+		m.message = "Found a " + letter.Gift + " in the envelope!"
+		letter.Gift = ""
+	}
+}
+
+// updateMailWriter types the letter and has the creature carry it once it's
+// done, going back to the bag either way
+func (g *Game) updateMailWriter() {
+	m := &g.mailScreen
+	if g.input.IsKeyJustPressed(ebiten.KeyEscape) {
+		g.gameState = StateBag
+		return
+	}
+	g.typeText(&m.draft, maxLetterLength)
+	if !g.input.IsKeyJustPressed(ebiten.KeyEnter) || m.draft == "" {
+		return
+	}
+	c := &g.creatures[m.creature]
+	g.bagMessage = g.giveItem(mailItem, c)
+	c.mail = &Letter{From: g.playerName, Text: m.draft}
+	g.gameState = StateBag
+}
+
+// drawMailbox draws the mailbox at home, with its flag up while there's
+// unread mail
+func (g *Game) drawMailbox(screen *ebiten.Image, x, y float32) {
+	vector.DrawFilledRect(screen, x-2, y-2, 4, 14, color.RGBA{110, 80, 50, 255}, true)
+	vector.DrawFilledRect(screen, x-9, y-12, 18, 11, color.RGBA{60, 90, 170, 255}, true)
+	vector.DrawFilledRect(screen, x-7, y-8, 14, 2, color.RGBA{30, 40, 80, 255}, true)
+	if g.unreadLetters() > 0 {
+		vector.DrawFilledRect(screen, x+9, y-16, 2, 10, color.RGBA{90, 90, 90, 255}, true)
+		vector.DrawFilledRect(screen, x+11, y-16, 5, 4, color.RGBA{220, 40, 40, 255}, true)
+	}
+}
+
+// drawMail draws the mailbox screen: the letters in it, the one being read,
+// or the one being written
+func (g *Game) drawMail(screen *ebiten.Image) {
+	m := &g.mailScreen
+	g.drawPanel(screen, 10, 10, float32(screenWidth-20), float32(screenHeight-20), color.RGBA{60, 70, 110, 240})
+
+	switch m.stage {
+	case MailWriting:
+		c := &g.creatures[m.creature]
+		g.drawText(screen, "Write a letter for "+c.name+" to carry", 20, 30, color.White)
+		g.drawLetter(screen, Letter{From: g.playerName, Text: m.draft + g.textCursor()})
+		g.drawText(screen, g.fractionLabel("", len(m.draft), maxLetterLength), 20, float64(screenHeight-60), color.RGBA{200, 200, 200, 255})
+		g.drawHint(screen, "Type the letter. Enter to give it, ESC to cancel")
+		return
+	case MailReading:
+		letter := g.mail[m.selected]
+		letter.Text = g.fillNames(letter.Text)
+		g.drawText(screen, letter.title(), 20, 30, color.White)
+		g.drawLetter(screen, letter)
+		g.drawMailFooter(screen, "Space to put it away")
+		return
+	}
+
+	g.drawText(screen, "Mailbox", 20, 30, color.White)
+	if unread := g.unreadLetters(); unread > 0 {
+		label := g.countLabel("Unread: ", unread)
+		g.drawText(screen, label, float64(screenWidth-20-g.textWidth(label)), 30, color.RGBA{255, 220, 120, 255})
+	}
+	if len(g.mail) == 0 {
+		g.drawText(screen, "The mailbox is empty.", 30, float64(g.listTop()), color.RGBA{200, 200, 200, 255})
+		g.drawHint(screen, "ESC to go back")
+		return
+	}
+
+	rects := g.mailRects()
+	for row, rect := range rects {
+		i := m.top + row
+		letter := &g.mail[i]
+		clr := color.Color(color.RGBA{200, 200, 200, 255})
+		if !letter.Read {
+			clr = color.White
+		}
+		if i == m.selected {
+			g.drawText(screen, ">", float64(rect.x), float64(rect.y), color.RGBA{255, 255, 0, 255})
+			clr = color.RGBA{255, 255, 0, 255}
+		}
+		title := letter.title()
+		if !letter.Read {
+			title = "* " + title
+		}
+		day := "Day " + strconv.Itoa(letter.Day+1)
+		g.drawText(screen, title, float64(rect.x+g.selectorWidth()), float64(rect.y), clr)
+		g.drawText(screen, day, float64(rect.x+rect.width-g.textWidth(day)), float64(rect.y), clr)
+	}
+	g.drawMailFooter(screen, "Space to read, X to throw away, ESC to go back")
+}
+
+// drawLetter draws a letter on a sheet of paper, signed by whoever sent it
+func (g *Game) drawLetter(screen *ebiten.Image, letter Letter) {
+	top := g.listTop()
+	g.drawPanel(screen, 24, float32(top-6), float32(screenWidth-48), 120, color.RGBA{245, 240, 220, 255})
+	ink := color.RGBA{50, 50, 70, 255}
+	lines := g.wrapText(letter.Text, screenWidth-68)
+	for i, line := range lines {
+		g.drawText(screen, line, 34, float64(top+i*g.lineSpacing()), ink)
+	}
+	signature := "- " + letter.From
+	g.drawText(screen, signature, float64(screenWidth-34-g.textWidth(signature)), float64(top+120-6-g.lineSpacing()), ink)
+}
+
+// drawMailFooter draws the controls along the bottom of the mailbox, with the
+// last message above them
+func (g *Game) drawMailFooter(screen *ebiten.Image, hint string) {
+	hintTop := g.drawHint(screen, hint)
+	lines := g.wrapText(g.mailScreen.message, screenWidth-40)
+	for i, line := range lines {
+		g.drawText(screen, line, 20, float64(hintTop-(len(lines)-i)*g.lineSpacing()), color.RGBA{255, 255, 180, 255})
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"log"
 
 	"github.com/hajimehoshi/ebiten/v2"
@@ -13,12 +14,56 @@ const (
 )
 
 func main() {
-	ebiten.SetWindowSize(screenWidth*2, screenHeight*2)
+	spritesDir := flag.String("sprites", "", "folder of PNG images to pack into the sprite atlas in place of the drawn sprites")
+	// Testers can jump straight into a scenario
+	configFile := flag.String("config", defaultConfigFile, "launch configuration file to read")
+	seed := flag.Int64("seed", 0, "seed for the world of a new game, overriding the config")
+	terrain := flag.String("terrain", "", "terrain generator for the world of a new game, noise or cellular, overriding the config")
+	mapID := flag.String("map", "", "start a new game on this map, like "+overworldID+" or town0-building0")
+	skipMenu := flag.Bool("skip-menu", false, "start a new game straight away with the first starter")
+	debug := flag.Bool("debug", false, "show the debug overlay, as dev mode in the config does")
+	flag.Parse()
+
+	config, err := loadConfig(*configFile)
+	if err != nil {
+		log.Println("Failed to read config:", err)
+	}
+	if *seed != 0 {
+		config.Seed = *seed
+	}
+	if *terrain != "" {
+		if _, ok := parseTerrain(*terrain); !ok {
+			log.Fatalf("No terrain generator called %q", *terrain)
+		}
+		config.Terrain = *terrain
+	}
+	config.Dev = config.Dev || *debug
+	config.SkipMenu, config.Map = *skipMenu, *mapID
+	if config.DataDir != "" {
+		saveStore = dataDirStore(config.DataDir)
+	}
+
+	ebiten.SetWindowSize(screenWidth*config.WindowScale, screenHeight*config.WindowScale)
+	ebiten.SetVsyncEnabled(config.VSync)
 	ebiten.SetWindowTitle("Creaturegame")
+	// One step of game logic per Update; the clock keeps the game at the
+	// same speed if this is changed
+	ebiten.SetTPS(simulationRate)
+	// Closing the window asks about unsaved progress first
+	ebiten.SetWindowClosingHandled(true)
+
+	// Loose sprites go into the atlas before anything is drawn
+	if *spritesDir != "" {
+		if err := spriteAtlas().loadSprites(*spritesDir); err != nil {
+			log.Println("Failed to load sprites:", err)
+		}
+	}
 
-	game := NewGame()
+	game := NewGame(config)
 
-	if err := ebiten.RunGame(game); err != nil {
+	err = ebiten.RunGame(game)
+	game.shutdown()
+	if err != nil {
 		log.Fatal(err)
 	}
 }
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// tileSolidity says whether a tile should be impassable: 1 for solid, 0 for
+// walkable. Water depends on whether it's bridged, and tiles missing here
+// aren't checked.
+var tileSolidity = map[int]int{
+	TileGrass:         0,
+	TileTallGrass:     0,
+	TileRustlingGrass: 0,
+	TilePath:          0,
+	TileSand:          0,
+	TileDoor:          0,
+	TileCave:          0,
+	TileMountain:      1,
+	TileTree:          1,
+	TileRoof:          1,
+	TileRoofHeal:      1,
+	TileRoofShop:      1,
+	TileRoofGym:       1,
+	TileRoofSafari:    1,
+	TileRoofLab:       1,
+	TileRoofCorner:    1,
+	TileWall:          1,
+	TileSign:          1,
+	TileSoil:          1,
+	TileIce:           0,
+	TileCurrentUp:     1,
+	TileCurrentDown:   1,
+	TileCurrentLeft:   1,
+	TileCurrentRight:  1,
+}
+
+// encounterTile reports whether wild creatures can be met on a tile
+func encounterTile(tile int) bool {
+	return tile == TileGrass || tile == TileTallGrass || tile == TileRustlingGrass
+}
+
+// CheckMap generates every chunk of a map and checks the invariants the rest
+// of the game relies on, returning what's wrong, if anything: bridges only
+// over water, the grass flags matching the grass tiles, collision matching
+// the tiles, and the spawn point on ground or a bridge
+func CheckMap(m *Map) []error {
+	return checkArea(m, 0, 0, m.width, m.height)
+}
+
+// CheckSpawnArea checks the same invariants as CheckMap, but only on the
+// chunks around the spawn point, which is far quicker
+func CheckSpawnArea(m *Map) []error {
+	spawn := m.Spawn()
+	left := max(0, (spawn.x/chunkSize-1)*chunkSize)
+	top := max(0, (spawn.y/chunkSize-1)*chunkSize)
+	return checkArea(m, left, top, min(m.width, left+3*chunkSize), min(m.height, top+3*chunkSize))
+}
+
+// checkArea checks the invariants of CheckMap on the tiles from left, top up
+// to right, bottom, and the spawn point
+func checkArea(m *Map, left, top, right, bottom int) []error {
+	var problems []error
+	report := func(x, y int, format string, args ...any) {
+		problems = append(problems, fmt.Errorf("seed %d, %s terrain, tile %d,%d: %s", m.seed, terrainNames[m.config.terrain], x, y, fmt.Sprintf(format, args...)))
+	}
+
+	for y := top; y < bottom; y++ {
+		for x := left; x < right; x++ {
+			c, lx, ly := m.chunkAt(x, y)
+			i := ly*chunkSize + lx
+			base := c.tiles[LayerBase][ly][lx]
+			bridge := c.bridgeTiles.get(i)
+
+			if bridge && base != TileWater {
+				report(x, y, "bridge over tile %d rather than water", base)
+			}
+			if bridge != (c.tiles[LayerOverlay][ly][lx] == TileBridge) {
+				report(x, y, "bridge flag doesn't match the overlay")
+			}
+			if c.grassTiles.get(i) != encounterTile(base) {
+				report(x, y, "grass flag doesn't match tile %d", base)
+			}
+
+			solid, known := tileSolidity[base]
+			if base == TileWater {
+				solid, known = 1, true
+				if bridge {
+					solid = 0
+				}
+			}
+			if known && c.collisionMap.get(i) != (solid == 1) {
+				report(x, y, "collision doesn't match tile %d", base)
+			}
+		}
+
+		// Only a few rows of chunks are kept at once, as in play
+		if y%chunkSize == chunkSize-1 {
+			for key := range m.chunks {
+				if key.y <= y/chunkSize {
+					delete(m.chunks, key)
+				}
+			}
+		}
+	}
+
+	spawn := m.Spawn()
+	if tile := m.Tile(LayerBase, spawn.x, spawn.y); tile == TileWater && !m.IsBridge(spawn.x, spawn.y) || m.IsCollision(spawn.x, spawn.y) {
+		report(spawn.x, spawn.y, "spawn point is on tile %d, which can't be stood on", tile)
+	}
+	return problems
+}
+
+// CheckSeeds generates and checks the worlds from count seeds in a row
+// starting at first, with each terrain generator, returning every problem
+// found
+func CheckSeeds(first int64, count int) []error {
+	var problems []error
+	for seed := first; seed < first+int64(count); seed++ {
+		for terrain := range terrainNames {
+			problems = append(problems, CheckMap(GenerateOverworld(seed, terrain))...)
+		}
+	}
+	return problems
+}
+
+// snapshotGlyphs draw each tile as a character in a map snapshot
+var snapshotGlyphs = map[int]byte{
+	TileGrass:         '.',
+	TileTallGrass:     '"',
+	TileRustlingGrass: '\'',
+	TilePath:          '=',
+	TileWater:         '~',
+	TileSand:          ':',
+	TileMountain:      '^',
+	TileTree:          'T',
+	TileDoor:          'D',
+	TileCave:          'C',
+	TileSign:          'S',
+	TileSoil:          '%',
+	TileIce:           '*',
+	TileCurrentUp:     'A',
+	TileCurrentDown:   'V',
+	TileCurrentLeft:   '<',
+	TileCurrentRight:  '>',
+	TileWall:          '#',
+}
+
+// MapSnapshot draws the tiles of a map in a rectangle as text, a character a
+// tile and a line a row, for comparing against a known good copy. Bridges
+// show as '|', and tiles with no glyph of their own as '?'.
+func MapSnapshot(m *Map, left, top, width, height int) string {
+	var b strings.Builder
+	for y := top; y < top+height; y++ {
+		for x := left; x < left+width; x++ {
+			glyph, ok := snapshotGlyphs[m.Tile(LayerBase, x, y)]
+			switch {
+			case m.IsBridge(x, y):
+				glyph = '|'
+			case !ok:
+				glyph = '?'
+			}
+			b.WriteByte(glyph)
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
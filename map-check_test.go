@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden map snapshots in testdata")
+
+func TestMapInvariants(t *testing.T) {
+	// Each world takes a while to check in full, so only a few are; the
+	// sweep below covers many more around their spawn points
+	seeds := 4
+	if testing.Short() {
+		seeds = 1
+	}
+	for _, problem := range CheckSeeds(1, seeds) {
+		t.Error(problem)
+	}
+}
+
+func TestSpawnInvariants(t *testing.T) {
+	seeds := int64(2000)
+	if testing.Short() {
+		seeds = 100
+	}
+	for seed := int64(1); seed <= seeds; seed++ {
+		for terrain := range terrainNames {
+			for _, problem := range CheckSpawnArea(GenerateOverworld(seed, terrain)) {
+				t.Error(problem)
+			}
+		}
+	}
+}
+
+func TestMapSnapshot(t *testing.T) {
+	for seed := int64(1); seed <= 3; seed++ {
+		for terrain, name := range terrainNames {
+			m := GenerateOverworld(seed, terrain)
+			spawn := m.Spawn()
+			got := MapSnapshot(m, spawn.x-32, spawn.y-16, 64, 32)
+
+			golden := filepath.Join("testdata", fmt.Sprintf("seed%d-%s.golden", seed, name))
+			if *updateGolden {
+				if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				continue
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != string(want) {
+				t.Errorf("seed %d, %s terrain: the tiles around the spawn point differ from %s\n%s", seed, name, golden, got)
+			}
+		}
+	}
+}
+
+// BenchmarkGenerateChunks measures generating the chunks around the spawn
+// point of the world from seed 1
+func BenchmarkGenerateChunks(b *testing.B) {
+	m := GenerateOverworld(1, TerrainNoise)
+	spawn := m.Spawn()
+	cx, cy := spawn.x/chunkSize, spawn.y/chunkSize
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := range b.N {
+		m.generateChunk(cx-2+i%5, cy-2+i/5%5)
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"sort"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/vector"
 )
 
 // Tile type constants
@@ -16,6 +15,39 @@ const (
 	TileWater
 	TileBridge
 	TileMountain
+	TileTree
+	TileSand
+	TileRoof
+	TileRoofHeal
+	TileRoofShop
+	TileWall
+	TileDoor
+	TileSign
+	TileFloor
+	TileCounter
+	TileInnerWall
+	TileRoofGym
+	TileCave
+	TileCaveFloor
+	TileRock
+	TileHole
+	TileFilledHole
+	TileSwitch
+	TileGate
+	TileGateOpen
+	TileTallGrass
+	TileRustlingGrass
+	TileRoofSafari
+	TileRoofLab
+	TileSoil
+	TileIce
+	TileRoofCorner
+	// Water flowing one way, in the order of the directions
+	TileCurrentUp
+	TileCurrentDown
+	TileCurrentLeft
+	TileCurrentRight
+	TileCount
 )
 
 // Layer constants
@@ -26,64 +58,161 @@ const (
 	LayerCount
 )
 
-// Map represents the game world
+// Map represents the game world, split into chunks that are generated on demand
 type Map struct {
-	tiles       [LayerCount][][]int
-	width       int
-	height      int
-	grassTiles  map[string]bool
-	bridgeTiles map[string]bool
-	// Add collision map
-	collisionMap map[string]bool
+	id string
+	// Size of the world in tiles
+	width  int
+	height int
+	// Seed used to generate every chunk deterministically
+	seed int64
+	// Regions, towns and routes laid out before any chunk is generated
+	plan *WorldPlan
+	// How the terrain of each chunk is produced
+	config GenerationConfig
+	// Climate noise used to assign biomes
+	temperature *Noise
+	moisture    *Noise
+	// Height noise used by the noise terrain generator
+	elevation *Noise
+	// Chunks currently held in memory, keyed by chunk coordinate
+	chunks map[Point]*Chunk
+	// Static maps are built up front and never stream chunks in or out
+	static bool
+	// Maps such as interiors where wild creatures never appear
+	noEncounters bool
+	// Door tiles that move the player to another map
+	warps map[Point]Warp
+	// Signs, item balls and other things the player can interact with
+	objects map[Point]*MapObject
+	// Item balls that have been picked up and must not come back
+	pickedUp map[Point]bool
+	// When each berry tree was last picked
+	harvested map[Point]Harvest
+	// Trees cut and boulders pushed, by where they started
+	obstacles map[Point]ObstacleState
+	// Wild creatures found on the map, overriding the biome tables
+	encounters []EncounterEntry
+	// Where the player appears when warping into this map
+	entrance Point
+	// Where the weekend visitor stands, on maps they visit
+	visitorSpot *Point
+	// Tiles laid over the base layer for now, like paths flooded by rain.
+	// The chunks' own tiles are left alone, so lifting an override brings
+	// back the tile underneath.
+	overrides map[Point]int
 }
 
-// Initialize a map with layers, including more realistic water bodies and bridges
-func (g *Game) initMap() {
-	width, height := 20, 15
-	g.worldMap = Map{
-		width:        width,
-		height:       height,
-		grassTiles:   make(map[string]bool),
-		bridgeTiles:  make(map[string]bool),
-		collisionMap: make(map[string]bool),
+// overworldID is the map ID of the overworld
+const overworldID = "overworld"
+
+// Point is a tile coordinate on the map
+type Point struct {
+	x, y int
+}
+
+// GenerateOverworld returns the overworld made from a seed by a terrain
+// generator, with its towns, routes and signs laid out. Chunks are generated
+// lazily as they're reached, and always come out the same for the same seed
+// and generator.
+func GenerateOverworld(seed int64, terrain int) *Map {
+	config := overworldConfig
+	config.terrain = terrain
+	overworld := &Map{
+		id:        overworldID,
+		width:     worldWidth,
+		height:    worldHeight,
+		seed:      seed,
+		chunks:    make(map[Point]*Chunk),
+		config:    config,
+		warps:     make(map[Point]Warp),
+		objects:   make(map[Point]*MapObject),
+		pickedUp:  make(map[Point]bool),
+		harvested: make(map[Point]Harvest),
+		obstacles: make(map[Point]ObstacleState),
+		overrides: make(map[Point]int),
+	}
+	overworld.plan = newWorldPlan(seed, worldWidth, worldHeight)
+	overworld.temperature = newNoise(seed + 1)
+	overworld.moisture = newNoise(seed + 2)
+	overworld.elevation = newNoise(seed + 3)
+
+	// Signs placed by the generator
+	for _, sign := range overworld.plan.signs {
+		overworld.objects[sign.pos] = &MapObject{kind: ObjectSign, text: sign.text}
 	}
 
-	// Initialize layers
-	for layer := range LayerCount {
-		g.worldMap.tiles[layer] = make([][]int, height)
-		for y := range height {
-			g.worldMap.tiles[layer][y] = make([]int, width)
-			for x := range width {
-				g.worldMap.tiles[layer][y][x] = TileGrass // Default to grass
+	// A sleeping creature blocks one of the routes
+	overworld.placeRouteSleeper()
+	return overworld
+}
+
+// Spawn returns where a new game starts: the middle of the first town
+func (m *Map) Spawn() Point {
+	return m.plan.regions[0].town
+}
 
-				// Mark as grass tile for encounter checks
-				key := formatCoord(x, y)
-				g.worldMap.grassTiles[key] = true
+// initMap sets up the overworld from a seed and terrain generator, and the
+// interiors of its buildings
+func (g *Game) initMap(seed int64, terrain int) {
+	overworld := GenerateOverworld(seed, terrain)
+	g.maps = map[string]*Map{overworldID: overworld}
+	g.worldMap = overworld
+	g.returnPoints = nil
+
+	// Every building door leads to its own interior map. Each town's heal
+	// center has someone teaching a different recipe, a bulletin board of
+	// tasks, a board of trades on offer, a PC for reaching storage and
+	// someone who re-teaches forgotten moves.
+	recipes := craftableItems()
+	for i, region := range overworld.plan.regions {
+		for _, building := range region.buildings {
+			interior := newInteriorMap(building)
+			if building.kind == BuildingHealCenter {
+				interior.addCrafter(recipes[i%len(recipes)])
+				interior.addBulletinBoard()
+				interior.addTradeBoard()
+				interior.addStoragePC()
+				interior.addMoveRelearner()
+			}
+			// Gyms in snowfields have icy floors to slide across
+			if building.kind == BuildingGym && overworld.BiomeAt(building.door.x, building.door.y) == BiomeSnowfield {
+				interior.freezeGymFloor()
+			}
+			g.maps[building.interior] = interior
+			overworld.warps[building.door] = Warp{
+				mapID: building.interior,
+				x:     interior.entrance.x,
+				y:     interior.entrance.y,
 			}
 		}
 	}
 
-	// Generate realistic water bodies using cellular automata
-	g.generateWaterBodies(width, height)
+	// The safari zone is reached through its gate
+	g.maps[safariZoneID] = newSafariZone()
 
-	// Generate paths connecting different areas
-	g.generatePaths(width, height)
+	// Start the player in the middle of the first town
+	spawn := overworld.Spawn()
+	g.player.tileX, g.player.tileY = spawn.x, spawn.y
+	g.player.visualX = float32(spawn.x * tileSize)
+	g.player.visualY = float32(spawn.y * tileSize)
+	g.snapCamera()
 
-	// Place mountains in clusters away from water
-	g.generateMountains(width, height)
+	// Until the player uses a heal center, they wake up back in their home town
+	g.respawn = Respawn{mapID: overworldID, x: spawn.x, y: spawn.y, direction: DirectionDown}
 
-	// Add bridges at strategic locations
-	g.placeBridges(width, height)
+	// Generate the chunks around the player's starting position
+	g.worldMap.streamChunks(g.player.tileX, g.player.tileY)
 }
 
 // generateWaterBodies creates realistic water features using cellular automata
-func (g *Game) generateWaterBodies(width, height int) {
-	// Initialize water cells randomly (about 30% of tiles)
+func (c *Chunk) generateWaterBodies(rng *rand.Rand, width, height int, waterDensity float32) {
+	// Initialize water cells randomly (waterDensity of tiles, 30% in a typical region)
 	waterMap := make([][]bool, height)
 	for y := range height {
 		waterMap[y] = make([]bool, width)
 		for x := range width {
-			if rand.Float32() < 0.3 {
+			if rng.Float32() < waterDensity {
 				waterMap[y][x] = true
 			}
 		}
@@ -116,7 +245,7 @@ func (g *Game) generateWaterBodies(width, height int) {
 	}
 
 	// Create rivers by drawing lines between water bodies
-	riverOrigins := []struct{ x, y int }{}
+	riverOrigins := []Point{}
 
 	// Find potential river origins (water near land)
 	for y := range height {
@@ -135,8 +264,8 @@ func (g *Game) generateWaterBodies(width, height int) {
 						break
 					}
 				}
-				if hasLandNeighbor && rand.Float32() < 0.2 {
-					riverOrigins = append(riverOrigins, struct{ x, y int }{x, y})
+				if hasLandNeighbor && rng.Float32() < 0.2 {
+					riverOrigins = append(riverOrigins, Point{x, y})
 				}
 			}
 		}
@@ -144,22 +273,22 @@ func (g *Game) generateWaterBodies(width, height int) {
 
 	// Draw rivers from origins
 	for _, origin := range riverOrigins {
-		if len(riverOrigins) <= 2 || rand.Float32() < 0.5 {
+		if len(riverOrigins) <= 2 || rng.Float32() < 0.5 {
 			// Create river path
 			x, y := origin.x, origin.y
-			length := rand.Intn(8) + 3
+			length := rng.Intn(8) + 3
 			dx, dy := 0, 0
 
 			// Choose a consistent direction for the river
-			if rand.Float32() < 0.5 {
-				dx = rand.Intn(3) - 1 // -1, 0, or 1
+			if rng.Float32() < 0.5 {
+				dx = rng.Intn(3) - 1 // -1, 0, or 1
 				if dx == 0 {
-					dy = rand.Intn(2)*2 - 1 // -1 or 1
+					dy = rng.Intn(2)*2 - 1 // -1 or 1
 				}
 			} else {
-				dy = rand.Intn(3) - 1 // -1, 0, or 1
+				dy = rng.Intn(3) - 1 // -1, 0, or 1
 				if dy == 0 {
-					dx = rand.Intn(2)*2 - 1 // -1 or 1
+					dx = rng.Intn(2)*2 - 1 // -1 or 1
 				}
 			}
 
@@ -173,16 +302,16 @@ func (g *Game) generateWaterBodies(width, height int) {
 				waterMap[ny][nx] = true
 
 				// Slight chance of changing direction
-				if rand.Float32() < 0.2 {
-					if rand.Float32() < 0.5 {
-						dx += rand.Intn(3) - 1
+				if rng.Float32() < 0.2 {
+					if rng.Float32() < 0.5 {
+						dx += rng.Intn(3) - 1
 						if dx < -1 {
 							dx = -1
 						} else if dx > 1 {
 							dx = 1
 						}
 					} else {
-						dy += rand.Intn(3) - 1
+						dy += rng.Intn(3) - 1
 						if dy < -1 {
 							dy = -1
 						} else if dy > 1 {
@@ -192,10 +321,10 @@ func (g *Game) generateWaterBodies(width, height int) {
 
 					// Ensure we have direction
 					if dx == 0 && dy == 0 {
-						if rand.Float32() < 0.5 {
-							dx = rand.Intn(2)*2 - 1
+						if rng.Float32() < 0.5 {
+							dx = rng.Intn(2)*2 - 1
 						} else {
-							dy = rand.Intn(2)*2 - 1
+							dy = rng.Intn(2)*2 - 1
 						}
 					}
 				}
@@ -209,28 +338,27 @@ func (g *Game) generateWaterBodies(width, height int) {
 	for y := range height {
 		for x := range width {
 			if waterMap[y][x] {
-				g.worldMap.tiles[LayerBase][y][x] = TileWater
+				c.tiles[LayerBase][y][x] = TileWater
 
 				// Add water to collision map
-				key := formatCoord(x, y)
-				g.worldMap.collisionMap[key] = true
-				delete(g.worldMap.grassTiles, key)
+				c.setCollision(x, y, true)
+				c.setGrass(x, y, false)
 			}
 		}
 	}
 }
 
 // generatePaths creates paths connecting different parts of the map
-func (g *Game) generatePaths(width, height int) {
+func (c *Chunk) generatePaths(rng *rand.Rand, width, height int) {
 	// Create a few random path starting points
-	pathPoints := []struct{ x, y int }{}
+	pathPoints := []Point{}
 
 	// Add a few starting points for paths
-	numPathPoints := rand.Intn(3) + 2
+	numPathPoints := rng.Intn(3) + 2
 	for range numPathPoints {
-		x := rand.Intn(width)
-		y := rand.Intn(height)
-		pathPoints = append(pathPoints, struct{ x, y int }{x, y})
+		x := rng.Intn(width)
+		y := rng.Intn(height)
+		pathPoints = append(pathPoints, Point{x, y})
 	}
 
 	// Connect path points with each other
@@ -241,18 +369,19 @@ func (g *Game) generatePaths(width, height int) {
 		// Simple pathfinding to connect points
 		x, y := start.x, start.y
 		for x != end.x || y != end.y {
-			if g.worldMap.tiles[LayerBase][y][x] != TileWater {
-				g.worldMap.tiles[LayerBase][y][x] = TilePath
+			if c.tiles[LayerBase][y][x] != TileWater {
+				c.tiles[LayerBase][y][x] = TilePath
 
-				// Remove from grass tiles for encounter checks
-				key := formatCoord(x, y)
-				delete(g.worldMap.grassTiles, key)
+				// Remove from grass tiles for encounter checks, and clear
+				// anything impassable the path was cut through
+				c.setGrass(x, y, false)
+				c.setCollision(x, y, false)
 			}
 
 			// Move toward end point
-			if x < end.x && rand.Float32() < 0.7 {
+			if x < end.x && rng.Float32() < 0.7 {
 				x++
-			} else if x > end.x && rand.Float32() < 0.7 {
+			} else if x > end.x && rng.Float32() < 0.7 {
 				x--
 			} else if y < end.y {
 				y++
@@ -262,20 +391,20 @@ func (g *Game) generatePaths(width, height int) {
 		}
 
 		// Set final tile (if not water)
-		if g.worldMap.tiles[LayerBase][end.y][end.x] != TileWater {
-			g.worldMap.tiles[LayerBase][end.y][end.x] = TilePath
+		if c.tiles[LayerBase][end.y][end.x] != TileWater {
+			c.tiles[LayerBase][end.y][end.x] = TilePath
 
 			// Remove from grass tiles for encounter checks
-			key := formatCoord(end.x, end.y)
-			delete(g.worldMap.grassTiles, key)
+			c.setGrass(end.x, end.y, false)
+			c.setCollision(end.x, end.y, false)
 		}
 	}
 }
 
 // generateMountains places mountain clusters in sensible locations
-func (g *Game) generateMountains(width, height int) {
+func (c *Chunk) generateMountains(rng *rand.Rand, width, height int, maxClusters int) {
 	// Add mountains (impassable) in clusters
-	numMountainClusters := rand.Intn(3) + 1
+	numMountainClusters := rng.Intn(maxClusters) + 1
 	for range numMountainClusters {
 		// Find a spot for mountains (preferably away from water)
 		var mountainX, mountainY int
@@ -283,8 +412,8 @@ func (g *Game) generateMountains(width, height int) {
 		validSpot := false
 
 		for !validSpot && attempts < 20 {
-			mountainX = rand.Intn(width-4) + 2
-			mountainY = rand.Intn(height-4) + 2
+			mountainX = rng.Intn(width-4) + 2
+			mountainY = rng.Intn(height-4) + 2
 
 			// Check if the area has minimal water
 			waterCount := 0
@@ -292,7 +421,7 @@ func (g *Game) generateMountains(width, height int) {
 				for dx := -2; dx <= 2; dx++ {
 					nx, ny := mountainX+dx, mountainY+dy
 					if nx >= 0 && nx < width && ny >= 0 && ny < height &&
-						g.worldMap.tiles[LayerBase][ny][nx] == TileWater {
+						c.tiles[LayerBase][ny][nx] == TileWater {
 						waterCount++
 					}
 				}
@@ -303,28 +432,27 @@ func (g *Game) generateMountains(width, height int) {
 		}
 
 		// Create mountain cluster
-		clusterSize := rand.Intn(8) + 5
+		clusterSize := rng.Intn(8) + 5
 		for range clusterSize {
 			// Mountains form in connected patterns
-			offsetX := rand.Intn(5) - 2
-			offsetY := rand.Intn(5) - 2
+			offsetX := rng.Intn(5) - 2
+			offsetY := rng.Intn(5) - 2
 
 			nx, ny := mountainX+offsetX, mountainY+offsetY
 			if nx >= 0 && nx < width && ny >= 0 && ny < height &&
-				g.worldMap.tiles[LayerBase][ny][nx] != TileWater {
-				g.worldMap.tiles[LayerBase][ny][nx] = TileMountain
+				c.tiles[LayerBase][ny][nx] != TileWater {
+				c.tiles[LayerBase][ny][nx] = TileMountain
 
 				// Add mountain to collision map
-				key := formatCoord(nx, ny)
-				g.worldMap.collisionMap[key] = true
-				delete(g.worldMap.grassTiles, key)
+				c.setCollision(nx, ny, true)
+				c.setGrass(nx, ny, false)
 			}
 		}
 	}
 }
 
 // placeBridges adds bridges at strategic locations over water
-func (g *Game) placeBridges(width, height int) {
+func (c *Chunk) placeBridges(rng *rand.Rand, width, height int) {
 	// Find potential bridge locations by looking for water bodies that separate land
 	bridgeCandidates := []struct {
 		x, y      int
@@ -336,12 +464,12 @@ func (g *Game) placeBridges(width, height int) {
 	for y := 1; y < height-1; y++ {
 		for x := 1; x < width-2; x++ {
 			// Look for patterns like: land - water - water - land
-			if g.worldMap.tiles[LayerBase][y][x-1] != TileWater &&
-				g.worldMap.tiles[LayerBase][y][x] == TileWater {
+			if c.tiles[LayerBase][y][x-1] != TileWater &&
+				c.tiles[LayerBase][y][x] == TileWater {
 
 				// Find the end of the water stretch
 				endX := x
-				for endX < width-1 && g.worldMap.tiles[LayerBase][y][endX] == TileWater {
+				for endX < width-1 && c.tiles[LayerBase][y][endX] == TileWater {
 					endX++
 				}
 
@@ -349,7 +477,7 @@ func (g *Game) placeBridges(width, height int) {
 				// but also not too short (at least 2 tiles of water)
 				waterLength := endX - x
 				if endX < width &&
-					g.worldMap.tiles[LayerBase][y][endX] != TileWater &&
+					c.tiles[LayerBase][y][endX] != TileWater &&
 					waterLength >= 2 && waterLength <= 5 {
 
 					// Check that this isn't just following the coastline
@@ -359,10 +487,10 @@ func (g *Game) placeBridges(width, height int) {
 					leftIsSolid := false
 					if x-1 >= 0 && y-1 >= 0 && y+1 < height {
 						landCount := 0
-						if g.worldMap.tiles[LayerBase][y-1][x-1] != TileWater {
+						if c.tiles[LayerBase][y-1][x-1] != TileWater {
 							landCount++
 						}
-						if g.worldMap.tiles[LayerBase][y+1][x-1] != TileWater {
+						if c.tiles[LayerBase][y+1][x-1] != TileWater {
 							landCount++
 						}
 						leftIsSolid = landCount >= 1
@@ -372,10 +500,10 @@ func (g *Game) placeBridges(width, height int) {
 					rightIsSolid := false
 					if endX < width && y-1 >= 0 && y+1 < height {
 						landCount := 0
-						if g.worldMap.tiles[LayerBase][y-1][endX] != TileWater {
+						if c.tiles[LayerBase][y-1][endX] != TileWater {
 							landCount++
 						}
-						if g.worldMap.tiles[LayerBase][y+1][endX] != TileWater {
+						if c.tiles[LayerBase][y+1][endX] != TileWater {
 							landCount++
 						}
 						rightIsSolid = landCount >= 1
@@ -396,12 +524,12 @@ func (g *Game) placeBridges(width, height int) {
 	for x := 1; x < width-1; x++ {
 		for y := 1; y < height-2; y++ {
 			// Look for patterns like: land - water - water - land
-			if g.worldMap.tiles[LayerBase][y-1][x] != TileWater &&
-				g.worldMap.tiles[LayerBase][y][x] == TileWater {
+			if c.tiles[LayerBase][y-1][x] != TileWater &&
+				c.tiles[LayerBase][y][x] == TileWater {
 
 				// Find the end of the water stretch
 				endY := y
-				for endY < height-1 && g.worldMap.tiles[LayerBase][endY][x] == TileWater {
+				for endY < height-1 && c.tiles[LayerBase][endY][x] == TileWater {
 					endY++
 				}
 
@@ -409,7 +537,7 @@ func (g *Game) placeBridges(width, height int) {
 				// but also not too short (at least 2 tiles of water)
 				waterLength := endY - y
 				if endY < height &&
-					g.worldMap.tiles[LayerBase][endY][x] != TileWater &&
+					c.tiles[LayerBase][endY][x] != TileWater &&
 					waterLength >= 2 && waterLength <= 5 {
 
 					// Check that this isn't just following the coastline
@@ -419,10 +547,10 @@ func (g *Game) placeBridges(width, height int) {
 					topIsSolid := false
 					if y-1 >= 0 && x-1 >= 0 && x+1 < width {
 						landCount := 0
-						if g.worldMap.tiles[LayerBase][y-1][x-1] != TileWater {
+						if c.tiles[LayerBase][y-1][x-1] != TileWater {
 							landCount++
 						}
-						if g.worldMap.tiles[LayerBase][y-1][x+1] != TileWater {
+						if c.tiles[LayerBase][y-1][x+1] != TileWater {
 							landCount++
 						}
 						topIsSolid = landCount >= 1
@@ -432,10 +560,10 @@ func (g *Game) placeBridges(width, height int) {
 					bottomIsSolid := false
 					if endY < height && x-1 >= 0 && x+1 < width {
 						landCount := 0
-						if g.worldMap.tiles[LayerBase][endY][x-1] != TileWater {
+						if c.tiles[LayerBase][endY][x-1] != TileWater {
 							landCount++
 						}
-						if g.worldMap.tiles[LayerBase][endY][x+1] != TileWater {
+						if c.tiles[LayerBase][endY][x+1] != TileWater {
 							landCount++
 						}
 						bottomIsSolid = landCount >= 1
@@ -493,7 +621,7 @@ func (g *Game) placeBridges(width, height int) {
 	numBridges := min(len(scoredBridges), 3)
 
 	// Keep track of bridge locations to avoid building bridges too close together
-	bridgeMap := make(map[string]bool)
+	bridgeMap := newBitset(width * height)
 
 	// Place highest scoring bridges
 	bridgesPlaced := 0
@@ -506,7 +634,7 @@ func (g *Game) placeBridges(width, height int) {
 		if bridge.direction == 0 { // Horizontal bridge
 			// Find end of water
 			endX := bridge.x
-			for endX < width && g.worldMap.tiles[LayerBase][bridge.y][endX] == TileWater {
+			for endX < width && c.tiles[LayerBase][bridge.y][endX] == TileWater {
 				endX++
 			}
 
@@ -514,8 +642,7 @@ func (g *Game) placeBridges(width, height int) {
 			buffer := 2 // Minimum distance between bridges
 			for y := bridge.y - buffer; y <= bridge.y+buffer; y++ {
 				for x := bridge.x - buffer; x <= endX+buffer; x++ {
-					key := formatCoord(x, y)
-					if bridgeMap[key] {
+					if x >= 0 && x < width && y >= 0 && y < height && bridgeMap.get(y*width+x) {
 						tooClose = true
 						break
 					}
@@ -528,18 +655,17 @@ func (g *Game) placeBridges(width, height int) {
 			if !tooClose {
 				// Place bridge tiles over water
 				for x := bridge.x; x < endX; x++ {
-					g.worldMap.tiles[LayerOverlay][bridge.y][x] = TileBridge
-					key := formatCoord(x, bridge.y)
-					g.worldMap.bridgeTiles[key] = true
-					delete(g.worldMap.collisionMap, key)
-					bridgeMap[key] = true
+					c.tiles[LayerOverlay][bridge.y][x] = TileBridge
+					c.setBridge(x, bridge.y, true)
+					c.setCollision(x, bridge.y, false)
+					bridgeMap.set(bridge.y*width+x, true)
 				}
 				bridgesPlaced++
 			}
 		} else { // Vertical bridge
 			// Find end of water
 			endY := bridge.y
-			for endY < height && g.worldMap.tiles[LayerBase][endY][bridge.x] == TileWater {
+			for endY < height && c.tiles[LayerBase][endY][bridge.x] == TileWater {
 				endY++
 			}
 
@@ -547,8 +673,7 @@ func (g *Game) placeBridges(width, height int) {
 			buffer := 2 // Minimum distance between bridges
 			for y := bridge.y - buffer; y <= endY+buffer; y++ {
 				for x := bridge.x - buffer; x <= bridge.x+buffer; x++ {
-					key := formatCoord(x, y)
-					if bridgeMap[key] {
+					if x >= 0 && x < width && y >= 0 && y < height && bridgeMap.get(y*width+x) {
 						tooClose = true
 						break
 					}
@@ -561,11 +686,10 @@ func (g *Game) placeBridges(width, height int) {
 			if !tooClose {
 				// Place bridge tiles over water
 				for y := bridge.y; y < endY; y++ {
-					g.worldMap.tiles[LayerOverlay][y][bridge.x] = TileBridge
-					key := formatCoord(bridge.x, y)
-					g.worldMap.bridgeTiles[key] = true
-					delete(g.worldMap.collisionMap, key)
-					bridgeMap[key] = true
+					c.tiles[LayerOverlay][y][bridge.x] = TileBridge
+					c.setBridge(bridge.x, y, true)
+					c.setCollision(bridge.x, y, false)
+					bridgeMap.set(y*width+bridge.x, true)
 				}
 				bridgesPlaced++
 			}
@@ -589,26 +713,110 @@ func min(a, b int) int {
 	return b
 }
 
-// Helper function to format coordinates for the various tile maps
-func formatCoord(x, y int) string {
-	return string(rune(x)) + "," + string(rune(y))
+// Tile returns the tile at x, y on the given layer, generating its chunk if
+// needed. Overrides stand in for base layer tiles.
+func (m *Map) Tile(layer, x, y int) int {
+	if tile, ok := m.overrides[Point{x, y}]; ok && layer == LayerBase {
+		return tile
+	}
+	c, lx, ly := m.chunkAt(x, y)
+	if c == nil {
+		return TileGrass
+	}
+	return c.tiles[layer][ly][lx]
+}
+
+// BiomeAt returns the biome of the tile at x, y
+func (m *Map) BiomeAt(x, y int) int {
+	c, lx, ly := m.chunkAt(x, y)
+	if c == nil {
+		return BiomeGrassland
+	}
+	return int(c.biomes[ly][lx])
+}
+
+// climateBiome works out the biome of tile x, y from the climate noise
+func (m *Map) climateBiome(x, y int) int {
+	temperature := m.temperature.Fractal(float64(x)/biomeScale, float64(y)/biomeScale, 3)
+	moisture := m.moisture.Fractal(float64(x)/biomeScale, float64(y)/biomeScale, 3)
+	return biomeFromClimate(temperature, moisture)
+}
+
+// IsCollision reports whether the tile at x, y is impassable, either because
+// of the terrain, a flood or an object standing on it. Signs stand beside
+// their tile and buried items under it.
+func (m *Map) IsCollision(x, y int) bool {
+	c, lx, ly := m.chunkAt(x, y)
+	if c == nil || c.collisionMap.get(ly*chunkSize+lx) || m.overrides[Point{x, y}] == TileWater {
+		return true
+	}
+	object, ok := m.objects[Point{x, y}]
+	return ok && object.kind != ObjectSign && object.kind != ObjectHiddenItem
+}
+
+// IsGrass reports whether the tile at x, y is grass that can trigger encounters
+func (m *Map) IsGrass(x, y int) bool {
+	c, lx, ly := m.chunkAt(x, y)
+	return c != nil && c.grassTiles.get(ly*chunkSize+lx)
+}
+
+// IsBridge reports whether the tile at x, y is covered by a bridge
+func (m *Map) IsBridge(x, y int) bool {
+	c, lx, ly := m.chunkAt(x, y)
+	return c != nil && c.bridgeTiles.get(ly*chunkSize+lx)
 }
 
 // updateOverworld handles overworld state updates
 func (g *Game) updateOverworld() {
+	if g.poisonFlash > 0 {
+		g.poisonFlash--
+	}
+	g.updateRustle()
+	g.updateHUD()
+	g.updateFollowerReaction()
+	g.updateEmotes()
+
+	// Keep up with which directions are held, even mid-step
+	g.updateHeldDirections()
+
+	// Nothing moves while fading between maps
+	if g.transition.active {
+		g.updateTransition()
+		return
+	}
+
+	// An open dialogue box takes all input until it's closed
+	if g.dialogue.active {
+		g.updateDialogue()
+		return
+	}
+
+	// Platforms drift on, carrying the player if they're aboard
+	g.updatePlatforms()
+
 	// Handle movement based on the current state
 	switch g.player.movementState {
 	case MovementIdle:
+		// Buffered presses only carry straight on from the step they were
+		// made in, not past a battle or warp that ended it
+		g.player.buffered = DirectionNone
+
+		// Interact with whatever the player is facing
+		if g.input.IsActionJustPressed(ebiten.KeySpace) {
+			g.interact()
+			break
+		}
+
 		// Check for key presses for continuous movement
 		g.handlePlayerMovement()
 
-	case MovementMoving:
+	case MovementMoving, MovementCarried:
 		// Update visual position to smoothly move toward the target tile
 		targetX := float32(g.player.tileX * tileSize)
 		targetY := float32(g.player.tileY * tileSize)
 
 		// Calculate how fast to move
-		const movementSpeed = 4.0
+		movementSpeed := g.movementSpeed()
 
 		// Update visual position
 		if g.player.visualX < targetX {
@@ -635,24 +843,90 @@ func (g *Game) updateOverworld() {
 			}
 		}
 
-		// Animation frame count
-		g.player.frameCount++
+		// Remember direction taps made as the step finishes
+		remaining := max(abs32(targetX-g.player.visualX), abs32(targetY-g.player.visualY))
+		g.bufferDirection(remaining, movementSpeed)
+
+		g.updateFollower(movementSpeed)
 
 		// Check if movement is complete
 		if g.player.visualX == targetX && g.player.visualY == targetY {
 			g.player.movementState = MovementIdle
+			g.takeStep()
 
 			// Check for bridge tiles and adjust player layer
-			key := formatCoord(g.player.tileX, g.player.tileY)
-			if g.worldMap.bridgeTiles[key] {
+			if g.worldMap.IsBridge(g.player.tileX, g.player.tileY) {
 				g.player.currentLayer = LayerOverlay
 			} else {
 				g.player.currentLayer = LayerBase
 			}
 
-			// Check for wild creature encounters in grass when arriving at a new tile
-			if g.worldMap.grassTiles[key] && g.player.currentLayer == LayerBase && rand.Float32() < g.encounterRate {
-				g.startBattle()
+			// Doors move the player to another map and end the step there
+			if g.checkWarp() {
+				return
+			}
+
+			// Ice carries the player on until something stops them, and
+			// currents carry them on while they surf
+			g.updateSurfing()
+			g.updateBike()
+			if g.slide() || g.drift() {
+				return
+			}
+
+			// Entering a new biome changes the weather, and rain floods low
+			// paths
+			g.updateBiome()
+			g.updateFlooding()
+
+			// Poison wears the party down as they walk
+			if g.updatePoison() {
+				return
+			}
+
+			// Repels wear off after enough steps
+			if g.updateRepel() {
+				return
+			}
+
+			// Eggs carried far enough hatch
+			if g.checkHatch() {
+				return
+			}
+
+			// Time runs out in the safari zone
+			if g.updateSafariSteps() {
+				return
+			}
+
+			// The rival steps out at points along the story
+			if g.checkRivalAmbush() {
+				return
+			}
+
+			// Stepping into rustling grass always finds what's hiding there
+			if g.enterRustle() {
+				return
+			}
+
+			// Check for wild creature encounters when arriving at a new tile,
+			// at the rate for the kind of ground it is, as modified. Nothing
+			// attacks the player on a platform.
+			zone := g.worldMap.EncounterZone(g.player.tileX, g.player.tileY, g.player.currentLayer)
+			if g.worldMap.platformAt(g.player.tileX, g.player.tileY) != nil {
+				zone = ZoneNone
+			}
+			if zone != ZoneNone && rand.Float32() < g.encounterRate(zone) {
+				if g.roamerEncounter(g.player.tileX, g.player.tileY) {
+					g.startRoamerBattle()
+				} else {
+					wild := g.rollWildCreature(zone, g.player.tileX, g.player.tileY)
+					g.rollTraits(&wild)
+					g.startBattle(wild)
+				}
+			}
+			if g.gameState == StateOverworld {
+				g.spawnRustle()
 			}
 
 			// Continue movement if key is still held (for continuous movement)
@@ -660,139 +934,86 @@ func (g *Game) updateOverworld() {
 		}
 	}
 
+	// Walk or pedal in place while moving, and face the way the player last
+	// pressed
+	g.player.sprite.direction = g.player.direction
+	switch {
+	case g.player.riding && g.player.movementState != MovementIdle:
+		g.player.sprite.play("ride", g.ticks)
+	case g.player.movementState == MovementMoving:
+		g.player.sprite.play("walk", g.ticks)
+	default:
+		g.player.sprite.play("idle", g.ticks)
+	}
+
 	// Update camera position to follow player
 	g.updateCamera()
+
+	// Keep the chunks around the player generated
+	g.worldMap.streamChunks(g.player.tileX, g.player.tileY)
 }
 
 // drawOverworld draws the overworld map and player
 func (g *Game) drawOverworld(screen *ebiten.Image) {
+	// Interiors sit on a dark background rather than the sky
+	if g.worldMap.static {
+		screen.Fill(color.Black)
+	}
+
 	// Draw the base layer first
 	g.drawMapLayer(screen, LayerBase)
+	g.drawRustleMarker(screen)
 
 	// Draw the overlay layer (bridges, etc.)
 	g.drawMapLayer(screen, LayerOverlay)
-
-	// Draw the player at visual position (for smooth movement)
-	playerColor := color.RGBA{255, 0, 0, 255}
-	vector.DrawFilledRect(
-		screen,
-		g.player.visualX-g.camera.x,
-		g.player.visualY-g.camera.y,
-		tileSize,
-		tileSize,
-		playerColor,
-		true,
-	)
-
-	// Draw player direction indicator
-	indicatorSize := tileSize / 4
-
-	switch g.player.direction {
-	case DirectionUp: // Up
-		vector.DrawFilledRect(
-			screen,
-			g.player.visualX-g.camera.x+float32(tileSize/2-indicatorSize/2),
-			g.player.visualY-g.camera.y,
-			float32(indicatorSize),
-			float32(indicatorSize),
-			color.White,
-			true,
-		)
-	case DirectionDown: // Down
-		vector.DrawFilledRect(
-			screen,
-			g.player.visualX-g.camera.x+float32(tileSize/2-indicatorSize/2),
-			g.player.visualY-g.camera.y+float32(tileSize-indicatorSize),
-			float32(indicatorSize),
-			float32(indicatorSize),
-			color.White,
-			true,
-		)
-	case DirectionLeft: // Left
-		vector.DrawFilledRect(
-			screen,
-			g.player.visualX-g.camera.x,
-			g.player.visualY-g.camera.y+float32(tileSize/2-indicatorSize/2),
-			float32(indicatorSize),
-			float32(indicatorSize),
-			color.White,
-			true,
-		)
-	case DirectionRight: // Right
-		vector.DrawFilledRect(
-			screen,
-			g.player.visualX-g.camera.x+float32(tileSize-indicatorSize),
-			g.player.visualY-g.camera.y+float32(tileSize/2-indicatorSize/2),
-			float32(indicatorSize),
-			float32(indicatorSize),
-			color.White,
-			true,
-		)
+	g.drawPlatforms(screen)
+
+	// Draw the objects layer (item balls, etc.) and the garden
+	g.drawObjects(screen)
+	g.drawGarden(screen)
+
+	// Draw the lead creature trailing behind, then the player at their
+	// visual position (for smooth movement)
+	g.drawFollower(screen)
+	g.drawRival(screen)
+	g.drawSurfMount(screen)
+	g.drawBike(screen)
+	g.player.sprite.draw(screen, g.player.visualX-g.camera.x, g.player.visualY-g.camera.y, g.ticks)
+
+	// Draw weather on top of the world, but not indoors, and then any
+	// emotes over people's heads
+	if !g.worldMap.static {
+		g.drawWeather(screen)
 	}
-
-	// Debug info (optional)
-	// op := &text.DrawOptions{}
-	// op.GeoM.Translate(10, 10)
-	// op.ColorScale.ScaleWithColor(color.White)
-	// text.Draw(screen, fmt.Sprintf("Tile: %d,%d Layer: %d", g.player.tileX, g.player.tileY, g.player.currentLayer), g.fontFace, op)
+	g.drawEmotes(screen)
+
+	// Show the HUD, warn when the party needs healing, and show any repel
+	// counting down
+	g.drawHUD(screen)
+	g.drawAreaBanner(screen)
+	g.drawStatusIndicator(screen)
+	g.drawRepelCounter(screen)
+	g.drawChain(screen)
+	g.drawSafariCounter(screen)
+
+	// Draw any open dialogue box
+	g.drawDialogue(screen)
+
+	// Fade to black while going through a door
+	g.drawTransition(screen)
 }
 
-// drawMapLayer draws a specific layer of the map
-func (g *Game) drawMapLayer(screen *ebiten.Image, layer int) {
+// visibleTiles returns the range of tiles the camera can see, clamped to the
+// map, from the first column and row to just past the last
+func (g *Game) visibleTiles() (startX, startY, endX, endY int) {
 	// Calculate visible tile range based on camera position
-	startX := int(g.camera.x) / tileSize
-	startY := int(g.camera.y) / tileSize
-	endX := startX + screenWidth/tileSize + 2 // +2 to handle partially visible tiles
-	endY := startY + screenHeight/tileSize + 2
+	startX = int(g.camera.x) / tileSize
+	startY = int(g.camera.y) / tileSize
+	endX = startX + screenWidth/tileSize + 2 // +2 to handle partially visible tiles
+	endY = startY + screenHeight/tileSize + 2
 
 	// Clamp to map bounds
-	if startX < 0 {
-		startX = 0
-	}
-	if startY < 0 {
-		startY = 0
-	}
-	if endX > g.worldMap.width {
-		endX = g.worldMap.width
-	}
-	if endY > g.worldMap.height {
-		endY = g.worldMap.height
-	}
-
-	// Only draw visible tiles
-	for y := startY; y < endY; y++ {
-		for x := startX; x < endX; x++ {
-			tile := g.worldMap.tiles[layer][y][x]
-			if tile == 0 && layer > LayerBase {
-				continue // Skip empty tiles in overlay layers
-			}
-
-			var tileColor color.RGBA
-
-			switch tile {
-			case TileGrass:
-				tileColor = color.RGBA{34, 139, 34, 255} // Green
-			case TilePath:
-				tileColor = color.RGBA{210, 180, 140, 255} // Brown
-			case TileWater:
-				tileColor = color.RGBA{30, 144, 255, 255} // Blue
-			case TileBridge:
-				tileColor = color.RGBA{139, 69, 19, 255} // Dark brown
-			case TileMountain:
-				tileColor = color.RGBA{105, 105, 105, 255} // Dark grey
-			default:
-				continue // Skip drawing if empty
-			}
-
-			vector.DrawFilledRect(
-				screen,
-				float32(x*tileSize)-g.camera.x,
-				float32(y*tileSize)-g.camera.y,
-				tileSize,
-				tileSize,
-				tileColor,
-				true,
-			)
-		}
-	}
+	startX, startY = max(startX, 0), max(startY, 0)
+	endX, endY = min(endX, g.worldMap.width), min(endY, g.worldMap.height)
+	return startX, startY, endX, endY
 }
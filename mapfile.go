@@ -0,0 +1,92 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+)
+
+//go:embed maps/*.json
+var mapFiles embed.FS
+
+// mapFile is the on-disk format of a hand-made map
+type mapFile struct {
+	// One string per row; see mapFileTiles for the characters
+	Tiles   []string        `json:"tiles"`
+	Objects []mapFileObject `json:"objects"`
+}
+
+// mapFileObject is an object placed in a map file
+type mapFileObject struct {
+	Type string `json:"type"`
+	X    int    `json:"x"`
+	Y    int    `json:"y"`
+	Text string `json:"text,omitempty"`
+	Item string `json:"item,omitempty"`
+	// Conversation a person has, from dialogues/dialogues.json
+	Dialogue string `json:"dialogue,omitempty"`
+}
+
+// mapFileTiles maps the characters used in map files to tiles
+var mapFileTiles = map[rune]struct {
+	tile  int
+	solid bool
+}{
+	'.': {TileFloor, false},
+	'#': {TileInnerWall, true},
+	'C': {TileCounter, true},
+	'S': {TileSign, true},
+	'D': {TileDoor, false},
+	',': {TileCaveFloor, false},
+	'R': {TileRock, true},
+	'I': {TileIce, false},
+}
+
+// loadMapFile builds a static map from maps/<name>.json. Door tiles in the
+// file become exits back to wherever the player entered from.
+func loadMapFile(name, id string) (*Map, error) {
+	data, err := mapFiles.ReadFile("maps/" + name + ".json")
+	if err != nil {
+		return nil, err
+	}
+
+	var file mapFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	width := 0
+	for _, row := range file.Tiles {
+		width = max(width, len(row))
+	}
+	m := newStaticMap(id, width, len(file.Tiles))
+
+	for y, row := range file.Tiles {
+		for x, ch := range row {
+			t, ok := mapFileTiles[ch]
+			if !ok {
+				continue
+			}
+			m.stamp(x, y, t.tile, t.solid)
+
+			if t.tile == TileDoor {
+				m.warps[Point{x, y}] = Warp{back: true}
+				m.entrance = Point{x, y - 1}
+			}
+		}
+	}
+
+	for _, obj := range file.Objects {
+		switch obj.Type {
+		case "sign":
+			m.objects[Point{obj.X, obj.Y}] = &MapObject{kind: ObjectSign, text: obj.Text}
+		case "item":
+			m.objects[Point{obj.X, obj.Y}] = &MapObject{kind: ObjectItem, item: obj.Item}
+		case "npc":
+			m.objects[Point{obj.X, obj.Y}] = &MapObject{kind: ObjectPerson, dialogue: obj.Dialogue}
+		case "mailbox":
+			m.objects[Point{obj.X, obj.Y}] = &MapObject{kind: ObjectMailbox}
+		}
+	}
+
+	return m, nil
+}
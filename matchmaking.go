@@ -0,0 +1,251 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Matchmaking constants
+const (
+	// Bounds each request to the matchmaking server
+	matchmakingTimeout = 15 * time.Second
+	// How often the player's ticket is checked while queued
+	matchmakingPoll = 2 * time.Second
+)
+
+// Pairing statuses the matchmaking server reports for a ticket
+const (
+	// Still looking for an opponent
+	PairingWaiting = "waiting"
+	// Paired with an opponent; both have to accept before the match starts
+	PairingPaired = "paired"
+	// Both accepted: the match is waiting on the referee
+	PairingReady = "ready"
+	// The opponent declined or left, and the ticket is back in the queue
+	PairingDeclined = "declined"
+	// Not from the server: the queue couldn't be reached
+	PairingFailed = "failed"
+)
+
+// MatchmakingConfig is read from matchmaking.json in the save store. With it
+// in place, ranked battles are found through a matchmaking server over HTTP
+// rather than by typing a referee's address. Every request sends the token
+// as a bearer token.
+//
+//   - POST /tickets with {"trainer", "rating"} joins the queue and returns
+//     {"ticket"}.
+//   - GET /tickets/{ticket} returns the ticket's Pairing.
+//   - POST /tickets/{ticket}/accept accepts the opponent it was paired with.
+//   - DELETE /tickets/{ticket} leaves the queue, declining any pairing.
+//
+// Once both players accept, the pairing is ready and names a referee and a
+// match code. Both players join that referee with the code, and it pairs
+// them with each other.
+type MatchmakingConfig struct {
+	URL   string `json:"url"`
+	Token string `json:"token"`
+}
+
+// Pairing is what the matchmaking server says about a ticket
+type Pairing struct {
+	Status string `json:"status"`
+	// The opponent, once paired
+	Opponent string `json:"opponent,omitempty"`
+	Rating   int    `json:"rating,omitempty"`
+	// Where and under what code to play, once ready
+	Referee string `json:"referee,omitempty"`
+	Match   string `json:"match,omitempty"`
+	// Why the queue failed, for PairingFailed
+	Error string `json:"-"`
+}
+
+// Matchmaker queues the player for ranked battles on a matchmaking server
+type Matchmaker struct {
+	config MatchmakingConfig
+	client *http.Client
+}
+
+// newMatchmaker creates a matchmaker from the matchmaking settings, or
+// returns nil if matchmaking isn't set up
+func newMatchmaker() *Matchmaker {
+	encoded, err := saveStore.Read(matchmakingConfigFile)
+	if err != nil {
+		return nil
+	}
+	var config MatchmakingConfig
+	if err := json.Unmarshal(encoded, &config); err != nil || config.URL == "" {
+		return nil
+	}
+	return &Matchmaker{config: config, client: &http.Client{Timeout: matchmakingTimeout}}
+}
+
+// MatchQueue is the player's place in the matchmaking queue. A background
+// goroutine holds the ticket and reports each change in its pairing.
+type MatchQueue struct {
+	pairings chan Pairing
+	accept   chan struct{}
+	stop     chan struct{}
+	// The last pairing reported, and whether the player accepted it
+	pairing  Pairing
+	accepted bool
+}
+
+// queue joins the matchmaking queue with the player's name and rating
+func (m *Matchmaker) queue(trainer string, rating int) *MatchQueue {
+	q := &MatchQueue{
+		pairings: make(chan Pairing, 4),
+		accept:   make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+	}
+	go m.run(q, trainer, rating)
+	return q
+}
+
+// run holds a ticket in the queue until the match is ready, the queue
+// fails, or the player leaves, passing on acceptance as it goes
+func (m *Matchmaker) run(q *MatchQueue, trainer string, rating int) {
+	var ticket struct {
+		Ticket string `json:"ticket"`
+	}
+	err := m.request(http.MethodPost, "/tickets", map[string]any{"trainer": trainer, "rating": rating}, &ticket)
+	if err != nil {
+		q.report(Pairing{Status: PairingFailed, Error: err.Error()})
+		return
+	}
+	path := "/tickets/" + url.PathEscape(ticket.Ticket)
+
+	poll := time.NewTicker(matchmakingPoll)
+	defer poll.Stop()
+	var last Pairing
+	for {
+		select {
+		case <-q.stop:
+			m.request(http.MethodDelete, path, nil, nil)
+			return
+		case <-q.accept:
+			err = m.request(http.MethodPost, path+"/accept", nil, nil)
+		case <-poll.C:
+		}
+
+		var p Pairing
+		if err == nil {
+			err = m.request(http.MethodGet, path, nil, &p)
+		}
+		if err != nil {
+			m.request(http.MethodDelete, path, nil, nil)
+			q.report(Pairing{Status: PairingFailed, Error: err.Error()})
+			return
+		}
+		if p != last && !q.report(p) {
+			m.request(http.MethodDelete, path, nil, nil)
+			return
+		}
+		last = p
+		if p.Status == PairingReady {
+			return
+		}
+	}
+}
+
+// request sends a request to the matchmaking server, decoding the reply
+// into out if it's wanted
+func (m *Matchmaker) request(method, path string, body, out any) error {
+	var encoded []byte
+	if body != nil {
+		encoded, _ = json.Marshal(body)
+	}
+	req, err := http.NewRequest(method, m.config.URL+path, bytes.NewReader(encoded))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+m.config.Token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("server returned %s", resp.Status)
+	}
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	return nil
+}
+
+// report passes a pairing on to the game, reporting false if the player
+// left the queue first
+func (q *MatchQueue) report(p Pairing) bool {
+	select {
+	case q.pairings <- p:
+		return true
+	case <-q.stop:
+		return false
+	}
+}
+
+// leave takes the player out of the queue
+func (q *MatchQueue) leave() {
+	close(q.stop)
+}
+
+// joinQueue starts looking for a ranked battle through the matchmaking
+// server
+func (g *Game) joinQueue() {
+	t := &g.trade
+	t.ranked = true
+	t.queue = g.matchmaker.queue(g.playerName, g.ranked.rating())
+	t.stage = TradeQueued
+	t.status = "Joining the queue..."
+}
+
+// updateQueue waits on the trade screen for the matchmaking server to pair
+// the player with an opponent and for both to accept, then joins the referee
+// it names
+func (g *Game) updateQueue() {
+	t := &g.trade
+	q := t.queue
+	if g.input.IsActionJustPressed(ebiten.KeyEscape) {
+		q.leave()
+		t.queue, t.stage, t.status = nil, TradeMenu, "Left the queue."
+		return
+	}
+	if q.pairing.Status == PairingPaired && !q.accepted &&
+		(g.input.IsActionJustPressed(ebiten.KeySpace) || g.input.IsActionJustPressed(ebiten.KeyEnter)) {
+		q.accepted = true
+		q.accept <- struct{}{}
+		t.status = "Waiting for " + q.pairing.Opponent + " to accept..."
+	}
+
+	select {
+	case p := <-q.pairings:
+		q.pairing = p
+		switch p.Status {
+		case PairingWaiting:
+			t.status = "Looking for an opponent near your rating of " + strconv.Itoa(g.ranked.rating()) + "..."
+		case PairingDeclined:
+			q.accepted = false
+			t.status = "Your opponent backed out. Looking for another..."
+		case PairingPaired:
+			q.accepted = false
+			t.status = "Found " + p.Opponent + ", rated " + strconv.Itoa(p.Rating) + ". Space to accept, Esc to leave the queue."
+		case PairingReady:
+			t.queue, t.match, t.address = nil, p.Match, p.Referee
+			t.join()
+		case PairingFailed:
+			t.queue, t.stage, t.status = nil, TradeMenu, "Matchmaking failed: "+p.Error
+		}
+	default:
+	}
+}
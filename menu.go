@@ -3,68 +3,183 @@ package main
 import (
 	"image/color"
 	"log"
-	"os"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/text/v2"
 )
 
 // updateMainMenu handles main menu state updates
 func (g *Game) updateMainMenu() {
-	if inpututil.IsKeyJustPressed(ebiten.KeyUp) {
+	if g.input.IsActionJustPressed(ebiten.KeyUp) {
 		g.selectedOption = (g.selectedOption - 1 + len(g.menuOptions)) % len(g.menuOptions)
-	} else if inpututil.IsKeyJustPressed(ebiten.KeyDown) {
+	} else if g.input.IsActionJustPressed(ebiten.KeyDown) {
 		g.selectedOption = (g.selectedOption + 1) % len(g.menuOptions)
 	}
 
-	if inpututil.IsKeyJustPressed(ebiten.KeySpace) || inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
-		switch g.selectedOption {
-		case 0: // New Game
-			g.initGame()
+	clicked := g.mouseSelect(g.mainMenuRects(), &g.selectedOption)
+
+	if g.input.IsActionJustPressed(ebiten.KeySpace) || g.input.IsActionJustPressed(ebiten.KeyEnter) || clicked {
+		switch g.menuOptions[g.selectedOption] {
+		case "Continue", "Recover Save":
+			load := g.loadGame
+			if g.menuOptions[g.selectedOption] == "Recover Save" {
+				load = g.restoreEmergencySave
+			}
+			if err := load(); err != nil {
+				log.Println("Failed to load save:", err)
+				g.showToast("The save is damaged and can't be loaded.")
+				return
+			}
+			g.currentBiome = g.worldMap.BiomeAt(g.player.tileX, g.player.tileY)
+			g.weather = biomes[g.currentBiome].rollWeather()
 			g.gameState = StateOverworld
-		case 1: // Options - could be implemented later
-			// For now, just print to console
-			log.Println("Options selected (not implemented)")
-		case 2: // Exit
-			os.Exit(0)
-			// return errors.New("exit game")
+		case "New Game":
+			g.initGame()
+			g.openNameEntry(NamePlayer)
+		case "Options":
+			g.openOptions()
+		case "Exit":
+			g.requestQuit(false)
 		}
 	}
 }
 
+// mainMenuRects lays out the main menu options, with room for the selector arrow
+func (g *Game) mainMenuRects() []Rect {
+	// Keep clear of the sync status and controls hint at the bottom
+	top := min(screenHeight/2, screenHeight-2*g.lineSpacing()-10-len(g.menuOptions)*g.rowHeight())
+	width := g.selectorWidth() + g.widestText(g.menuOptions) + 10
+	return listRects(screenWidth/2-45, top, width, g.rowHeight(), len(g.menuOptions))
+}
+
 // drawMainMenu draws the main menu
 func (g *Game) drawMainMenu(screen *ebiten.Image) {
 	// Draw title
 	titleOp := &text.DrawOptions{}
 	titleOp.GeoM.Translate(float64(screenWidth/2-50), float64(screenHeight/4))
-	titleOp.ColorScale.ScaleWithColor(color.RGBA{255, 255, 255, 255})
+	titleOp.ColorScale.ScaleWithColor(g.uiText(color.RGBA{255, 255, 255, 255}))
 	text.Draw(screen, "CreatureGame", g.fontFace, titleOp)
 
 	// Draw menu options
+	rects := g.mainMenuRects()
 	for i, option := range g.menuOptions {
 		op := &text.DrawOptions{}
-		op.GeoM.Translate(float64(screenWidth/2-30), float64(screenHeight/2+i*20))
+		op.GeoM.Translate(float64(rects[i].x+g.selectorWidth()), float64(rects[i].y))
 
 		// Highlight selected option
 		if i == g.selectedOption {
-			op.ColorScale.ScaleWithColor(color.RGBA{255, 255, 0, 255}) // Yellow for selected
+			op.ColorScale.ScaleWithColor(g.uiText(color.RGBA{255, 255, 0, 255})) // Yellow for selected
 
 			// Draw selector arrow
 			selectorOp := &text.DrawOptions{}
-			selectorOp.GeoM.Translate(float64(screenWidth/2-45), float64(screenHeight/2+i*20))
-			selectorOp.ColorScale.ScaleWithColor(color.RGBA{255, 255, 0, 255})
+			selectorOp.GeoM.Translate(float64(rects[i].x), float64(rects[i].y))
+			selectorOp.ColorScale.ScaleWithColor(g.uiText(color.RGBA{255, 255, 0, 255}))
 			text.Draw(screen, ">", g.fontFace, selectorOp)
 		} else {
-			op.ColorScale.ScaleWithColor(color.RGBA{255, 255, 255, 255}) // White for unselected
+			op.ColorScale.ScaleWithColor(g.uiText(color.RGBA{255, 255, 255, 255})) // White for unselected
 		}
 
 		text.Draw(screen, option, g.fontFace, op)
 	}
 
 	// Draw instructions
-	instructionsOp := &text.DrawOptions{}
-	instructionsOp.GeoM.Translate(10, float64(screenHeight-25))
-	instructionsOp.ColorScale.ScaleWithColor(color.RGBA{200, 200, 200, 255})
-	text.Draw(screen, "Arrow keys to navigate, Space/Enter to select", g.fontFace, instructionsOp)
+	hintTop := g.drawHint(screen, "Arrow keys to navigate, Space/Enter to select")
+
+	// Show how the save sync is going, if it's set up
+	if g.sync != nil {
+		syncOp := &text.DrawOptions{}
+		syncOp.GeoM.Translate(20, float64(hintTop-g.rowHeight()))
+		syncOp.ColorScale.ScaleWithColor(g.uiText(color.RGBA{170, 200, 230, 255}))
+		text.Draw(screen, "Cloud: "+g.sync.status, g.fontFace, syncOp)
+	}
+}
+
+// updatePauseMenu handles the menu opened with Enter in the overworld
+func (g *Game) updatePauseMenu() {
+	if g.input.IsActionJustPressed(ebiten.KeyUp) {
+		g.selectedPause = (g.selectedPause - 1 + len(g.pauseOptions)) % len(g.pauseOptions)
+	} else if g.input.IsActionJustPressed(ebiten.KeyDown) {
+		g.selectedPause = (g.selectedPause + 1) % len(g.pauseOptions)
+	}
+
+	if g.input.IsActionJustPressed(ebiten.KeyEscape) {
+		g.gameState = StateOverworld
+		return
+	}
+
+	clicked := g.mouseSelect(g.pauseMenuRects(), &g.selectedPause)
+
+	if g.input.IsActionJustPressed(ebiten.KeySpace) || g.input.IsActionJustPressed(ebiten.KeyEnter) || clicked {
+		switch g.pauseOptions[g.selectedPause] {
+		case "Creatures":
+			g.gameState = StateCreatureMenu
+			g.menuSection = 0
+			g.selectedOption = 0
+			g.selectedCreature = 0
+		case "Dex":
+			g.openDex()
+		case "Bag":
+			g.openBag()
+		case "Craft":
+			g.openCraftMenu()
+		case "Map":
+			g.openTownMap()
+		case "Trade":
+			g.openTrade()
+		case "Options":
+			g.openOptions()
+		case "Save":
+			g.gameState = StateOverworld
+			if err := g.saveGame(); err != nil {
+				log.Println("Failed to save:", err)
+				g.showDialogue("The game could not be saved.")
+				return
+			}
+			g.showToast("The game was saved.")
+		case "Close":
+			g.gameState = StateOverworld
+		}
+	}
+}
+
+// pauseMenuRects lays out the pause menu options down the right side of the screen
+func (g *Game) pauseMenuRects() []Rect {
+	width := g.selectorWidth() + g.widestText(g.pauseOptions) + 8
+	return listRects(screenWidth-10-width, 20, width, g.rowHeight(), len(g.pauseOptions))
+}
+
+// drawPauseMenu draws the pause menu over the right side of the overworld
+func (g *Game) drawPauseMenu(screen *ebiten.Image) {
+	rects := g.pauseMenuRects()
+	menuX := float32(rects[0].x - 8)
+	g.drawPanel(screen, menuX, 10, float32(screenWidth-10)-menuX, float32(20+len(g.pauseOptions)*g.rowHeight()), color.RGBA{50, 50, 100, 240})
+
+	for i, option := range g.pauseOptions {
+		op := &text.DrawOptions{}
+		op.GeoM.Translate(float64(rects[i].x+g.selectorWidth()), float64(rects[i].y))
+
+		if i == g.selectedPause {
+			op.ColorScale.ScaleWithColor(g.uiText(color.RGBA{255, 255, 0, 255})) // Yellow for selected
+
+			// Draw selector arrow
+			selectorOp := &text.DrawOptions{}
+			selectorOp.GeoM.Translate(float64(rects[i].x), float64(rects[i].y))
+			selectorOp.ColorScale.ScaleWithColor(g.uiText(color.RGBA{255, 255, 0, 255}))
+			text.Draw(screen, ">", g.fontFace, selectorOp)
+		} else {
+			op.ColorScale.ScaleWithColor(g.uiText(color.White))
+		}
+
+		text.Draw(screen, option, g.fontFace, op)
+	}
+
+	// The in-game date, in its own panel underneath
+	date := g.calendar.dateText()
+	dateY := float32(10+20+len(g.pauseOptions)*g.rowHeight()) + 4
+	dateX := min32(menuX, float32(screenWidth-10-g.textWidth(date)-16))
+	g.drawPanel(screen, dateX, dateY, float32(screenWidth-10)-dateX, float32(g.lineHeight()+10), color.RGBA{50, 50, 100, 240})
+	dateOp := &text.DrawOptions{}
+	dateOp.GeoM.Translate(float64(dateX+8), float64(dateY+5))
+	dateOp.ColorScale.ScaleWithColor(g.uiText(color.White))
+	text.Draw(screen, date, g.fontFace, dateOp)
 }
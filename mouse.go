@@ -0,0 +1,54 @@
+package main
+
+// Rect is an area of the screen, in pixels
+type Rect struct {
+	x, y          int
+	width, height int
+}
+
+// contains reports whether a screen position is inside the rectangle
+func (r Rect) contains(x, y int) bool {
+	return x >= r.x && x < r.x+r.width && y >= r.y && y < r.y+r.height
+}
+
+// listRects lays out a column of count menu items, each a row of the given
+// height, with the first at x, y
+func listRects(x, y, width, rowHeight, count int) []Rect {
+	rects := make([]Rect, count)
+	for i := range rects {
+		rects[i] = Rect{x, y + i*rowHeight, width, rowHeight}
+	}
+	return rects
+}
+
+// Mouse tracks the pointer between frames
+type Mouse struct {
+	x, y int
+	// The pointer moved this frame, so hovering should take over the selection
+	moved bool
+}
+
+// updateMouse notes where the pointer is this frame
+func (g *Game) updateMouse() {
+	x, y := g.input.CursorPosition()
+	g.mouse.moved = x != g.mouse.x || y != g.mouse.y
+	g.mouse.x, g.mouse.y = x, y
+}
+
+// mouseSelect moves a menu's selection to the item under the pointer when the
+// pointer moves, so the keyboard keeps control while the mouse is still, and
+// reports whether an item was clicked
+func (g *Game) mouseSelect(rects []Rect, selected *int) bool {
+	clicked := g.input.IsClickJustPressed()
+	if !g.mouse.moved && !clicked {
+		return false
+	}
+
+	for i, r := range rects {
+		if r.contains(g.mouse.x, g.mouse.y) {
+			*selected = i
+			return clicked
+		}
+	}
+	return false
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"image/color"
+	"log"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+//go:embed animations/moves.json
+var animationFiles embed.FS
+
+// moveAnimationFile is the format of animations/moves.json: the animation
+// for each move by name, falling back to the one for its type, then to the
+// default
+type moveAnimationFile struct {
+	Default []vfxStep            `json:"default"`
+	Types   map[string][]vfxStep `json:"types"`
+	Moves   map[string][]vfxStep `json:"moves"`
+}
+
+// vfxStep is one step of a move animation, played after the one before it.
+// Kinds are:
+//   - projectile: shapes fly from the attacker to the defender
+//   - rain: shapes fall onto the defender from above
+//   - beam: a line reaches from the attacker to the defender
+//   - burst: a ring spreads out from the defender
+//   - flash: the screen flashes a color
+//   - shake: the defender, or the attacker, shakes
+type vfxStep struct {
+	Kind string `json:"kind"`
+	// circle, square or ring, for projectiles and rain
+	Shape  string   `json:"shape,omitempty"`
+	Color  [3]uint8 `json:"color"`
+	Size   float32  `json:"size,omitempty"`
+	Count  int      `json:"count,omitempty"`
+	Frames int      `json:"frames"`
+	// How far a shake moves, and "attacker" to shake the attacker instead
+	Strength float32 `json:"strength,omitempty"`
+	Target   string  `json:"target,omitempty"`
+	// Sound effect played as the step starts
+	Sound string `json:"sound,omitempty"`
+}
+
+// moveAnimations holds every move animation, loaded from the embedded file
+var moveAnimations = loadMoveAnimations()
+
+// loadMoveAnimations reads the move animations from animations/moves.json
+func loadMoveAnimations() moveAnimationFile {
+	var file moveAnimationFile
+	data, err := animationFiles.ReadFile("animations/moves.json")
+	if err == nil {
+		err = json.Unmarshal(data, &file)
+	}
+	if err != nil {
+		log.Fatalf("loading move animations: %v", err)
+	}
+	return file
+}
+
+// moveAnimation returns the animation steps for a move
+func moveAnimation(move Move) []vfxStep {
+	if steps, ok := moveAnimations.Moves[move.name]; ok {
+		return steps
+	}
+	if steps, ok := moveAnimations.Types[move.type1]; ok {
+		return steps
+	}
+	return moveAnimations.Default
+}
+
+// queueMoveAnimation queues the animation of a move used on the enemy, or on
+// the player if enemy is false; there's none when animations are off
+func (g *Game) queueMoveAnimation(move Move, enemy bool) {
+	if g.settings.Animation == AnimationOff {
+		return
+	}
+
+	from, to := g.battlerRect(!enemy), g.battlerRect(enemy)
+	fromX, fromY := float32(from.x+from.width/2), float32(from.y+from.height/2)
+	toX, toY := float32(to.x+to.width/2), float32(to.y+to.height/2)
+
+	for _, step := range moveAnimation(move) {
+		clr := color.RGBA{step.Color[0], step.Color[1], step.Color[2], 255}
+		count := max(step.Count, 1)
+		animation := BattleAnimation{frames: max(step.Frames, 1), sound: step.Sound, holdsBars: true}
+
+		switch step.Kind {
+		case "projectile":
+			animation.draw = func(screen *ebiten.Image, progress float32) {
+				// Several shots follow each other in
+				for i := range count {
+					t := progress*(1+float32(count-1)*0.25) - float32(i)*0.25
+					if t < 0 || t > 1 {
+						continue
+					}
+					drawVFXShape(screen, step.Shape, fromX+(toX-fromX)*t, fromY+(toY-fromY)*t, step.Size, clr)
+				}
+			}
+		case "rain":
+			animation.draw = func(screen *ebiten.Image, progress float32) {
+				for i := range count {
+					t := progress*(1+float32(count-1)*0.2) - float32(i)*0.2
+					if t < 0 || t > 1 {
+						continue
+					}
+					x := toX + float32((i*37)%40-20)
+					drawVFXShape(screen, step.Shape, x, toY-80+t*80, step.Size, clr)
+				}
+			}
+		case "beam":
+			animation.draw = func(screen *ebiten.Image, progress float32) {
+				t := min32(progress*2, 1)
+				vector.StrokeLine(screen, fromX, fromY, fromX+(toX-fromX)*t, fromY+(toY-fromY)*t, max(step.Size, 1), clr, true)
+			}
+		case "burst":
+			animation.draw = func(screen *ebiten.Image, progress float32) {
+				faded := clr
+				faded.A = uint8(255 * (1 - progress))
+				vector.StrokeCircle(screen, toX, toY, 4+progress*step.Size, 2, premultiply(faded), true)
+			}
+		case "flash":
+			animation.draw = func(screen *ebiten.Image, progress float32) {
+				if g.settings.ReduceFlashes {
+					return
+				}
+				faded := clr
+				faded.A = uint8(160 * (1 - progress))
+				vector.DrawFilledRect(screen, 0, 0, screenWidth, screenHeight, premultiply(faded), false)
+			}
+		case "shake":
+			animation.shake = step.Strength
+			animation.shakeEnemy = enemy != (step.Target == "attacker")
+			animation.draw = func(*ebiten.Image, float32) {}
+		default:
+			log.Printf("unknown move animation step %q", step.Kind)
+			continue
+		}
+
+		g.battle.animations = append(g.battle.animations, animation)
+	}
+}
+
+// drawVFXShape draws one shape of a projectile or rain step centered on x, y
+func drawVFXShape(screen *ebiten.Image, shape string, x, y, size float32, clr color.RGBA) {
+	switch shape {
+	case "square":
+		vector.DrawFilledRect(screen, x-size/2, y-size/2, size, size, clr, true)
+	case "ring":
+		vector.StrokeCircle(screen, x, y, size/2, 2, clr, true)
+	default:
+		vector.DrawFilledCircle(screen, x, y, size/2, clr, true)
+	}
+}
+
+// premultiply scales a color's channels by its alpha, as vector expects
+func premultiply(clr color.RGBA) color.RGBA {
+	a := uint16(clr.A)
+	return color.RGBA{uint8(uint16(clr.R) * a / 255), uint8(uint16(clr.G) * a / 255), uint8(uint16(clr.B) * a / 255), clr.A}
+}
+
+// shakeOffset returns how far sideways to draw the enemy, or the player's
+// creature, while the animation playing shakes it
+func (g *Game) shakeOffset(enemy bool) float32 {
+	if len(g.battle.animations) == 0 {
+		return 0
+	}
+	step := &g.battle.animations[0]
+	if step.shake == 0 || step.shakeEnemy != enemy {
+		return 0
+	}
+	progress := float64(step.frame) / float64(step.frames)
+	return step.shake * float32(math.Sin(float64(step.frame)*1.6)*(1-progress))
+}
@@ -0,0 +1,165 @@
+package main
+
+import "creaturegame-2/engine"
+
+// moveFromEngine returns a move the battle rules describe as the game has it
+func moveFromEngine(m engine.Move) Move {
+	return Move{name: m.Name, power: m.Power, accuracy: m.Accuracy, type1: m.Type, pp: m.PP, maxPP: m.MaxPP, effect: m.Effect, effectChance: m.Chance, kind: m.Kind}
+}
+
+// leaveField clears what one side's creature was in the middle of as it
+// leaves the field, freeing the other side's creature if it had bound it
+func (b *Battle) leaveField(mine bool) {
+	own, other := &b.playerVolatile, &b.enemyVolatile
+	if !mine {
+		own, other = other, own
+	}
+	*own = engine.Volatile{}
+	other.Bound, other.BoundBy = 0, ""
+}
+
+// chargeUp starts a two-turn move charging, or lets go of one charged last
+// turn, reporting the message to show and true if the move only charged
+func chargeUp(move Move, attacker *Creature, state *engine.Volatile) (string, bool) {
+	if state.Charging != nil {
+		state.Charging, state.Vanished = nil, false
+		return "", false
+	}
+	if !engine.TwoTurn(move.engineMove()) {
+		return "", false
+	}
+	charging := move.engineMove()
+	state.Charging, state.Vanished = &charging, move.kind == engine.KindVanish
+	return chargeText(move, attacker), true
+}
+
+// chargeText says a creature is charging a two-turn move
+func chargeText(move Move, attacker *Creature) string {
+	if move.kind == engine.KindVanish {
+		return " " + attacker.displayName() + " moved out of reach!"
+	}
+	return " " + attacker.displayName() + " is charging up!"
+}
+
+// missText says an attack missed a creature out of reach
+const missText = " The attack missed!"
+
+// afterHit takes a move's recoil, drains HP or binds the defender once the
+// move has hit, returning the messages saying so
+func afterHit(move Move, damage int, attacker, defender *Creature, target *engine.Volatile) string {
+	text := ""
+	if recoil := engine.Recoil(move.engineMove(), attacker.fighter(), damage); recoil > 0 {
+		attacker.hp -= recoil
+		text += recoilText(attacker)
+	}
+	if drain := engine.Drain(move.engineMove(), attacker.fighter(), damage); drain > 0 {
+		attacker.hp += drain
+		text += drainText(defender)
+	}
+	if move.kind == engine.KindBind && defender.hp > 0 && target.Bound == 0 {
+		target.Bound, target.BoundBy = engine.BindTurns(battleRand), move.name
+		text += boundText(defender, move.name)
+	}
+	return text
+}
+
+// recoilText says recoil hurt a creature
+func recoilText(attacker *Creature) string {
+	return " " + attacker.displayName() + " is hit with recoil!"
+}
+
+// drainText says a creature had its HP drained
+func drainText(defender *Creature) string {
+	return " " + defender.displayName() + " had its energy drained!"
+}
+
+// boundText says a creature was trapped by a binding move
+func boundText(defender *Creature, by string) string {
+	return " " + defender.displayName() + " was trapped by " + by + "!"
+}
+
+// hurtBound hurts a creature bound by the other side's, which happens each
+// time its binder acts, returning the message saying so
+func hurtBound(c *Creature, state *engine.Volatile) string {
+	damage := min(engine.BindDamage(c.fighter()), c.hp)
+	c.hp -= damage
+	by := state.BoundBy
+	if state.Bound--; state.Bound == 0 {
+		state.BoundBy = ""
+	}
+	return hurtText(c, by, state.Bound == 0)
+}
+
+// hurtText says a bound creature was hurt, and whether it got free
+func hurtText(c *Creature, by string, freed bool) string {
+	text := " " + c.displayName() + " is hurt by " + by + "!"
+	if freed {
+		text += " " + c.displayName() + " was freed from " + by + "!"
+	}
+	return text
+}
+
+// playerMove has the player's creature use a move on the enemy, returning
+// what happened to add to the battle text
+func (g *Game) playerMove(move Move) string {
+	b := &g.battle
+	attacker, defender := b.playerCreature, &b.enemyCreature
+	wasBound := b.enemyVolatile.Bound > 0
+	text, charged := chargeUp(move, attacker, &b.playerVolatile)
+	switch {
+	case charged:
+	case b.enemyVolatile.Vanished:
+		text = missText
+	default:
+		hit := calculateDamage(*attacker, *defender, move)
+		broke := false
+		if b.raid != nil {
+			hit.damage, broke = b.raid.absorb(hit.damage)
+		}
+		g.queueMoveAnimation(move, true)
+		g.queueHitPopup(hit, true)
+		defender.hp = max(defender.hp-hit.damage, 0)
+		g.publishHit(hit.damage, defender.maxHP)
+
+		if broke {
+			text += " A shield broke!"
+		}
+		if effect := applyMoveEffect(move, defender); effect != "" {
+			text += " " + effect
+		}
+		text += afterHit(move, hit.damage, attacker, defender, &b.enemyVolatile)
+	}
+	if wasBound && defender.hp > 0 {
+		text += hurtBound(defender, &b.enemyVolatile)
+	}
+	return text
+}
+
+// enemyMove has the enemy use a move on the player's creature, returning
+// what happened to add to the battle text
+func (g *Game) enemyMove(move Move) string {
+	b := &g.battle
+	attacker, defender := &b.enemyCreature, b.playerCreature
+	wasBound := b.playerVolatile.Bound > 0
+	text, charged := chargeUp(move, attacker, &b.enemyVolatile)
+	switch {
+	case charged:
+	case b.playerVolatile.Vanished:
+		text = missText
+	default:
+		hit := calculateDamage(*attacker, *defender, move)
+		g.queueMoveAnimation(move, false)
+		g.queueHitPopup(hit, false)
+		defender.hp = max(defender.hp-hit.damage, 0)
+		g.publishHit(hit.damage, defender.maxHP)
+
+		if effect := g.partyMoveEffect(move, defender); effect != "" {
+			text += " " + effect
+		}
+		text += afterHit(move, hit.damage, attacker, defender, &b.playerVolatile)
+	}
+	if wasBound && defender.hp > 0 {
+		text += hurtBound(defender, &b.playerVolatile)
+	}
+	return text
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"slices"
+	"strconv"
+
+	"creaturegame-2/engine"
+)
+
+// Move relearner constants
+const (
+	// What re-teaching a move costs, in money or tokens
+	relearnPrice  = 500
+	relearnTokens = 25
+)
+
+// TutorMove is a move only the move tutor teaches, to creatures of the
+// types that can learn it, for money or tokens
+type TutorMove struct {
+	move Move
+	// Types of creature that can learn it; any creature can if there are none
+	types  []string
+	price  int
+	tokens int
+}
+
+// tutorMoves are the moves the move tutor teaches
+var tutorMoves = []TutorMove{
+	{Move{name: "Body Slam", power: 85, accuracy: 100, type1: "Normal", maxPP: 15}, nil, 2000, 100},
+	{Move{name: "Double-Edge", power: 120, accuracy: 100, type1: "Normal", maxPP: 15, kind: engine.KindRecoil}, nil, 4000, 200},
+	{Move{name: "Fire Punch", power: 75, accuracy: 100, type1: "Fire", maxPP: 15}, []string{"Fire", "Normal", "Rock"}, 3000, 150},
+	{Move{name: "Ice Punch", power: 75, accuracy: 100, type1: "Ice", maxPP: 15}, []string{"Ice", "Normal", "Water"}, 3000, 150},
+	{Move{name: "Thunder Punch", power: 75, accuracy: 100, type1: "Electric", maxPP: 15}, []string{"Electric", "Normal", "Rock"}, 3000, 150},
+	{Move{name: "Sludge Bomb", power: 90, accuracy: 100, type1: "Poison", maxPP: 10, effect: EffectPoison, effectChance: 30}, []string{"Poison", "Grass"}, 4000, 200},
+	{Move{name: "Hydro Pump", power: 110, accuracy: 80, type1: "Water", maxPP: 5}, []string{"Water", "Ice"}, 5000, 250},
+}
+
+// findTutorMove looks up a move the move tutor teaches by name
+func findTutorMove(name string) (Move, bool) {
+	for _, t := range tutorMoves {
+		if t.move.name == name {
+			return t.move, true
+		}
+	}
+	return Move{}, false
+}
+
+// canLearn reports whether a creature is of a type that can learn a tutor
+// move
+func (t TutorMove) canLearn(c *Creature) bool {
+	return len(t.types) == 0 || slices.Contains(t.types, c.type1) || c.type2 != "" && slices.Contains(t.types, c.type2)
+}
+
+// addMoveRelearner puts someone who re-teaches forgotten moves in the
+// corner of a heal center
+func (m *Map) addMoveRelearner() {
+	m.objects[Point{m.width - 2, m.height - 2}] = &MapObject{kind: ObjectMoveRelearner}
+}
+
+// talkToMoveTutor offers the tutor's moves, then teaches the one picked to
+// a creature in the party that can learn it
+func (g *Game) talkToMoveTutor() {
+	options := []string{}
+	for _, t := range tutorMoves {
+		options = append(options, t.move.name)
+	}
+	options = append(options, "Nothing")
+	g.showChoice("I teach moves no creature picks up by itself. Which one would you like?", options, func(i int) {
+		if i >= len(tutorMoves) {
+			return
+		}
+		t := tutorMoves[i]
+		g.choosePartyMember("Who should learn "+t.move.name+"?", func(c *Creature) {
+			switch {
+			case !t.canLearn(c):
+				g.showDialogue("Sorry, " + c.displayName() + " can't learn " + t.move.name + ".")
+			case c.knowsMove(t.move.name):
+				g.showDialogue(c.displayName() + " already knows " + t.move.name + "!")
+			default:
+				g.chargeFee(t.move.name, t.price, t.tokens, func(pay func()) {
+					g.teachMove(c, t.move, pay)
+				})
+			}
+		})
+	})
+}
+
+// relearnableMoves returns the moves a creature could have learned by its
+// level that it doesn't know now
+func (c *Creature) relearnableMoves() []Move {
+	species := findSpecies(c.name)
+	moves := slices.Clone(species.withForm(c.form).moves)
+	for _, learned := range species.learnset {
+		if learned.level <= c.level {
+			moves = append(moves, learned.move)
+		}
+	}
+	var relearnable []Move
+	for _, move := range moves {
+		if !c.knowsMove(move.name) && !slices.ContainsFunc(relearnable, func(m Move) bool { return m.name == move.name }) {
+			relearnable = append(relearnable, move)
+		}
+	}
+	return relearnable
+}
+
+// talkToMoveRelearner re-teaches a creature in the party a move it could
+// have learned by now but doesn't know
+func (g *Game) talkToMoveRelearner() {
+	g.choosePartyMember("I can remind a creature of a move it's forgotten. Which one?", func(c *Creature) {
+		moves := c.relearnableMoves()
+		if len(moves) == 0 {
+			g.showDialogue("There's no move " + c.displayName() + " could be reminded of.")
+			return
+		}
+		options := []string{}
+		for _, move := range moves {
+			options = append(options, move.name)
+		}
+		options = append(options, "Back")
+		g.showChoice("Which move should "+c.displayName()+" remember?", options, func(i int) {
+			if i >= len(moves) {
+				return
+			}
+			g.chargeFee(moves[i].name, relearnPrice, relearnTokens, func(pay func()) {
+				g.teachMove(c, moves[i], pay)
+			})
+		})
+	})
+}
+
+// choosePartyMember asks which creature in the party to teach or rename,
+// running choose with the one picked. Eggs can't be picked.
+func (g *Game) choosePartyMember(message string, choose func(*Creature)) {
+	options := []string{}
+	for _, c := range g.creatures {
+		options = append(options, c.displayName()+" Lv."+strconv.Itoa(c.level))
+	}
+	options = append(options, "Back")
+	g.showChoice(message, options, func(i int) {
+		if i >= len(g.creatures) {
+			return
+		}
+		if c := &g.creatures[i]; c.egg {
+			g.showDialogue("That's an egg! Come back once it's hatched.")
+		} else {
+			choose(c)
+		}
+	})
+}
+
+// chargeFee asks whether to pay for a move in money or tokens, running
+// teach with what takes the fee once the move's been taught
+func (g *Game) chargeFee(name string, price, tokens int, teach func(pay func())) {
+	money, tokenCost := "$"+strconv.Itoa(price), strconv.Itoa(tokens)+" tokens"
+	g.showChoice("Teaching "+name+" is "+money+", or "+tokenCost+" from the game corner.",
+		[]string{"Pay " + money, "Pay " + tokenCost, "Never mind"}, func(i int) {
+			switch {
+			case i == 0 && g.money < price:
+				g.showDialogue("You can't afford " + money + ".")
+			case i == 0:
+				teach(func() { g.money -= price })
+			case i == 1 && g.tokens < tokens:
+				g.showDialogue("You don't have " + tokenCost + ".")
+			case i == 1:
+				teach(func() { g.tokens -= tokens })
+			}
+		})
+}
+
+// teachMove teaches a creature a move, asking which move to forget for it
+// if it already knows four. pay runs only if the move is learned.
+func (g *Game) teachMove(c *Creature, move Move, pay func()) {
+	move.pp = move.maxPP
+	if len(c.moves) < maxMoves {
+		pay()
+		c.moves = append(c.moves, move)
+		g.audio.playSound("fanfare")
+		g.showDialogue(c.displayName() + " learned " + move.name + "!")
+		return
+	}
+	options := []string{}
+	for _, known := range c.moves {
+		options = append(options, known.name)
+	}
+	options = append(options, "Give up")
+	g.showChoice(c.displayName()+" already knows four moves. Which should it forget to learn "+move.name+"?", options, func(i int) {
+		if i >= len(c.moves) {
+			g.showDialogue(c.displayName() + " didn't learn " + move.name + ".")
+			return
+		}
+		pay()
+		forgotten := c.moves[i].name
+		c.moves[i] = move
+		g.audio.playSound("fanfare")
+		g.showDialogue("1, 2 and... Poof! " + c.displayName() + " forgot " + forgotten + " and learned " + move.name + "!")
+	})
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"image/color"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// maxNameLength is the most characters a name can have
+const maxNameLength = 8
+
+// Names given to the player and rival if left blank
+const (
+	defaultPlayerName = "Player"
+	defaultRivalName  = "Rival"
+)
+
+// Name entry field constants: whose name is being entered
+const (
+	NamePlayer = iota
+	NameRival
+	NameCreature
+)
+
+// nameKeys is the on-screen keyboard, row by row. DEL rubs out the last
+// character and OK accepts the name.
+var nameKeys = [][]string{
+	{"A", "B", "C", "D", "E", "F", "G", "H", "I", "J"},
+	{"K", "L", "M", "N", "O", "P", "Q", "R", "S", "T"},
+	{"U", "V", "W", "X", "Y", "Z", " ", "-", ".", "'"},
+	{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"},
+	{"k", "l", "m", "n", "o", "p", "q", "r", "s", "t"},
+	{"u", "v", "w", "x", "y", "z"},
+	{"DEL", "OK"},
+}
+
+// NameEntry is the screen for typing in the player's or rival's name, or a
+// nickname for a creature in the party, on the on-screen keyboard
+type NameEntry struct {
+	field int
+	name  string
+	// Party creature being nicknamed
+	creature *Creature
+	// Key highlighted, by row and column
+	row, column int
+	// Why the name typed can't be used
+	message string
+}
+
+// openNameEntry switches to the name entry screen for a field
+func (g *Game) openNameEntry(field int) {
+	g.nameEntry = NameEntry{field: field}
+	g.gameState = StateNameEntry
+}
+
+// openNicknameEntry switches to the name entry screen to nickname a creature
+// in the party, starting from the nickname it has
+func (g *Game) openNicknameEntry(c *Creature) {
+	g.openNameEntry(NameCreature)
+	g.nameEntry.creature = c
+	g.nameEntry.name = c.nickname
+}
+
+// nameKeyRects lays out the on-screen keyboard, one rect per key in row order
+func (g *Game) nameKeyRects() []Rect {
+	cell := g.textWidth("W") + 14
+	top := 80
+	rects := []Rect{}
+	for row, keys := range nameKeys {
+		x := (screenWidth - len(nameKeys[0])*cell) / 2
+		for _, key := range keys {
+			width := max(cell, g.textWidth(key)+14)
+			rects = append(rects, Rect{x, top + row*g.rowHeight(), width, g.rowHeight()})
+			x += width
+		}
+	}
+	return rects
+}
+
+// updateNameEntry moves around the keyboard and types the highlighted key.
+// ESC rubs out a character and Enter accepts the name.
+func (g *Game) updateNameEntry() {
+	n := &g.nameEntry
+	if g.input.IsActionJustPressed(ebiten.KeyUp) {
+		n.row = (n.row - 1 + len(nameKeys)) % len(nameKeys)
+	} else if g.input.IsActionJustPressed(ebiten.KeyDown) {
+		n.row = (n.row + 1) % len(nameKeys)
+	}
+	n.column = min(n.column, len(nameKeys[n.row])-1)
+	if g.input.IsActionJustPressed(ebiten.KeyLeft) {
+		n.column = (n.column - 1 + len(nameKeys[n.row])) % len(nameKeys[n.row])
+	} else if g.input.IsActionJustPressed(ebiten.KeyRight) {
+		n.column = (n.column + 1) % len(nameKeys[n.row])
+	}
+
+	if g.input.IsActionJustPressed(ebiten.KeyEnter) {
+		g.acceptName()
+		return
+	}
+	if g.input.IsActionJustPressed(ebiten.KeyEscape) {
+		g.pressNameKey("DEL")
+		return
+	}
+
+	// Find the highlighted key among the rects, and pick up a click on any
+	index := 0
+	for row := range n.row {
+		index += len(nameKeys[row])
+	}
+	index += n.column
+	clicked := g.mouseSelect(g.nameKeyRects(), &index)
+	for row, keys := range nameKeys {
+		if index < len(keys) {
+			n.row, n.column = row, index
+			break
+		}
+		index -= len(keys)
+	}
+
+	if g.input.IsActionJustPressed(ebiten.KeySpace) || clicked {
+		g.pressNameKey(nameKeys[n.row][n.column])
+	}
+}
+
+// pressNameKey types a key of the on-screen keyboard into the name
+func (g *Game) pressNameKey(key string) {
+	n := &g.nameEntry
+	n.message = ""
+	switch key {
+	case "DEL":
+		if n.name != "" {
+			n.name = n.name[:len(n.name)-1]
+		}
+	case "OK":
+		g.acceptName()
+	default:
+		if len(n.name) < maxNameLength {
+			n.name += key
+		}
+	}
+}
+
+// acceptName stores the entered name, falling back to the default for a
+// blank one, and moves on from the player's name to the rival's, then to
+// picking a starter. A name the filter turns down stays to be changed.
+func (g *Game) acceptName() {
+	name := strings.TrimSpace(g.nameEntry.name)
+	if problem := g.nameProblem(name); problem != "" {
+		g.nameEntry.message = problem
+		g.audio.playSound("bump")
+		return
+	}
+	switch g.nameEntry.field {
+	case NamePlayer:
+		if name == "" {
+			name = defaultPlayerName
+		}
+		g.playerName = name
+		g.openNameEntry(NameRival)
+	case NameRival:
+		if name == "" {
+			name = defaultRivalName
+		}
+		g.rivalName = name
+		g.openStarterScene()
+	case NameCreature:
+		g.gameState = StateOverworld
+		g.renameCreature(g.nameEntry.creature, name)
+	}
+}
+
+// fillNames puts the player's and rival's names in place of the {player}
+// and {rival} placeholders in a message
+func (g *Game) fillNames(message string) string {
+	return strings.NewReplacer("{player}", g.playerName, "{rival}", g.rivalName).Replace(message)
+}
+
+// drawNameEntry draws the name typed so far above the on-screen keyboard
+func (g *Game) drawNameEntry(screen *ebiten.Image) {
+	n := &g.nameEntry
+	g.drawPanel(screen, 10, 10, float32(screenWidth-20), float32(screenHeight-20), color.RGBA{50, 50, 100, 240})
+
+	drawText := func(x, y int, line string, clr color.Color) {
+		op := &text.DrawOptions{}
+		op.GeoM.Translate(float64(x), float64(y))
+		op.ColorScale.ScaleWithColor(g.uiText(clr))
+		text.Draw(screen, line, g.fontFace, op)
+	}
+
+	title := "What is your name?"
+	switch n.field {
+	case NameRival:
+		title = "What is your rival's name?"
+	case NameCreature:
+		title = "What should " + n.creature.name + " be called?"
+	}
+	drawText(20, 30, title, color.White)
+
+	// The name, with a blank underlined for each character left
+	slot := g.textWidth("W") + 2
+	x := (screenWidth - maxNameLength*slot) / 2
+	for i := range maxNameLength {
+		sx := x + i*slot
+		vector.DrawFilledRect(screen, float32(sx), float32(50+g.lineHeight()+2), float32(slot-2), 1, color.White, false)
+		if i < len(n.name) {
+			drawText(sx, 50, n.name[i:i+1], color.White)
+		}
+	}
+
+	rects := g.nameKeyRects()
+	i := 0
+	for row, keys := range nameKeys {
+		for column, key := range keys {
+			r := rects[i]
+			i++
+			clr := color.RGBA{255, 255, 255, 255}
+			if row == n.row && column == n.column {
+				clr = color.RGBA{255, 255, 0, 255}
+				vector.StrokeRect(screen, float32(r.x+2), float32(r.y), float32(r.width-4), float32(r.height), 1, clr, false)
+			}
+			drawText(r.x+(r.width-g.textWidth(key))/2, r.y+(r.height-g.lineHeight())/2, key, clr)
+		}
+	}
+
+	hintTop := g.drawHint(screen, "Space to type, ESC to delete, Enter to finish")
+	if n.message != "" {
+		drawText(20, hintTop-g.lineSpacing(), n.message, color.RGBA{255, 120, 120, 255})
+	}
+}
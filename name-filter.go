@@ -0,0 +1,101 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+//go:embed names/filters.json
+var nameFilterFiles embed.FS
+
+// defaultLocale is the name filter used until the player picks another, and
+// in place of one that isn't in the file
+const defaultLocale = "en"
+
+// NameFilter is what a name can be in a locale, as configured in
+// names/filters.json: the characters it can use, and words it can't
+// contain. Blocked words are caught anywhere in a name, even split up by
+// spaces or punctuation; short words that turn up inside harmless ones are
+// listed as Words, and only caught on their own.
+type NameFilter struct {
+	Name       string   `json:"name"`
+	Characters string   `json:"characters"`
+	Blocked    []string `json:"blocked"`
+	Words      []string `json:"words"`
+}
+
+// nameFilters holds the name filter for each locale, loaded from the
+// embedded file
+var nameFilters = loadNameFilters()
+
+// loadNameFilters reads the name filters from names/filters.json
+func loadNameFilters() map[string]NameFilter {
+	var filters map[string]NameFilter
+	data, err := nameFilterFiles.ReadFile("names/filters.json")
+	if err == nil {
+		err = json.Unmarshal(data, &filters)
+	}
+	if err == nil && filters[defaultLocale].Characters == "" {
+		err = fmt.Errorf("no %s filter", defaultLocale)
+	}
+	if err != nil {
+		log.Fatalf("loading name filters: %v", err)
+	}
+	return filters
+}
+
+// locales returns the locales with a name filter, in order
+func locales() []string {
+	var list []string
+	for locale := range nameFilters {
+		list = append(list, locale)
+	}
+	slices.Sort(list)
+	return list
+}
+
+// nameFilter returns the name filter for the player's locale
+func (g *Game) nameFilter() NameFilter {
+	if filter, ok := nameFilters[g.settings.Locale]; ok {
+		return filter
+	}
+	return nameFilters[defaultLocale]
+}
+
+// nameProblem returns why a name can't be used, or nothing if it can
+func (g *Game) nameProblem(name string) string {
+	filter := g.nameFilter()
+	for _, r := range name {
+		if !strings.ContainsRune(filter.Characters, r) {
+			return "Names can't use " + string(r) + "."
+		}
+	}
+
+	lower := strings.ToLower(name)
+	words := strings.FieldsFunc(lower, func(r rune) bool { return !unicode.IsLetter(r) })
+	joined := strings.Join(words, "")
+	for _, blocked := range filter.Blocked {
+		if strings.Contains(joined, blocked) {
+			return "That name isn't allowed."
+		}
+	}
+	for _, word := range filter.Words {
+		if slices.Contains(words, word) || joined == word {
+			return "That name isn't allowed."
+		}
+	}
+	return ""
+}
+
+// filterNickname drops a nickname that wouldn't be allowed here from a
+// creature received from someone else, so it goes by its species name
+func (g *Game) filterNickname(c *Creature) {
+	if c.nickname != "" && g.nameProblem(c.nickname) != "" {
+		c.nickname = ""
+	}
+}
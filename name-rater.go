@@ -0,0 +1,40 @@
+package main
+
+// addNameRater puts the name rater at the back of a house
+func (m *Map) addNameRater() {
+	m.objects[Point{m.width/2 + 1, 1}] = &MapObject{kind: ObjectNameRater}
+}
+
+// talkToNameRater rates the name of a creature in the party, and gives it a
+// new one if the player wants. Only the trainer who caught a creature can
+// rename it.
+func (g *Game) talkToNameRater() {
+	g.choosePartyMember("I'm the name rater! Whose name shall I rate?", func(c *Creature) {
+		if c.trainer != g.playerName {
+			g.showDialogue(c.displayName() + " was named by " + c.trainer + ". A name from its first trainer is best left as it is.")
+			return
+		}
+		rating := "Hmm, " + c.displayName() + "... A fine name, but it could be finer. Shall I give it a new one?"
+		if c.nickname == "" {
+			rating = "Hmm, plain " + c.name + "... It deserves a name of its own. Shall I give it one?"
+		}
+		g.showPrompt(rating, func() { g.openNicknameEntry(c) })
+	})
+}
+
+// renameCreature gives a creature a nickname typed in at the name rater, or
+// takes its nickname away if it's left blank
+func (g *Game) renameCreature(c *Creature, name string) {
+	if name == "" || name == c.name {
+		c.nickname = ""
+		g.showDialogue("Back to plain " + c.name + ". Simple, but it suits it.")
+		return
+	}
+	if name == c.nickname {
+		g.showDialogue(c.nickname + " it stays! Perhaps it was already the best name.")
+		return
+	}
+	c.nickname = name
+	g.audio.playSound("heal")
+	g.showDialogue("From now on it shall be known as " + name + "! A far better name than before!")
+}
@@ -0,0 +1,85 @@
+package main
+
+// statNames names the stats, for describing natures
+var statNames = [StatCount]string{"HP", "Attack", "Defense", "Speed"}
+
+// Nature is a creature's temperament, which raises one of its stats by a
+// tenth and lowers another by as much. A nature that raises and lowers the
+// same stat does neither.
+type Nature struct {
+	name     string
+	up, down int
+}
+
+// natures are every nature a creature can have, the plain one first. HP is
+// never changed by a nature.
+var natures = []Nature{
+	{"Hardy", StatHP, StatHP},
+	{"Lonely", StatAttack, StatDefense},
+	{"Brave", StatAttack, StatSpeed},
+	{"Bold", StatDefense, StatAttack},
+	{"Relaxed", StatDefense, StatSpeed},
+	{"Timid", StatSpeed, StatAttack},
+	{"Hasty", StatSpeed, StatDefense},
+}
+
+// scale raises or lowers a stat by the nature
+func (n Nature) scale(stat, value int) int {
+	switch {
+	case n.up == n.down:
+		return value
+	case stat == n.up:
+		return value * 11 / 10
+	case stat == n.down:
+		return value * 9 / 10
+	}
+	return value
+}
+
+// text describes a nature and what it does
+func (n Nature) text() string {
+	if n.up == n.down {
+		return n.name
+	}
+	return n.name + " (+" + statNames[n.up] + " -" + statNames[n.down] + ")"
+}
+
+// goodNature returns the nature that suits a species best: raising its
+// strongest stat and lowering its weakest
+func goodNature(species *Species) int {
+	stats := [StatCount]int{StatAttack: species.attack, StatDefense: species.defense, StatSpeed: species.speed}
+	best, worst := StatAttack, StatAttack
+	for stat := StatDefense; stat < StatCount; stat++ {
+		if stats[stat] > stats[best] {
+			best = stat
+		}
+		if stats[stat] < stats[worst] {
+			worst = stat
+		}
+	}
+	for i, n := range natures {
+		if n.up == best && n.down == worst {
+			return i
+		}
+	}
+	return 0
+}
+
+// statAt returns what one of a creature's stats is at its level: the
+// species' base stat scaled up, plus its individual value, then raised or
+// lowered by its nature
+func (c *Creature) statAt(stat, base int) int {
+	return natures[c.nature].scale(stat, scaleStat(base, c.level)+ivBonus(c.ivs[stat], c.level))
+}
+
+// applyTraits sets a newly made creature's stats from its individual values
+// and nature
+func (c *Creature) applyTraits() {
+	species := findSpecies(c.name).withForm(c.form)
+	damage := c.maxHP - c.hp
+	c.maxHP = c.statAt(StatHP, species.hp)
+	c.hp = max(c.maxHP-damage, 1)
+	c.attack = c.statAt(StatAttack, species.attack)
+	c.defense = c.statAt(StatDefense, species.defense)
+	c.speed = c.statAt(StatSpeed, species.speed)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Noise is a seeded 2D Perlin gradient noise source
+type Noise struct {
+	perm [512]int
+}
+
+// newNoise creates a noise source with a permutation table shuffled by seed
+func newNoise(seed int64) *Noise {
+	n := &Noise{}
+	p := rand.New(rand.NewSource(seed)).Perm(256)
+	for i := range 512 {
+		n.perm[i] = p[i&255]
+	}
+	return n
+}
+
+// At returns the noise value at x, y in roughly the range -1..1
+func (n *Noise) At(x, y float64) float64 {
+	// Find the unit grid cell containing the point
+	x0, y0 := math.Floor(x), math.Floor(y)
+	xi, yi := int(x0)&255, int(y0)&255
+	xf, yf := x-x0, y-y0
+
+	// Smooth the interpolation weights
+	u, v := fade(xf), fade(yf)
+
+	// Hash the four cell corners
+	aa := n.perm[n.perm[xi]+yi]
+	ab := n.perm[n.perm[xi]+yi+1]
+	ba := n.perm[n.perm[xi+1]+yi]
+	bb := n.perm[n.perm[xi+1]+yi+1]
+
+	// Blend the corner gradients
+	top := lerp(grad(aa, xf, yf), grad(ba, xf-1, yf), u)
+	bottom := lerp(grad(ab, xf, yf-1), grad(bb, xf-1, yf-1), u)
+	return lerp(top, bottom, v)
+}
+
+// Fractal sums several octaves of noise for more natural detail, keeping the
+// result in roughly the range -1..1
+func (n *Noise) Fractal(x, y float64, octaves int) float64 {
+	total, amplitude, frequency, norm := 0.0, 1.0, 1.0, 0.0
+	for range octaves {
+		total += n.At(x*frequency, y*frequency) * amplitude
+		norm += amplitude
+		amplitude *= 0.5
+		frequency *= 2
+	}
+	return total / norm
+}
+
+// fade is Perlin's smootherstep curve
+func fade(t float64) float64 {
+	return t * t * t * (t*(t*6-15) + 10)
+}
+
+// lerp linearly interpolates between a and b
+func lerp(a, b, t float64) float64 {
+	return a + t*(b-a)
+}
+
+// grad picks one of eight gradient directions from the hash and dots it with x, y
+func grad(hash int, x, y float64) float64 {
+	switch hash & 7 {
+	case 0:
+		return x + y
+	case 1:
+		return -x + y
+	case 2:
+		return x - y
+	case 3:
+		return -x - y
+	case 4:
+		return x
+	case 5:
+		return -x
+	case 6:
+		return y
+	default:
+		return -y
+	}
+}
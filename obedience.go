@@ -0,0 +1,59 @@
+package main
+
+// Obedience constants
+const (
+	// Highest level a creature from elsewhere obeys at with no badges, and
+	// how much higher each badge raises it
+	obedienceBaseLevel     = 20
+	obedienceLevelPerBadge = 10
+)
+
+// Ways a creature can disobey
+const (
+	DisobeyLoaf = iota
+	DisobeyOtherMove
+	DisobeyNap
+	DisobeyCount
+)
+
+// obedienceLevel returns the highest level of creature from elsewhere that
+// obeys the player with the badges they hold
+func (g *Game) obedienceLevel() int {
+	return obedienceBaseLevel + g.badges*obedienceLevelPerBadge
+}
+
+// mightDisobey reports whether a creature might not do as the player says:
+// one over the obedience level that didn't grow up with the player, because
+// it was traded or joined already strong
+func (g *Game) mightDisobey(c *Creature) bool {
+	limit := g.obedienceLevel()
+	return c.level > limit && (c.trainer != g.playerName || c.metLevel > limit)
+}
+
+// disobey rolls whether a creature ignores the move chosen for it, the
+// further over the obedience level the likelier. It returns the move it
+// uses instead, or -1 if it does nothing, with the battle text saying so;
+// a creature that obeys returns the chosen move and no text. Napping only
+// loses the turn, as there's no sleep status.
+func (g *Game) disobey(c *Creature, chosen int) (int, string) {
+	limit := g.obedienceLevel()
+	if battleRand.Intn(c.level+limit) < limit {
+		return chosen, ""
+	}
+
+	switch battleRand.Intn(DisobeyCount) {
+	case DisobeyOtherMove:
+		var others []int
+		for i, move := range c.moves {
+			if i != chosen && move.pp > 0 {
+				others = append(others, i)
+			}
+		}
+		if len(others) > 0 {
+			return others[battleRand.Intn(len(others))], c.displayName() + " ignored orders!"
+		}
+	case DisobeyNap:
+		return -1, c.displayName() + " began to nap!"
+	}
+	return -1, c.displayName() + " is loafing around!"
+}
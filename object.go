@@ -0,0 +1,375 @@
+package main
+
+import (
+	"image/color"
+	"math/rand"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Map object kind constants
+const (
+	ObjectSign = iota
+	ObjectItem
+	ObjectBerryTree
+	ObjectCutTree
+	ObjectBoulder
+	ObjectHealer
+	ObjectStaticEncounter
+	ObjectSafariAttendant
+	ObjectMarketClerk
+	ObjectLotteryClerk
+	ObjectVisitor
+	ObjectFusionScientist
+	ObjectCrafter
+	ObjectMineral
+	ObjectJunk
+	ObjectRaidDen
+	ObjectBulletinBoard
+	ObjectTradeBoard
+	ObjectBikeClerk
+	ObjectStoragePC
+	ObjectHiddenItem
+	ObjectPerson
+	ObjectMailbox
+	ObjectSlotMachine
+	ObjectPrizeClerk
+	ObjectCardDealer
+	ObjectMoveTutor
+	ObjectMoveRelearner
+	ObjectNameRater
+)
+
+// MapObject is something on the map the player can interact with
+type MapObject struct {
+	kind int
+	// Text shown when a sign is read
+	text string
+	// Item granted by an item ball or dug up, the berry a berry tree grows,
+	// the material gathered here or the recipe taught
+	item string
+	// Where an obstacle was first placed, which its saved state is keyed by
+	origin Point
+	// Fixed wild creature standing here
+	encounter *StaticEncounter
+	// How people standing on the map are drawn, and what they have to say
+	sprite   *AnimatedSprite
+	dialogue string
+}
+
+// itemRewards are the items hidden in item balls around the overworld
+var itemRewards = []string{"Potion", "Potion", "Super Potion", "Capture Ball", "Capture Ball", "Repel", "Revive"}
+
+// Sign is a readable sign placed by the world generator
+type Sign struct {
+	pos  Point
+	text string
+}
+
+// hintTexts are the tips shown on hint signs along routes
+var hintTexts = []string{
+	"TRAINER TIPS: Wild creatures only appear in grass. Stick to the path to travel safely.",
+	"TRAINER TIPS: Every region has its own climate, and its own creatures to find.",
+	"TRAINER TIPS: Bridges carry routes over water that would otherwise block your way.",
+	"TRAINER TIPS: Press C to check on your creatures at any time.",
+	"TRAINER TIPS: Step away from a wild creature with ESC if a battle goes badly.",
+	"TRAINER TIPS: {rival} came through here earlier. Don't fall behind, {player}!",
+}
+
+// townSigns returns the signs of a town: a welcome sign by the western
+// entrance and one beside the heal center, shop and gym doors
+func townSigns(region *Region) []Sign {
+	minX := region.town.x - townWidth/2
+	minY := region.town.y - townHeight/2
+
+	signs := []Sign{{Point{minX, minY + 5}, "Welcome to " + region.name + "!"}}
+	for _, b := range region.buildings {
+		pos := Point{b.door.x - 1, b.door.y + 1}
+		switch b.kind {
+		case BuildingHealCenter:
+			signs = append(signs, Sign{pos, "HEAL CENTER - Rest your creatures here."})
+		case BuildingShop:
+			signs = append(signs, Sign{pos, "SHOP - Supplies for every trainer."})
+		case BuildingGym:
+			signs = append(signs, Sign{pos, region.name + " GYM - The leader awaits challengers!"})
+		case BuildingLab:
+			signs = append(signs, Sign{pos, "FUSION LAB - Two creatures become one!"})
+		case BuildingGameCorner:
+			signs = append(signs, Sign{pos, "GAME CORNER - Slots and cards! Trade your tokens for prizes."})
+		}
+	}
+	return signs
+}
+
+// routeSigns returns a sign at each end of a route naming it, and a hint sign
+// halfway along
+func (p *WorldPlan) routeSigns(rng *rand.Rand, number int, route Route) []Sign {
+	path := route.tiles()
+	from, to := p.regions[route.from].name, p.regions[route.to].name
+	name := strings.ToUpper(routeName(number))
+
+	// Far enough out to be clear of the town square
+	offset := townWidth/2 + 3
+
+	signs := []Sign{}
+	if len(path) > offset*2 {
+		signs = append(signs,
+			Sign{beside(path, offset), name + " - " + from + " to " + to},
+			Sign{beside(path, len(path)-1-offset), name + " - " + to + " to " + from},
+		)
+	}
+	if len(path) > offset*4 {
+		signs = append(signs, Sign{beside(path, len(path)/2), hintTexts[rng.Intn(len(hintTexts))]})
+	}
+	return signs
+}
+
+// beside returns the tile next to step i of a path, off to the side of the
+// direction of travel so the sign doesn't block the route
+func beside(path []Point, i int) Point {
+	next := path[min(i+1, len(path)-1)]
+	if next.y == path[i].y {
+		return Point{path[i].x, path[i].y - 1}
+	}
+	return Point{path[i].x - 1, path[i].y}
+}
+
+// tiles returns every tile along a route's center line, in order
+func (r Route) tiles() []Point {
+	path := []Point{r.points[0]}
+	for i := range len(r.points) - 1 {
+		x, y := r.points[i].x, r.points[i].y
+		end := r.points[i+1]
+		for x != end.x || y != end.y {
+			switch {
+			case x < end.x:
+				x++
+			case x > end.x:
+				x--
+			case y < end.y:
+				y++
+			default:
+				y--
+			}
+			path = append(path, Point{x, y})
+		}
+	}
+	return path
+}
+
+// interact checks the tile the player is facing for something to interact
+// with, reaching across counters to whoever stands behind them
+func (g *Game) interact() {
+	dx, dy := directionDelta(g.player.direction)
+	if g.worldMap.Tile(LayerBase, g.player.tileX+dx, g.player.tileY+dy) == TileCounter {
+		dx, dy = dx*2, dy*2
+	}
+	if f := &g.follower; len(g.creatures) > 0 && f.tileX == g.player.tileX+dx && f.tileY == g.player.tileY+dy {
+		g.talkToFollower()
+		return
+	}
+	if g.worldMap.Tile(LayerBase, g.player.tileX+dx, g.player.tileY+dy) == TileSoil {
+		g.tendPlot(Point{g.player.tileX + dx, g.player.tileY + dy})
+		return
+	}
+	object, ok := g.worldMap.objects[Point{g.player.tileX + dx, g.player.tileY + dy}]
+	if !ok {
+		if !g.player.surfing && g.worldMap.IsWater(g.player.tileX+dx, g.player.tileY+dy) && g.worldMap.platformAt(g.player.tileX+dx, g.player.tileY+dy) == nil {
+			g.offerSurf()
+		}
+		return
+	}
+
+	switch object.kind {
+	case ObjectSign:
+		g.showDialogue(object.text)
+	case ObjectItem, ObjectHiddenItem:
+		g.pickUpItem(Point{g.player.tileX + dx, g.player.tileY + dy}, object)
+	case ObjectBerryTree:
+		g.harvestBerry(Point{g.player.tileX + dx, g.player.tileY + dy}, object)
+	case ObjectCutTree:
+		g.cutTree(Point{g.player.tileX + dx, g.player.tileY + dy})
+	case ObjectBoulder:
+		g.examineBoulder()
+	case ObjectHealer:
+		g.healParty()
+	case ObjectStaticEncounter:
+		g.interactStaticEncounter(object.encounter)
+	case ObjectSafariAttendant:
+		g.talkToSafariAttendant()
+	case ObjectMarketClerk:
+		g.talkToMarketClerk()
+	case ObjectLotteryClerk:
+		g.drawLottery()
+	case ObjectVisitor:
+		g.talkToVisitor()
+	case ObjectFusionScientist:
+		g.talkToFusionScientist()
+	case ObjectCrafter:
+		if !g.deliverQuest() {
+			g.talkToCrafter(object.item)
+		}
+	case ObjectMineral, ObjectJunk:
+		g.gatherMaterial(Point{g.player.tileX + dx, g.player.tileY + dy}, object)
+	case ObjectRaidDen:
+		g.talkToRaidDen(Point{g.player.tileX + dx, g.player.tileY + dy})
+	case ObjectBulletinBoard:
+		g.readBulletinBoard()
+	case ObjectTradeBoard:
+		g.readTradeBoard()
+	case ObjectBikeClerk:
+		g.talkToBikeClerk()
+	case ObjectStoragePC:
+		g.openStorage()
+	case ObjectMailbox:
+		g.openMailbox()
+	case ObjectSlotMachine:
+		g.openSlots()
+	case ObjectPrizeClerk:
+		g.talkToPrizeClerk()
+	case ObjectCardDealer:
+		g.talkToCardDealer()
+	case ObjectMoveTutor:
+		g.talkToMoveTutor()
+	case ObjectMoveRelearner:
+		g.talkToMoveRelearner()
+	case ObjectNameRater:
+		g.talkToNameRater()
+	case ObjectPerson:
+		g.startConversation(object.dialogue, objectEntity(Point{g.player.tileX + dx, g.player.tileY + dy}))
+	}
+}
+
+// pickUpItem puts an item ball's contents in the bag and removes it from the
+// map for good
+func (g *Game) pickUpItem(pos Point, object *MapObject) {
+	g.addItem(object.item, 1)
+	delete(g.worldMap.objects, pos)
+	g.worldMap.pickedUp[pos] = true
+	if object.kind == ObjectHiddenItem {
+		g.showToast("Dug up a buried " + object.item + "!")
+	} else {
+		g.showToast("Found a " + object.item + "!")
+	}
+	g.audio.playSound("heal")
+}
+
+// placeItemBalls hides item balls in nooks of the chunk whose top-left tile is
+// originX, originY: open tiles mostly walled in by water, mountains or trees,
+// so reaching them means taking a detour. Balls already picked up stay gone
+// when the chunk is regenerated.
+func (m *Map) placeItemBalls(c *Chunk, rng *rand.Rand, originX, originY int) {
+	for y := 1; y < chunkSize-1; y++ {
+		for x := 1; x < chunkSize-1; x++ {
+			tile := c.tiles[LayerBase][y][x]
+			if c.collisionMap.get(y*chunkSize+x) || (tile != TileGrass && tile != TileSand) {
+				continue
+			}
+
+			// Count blocked neighbors
+			blocked := 0
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					if (dx != 0 || dy != 0) && c.collisionMap.get((y+dy)*chunkSize+x+dx) {
+						blocked++
+					}
+				}
+			}
+			if blocked < 5 || rng.Float32() >= 0.3 {
+				continue
+			}
+
+			pos := Point{originX + x, originY + y}
+			if !m.pickedUp[pos] && m.objects[pos] == nil {
+				m.objects[pos] = &MapObject{kind: ObjectItem, item: itemRewards[rng.Intn(len(itemRewards))]}
+			}
+
+			// Some nooks are also sealed off with trees to cut
+			m.placeCutTrees(c, rng, x, y, originX, originY)
+
+			// At most one item per chunk
+			return
+		}
+	}
+}
+
+// drawObjects draws the objects layer: item balls and other things sitting on the map
+func (g *Game) drawObjects(screen *ebiten.Image) {
+	// Look up the tiles on screen rather than going through every object,
+	// as the overworld keeps the objects of every chunk it has generated
+	startX, startY, endX, endY := g.visibleTiles()
+	for tileY := startY; tileY < endY; tileY++ {
+		for tileX := startX; tileX < endX; tileX++ {
+			pos := Point{tileX, tileY}
+			if object, ok := g.worldMap.objects[pos]; ok && object.kind != ObjectSign && object.kind != ObjectHiddenItem {
+				g.drawObject(screen, pos, object)
+			}
+		}
+	}
+}
+
+// drawObject draws an object on the map at a tile
+func (g *Game) drawObject(screen *ebiten.Image, pos Point, object *MapObject) {
+	x := float32(pos.x*tileSize) - g.camera.x + tileSize/2
+	y := float32(pos.y*tileSize) - g.camera.y + tileSize/2
+
+	switch object.kind {
+	case ObjectBerryTree:
+		g.drawBerryTree(screen, x, y, g.regrown(pos, object))
+	case ObjectCutTree:
+		g.drawCutTree(screen, x, y)
+	case ObjectBoulder:
+		g.drawBoulder(screen, x, y)
+	case ObjectHealer:
+		g.drawNPC(screen, object, x, y, color.RGBA{240, 140, 180, 255})
+	case ObjectSafariAttendant:
+		g.drawNPC(screen, object, x, y, color.RGBA{120, 160, 80, 255})
+	case ObjectMarketClerk:
+		g.drawNPC(screen, object, x, y, color.RGBA{230, 170, 60, 255})
+	case ObjectLotteryClerk:
+		g.drawNPC(screen, object, x, y, color.RGBA{170, 90, 200, 255})
+	case ObjectVisitor:
+		g.drawNPC(screen, object, x, y, color.RGBA{90, 190, 200, 255})
+	case ObjectFusionScientist:
+		g.drawNPC(screen, object, x, y, color.RGBA{235, 235, 245, 255})
+	case ObjectCrafter:
+		g.drawNPC(screen, object, x, y, color.RGBA{150, 110, 70, 255})
+	case ObjectBikeClerk:
+		g.drawNPC(screen, object, x, y, color.RGBA{200, 40, 50, 255})
+	case ObjectPerson:
+		g.drawNPC(screen, object, x, y, color.RGBA{220, 120, 90, 255})
+	case ObjectMineral:
+		g.drawMineral(screen, x, y, g.regrown(pos, object))
+	case ObjectJunk:
+		g.drawJunk(screen, x, y, g.regrown(pos, object))
+	case ObjectRaidDen:
+		g.drawRaidDen(screen, x, y, g.raidReady(pos))
+	case ObjectBulletinBoard:
+		g.drawBulletinBoard(screen, x, y)
+	case ObjectTradeBoard:
+		g.drawTradeBoard(screen, x, y)
+	case ObjectStoragePC:
+		g.drawStoragePC(screen, x, y)
+	case ObjectMailbox:
+		g.drawMailbox(screen, x, y)
+	case ObjectSlotMachine:
+		g.drawSlotMachine(screen, x, y)
+	case ObjectPrizeClerk:
+		g.drawNPC(screen, object, x, y, color.RGBA{240, 200, 60, 255})
+	case ObjectCardDealer:
+		g.drawNPC(screen, object, x, y, color.RGBA{40, 40, 50, 255})
+	case ObjectMoveTutor:
+		g.drawNPC(screen, object, x, y, color.RGBA{120, 60, 170, 255})
+	case ObjectMoveRelearner:
+		g.drawNPC(screen, object, x, y, color.RGBA{150, 150, 160, 255})
+	case ObjectNameRater:
+		g.drawNPC(screen, object, x, y, color.RGBA{90, 160, 150, 255})
+	case ObjectStaticEncounter:
+		g.drawStaticEncounter(screen, x, y, object.encounter)
+	default:
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(float64(x-10), float64(y-10))
+		screen.DrawImage(ballSprite(), op)
+	}
+}
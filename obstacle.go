@@ -0,0 +1,198 @@
+package main
+
+import (
+	"image/color"
+	"math/rand"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Field abilities party creatures can use to clear obstacles
+const (
+	AbilityCut      = "Cut"
+	AbilityStrength = "Strength"
+)
+
+// ObstacleState records what the player has done to an obstacle, keyed by
+// where the obstacle started out
+type ObstacleState struct {
+	// Cut down, or pushed into a hole
+	Cleared bool `json:"cleared"`
+	// Where a boulder now rests, or the hole it filled
+	Pos Point `json:"pos"`
+}
+
+// fieldAbilityUser returns the first party creature able to use an ability,
+// or nil if none can
+func (g *Game) fieldAbilityUser(ability string) *Creature {
+	for i := range g.creatures {
+		if species := findSpecies(g.creatures[i].name); species != nil && species.ability == ability {
+			return &g.creatures[i]
+		}
+	}
+	return nil
+}
+
+// placeCutTrees plants small trees on the open tiles around a nook at the
+// chunk-local tile x, y, so reaching it takes Cut. Trees already cut down stay
+// gone when the chunk is regenerated.
+func (m *Map) placeCutTrees(c *Chunk, rng *rand.Rand, x, y, originX, originY int) {
+	if rng.Float32() >= 0.5 {
+		return
+	}
+
+	for _, d := range [][2]int{{0, -1}, {0, 1}, {-1, 0}, {1, 0}} {
+		nx, ny := x+d[0], y+d[1]
+		tile := c.tiles[LayerBase][ny][nx]
+		if c.collisionMap.get(ny*chunkSize+nx) || (tile != TileGrass && tile != TileSand) {
+			continue
+		}
+
+		pos := Point{originX + nx, originY + ny}
+		if !m.obstacles[pos].Cleared && m.objects[pos] == nil {
+			m.objects[pos] = &MapObject{kind: ObjectCutTree, origin: pos}
+		}
+	}
+}
+
+// addBoulder places a pushable boulder on a static map
+func (m *Map) addBoulder(x, y int) {
+	pos := Point{x, y}
+	m.objects[pos] = &MapObject{kind: ObjectBoulder, origin: pos}
+}
+
+// applyObstacles restores cut trees and pushed boulders on a static map from
+// its saved obstacle state
+func (m *Map) applyObstacles() {
+	// Lift every moved obstacle off the map before putting any back, so a
+	// boulder resting where another started isn't clobbered
+	moved := make(map[Point]*MapObject)
+	for origin := range m.obstacles {
+		if object, ok := m.objects[origin]; ok && object.origin == origin {
+			moved[origin] = object
+			delete(m.objects, origin)
+		}
+	}
+
+	for origin, object := range moved {
+		state := m.obstacles[origin]
+		switch {
+		case !state.Cleared:
+			m.objects[state.Pos] = object
+		case object.kind == ObjectBoulder:
+			m.stamp(state.Pos.x, state.Pos.y, TileFilledHole, false)
+		}
+	}
+
+	m.updateSwitches()
+}
+
+// cutTree cuts down the tree at pos if a party creature knows Cut
+func (g *Game) cutTree(pos Point) {
+	user := g.fieldAbilityUser(AbilityCut)
+	if user == nil {
+		g.showDialogue("This tree looks like it could be cut down.")
+		return
+	}
+
+	object := g.worldMap.objects[pos]
+	delete(g.worldMap.objects, pos)
+	g.worldMap.obstacles[object.origin] = ObstacleState{Cleared: true, Pos: pos}
+	g.showDialogue(user.name + " used Cut!")
+}
+
+// examineBoulder explains how to move a boulder
+func (g *Game) examineBoulder() {
+	if user := g.fieldAbilityUser(AbilityStrength); user != nil {
+		g.showDialogue(user.name + " can move boulders with Strength. Walk into it to push.")
+		return
+	}
+	g.showDialogue("It's a big boulder. A strong creature might be able to push it.")
+}
+
+// pushBoulder pushes a boulder the player walks into one tile further, if a
+// party creature knows Strength and the tile beyond is free. Boulders pushed
+// into a hole fill it in for good.
+func (g *Game) pushBoulder() {
+	dx, dy := directionDelta(g.player.direction)
+	pos := Point{g.player.tileX + dx, g.player.tileY + dy}
+	object, ok := g.worldMap.objects[pos]
+	if !ok || object.kind != ObjectBoulder || g.fieldAbilityUser(AbilityStrength) == nil {
+		return
+	}
+
+	m := g.worldMap
+	dest := Point{pos.x + dx, pos.y + dy}
+	if dest.x < 0 || dest.y < 0 || dest.x >= m.width || dest.y >= m.height {
+		return
+	}
+
+	if m.Tile(LayerBase, dest.x, dest.y) == TileHole {
+		delete(m.objects, pos)
+		m.stamp(dest.x, dest.y, TileFilledHole, false)
+		m.obstacles[object.origin] = ObstacleState{Cleared: true, Pos: dest}
+		g.audio.playSound("bump")
+		return
+	}
+
+	if m.IsCollision(dest.x, dest.y) {
+		return
+	}
+
+	delete(m.objects, pos)
+	m.objects[dest] = object
+	m.obstacles[object.origin] = ObstacleState{Pos: dest}
+	m.updateSwitches()
+	g.audio.playSound("bump")
+}
+
+// updateSwitches opens every gate on the map while a boulder rests on a
+// switch, and closes them otherwise
+func (m *Map) updateSwitches() {
+	// Switches are only built into static maps
+	if !m.static {
+		return
+	}
+
+	pressed := false
+	for pos, object := range m.objects {
+		if object.kind == ObjectBoulder && m.Tile(LayerBase, pos.x, pos.y) == TileSwitch {
+			pressed = true
+			break
+		}
+	}
+
+	for y := range m.height {
+		for x := range m.width {
+			switch m.Tile(LayerBase, x, y) {
+			case TileGate, TileGateOpen:
+				if pressed {
+					m.stamp(x, y, TileGateOpen, false)
+				} else {
+					m.stamp(x, y, TileGate, true)
+				}
+			}
+		}
+	}
+}
+
+// arrowJustPressed reports whether any movement key was pressed this frame
+func (g *Game) arrowJustPressed() bool {
+	return g.input.IsActionJustPressed(ebiten.KeyUp) || g.input.IsActionJustPressed(ebiten.KeyDown) ||
+		g.input.IsActionJustPressed(ebiten.KeyLeft) || g.input.IsActionJustPressed(ebiten.KeyRight)
+}
+
+// drawCutTree draws a small tree that can be cut down
+func (g *Game) drawCutTree(screen *ebiten.Image, x, y float32) {
+	vector.DrawFilledRect(screen, x-2, y+2, 4, 10, color.RGBA{100, 60, 30, 255}, true)
+	vector.DrawFilledCircle(screen, x, y-2, 8, color.RGBA{50, 150, 60, 255}, true)
+	vector.StrokeLine(screen, x-5, y-2, x+5, y-2, 1, color.RGBA{20, 80, 30, 255}, true)
+}
+
+// drawBoulder draws a pushable boulder
+func (g *Game) drawBoulder(screen *ebiten.Image, x, y float32) {
+	vector.DrawFilledCircle(screen, x, y, 13, color.RGBA{60, 55, 50, 255}, true)
+	vector.DrawFilledCircle(screen, x, y, 12, color.RGBA{140, 130, 120, 255}, true)
+	vector.DrawFilledCircle(screen, x-4, y-4, 4, color.RGBA{175, 165, 155, 255}, true)
+}
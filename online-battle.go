@@ -0,0 +1,482 @@
+package main
+
+import (
+	"encoding/json"
+	"image/color"
+	"math"
+	"net"
+	"strconv"
+	"time"
+
+	"creaturegame-2/engine"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// defaultRefereeAddress is where ranked battles are looked for unless the
+// player types another address
+const defaultRefereeAddress = "localhost:" + engine.RefereePort
+
+// OnlineBattle is a ranked battle against another player, played out by a
+// referee server. The game only sends its choice of move each turn and shows
+// what the referee says happened.
+type OnlineBattle struct {
+	conn    net.Conn
+	encoder *json.Encoder
+	// Messages arrive from a background goroutine, which stops once done
+	// is closed as the game hangs up
+	incoming chan engine.Message
+	closed   chan error
+	done     chan struct{}
+	// Which side of the match the player is, and who they're up against
+	side     int
+	opponent string
+	// The opponent's rating, which their game reports, or 0 if it didn't
+	rating int
+	// Ruleset the referee is holding both sides to
+	rules engine.Ruleset
+	// Turn being chosen, counting from 1, when the choice is due and how
+	// many seconds were given for it, if the ruleset has a timer
+	turn      int
+	deadline  time.Time
+	turnLimit int
+	// Copies of the party that fight, so a ranked battle leaves the party
+	// as it was, and the opponent's team
+	mine, theirs []Creature
+	// A move was sent and the referee hasn't played the turn yet
+	waiting bool
+	// Attacks in the last turn still to be shown
+	events []engine.TurnEvent
+	// Set once the referee has said how the match ended
+	over   bool
+	winner int
+	reason string
+}
+
+// rankedTeam returns the party creatures able to fight a ranked battle
+func (g *Game) rankedTeam() []Creature {
+	var team []Creature
+	for _, c := range g.creatures {
+		if !c.egg && c.hp > 0 && len(team) < engine.MaxTeam {
+			team = append(team, c)
+		}
+	}
+	return team
+}
+
+// joinReferee sends the party to the referee to be matched with another
+// player, then waits on the trade screen for the match to start
+func (g *Game) joinReferee(conn net.Conn) {
+	o := &OnlineBattle{
+		conn:     conn,
+		encoder:  json.NewEncoder(conn),
+		incoming: make(chan engine.Message, 8),
+		closed:   make(chan error, 1),
+		done:     make(chan struct{}),
+		mine:     g.rankedTeam(),
+	}
+	incoming, closed, done := o.incoming, o.closed, o.done
+	go func() {
+		decoder := json.NewDecoder(conn)
+		for {
+			var msg engine.Message
+			if err := decoder.Decode(&msg); err != nil {
+				closed <- err
+				return
+			}
+			select {
+			case incoming <- msg:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	team := make([]engine.Fighter, len(o.mine))
+	for i := range o.mine {
+		team[i] = o.mine[i].fighter()
+	}
+	o.send(engine.Message{Type: engine.MessageJoin, Trainer: g.playerName, Rating: g.ranked.rating(), Team: team, Match: g.trade.match})
+
+	g.trade.referee = o
+	g.trade.stage = TradeMatching
+	g.trade.status = "Waiting for an opponent..."
+}
+
+// send sends a message to the referee; a lost connection shows up on the
+// receiving side
+func (o *OnlineBattle) send(msg engine.Message) {
+	o.encoder.Encode(msg)
+}
+
+// hangUp closes the connection to the referee, letting go of the goroutine
+// reading from it
+func (o *OnlineBattle) hangUp() {
+	select {
+	case <-o.done:
+	default:
+		close(o.done)
+		o.conn.Close()
+	}
+}
+
+// updateMatching waits on the trade screen for the referee to find an
+// opponent
+func (g *Game) updateMatching() {
+	t := &g.trade
+	if g.input.IsActionJustPressed(ebiten.KeyEscape) {
+		g.closeTrade("")
+		return
+	}
+
+	select {
+	case msg := <-t.referee.incoming:
+		switch msg.Type {
+		case engine.MessageStart:
+			o := t.referee
+			t.referee = nil
+			g.trade = TradeSession{}
+			g.startOnlineBattle(o, msg)
+		case engine.MessageError:
+			t.referee.hangUp()
+			t.referee = nil
+			t.stage = TradeMenu
+			t.status = "The referee refused the team: " + msg.Reason
+		}
+	case <-t.referee.closed:
+		t.referee.hangUp()
+		t.referee = nil
+		t.stage = TradeMenu
+		t.status = "Lost connection to the referee."
+	default:
+	}
+}
+
+// startOnlineBattle begins a ranked battle once the referee has matched the
+// player with an opponent
+func (g *Game) startOnlineBattle(o *OnlineBattle, start engine.Message) {
+	o.side, o.opponent, o.rating = start.Side, start.Trainer, start.Rating
+	if start.Rules != nil {
+		o.rules = *start.Rules
+	}
+	o.turn = 1
+	o.startTimer(start.Seconds)
+	for _, f := range start.Team {
+		if findSpecies(f.Name) == nil {
+			o.hangUp()
+			g.gameState = StateOverworld
+			g.showDialogue("The opponent has creatures this game doesn't know.")
+			return
+		}
+		o.theirs = append(o.theirs, creatureFromFighter(f))
+	}
+
+	g.setUpBattle(o.theirs[0])
+	g.battle.safari = false
+	g.battle.online = o
+	g.battle.playerCreature = &o.mine[0]
+	g.battle.announcement = o.opponent + " sent out " + o.theirs[0].name + "!"
+	if o.rules.Name != "" {
+		g.battle.announcement += " " + o.rules.Name + " rules: " + o.rules.Describe() + "."
+	}
+	g.updateHPBars()
+}
+
+// creatureFromFighter makes a creature of the opponent's team from what the
+// referee sent
+func creatureFromFighter(f engine.Fighter) Creature {
+	c := newCreatureForm(f.Name, f.Form, f.Level)
+	c.hp, c.maxHP = f.HP, f.MaxHP
+	c.attack, c.defense, c.speed = f.Attack, f.Defense, f.Speed
+	c.type1, c.type2 = f.Type1, f.Type2
+	c.status = f.Status
+	c.moves = nil
+	for _, m := range f.Moves {
+		c.moves = append(c.moves, moveFromEngine(m))
+	}
+	return c
+}
+
+// updateOnlineBattle plays out a ranked battle: the player picks a move, the
+// referee resolves the turn, and each attack it reports is shown in turn
+func (g *Game) updateOnlineBattle() {
+	o := g.battle.online
+	if g.receiveReferee() {
+		return
+	}
+
+	if len(o.events) > 0 {
+		g.showTurnEvent(o.events[0])
+		o.events = o.events[1:]
+		return
+	}
+	if o.over {
+		g.finishOnlineBattle()
+		return
+	}
+	if o.waiting {
+		return
+	}
+	g.battle.currentTurn = 0
+
+	// A move charged last turn strikes whatever's chosen, so any move the
+	// referee accepts is sent for it
+	if g.battle.playerVolatile.Charging != nil {
+		o.send(engine.Message{Type: engine.MessageChoose, Move: g.battle.playerCreature.firstMoveWithPP()})
+		o.waiting = true
+		g.battle.currentTurn = 1
+		return
+	}
+
+	if g.input.IsActionJustPressed(ebiten.KeyEscape) {
+		o.send(engine.Message{Type: engine.MessageForfeit})
+		o.waiting = true
+		return
+	}
+
+	moves := g.battle.playerCreature.moves
+	if g.input.IsActionJustPressed(ebiten.KeyUp) {
+		g.battle.selectedAction = (g.battle.selectedAction - 1 + len(moves)) % len(moves)
+	} else if g.input.IsActionJustPressed(ebiten.KeyDown) {
+		g.battle.selectedAction = (g.battle.selectedAction + 1) % len(moves)
+	}
+	clicked := g.mouseSelect(g.moveRects(), &g.battle.selectedAction)
+
+	// Out of time, the first move with PP left is used, as the referee would,
+	// or Struggle if there's none
+	if o.timeUp() {
+		g.battle.selectedAction = g.battle.playerCreature.firstMoveWithPP()
+		name := moves[g.battle.selectedAction].name
+		if g.battle.playerCreature.outOfPP() {
+			name = struggle.name
+		}
+		g.chooseOnlineMove()
+		g.battle.battleText = "Time's up! " + g.battle.playerCreature.displayName() + " uses " + name + "."
+		g.battle.battleTextTimer = 40
+		return
+	}
+
+	if g.input.IsActionJustPressed(ebiten.KeySpace) || clicked {
+		move := &moves[g.battle.selectedAction]
+		if move.pp <= 0 && !g.battle.playerCreature.outOfPP() {
+			g.battle.battleText = "There's no PP left for this move!"
+			g.battle.battleTextTimer = 40
+			return
+		}
+		g.chooseOnlineMove()
+	}
+}
+
+// firstMoveWithPP returns the index of a creature's first move with PP left,
+// or its first if none has any, as the referee picks when time runs out
+func (c *Creature) firstMoveWithPP() int {
+	for i, move := range c.moves {
+		if move.pp > 0 {
+			return i
+		}
+	}
+	return 0
+}
+
+// chooseOnlineMove sends the referee the move selected for this turn
+func (g *Game) chooseOnlineMove() {
+	o := g.battle.online
+	move := &g.battle.playerCreature.moves[g.battle.selectedAction]
+	// The referee keeps its own count; this one is for showing
+	move.pp = max(move.pp-1, 0)
+	o.send(engine.Message{Type: engine.MessageChoose, Move: g.battle.selectedAction})
+	o.waiting = true
+	g.battle.currentTurn = 1
+}
+
+// startTimer starts the countdown to choose the next move, if the referee
+// gave one
+func (o *OnlineBattle) startTimer(seconds int) {
+	o.deadline, o.turnLimit = time.Time{}, seconds
+	if seconds > 0 {
+		o.deadline = time.Now().Add(time.Duration(seconds) * time.Second)
+	}
+}
+
+// timeUp reports whether the countdown to choose a move has run out
+func (o *OnlineBattle) timeUp() bool {
+	return !o.deadline.IsZero() && time.Now().After(o.deadline)
+}
+
+// receiveReferee handles messages from the referee, reporting whether the
+// battle ended because the connection was lost
+func (g *Game) receiveReferee() bool {
+	o := g.battle.online
+	for {
+		// Every message is handled before noticing the connection closed,
+		// as the referee hangs up straight after saying who won
+		select {
+		case msg := <-o.incoming:
+			switch msg.Type {
+			case engine.MessageTurn:
+				o.events = append(o.events, msg.Events...)
+				o.waiting = false
+				o.turn++
+				o.startTimer(msg.Seconds)
+			case engine.MessageEnd:
+				o.over, o.winner, o.reason = true, msg.Winner, msg.Reason
+			case engine.MessageError:
+				o.waiting = false
+				g.battle.currentTurn = 0
+				g.battle.battleText = msg.Reason
+				g.battle.battleTextTimer = 40
+			}
+			continue
+		default:
+		}
+
+		select {
+		case <-o.closed:
+			if o.over {
+				return false
+			}
+			o.hangUp()
+			g.battle.online = nil
+			g.endBattle()
+			g.showDialogue("Lost connection to the referee.")
+			return true
+		default:
+			return false
+		}
+	}
+}
+
+// showTurnEvent shows one attack of a turn as the referee played it out
+func (g *Game) showTurnEvent(e engine.TurnEvent) {
+	o := g.battle.online
+	mine := e.Side == o.side
+	attacker, defender := g.battle.playerCreature, &g.battle.enemyCreature
+	if !mine {
+		attacker, defender = defender, attacker
+	}
+
+	move := struggle
+	for _, m := range attacker.moves {
+		if m.name == e.Move {
+			move = m
+		}
+	}
+	own, target := &g.battle.playerVolatile, &g.battle.enemyVolatile
+	if !mine {
+		own, target = target, own
+	}
+
+	g.battle.battleText = attacker.name + " used " + move.name + "!"
+	switch {
+	case e.Charged:
+		charging := move.engineMove()
+		own.Charging, own.Vanished = &charging, move.kind == engine.KindVanish
+		g.battle.battleText += chargeText(move, attacker)
+	case e.Missed:
+		own.Charging, own.Vanished = nil, false
+		g.battle.battleText += missText
+	default:
+		own.Charging, own.Vanished = nil, false
+		hit := Hit{damage: e.Hit.Damage, effectiveness: e.Hit.Effectiveness, critical: e.Hit.Critical}
+		g.queueMoveAnimation(move, mine)
+		g.queueHitPopup(hit, mine)
+		g.publishHit(hit.damage, defender.maxHP)
+		if e.Status != StatusNone {
+			g.battle.battleText += " " + inflictStatus(defender, e.Status)
+		}
+		switch move.kind {
+		case engine.KindRecoil:
+			if e.AttackerHP < attacker.hp {
+				g.battle.battleText += recoilText(attacker)
+			}
+			attacker.hp = e.AttackerHP
+		case engine.KindDrain:
+			if e.AttackerHP > attacker.hp {
+				g.battle.battleText += drainText(defender)
+			}
+			attacker.hp = e.AttackerHP
+		}
+		if e.Bound > 0 {
+			target.Bound, target.BoundBy = e.Bound, move.name
+			g.battle.battleText += boundText(defender, move.name)
+		}
+	}
+	if e.BindDamage > 0 {
+		by := target.BoundBy
+		if target.Bound--; target.Bound <= 0 {
+			target.Bound, target.BoundBy = 0, ""
+		}
+		g.battle.battleText += hurtText(defender, by, target.Bound == 0)
+	}
+	defender.hp = e.HP
+	g.battle.battleTextTimer = 60
+	if defender.hp > 0 {
+		return
+	}
+
+	g.battle.battleText += " " + defender.name + " fainted!"
+	g.battle.leaveField(!mine)
+	switch {
+	case e.SentOut < 0:
+	case mine:
+		g.battle.enemyCreature = o.theirs[e.SentOut]
+		g.battle.battleText += " " + o.opponent + " sent out " + g.battle.enemyCreature.name + "!"
+	default:
+		g.battle.playerCreature = &o.mine[e.SentOut]
+		g.battle.selectedAction = 0
+		g.battle.battleText += " Go, " + g.battle.playerCreature.name + "!"
+	}
+}
+
+// drawTurnTimer draws the time left to choose a move along the top of the
+// battle UI, running short in red, and beside the prompt for a move the
+// seconds left and whether sudden death has begun
+func (g *Game) drawTurnTimer(screen *ebiten.Image, uiTop int) {
+	o := g.battle.online
+	if o.deadline.IsZero() || o.waiting || o.over {
+		return
+	}
+	left := max(time.Until(o.deadline), 0)
+	seconds := int(math.Ceil(left.Seconds()))
+	clr := color.RGBA{255, 220, 80, 255}
+	if seconds <= 10 {
+		clr = color.RGBA{230, 60, 60, 255}
+	}
+	fraction := float32(left.Seconds() / float64(o.turnLimit))
+	vector.DrawFilledRect(screen, 0, float32(uiTop), screenWidth*fraction, 3, clr, false)
+
+	if g.battle.battleTextTimer > 0 || g.battle.intro != IntroDone || len(o.events) > 0 {
+		return
+	}
+	prefix := ""
+	if o.rules.InSuddenDeath(o.turn) {
+		prefix = "Sudden death! "
+	}
+	label := g.cachedLabel(labelKey{prefix: prefix, name: "timer", a: seconds}, func() string {
+		return prefix + strconv.Itoa(seconds/60) + ":" + strconv.Itoa(seconds%60/10) + strconv.Itoa(seconds%10)
+	})
+	g.drawText(screen, label, float64(screenWidth-10-g.textWidth(label)), float64(uiTop+20), clr)
+}
+
+// finishOnlineBattle hangs up on the referee and says how the match ended
+func (g *Game) finishOnlineBattle() {
+	o := g.battle.online
+	o.hangUp()
+	g.battle.online = nil
+	g.endBattle()
+
+	message := "The battle was called off."
+	switch o.winner {
+	case o.side:
+		message = "You beat " + o.opponent + "!"
+	case 1 - o.side:
+		message = o.opponent + " won the battle."
+	}
+	if o.reason != "" {
+		message = o.reason + " " + message
+	}
+	if o.winner == o.side || o.winner == 1-o.side {
+		message += " " + g.recordRankedResult(o.rating, o.winner == o.side)
+	}
+	g.showDialogue(message)
+}
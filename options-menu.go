@@ -0,0 +1,200 @@
+package main
+
+import (
+	"image/color"
+	"log"
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+)
+
+// onOff labels a setting that can be switched on and off
+func onOff(on bool) string {
+	if on {
+		return "On"
+	}
+	return "Off"
+}
+
+// openOptions shows the options screen, returning to the current state when closed
+func (g *Game) openOptions() {
+	g.optionsReturn = g.gameState
+	g.gameState = StateOptions
+	g.selectedSetting, g.optionsTop = 0, 0
+}
+
+// optionLabels returns the lines of the options screen, ending with Back
+func (g *Game) optionLabels() []string {
+	return []string{
+		"Controls: " + controlsNames[g.settings.Controls],
+		"Vibration: " + onOff(g.settings.Vibration),
+		"Tap to turn: " + onOff(g.settings.TapToTurn),
+		"UI scale: " + uiScaleLabel(g.settings.UIScale),
+		"High contrast: " + onOff(g.settings.HighContrast),
+		"Screen flashes: " + onOff(!g.settings.ReduceFlashes),
+		"Animations: " + animationNames[g.settings.Animation],
+		"Font: " + fontNames[g.settings.Font],
+		"Text size: " + textSizeLabel(g.settings),
+		"Clip recording (F9): " + onOff(g.settings.Recording),
+		"Fast-forward: " + fastForwardModes[g.settings.FastForward].name,
+		"Overworld HUD: " + onOff(g.settings.HUD),
+		"Name filter: " + g.nameFilter().Name,
+		"Back",
+	}
+}
+
+// textSizeLabel names the text size, which only the scalable font has
+func textSizeLabel(settings Settings) string {
+	if settings.Font == FontPixel {
+		return "1x (Go font only)"
+	}
+	return textSizeNames[settings.TextSize]
+}
+
+// optionRows returns how many options fit on the screen at once
+func (g *Game) optionRows() int {
+	return (screenHeight - 35 - g.listTop()) / g.rowHeight()
+}
+
+// optionRects lays out the options in view
+func (g *Game) optionRects() []Rect {
+	labels := g.optionLabels()
+	rows := min(g.optionRows(), len(labels)-g.optionsTop)
+	return listRects(20, g.listTop(), g.selectorWidth()+g.widestText(labels)+10, g.rowHeight(), rows)
+}
+
+// updateOptionsMenu handles the options screen
+func (g *Game) updateOptionsMenu() {
+	count := len(g.optionLabels())
+	if g.input.IsActionJustPressed(ebiten.KeyUp) {
+		g.selectedSetting = (g.selectedSetting - 1 + count) % count
+	} else if g.input.IsActionJustPressed(ebiten.KeyDown) {
+		g.selectedSetting = (g.selectedSetting + 1) % count
+	}
+
+	row := g.selectedSetting - g.optionsTop
+	clicked := g.mouseSelect(g.optionRects(), &row)
+	g.selectedSetting = g.optionsTop + row
+
+	// Scroll to keep the selected option in view
+	if rows := g.optionRows(); g.selectedSetting < g.optionsTop {
+		g.optionsTop = g.selectedSetting
+	} else if g.selectedSetting >= g.optionsTop+rows {
+		g.optionsTop = g.selectedSetting - rows + 1
+	}
+
+	back := g.selectedSetting == count-1
+	change := g.input.IsActionJustPressed(ebiten.KeySpace) || g.input.IsActionJustPressed(ebiten.KeyEnter) || clicked ||
+		g.input.IsActionJustPressed(ebiten.KeyLeft) || g.input.IsActionJustPressed(ebiten.KeyRight)
+
+	if g.input.IsActionJustPressed(ebiten.KeyEscape) || back && change {
+		g.closeOptions()
+		return
+	}
+	if !change {
+		return
+	}
+
+	switch g.selectedSetting {
+	case 0:
+		step := 1
+		if g.input.IsActionJustPressed(ebiten.KeyLeft) {
+			step = ControlsCount - 1
+		}
+		g.settings.Controls = (g.settings.Controls + step) % ControlsCount
+	case 1:
+		g.settings.Vibration = !g.settings.Vibration
+		if g.settings.Vibration {
+			g.events.publish(Event{kind: EventBump, strength: 0.5})
+		}
+	case 2:
+		g.settings.TapToTurn = !g.settings.TapToTurn
+	case 3:
+		step := 1
+		if g.input.IsActionJustPressed(ebiten.KeyLeft) {
+			step = len(uiScales) - 1
+		}
+		g.settings.UIScale = uiScales[(g.settings.UIScale+step)%len(uiScales)]
+	case 4:
+		g.settings.HighContrast = !g.settings.HighContrast
+	case 5:
+		g.settings.ReduceFlashes = !g.settings.ReduceFlashes
+	case 6:
+		step := 1
+		if g.input.IsActionJustPressed(ebiten.KeyLeft) {
+			step = AnimationCount - 1
+		}
+		g.settings.Animation = (g.settings.Animation + step) % AnimationCount
+	case 7:
+		g.settings.Font = (g.settings.Font + 1) % FontCount
+		g.applyFont()
+	case 8:
+		step := 1
+		if g.input.IsActionJustPressed(ebiten.KeyLeft) {
+			step = len(textSizes) - 1
+		}
+		g.settings.TextSize = (g.settings.TextSize + step) % len(textSizes)
+		g.applyFont()
+	case 9:
+		g.settings.Recording = !g.settings.Recording
+		if !g.settings.Recording {
+			g.recorder.reset()
+		}
+	case 10:
+		step := 1
+		if g.input.IsActionJustPressed(ebiten.KeyLeft) {
+			step = len(fastForwardModes) - 1
+		}
+		g.settings.FastForward = (g.settings.FastForward + step) % len(fastForwardModes)
+	case 11:
+		g.settings.HUD = !g.settings.HUD
+	case 12:
+		list := locales()
+		step := 1
+		if g.input.IsActionJustPressed(ebiten.KeyLeft) {
+			step = len(list) - 1
+		}
+		g.settings.Locale = list[(slices.Index(list, g.settings.Locale)+step)%len(list)]
+	}
+}
+
+// closeOptions saves the options and goes back to where they were opened from
+func (g *Game) closeOptions() {
+	if err := saveSettings(g.settings); err != nil {
+		log.Println("Failed to save settings:", err)
+	}
+	g.gameState = g.optionsReturn
+}
+
+// drawOptionsMenu draws the options screen
+func (g *Game) drawOptionsMenu(screen *ebiten.Image) {
+	g.drawPanel(screen, 10, 10, float32(screenWidth-20), float32(screenHeight-20), color.RGBA{50, 50, 100, 240})
+
+	titleOp := &text.DrawOptions{}
+	titleOp.GeoM.Translate(20, 30)
+	titleOp.ColorScale.ScaleWithColor(g.uiText(color.White))
+	text.Draw(screen, "Options", g.fontFace, titleOp)
+
+	rects, labels := g.optionRects(), g.optionLabels()
+	for i, r := range rects {
+		label := labels[g.optionsTop+i]
+		op := &text.DrawOptions{}
+		op.GeoM.Translate(float64(r.x+g.selectorWidth()), float64(r.y))
+
+		if g.optionsTop+i == g.selectedSetting {
+			op.ColorScale.ScaleWithColor(g.uiText(color.RGBA{255, 255, 0, 255})) // Yellow for selected
+
+			selectorOp := &text.DrawOptions{}
+			selectorOp.GeoM.Translate(float64(r.x), float64(r.y))
+			selectorOp.ColorScale.ScaleWithColor(g.uiText(color.RGBA{255, 255, 0, 255}))
+			text.Draw(screen, ">", g.fontFace, selectorOp)
+		} else {
+			op.ColorScale.ScaleWithColor(g.uiText(color.White))
+		}
+
+		text.Draw(screen, label, g.fontFace, op)
+	}
+
+	g.drawHint(screen, "Arrows to navigate, Space to change, ESC to exit")
+}
@@ -1,14 +1,17 @@
 package main
 
 import (
+	"slices"
+
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
 )
 
 // Movement states for tile-based movement
 const (
 	MovementIdle = iota
 	MovementMoving
+	// Carried a tile by ice, a current or a platform rather than walking it
+	MovementCarried
 )
 
 // Direction constants
@@ -19,6 +22,56 @@ const (
 	DirectionRight
 )
 
+// DirectionNone marks no direction, such as when no move is buffered
+const DirectionNone = -1
+
+// inputBufferFrames is how close to the end of a step a direction press is
+// remembered and used for the next step
+const inputBufferFrames = 4
+
+// turnHoldFrames is how long a direction has to be held from a standstill
+// before the player walks rather than just turning to face it
+const turnHoldFrames = 6
+
+// directionKeys are the keys that move the player, indexed by direction
+var directionKeys = []struct {
+	key       ebiten.Key
+	direction int
+}{
+	{ebiten.KeyUp, DirectionUp},
+	{ebiten.KeyDown, DirectionDown},
+	{ebiten.KeyLeft, DirectionLeft},
+	{ebiten.KeyRight, DirectionRight},
+}
+
+// directionDelta returns the tile offset of one step in a direction
+func directionDelta(direction int) (int, int) {
+	switch direction {
+	case DirectionUp:
+		return 0, -1
+	case DirectionDown:
+		return 0, 1
+	case DirectionLeft:
+		return -1, 0
+	default:
+		return 1, 0
+	}
+}
+
+// oppositeDirection returns the direction facing the other way
+func oppositeDirection(direction int) int {
+	switch direction {
+	case DirectionUp:
+		return DirectionDown
+	case DirectionDown:
+		return DirectionUp
+	case DirectionLeft:
+		return DirectionRight
+	default:
+		return DirectionLeft
+	}
+}
+
 // Camera tracks the viewport
 type Camera struct {
 	x, y float32
@@ -33,9 +86,20 @@ type Player struct {
 	// Movement state tracking
 	movementState int
 	direction     int
-	frameCount    int
+	sprite        *AnimatedSprite
 	// Layer the player is currently on (for bridges, etc.)
 	currentLayer int
+	// Direction pressed near the end of the last step, taken next if no
+	// direction is held when it ends
+	buffered int
+	// Frames a direction has been held, to tell a tap to turn from a walk
+	holdFrames int
+	// Directions held down, in the order they were pressed
+	held []int
+	// Riding a creature across water
+	surfing bool
+	// Riding the bike
+	riding bool
 }
 
 // updateCamera centers the camera on the player with smooth movement
@@ -48,6 +112,18 @@ func (g *Game) updateCamera() {
 	g.camera.x += (targetX - g.camera.x) * 0.1
 	g.camera.y += (targetY - g.camera.y) * 0.1
 
+	g.clampCamera()
+}
+
+// snapCamera centers the camera on the player immediately, without smoothing
+func (g *Game) snapCamera() {
+	g.camera.x = g.player.visualX - screenWidth/2 + tileSize/2
+	g.camera.y = g.player.visualY - screenHeight/2 + tileSize/2
+	g.clampCamera()
+}
+
+// clampCamera keeps the camera inside the map, centering maps smaller than the screen
+func (g *Game) clampCamera() {
 	// Clamp camera to map bounds
 	if g.camera.x < 0 {
 		g.camera.x = 0
@@ -63,6 +139,14 @@ func (g *Game) updateCamera() {
 	if g.camera.y > maxY {
 		g.camera.y = maxY
 	}
+
+	// Small maps such as interiors are centered instead
+	if maxX < 0 {
+		g.camera.x = maxX / 2
+	}
+	if maxY < 0 {
+		g.camera.y = maxY / 2
+	}
 }
 
 // handlePlayerMovement processes player movement input
@@ -70,7 +154,7 @@ func (g *Game) handlePlayerMovement() {
 	// Variable to track if we've started movement
 	moved := false
 
-	if inpututil.IsKeyJustPressed(ebiten.KeyC) {
+	if g.input.IsActionJustPressed(ebiten.KeyC) {
 		g.gameState = StateCreatureMenu
 		g.menuSection = 0
 		g.selectedOption = 0
@@ -78,49 +162,144 @@ func (g *Game) handlePlayerMovement() {
 		return
 	}
 
-	// Handle arrow keys for movement
-	if ebiten.IsKeyPressed(ebiten.KeyUp) {
-		g.player.direction = DirectionUp
-		// Check if we can move to the target tile
-		newY := g.player.tileY - 1
-		if newY >= 0 && !g.isCollision(g.player.tileX, newY) {
-			g.player.tileY = newY
-			moved = true
-		}
-	} else if ebiten.IsKeyPressed(ebiten.KeyDown) {
-		g.player.direction = DirectionDown
-		// Check if we can move to the target tile
-		newY := g.player.tileY + 1
-		if newY < g.worldMap.height && !g.isCollision(g.player.tileX, newY) {
-			g.player.tileY = newY
-			moved = true
-		}
-	} else if ebiten.IsKeyPressed(ebiten.KeyLeft) {
-		g.player.direction = DirectionLeft
-		// Check if we can move to the target tile
-		newX := g.player.tileX - 1
-		if newX >= 0 && !g.isCollision(newX, g.player.tileY) {
-			g.player.tileX = newX
-			moved = true
-		}
-	} else if ebiten.IsKeyPressed(ebiten.KeyRight) {
-		g.player.direction = DirectionRight
-		// Check if we can move to the target tile
-		newX := g.player.tileX + 1
-		if newX < g.worldMap.width && !g.isCollision(newX, g.player.tileY) {
-			g.player.tileX = newX
+	if g.input.IsActionJustPressed(ebiten.KeyB) {
+		g.openBag()
+		return
+	}
+
+	if g.input.IsActionJustPressed(ebiten.KeyM) {
+		g.openTownMap()
+		return
+	}
+
+	if g.input.IsActionJustPressed(ebiten.KeyV) {
+		g.showToast(g.toggleBike())
+		return
+	}
+
+	if g.input.IsActionJustPressed(ebiten.KeyEnter) {
+		g.gameState = StateMenu
+		g.selectedPause = 0
+		return
+	}
+
+	// Take the held directions, latest pressed first, after any tap buffered
+	// during the last step that has since been let go, so quick taps aren't
+	// lost and zig-zagging takes each turn in the order it was pressed
+	directions := g.heldDirections()
+	if len(directions) == 0 {
+		g.player.holdFrames = 0
+	} else {
+		g.player.holdFrames++
+	}
+	buffered := g.player.buffered != DirectionNone && (len(directions) == 0 || directions[0] != g.player.buffered)
+	if buffered {
+		directions = append([]int{g.player.buffered}, directions...)
+	}
+	g.player.buffered = DirectionNone
+
+	// A quick tap of a new direction from a standstill only turns to face it
+	if g.settings.TapToTurn && !buffered && len(directions) > 0 &&
+		directions[0] != g.player.direction && g.player.holdFrames < turnHoldFrames {
+		g.player.direction = directions[0]
+		return
+	}
+
+	// Step the first way that's open, so holding two directions slides
+	// around corners instead of stopping against them
+	for _, direction := range directions {
+		if g.canStep(direction) {
+			dx, dy := directionDelta(direction)
+			g.player.direction = direction
+			g.player.tileX += dx
+			g.player.tileY += dy
 			moved = true
+			break
 		}
 	}
+	if !moved && len(directions) > 0 {
+		g.player.direction = directions[0]
+	}
+
+	// Walking into a boulder pushes it
+	if !moved && (g.arrowJustPressed() || buffered) {
+		g.events.publish(Event{kind: EventBump, strength: 0.2})
+		g.pushBoulder()
+	}
 
-	// If we moved, update the movement state
+	// If we moved, update the movement state; turning while walking
+	// doesn't need a pause
 	if moved {
+		dx, dy := directionDelta(g.player.direction)
+		g.follow(g.player.tileX-dx, g.player.tileY-dy)
 		g.player.movementState = MovementMoving
+		g.player.holdFrames = turnHoldFrames
 	}
 }
 
-// isCollision checks if a tile is impassable
-func (g *Game) isCollision(x, y int) bool {
-	key := formatCoord(x, y)
-	return g.worldMap.collisionMap[key]
+// updateHeldDirections keeps track of the order the held direction keys were
+// pressed in, dropping any that have been let go
+func (g *Game) updateHeldDirections() {
+	held := make([]int, 0, len(directionKeys))
+	for _, direction := range g.player.held {
+		if g.input.IsActionPressed(directionKeys[direction].key) {
+			held = append(held, direction)
+		}
+	}
+	for _, k := range directionKeys {
+		if g.input.IsActionPressed(k.key) && !slices.Contains(held, k.direction) {
+			held = append(held, k.direction)
+		}
+	}
+	g.player.held = held
+}
+
+// heldDirections returns the directions held down, the latest pressed first
+func (g *Game) heldDirections() []int {
+	directions := slices.Clone(g.player.held)
+	slices.Reverse(directions)
+	return directions
+}
+
+// canStep reports whether the player can walk one tile in a direction, or
+// surf it, or step onto a platform there
+func (g *Game) canStep(direction int) bool {
+	dx, dy := directionDelta(direction)
+	x, y := g.player.tileX+dx, g.player.tileY+dy
+	switch {
+	case x < 0 || y < 0 || x >= g.worldMap.width || y >= g.worldMap.height:
+		return false
+	case g.worldMap.platformAt(x, y) != nil, g.player.surfing && g.worldMap.IsWater(x, y):
+		return true
+	}
+	return !g.worldMap.IsCollision(x, y)
+}
+
+// carryPlayer moves the player a tile without them walking it, as ice,
+// currents and platforms do. The step ends like a walked one.
+func (g *Game) carryPlayer(dx, dy int) {
+	x, y := g.player.tileX, g.player.tileY
+	g.player.tileX, g.player.tileY = x+dx, y+dy
+	g.follow(x, y)
+	g.player.movementState = MovementCarried
+}
+
+// bufferDirection remembers a direction pressed in the last few frames of a step
+func (g *Game) bufferDirection(remaining float32, speed float32) {
+	if remaining > speed*inputBufferFrames {
+		return
+	}
+	for _, k := range directionKeys {
+		if g.input.IsActionJustPressed(k.key) {
+			g.player.buffered = k.direction
+		}
+	}
+}
+
+// abs32 returns the absolute value of x
+func abs32(x float32) float32 {
+	if x < 0 {
+		return -x
+	}
+	return x
 }
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"image/color"
+	"math/rand"
+	"strconv"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Quest kind constants
+const (
+	// Defeat a number of wild creatures of one species in a region
+	QuestDefeat = iota
+	// Bring a number of an item to the crafter in a region's heal center
+	QuestDeliver
+)
+
+// questsPerDay is how many tasks are pinned to the bulletin boards each day
+const questsPerDay = 3
+
+// Quest is a task taken from a bulletin board. Tasks are made up fresh each
+// day, and pay more the harder they are and the more badges the player has.
+type Quest struct {
+	Kind    int    `json:"kind"`
+	Region  string `json:"region"`
+	Species string `json:"species,omitempty"`
+	Item    string `json:"item,omitempty"`
+	Count   int    `json:"count"`
+	// Creatures defeated so far, for a defeat task
+	Progress int `json:"progress,omitempty"`
+	Reward   int `json:"reward"`
+}
+
+// QuestLog is the task the player has taken, if any, and which of the day's
+// postings they've taken already
+type QuestLog struct {
+	active *Quest
+	day    int
+	taken  [questsPerDay]bool
+}
+
+// text describes a task, like it's written on the board
+func (q *Quest) text() string {
+	if q.Kind == QuestDeliver {
+		return "Bring " + strconv.Itoa(q.Count) + " " + q.Item + " to the crafter in " + q.Region + "."
+	}
+	return "Defeat " + strconv.Itoa(q.Count) + " wild " + q.Species + " in " + q.Region + "."
+}
+
+// done reports whether a defeat task has been carried out; delivery tasks
+// are done the moment they're handed over
+func (q *Quest) done() bool {
+	return q.Kind == QuestDefeat && q.Progress >= q.Count
+}
+
+// addBulletinBoard pins a bulletin board of tasks to the wall of a heal center
+func (m *Map) addBulletinBoard() {
+	m.objects[Point{m.width - 2, 1}] = &MapObject{kind: ObjectBulletinBoard}
+}
+
+// questPostings makes up the day's tasks, which are the same on every board
+func (g *Game) questPostings(day int) []Quest {
+	overworld := g.maps[overworldID]
+	plan := overworld.plan
+	rng := rand.New(rand.NewSource(overworld.seed + int64(day)*7877))
+	bonus := 100 + 25*g.badges
+
+	var postings []Quest
+	for range questsPerDay {
+		region := plan.regions[rng.Intn(len(plan.regions))]
+		if rng.Intn(2) == 0 {
+			deliverable := append(append(append([]string(nil), berryTrees...), caveMinerals...), junkItems...)
+			item := deliverable[rng.Intn(len(deliverable))]
+			count := 1 + rng.Intn(3)
+			postings = append(postings, Quest{
+				Kind:   QuestDeliver,
+				Region: region.name,
+				Item:   item,
+				Count:  count,
+				Reward: count * (findItem(item).price + 60) * 3 * bonus / 100,
+			})
+			continue
+		}
+
+		table := biomes[overworld.BiomeAt(region.center.x, region.center.y)].encounters
+		entry := table[rng.Intn(len(table))]
+		count := 3 + rng.Intn(3)
+		postings = append(postings, Quest{
+			Kind:    QuestDefeat,
+			Region:  region.name,
+			Species: entry.species,
+			Count:   count,
+			Reward:  count * entry.maxLevel * 20 * bonus / 100,
+		})
+	}
+	return postings
+}
+
+// subscribeQuests counts wild creatures defeated towards a defeat task
+func (g *Game) subscribeQuests() {
+	g.events.subscribe(EventDefeat, func(e Event) {
+		q := g.quests.active
+		if q == nil || q.Kind != QuestDefeat || q.done() || g.battle.trainer != "" {
+			return
+		}
+		if e.species != q.Species || g.locationName() != q.Region {
+			return
+		}
+		q.Progress++
+		if q.done() {
+			g.showToast("Task done! Report back to a bulletin board.")
+		} else {
+			g.showToast(q.Species + " defeated: " + strconv.Itoa(q.Progress) + "/" + strconv.Itoa(q.Count))
+		}
+	})
+}
+
+// readBulletinBoard pays out for a finished task, reports on one under way,
+// or offers the day's tasks still open
+func (g *Game) readBulletinBoard() {
+	log := &g.quests
+	if log.day != g.calendar.day {
+		log.day, log.taken = g.calendar.day, [questsPerDay]bool{}
+	}
+
+	if q := log.active; q != nil {
+		if q.done() {
+			g.finishQuest()
+			return
+		}
+		progress := ""
+		if q.Kind == QuestDefeat {
+			progress = " (" + strconv.Itoa(q.Progress) + "/" + strconv.Itoa(q.Count) + " so far)"
+		}
+		g.showPrompt("Your task: "+q.text()+progress+" Give up on it?", func() {
+			log.active = nil
+			g.showDialogue("You took your task off the board. Someone else will see to it.")
+		})
+		return
+	}
+
+	var open []int
+	for i := range questsPerDay {
+		if !log.taken[i] {
+			open = append(open, i)
+		}
+	}
+	if len(open) == 0 {
+		g.showDialogue("Every task on the board has been taken. New ones are pinned up each day.")
+		return
+	}
+	g.offerQuests(g.questPostings(log.day), open)
+}
+
+// offerQuests asks about each open posting in turn until one is taken
+func (g *Game) offerQuests(postings []Quest, open []int) {
+	log := &g.quests
+	q := postings[open[0]]
+	g.showPrompt("Task: "+q.text()+" Reward: $"+strconv.Itoa(q.Reward)+". Take it?", func() {
+		log.active, log.taken[open[0]] = &q, true
+		g.showDialogue("You took the task. Come back to any bulletin board when it's done.")
+	})
+	if len(open) > 1 {
+		g.dialogue.after = func() {
+			if log.active == nil {
+				g.offerQuests(postings, open[1:])
+			}
+		}
+	}
+}
+
+// deliverQuest hands over the items for a delivery task, if the crafter
+// being talked to is the one waiting for them, reporting whether they were
+func (g *Game) deliverQuest() bool {
+	q := g.quests.active
+	if q == nil || q.Kind != QuestDeliver || g.locationName() != q.Region {
+		return false
+	}
+	if g.bag[q.Item] < q.Count {
+		g.showDialogue("Did you see my note on the board? I need " + strconv.Itoa(q.Count) + " " + q.Item + ". You have " + strconv.Itoa(g.bag[q.Item]) + " so far.")
+		return true
+	}
+	g.showPrompt("Are those the "+strconv.Itoa(q.Count)+" "+q.Item+" I asked for on the board? Hand them over?", func() {
+		for range q.Count {
+			g.removeItem(q.Item)
+		}
+		g.finishQuest()
+	})
+	return true
+}
+
+// finishQuest pays the reward for the active task
+func (g *Game) finishQuest() {
+	q := g.quests.active
+	g.quests.active = nil
+	g.money += q.Reward
+	g.audio.playSound("fanfare")
+	g.showToast("Task complete! Earned $" + strconv.Itoa(q.Reward) + ".")
+	g.showDialogue("Thanks for your help! Here's the $" + strconv.Itoa(q.Reward) + " reward.")
+}
+
+// drawBulletinBoard draws a cork board with notes pinned to it
+func (g *Game) drawBulletinBoard(screen *ebiten.Image, x, y float32) {
+	vector.DrawFilledRect(screen, x-12, y-10, 24, 18, color.RGBA{110, 75, 40, 255}, true)
+	vector.DrawFilledRect(screen, x-10, y-8, 20, 14, color.RGBA{190, 140, 90, 255}, true)
+	for _, note := range [][2]float32{{-8, -6}, {-1, -7}, {3, -2}} {
+		vector.DrawFilledRect(screen, x+note[0], y+note[1], 6, 7, color.RGBA{245, 240, 225, 255}, true)
+		vector.DrawFilledCircle(screen, x+note[0]+3, y+note[1], 1, color.RGBA{200, 40, 40, 255}, true)
+	}
+}
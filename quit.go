@@ -0,0 +1,143 @@
+package main
+
+import (
+	"image/color"
+	"log"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// shutdownTimeout is the longest quitting waits on a sync or clip still
+// being written in the background
+const shutdownTimeout = 5 * time.Second
+
+// QuitPrompt asks whether to save before quitting
+type QuitPrompt struct {
+	open     bool
+	selected int
+	// The player chose to quit, which ends the game after this Update
+	confirmed bool
+}
+
+// subscribeUnsaved notes progress made since the game was last saved or
+// loaded: walking, battling, catching and being given things
+func (g *Game) subscribeUnsaved() {
+	for _, kind := range []int{EventStep, EventDefeat, EventCapture, EventReceive} {
+		g.events.subscribe(kind, func(Event) { g.unsaved = true })
+	}
+}
+
+// quitChoices returns the options on the quit prompt; saving is only offered
+// when there's progress to lose
+func (g *Game) quitChoices() []string {
+	if g.unsaved {
+		return []string{"Save & Quit", "Quit", "Cancel"}
+	}
+	return []string{"Quit", "Cancel"}
+}
+
+// requestQuit asks before quitting. Closing the window quits straight away
+// when nothing would be lost; quitting from a menu always asks.
+func (g *Game) requestQuit(windowClosed bool) {
+	if windowClosed && !g.unsaved {
+		g.quit.confirmed = true
+		return
+	}
+	g.quit = QuitPrompt{open: true}
+}
+
+// updateQuitPrompt handles the quit prompt, which takes all input while open
+func (g *Game) updateQuitPrompt() {
+	choices := g.quitChoices()
+	if g.input.IsActionJustPressed(ebiten.KeyUp) {
+		g.quit.selected = (g.quit.selected - 1 + len(choices)) % len(choices)
+	} else if g.input.IsActionJustPressed(ebiten.KeyDown) {
+		g.quit.selected = (g.quit.selected + 1) % len(choices)
+	}
+
+	if g.input.IsActionJustPressed(ebiten.KeyEscape) {
+		g.quit.open = false
+		return
+	}
+
+	clicked := g.mouseSelect(g.quitRects(), &g.quit.selected)
+
+	if g.input.IsActionJustPressed(ebiten.KeySpace) || g.input.IsActionJustPressed(ebiten.KeyEnter) || clicked {
+		switch choices[g.quit.selected] {
+		case "Save & Quit":
+			g.quit.open = false
+			if err := g.saveGame(); err != nil {
+				log.Println("Failed to save:", err)
+				g.showDialogue("The game could not be saved.")
+				return
+			}
+			g.quit.confirmed = true
+		case "Quit":
+			g.quit.confirmed = true
+		case "Cancel":
+			g.quit.open = false
+		}
+	}
+}
+
+// quitRects lays out the quit prompt's options in the middle of the screen
+func (g *Game) quitRects() []Rect {
+	choices := g.quitChoices()
+	width := g.selectorWidth() + g.widestText(choices) + 10
+	top := screenHeight/2 - len(choices)*g.rowHeight()/2 + g.lineSpacing()/2
+	return listRects(screenWidth/2-width/2, top, width, g.rowHeight(), len(choices))
+}
+
+// drawQuitPrompt draws the quit prompt over whatever is on screen
+func (g *Game) drawQuitPrompt(screen *ebiten.Image) {
+	if !g.quit.open {
+		return
+	}
+
+	title := "Quit the game?"
+	if g.unsaved {
+		title = "Save before quitting?"
+	}
+	rects := g.quitRects()
+	width := max(g.textWidth(title), rects[0].width) + 24
+	x := float32(screenWidth/2 - width/2)
+	y := float32(rects[0].y - g.lineSpacing() - 14)
+	height := float32(rects[len(rects)-1].y+g.rowHeight()+6) - y
+	g.drawPanel(screen, x, y, float32(width), height, color.RGBA{50, 50, 100, 240})
+	g.drawText(screen, title, float64(x+12), float64(y+8), color.White)
+
+	for i, choice := range g.quitChoices() {
+		clr := color.Color(color.White)
+		if i == g.quit.selected {
+			clr = color.RGBA{255, 255, 0, 255}
+			g.drawText(screen, ">", float64(rects[i].x), float64(rects[i].y), clr)
+		}
+		g.drawText(screen, choice, float64(rects[i].x+g.selectorWidth()), float64(rects[i].y), clr)
+	}
+}
+
+// shutdown lets work still going on in the background finish once the game
+// has ended: a save being synced and a clip being written. Sounds need no
+// cleaning up, as each plays on a player of its own.
+func (g *Game) shutdown() {
+	deadline := time.After(shutdownTimeout)
+	if g.sync != nil && g.sync.busy {
+		select {
+		case result := <-g.sync.results:
+			log.Println("Save sync:", result.status)
+		case <-deadline:
+			log.Println("Gave up waiting for the save to sync")
+		}
+	}
+	if g.recorder.encoding {
+		select {
+		case result := <-g.recorder.results:
+			if result.err != nil {
+				log.Println("Failed to save clip:", result.err)
+			}
+		case <-deadline:
+			log.Println("Gave up waiting for the clip to save")
+		}
+	}
+}
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"image/color"
+	"math"
+	"math/rand"
+	"strconv"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Raid constants
+const (
+	// Levels a raid creature has over the wild ones around its den, how many
+	// times their HP it has, and the percent its attack and defense are raised
+	raidLevelBonus = 10
+	raidHPScale    = 5
+	raidStatBoost  = 20
+	// Shields raised each time, and the share of a hit's damage that gets
+	// through one
+	raidShields       = 3
+	raidShieldDivisor = 4
+	// Chance of the giant creature turning on the ally instead of the lead
+	raidAllyTargetChance = 0.4
+	// Chance of the ball thrown after winning catching the creature
+	raidCatchChance = 0.8
+)
+
+// raidShieldAt are the shares of its HP at which a raid creature raises its
+// shields
+var raidShieldAt = []float64{2.0 / 3, 1.0 / 3}
+
+// RaidBattle is the state of a raid in progress: one giant creature out of a
+// den, against the player's lead and an ally fighting alongside it
+type RaidBattle struct {
+	den Point
+	// Shields up now, and how many times they've been raised
+	shields, raised int
+	// The second creature fighting, from the party or lent by a friend, and
+	// whether it has attacked this turn
+	ally        *Creature
+	allyTrainer string
+	allyActed   bool
+}
+
+// placeRaidDens sets a raid den in the grass beside a path in a few chunks,
+// whose top-left tile is originX, originY
+func (m *Map) placeRaidDens(c *Chunk, rng *rand.Rand, originX, originY int) {
+	if rng.Float32() >= 0.06 {
+		return
+	}
+
+	for y := 1; y < chunkSize-1; y++ {
+		for x := 1; x < chunkSize-1; x++ {
+			if c.tiles[LayerBase][y][x] != TileGrass || c.collisionMap.get(y*chunkSize+x) {
+				continue
+			}
+			besidePath := c.tiles[LayerBase][y-1][x] == TilePath || c.tiles[LayerBase][y+1][x] == TilePath ||
+				c.tiles[LayerBase][y][x-1] == TilePath || c.tiles[LayerBase][y][x+1] == TilePath
+			if !besidePath || rng.Float32() >= 0.2 {
+				continue
+			}
+
+			pos := Point{originX + x, originY + y}
+			if m.objects[pos] == nil {
+				m.objects[pos] = &MapObject{kind: ObjectRaidDen}
+			}
+			return
+		}
+	}
+}
+
+// raidReady reports whether a den has a giant creature in it today
+func (g *Game) raidReady(pos Point) bool {
+	day, beaten := g.raidsBeaten[pos]
+	return !beaten || day != g.calendar.day
+}
+
+// raidCreature returns the giant creature in a den today: one of the kinds
+// living in the grass around it, a good few levels stronger. The same den
+// holds the same creature all day.
+func (g *Game) raidCreature(pos Point) Creature {
+	rng := rand.New(rand.NewSource(g.worldMap.seed + int64(pos.x)*7919 + int64(pos.y)*104729 + int64(g.calendar.day)))
+	biome := g.worldMap.BiomeAt(pos.x, pos.y)
+	table := biomes[biome].encounters
+
+	total := 0
+	for _, entry := range table {
+		total += entry.weight
+	}
+	entry := table[0]
+	roll := rng.Intn(total)
+	for _, e := range table {
+		if roll < e.weight {
+			entry = e
+			break
+		}
+		roll -= e.weight
+	}
+	level := min(entry.maxLevel+raidLevelBonus, maxLevel)
+
+	c := newCreatureForm(entry.species, regionalForm(entry.species, biome), level)
+	c.maxHP *= raidHPScale
+	c.hp = c.maxHP
+	c.attack += c.attack * raidStatBoost / 100
+	c.defense += c.defense * raidStatBoost / 100
+	return c
+}
+
+// talkToRaidDen offers to take on the giant creature in a den
+func (g *Game) talkToRaidDen(pos Point) {
+	if !g.raidReady(pos) {
+		g.showDialogue("The den is quiet now. Another giant creature may gather here tomorrow.")
+		return
+	}
+	if g.nextHealthyCreature() == nil {
+		g.showDialogue("Something huge is glowing inside the den, but your creatures are too worn out to take it on.")
+		return
+	}
+
+	enemy := g.raidCreature(pos)
+	message := "A giant " + formName(enemy.name, enemy.form) + " is glowing inside the den! Take it on?"
+	if g.raidAlly != nil {
+		message = "A giant " + formName(enemy.name, enemy.form) + " is glowing inside the den! " + g.raidAllyTrainer + "'s " + g.raidAlly.name + " is ready to help. Take it on?"
+	}
+	g.showPrompt(message, func() {
+		g.startRaid(pos, enemy)
+	})
+}
+
+// startRaid starts a raid battle against the creature in a den, with an ally
+// fighting alongside the lead: a friend's lent creature if there is one, or
+// else the next healthy creature in the party
+func (g *Game) startRaid(pos Point, enemy Creature) {
+	g.setUpBattle(enemy)
+	r := &RaidBattle{den: pos}
+	g.battle.raid = r
+	g.battle.announcement = "A giant " + formName(enemy.name, enemy.form) + " burst out of the den!"
+
+	if g.raidAlly != nil {
+		ally := *g.raidAlly
+		ally.heal()
+		r.ally, r.allyTrainer = &ally, g.raidAllyTrainer
+		return
+	}
+	for i := range g.creatures {
+		c := &g.creatures[i]
+		if c != g.battle.playerCreature && c.hp > 0 {
+			r.ally = c
+			return
+		}
+	}
+}
+
+// absorb lets a shield take most of a hit, breaking it, and reports whether
+// one did. Hits that do nothing leave the shields alone.
+func (r *RaidBattle) absorb(damage int) (int, bool) {
+	if r.shields <= 0 || damage <= 0 {
+		return damage, false
+	}
+	r.shields--
+	return max(damage/raidShieldDivisor, 1), true
+}
+
+// bestMove returns the move that would hit a target hardest
+func bestMove(attacker, target Creature) Move {
+	best, bestPower := attacker.moves[0], -1.0
+	for _, move := range attacker.moves {
+		power := float64(move.power) * float64(typeEffectiveness(move.type1, target.type1))
+		if power > bestPower {
+			best, bestPower = move, power
+		}
+	}
+	return best
+}
+
+// updateRaid plays the parts of a raid turn around the lead's move and the
+// giant creature's attack on it: raising shields as its HP runs down, the
+// ally attacking, and the giant creature turning on the ally instead. It
+// reports whether any of these took the step, in which case the battle
+// waits for its text before going on.
+func (g *Game) updateRaid() bool {
+	r := g.battle.raid
+	enemy := &g.battle.enemyCreature
+
+	// An ally from the party sent out to replace a fainted lead takes its
+	// place for good
+	if r.ally == g.battle.playerCreature {
+		r.ally = nil
+	}
+
+	if r.raised < len(raidShieldAt) && float64(enemy.hp) <= raidShieldAt[r.raised]*float64(enemy.maxHP) {
+		r.raised++
+		r.shields = raidShields
+		g.battle.battleText = "The giant " + enemy.name + " raised " + strconv.Itoa(raidShields) + " shields! Hits will barely get through until they break."
+		g.battle.battleTextTimer = 80
+		g.events.publish(Event{kind: EventHeavyHit, strength: 0.4})
+		return true
+	}
+
+	ally := r.ally
+	if ally == nil || ally.hp <= 0 {
+		r.allyActed = false
+		return false
+	}
+
+	// The ally attacks after the lead, and doesn't use up PP
+	if !r.allyActed {
+		r.allyActed = true
+		move := bestMove(*ally, *enemy)
+		hit := calculateDamage(*ally, *enemy, move)
+		var broke bool
+		hit.damage, broke = r.absorb(hit.damage)
+		g.queueMoveAnimation(move, true)
+		g.queueHitPopup(hit, true)
+		enemy.hp = max(enemy.hp-hit.damage, 0)
+		g.publishHit(hit.damage, enemy.maxHP)
+		g.battle.battleText = ally.name + " used " + move.name + "!"
+		if broke {
+			g.battle.battleText += " A shield broke!"
+		}
+		g.battle.battleTextTimer = 60
+		return true
+	}
+
+	r.allyActed = false
+	if rand.Float64() >= raidAllyTargetChance {
+		return false
+	}
+
+	move := enemy.moves[rand.Intn(len(enemy.moves))]
+	hit := calculateDamage(*enemy, *ally, move)
+	ally.hp = max(ally.hp-hit.damage, 0)
+	g.battle.battleText = enemy.name + " used " + move.name + " on " + ally.name + "!"
+	if ally.hp <= 0 {
+		g.battle.battleText += " " + ally.name + " fainted!"
+	}
+	g.battle.battleTextTimer = 60
+	g.battle.currentTurn = 0
+	return true
+}
+
+// finishRaid marks a den as beaten for the day and gives the player a free
+// throw at the worn-out creature
+func (g *Game) finishRaid(r *RaidBattle) {
+	g.raidsBeaten[r.den] = g.calendar.day
+	beaten := g.battle.enemyCreature
+	g.showPrompt("The giant "+beaten.name+" is worn out and shrinking back to size! Throw a ball at it?", func() {
+		g.audio.playSound("ball")
+		g.audio.playSting("capture")
+		if rand.Float64() >= raidCatchChance {
+			g.showDialogue("Oh no! The " + beaten.name + " broke free and slipped back into the den.")
+			return
+		}
+		caught := newCreatureForm(beaten.name, beaten.form, beaten.level)
+		g.showDialogue("Gotcha! " + caught.name + " was caught! " + g.catchCreature(caught))
+	})
+}
+
+// lendToRaids keeps a creature a friend lent over the trade link, to fight
+// alongside the player in raids
+func (g *Game) lendToRaids(c Creature, trainer string) {
+	g.raidAlly, g.raidAllyTrainer = &c, trainer
+}
+
+// drawRaidBar draws the giant creature's HP bar across the top of the
+// screen, with its shields beside its name
+func (g *Game) drawRaidBar(screen *ebiten.Image) {
+	r := g.battle.raid
+	enemy := &g.battle.enemyCreature
+	x, y, width := float32(20), float32(8+g.lineHeight()), float32(screenWidth-40)
+
+	title := g.creatureLabel("Giant ", enemy, enemy.level)
+	g.drawText(screen, title, float64(x), 4, color.White)
+
+	// Shields as small blue squares after the name
+	shieldX := x + float32(g.textWidth(title)) + 8
+	shieldY := float32(4+g.lineHeight()/2) - 4
+	for i := range r.shields {
+		vector.DrawFilledRect(screen, shieldX+float32(i)*11, shieldY, 8, 8, color.RGBA{120, 200, 255, 255}, true)
+		vector.StrokeRect(screen, shieldX+float32(i)*11, shieldY, 8, 8, 1, color.White, true)
+	}
+
+	ratio := g.battle.enemyBar.shown(g.hpBarLength()) / float32(enemy.maxHP)
+	vector.DrawFilledRect(screen, x-1, y-1, width+2, 10, color.RGBA{20, 20, 20, 255}, true)
+	vector.DrawFilledRect(screen, x, y, width, 8, color.RGBA{100, 100, 100, 255}, true)
+	vector.DrawFilledRect(screen, x, y, width*ratio, 8, hpColor(ratio), true)
+	if r.shields > 0 {
+		vector.StrokeRect(screen, x-2, y-2, width+4, 12, 1.5, color.RGBA{120, 200, 255, 255}, true)
+	}
+}
+
+// drawRaidAlly draws the ally fighting beside the lead, with its name and HP
+func (g *Game) drawRaidAlly(screen *ebiten.Image) {
+	ally := g.battle.raid.ally
+	if ally == nil {
+		return
+	}
+	x, y, size := float32(200), float32(g.battleUITop()-30), float32(32)
+	clr := ally.color
+	if ally.hp <= 0 {
+		clr = shade(clr, 0.4)
+	}
+	drawBattler(screen, x, y, size, 1, clr)
+
+	name := g.cachedLabel(labelKey{prefix: " Lv.", name: ally.name, a: ally.level}, func() string {
+		return ally.name + " Lv." + strconv.Itoa(ally.level)
+	})
+	if trainer := g.battle.raid.allyTrainer; trainer != "" {
+		name = g.cachedLabel(labelKey{prefix: trainer + "'s ", name: ally.name}, func() string {
+			return trainer + "'s " + ally.name
+		})
+	}
+	g.drawText(screen, name, float64(x), float64(y)-25, color.White)
+
+	ratio := float32(ally.hp) / float32(ally.maxHP)
+	vector.DrawFilledRect(screen, x, y-12, size*2, 5, color.RGBA{100, 100, 100, 255}, true)
+	vector.DrawFilledRect(screen, x, y-12, size*2*ratio, 5, hpColor(ratio), true)
+}
+
+// drawRaidDen draws a ring of stones around a den, glowing while a giant
+// creature is inside
+func (g *Game) drawRaidDen(screen *ebiten.Image, x, y float32, ready bool) {
+	if ready {
+		pulse := float32(0.5 + 0.5*math.Sin(float64(g.ticks)/15))
+		vector.DrawFilledCircle(screen, x, y, 9+3*pulse, color.RGBA{200, 90, 255, uint8(90 + 80*pulse)}, true)
+	}
+	vector.DrawFilledCircle(screen, x, y, 7, color.RGBA{40, 30, 50, 255}, true)
+	for i := range 8 {
+		angle := float64(i) * math.Pi / 4
+		vector.DrawFilledCircle(screen, x+float32(math.Cos(angle))*11, y+float32(math.Sin(angle))*11, 3, color.RGBA{130, 125, 120, 255}, true)
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"math"
+	"strconv"
+)
+
+// Ranked rating constants
+const (
+	// Rating a player starts at before their first ranked battle
+	startingRating = 1000
+	// Most a rating moves after one battle
+	eloK = 32
+	// Lowest a rating can fall to
+	minRating = 100
+)
+
+// RankedRecord is how the player has done in ranked battles. The rating is
+// an Elo rating kept by the game itself, from the ratings opponents say they
+// have, so it's only a guide until a server keeps track.
+type RankedRecord struct {
+	// Zero until the first ranked battle
+	Rating int `json:"rating,omitempty"`
+	Wins   int `json:"wins,omitempty"`
+	Losses int `json:"losses,omitempty"`
+}
+
+// rating returns the player's rating, or the starting one if they haven't
+// played yet
+func (r RankedRecord) rating() int {
+	if r.Rating == 0 {
+		return startingRating
+	}
+	return r.Rating
+}
+
+// text describes the record, like "Rating 1016 (3-2)"
+func (r RankedRecord) text() string {
+	return "Rating " + strconv.Itoa(r.rating()) + " (" + strconv.Itoa(r.Wins) + "-" + strconv.Itoa(r.Losses) + ")"
+}
+
+// expectedScore is the chance Elo gives a player rated mine of beating one
+// rated theirs
+func expectedScore(mine, theirs int) float64 {
+	return 1 / (1 + math.Pow(10, float64(theirs-mine)/400))
+}
+
+// ratingChange is how much a rating moves after a battle against an opponent
+// with the given rating, scoring 1 for a win and 0 for a loss
+func ratingChange(mine, theirs int, score float64) int {
+	return int(math.Round(eloK * (score - expectedScore(mine, theirs))))
+}
+
+// recordRankedResult updates the player's rating after a ranked battle
+// against an opponent with the given rating, or the starting one if their
+// game didn't say, and describes the change
+func (g *Game) recordRankedResult(opponent int, won bool) string {
+	if opponent <= 0 {
+		opponent = startingRating
+	}
+	r := &g.ranked
+	score := 0.0
+	if won {
+		score = 1
+		r.Wins++
+	} else {
+		r.Losses++
+	}
+	before := r.rating()
+	r.Rating = max(before+ratingChange(before, opponent, score), minRating)
+
+	switch {
+	case r.Rating > before:
+		return "Your rating rose to " + strconv.Itoa(r.Rating) + " (+" + strconv.Itoa(r.Rating-before) + ")."
+	case r.Rating < before:
+		return "Your rating fell to " + strconv.Itoa(r.Rating) + " (" + strconv.Itoa(r.Rating-before) + ")."
+	}
+	return "Your rating stayed at " + strconv.Itoa(r.Rating) + "."
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+
+	"creaturegame-2/engine"
+)
+
+// gameMoves returns every move a creature can know, by name: those of each
+// species and form, those learned on evolving and those the tutor teaches
+func gameMoves() map[string]Move {
+	moves := make(map[string]Move)
+	for _, s := range speciesList {
+		for _, m := range s.moves {
+			moves[m.name] = m
+		}
+		for _, f := range s.forms {
+			for _, m := range f.moves {
+				moves[m.name] = m
+			}
+		}
+		for _, l := range s.learnset {
+			moves[l.move.name] = l.move
+		}
+	}
+	for _, t := range tutorMoves {
+		moves[t.move.name] = t.move
+	}
+	return moves
+}
+
+func TestEngineMoves(t *testing.T) {
+	moves := gameMoves()
+	for name, m := range moves {
+		want := m.engineMove()
+		want.PP = 0
+		if got, ok := engine.Moves[name]; !ok || got != want {
+			t.Errorf("the referee knows %s as %+v, want %+v", name, got, want)
+		}
+	}
+	if len(engine.Moves) != len(moves) {
+		t.Errorf("the referee knows %d moves, want the game's %d", len(engine.Moves), len(moves))
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Clip recording constants
+const (
+	// Frames kept, and the time between them, for about ten seconds of play
+	clipFrames   = 200
+	clipInterval = 50 * time.Millisecond
+	// Clips are recorded at a fraction of the screen size to keep them small
+	clipScale = 2
+)
+
+// Recorder keeps the last few seconds of frames in a ring buffer while clip
+// recording is on, so they can be saved as an animated GIF. Encoding happens
+// in the background so saving a clip doesn't stall the game.
+type Recorder struct {
+	small  *ebiten.Image
+	frames []*image.RGBA
+	// Slot the next frame goes in, how many slots are filled, and when the
+	// last frame was taken
+	next, count int
+	last        time.Time
+	results     chan ClipResult
+	encoding    bool
+}
+
+// ClipResult reports where a saved clip went, or why it couldn't be saved
+type ClipResult struct {
+	where string
+	err   error
+}
+
+// capture adds the finished frame to the ring buffer if it's time for
+// another one
+func (r *Recorder) capture(screen *ebiten.Image) {
+	now := time.Now()
+	if now.Sub(r.last) < clipInterval {
+		return
+	}
+	r.last = now
+
+	if r.small == nil {
+		r.small = ebiten.NewImage(screenWidth/clipScale, screenHeight/clipScale)
+		r.frames = make([]*image.RGBA, clipFrames)
+	}
+	r.small.Clear()
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(1.0/clipScale, 1.0/clipScale)
+	op.Filter = ebiten.FilterLinear
+	r.small.DrawImage(screen, op)
+
+	// Slots handed off to be encoded are nil, and get a new image
+	frame := r.frames[r.next]
+	if frame == nil {
+		frame = image.NewRGBA(r.small.Bounds())
+		r.frames[r.next] = frame
+	}
+	r.small.ReadPixels(frame.Pix)
+	r.next = (r.next + 1) % clipFrames
+	r.count = min(r.count+1, clipFrames)
+}
+
+// reset throws away the frames recorded so far, for when recording is
+// switched off
+func (r *Recorder) reset() {
+	r.small, r.frames = nil, nil
+	r.next, r.count = 0, 0
+}
+
+// save starts encoding the frames recorded so far into a GIF in the
+// background, reporting false if there's nothing to save or a clip is
+// still being saved
+func (r *Recorder) save() bool {
+	if r.count == 0 || r.encoding {
+		return false
+	}
+
+	// Oldest first, leaving the slots empty so capturing carries on into new
+	// images while these are encoded
+	frames := make([]*image.RGBA, 0, r.count)
+	for i := range r.count {
+		slot := (r.next - r.count + i + clipFrames) % clipFrames
+		frames = append(frames, r.frames[slot])
+		r.frames[slot] = nil
+	}
+	r.count = 0
+
+	if r.results == nil {
+		r.results = make(chan ClipResult, 1)
+	}
+	r.encoding = true
+	name := "clips/clip-" + time.Now().Format("20060102-150405") + ".gif"
+	go func() {
+		data, err := encodeClip(frames)
+		if err != nil {
+			r.results <- ClipResult{err: err}
+			return
+		}
+		where, err := writeClip(name, data)
+		r.results <- ClipResult{where: where, err: err}
+	}()
+	return true
+}
+
+// poll collects a finished clip, if any, on the game's goroutine
+func (r *Recorder) poll() (ClipResult, bool) {
+	select {
+	case result := <-r.results:
+		r.encoding = false
+		return result, true
+	default:
+		return ClipResult{}, false
+	}
+}
+
+// encodeClip turns frames into a looping GIF. The game only uses a handful
+// of colors, so each one is matched to the palette once and remembered.
+func encodeClip(frames []*image.RGBA) ([]byte, error) {
+	clip := &gif.GIF{}
+	nearest := make(map[color.RGBA]uint8)
+	for _, frame := range frames {
+		bounds := frame.Bounds()
+		paletted := image.NewPaletted(bounds, palette.Plan9)
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				c := frame.RGBAAt(x, y)
+				index, ok := nearest[c]
+				if !ok {
+					index = uint8(paletted.Palette.Index(c))
+					nearest[c] = index
+				}
+				paletted.SetColorIndex(x, y, index)
+			}
+		}
+		clip.Image = append(clip.Image, paletted)
+		clip.Delay = append(clip.Delay, int(clipInterval/(10*time.Millisecond)))
+	}
+
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, clip); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// updateRecorder saves a clip when F9 is pressed and reports when it's done
+func (g *Game) updateRecorder() {
+	if result, ok := g.recorder.poll(); ok {
+		if result.err != nil {
+			g.showToast("Couldn't save the clip")
+		} else {
+			g.showToast("Clip saved to " + result.where)
+		}
+	}
+
+	if !g.settings.Recording || !g.input.IsKeyJustPressed(ebiten.KeyF9) {
+		return
+	}
+	if g.recorder.save() {
+		g.showToast("Saving clip...")
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// useRepel starts a repel from the bag, unless one is already working
+func (g *Game) useRepel(name string) string {
+	if g.repelLeft() > 0 {
+		return "The last repel is still in effect."
+	}
+
+	g.removeItem(name)
+	g.repelEnd = g.steps + findItem(name).repelSteps
+	g.repelItem = name
+	return "Used a " + name + ". Weak wild creatures will stay away."
+}
+
+// repelLeft is how many more steps the active repel lasts
+func (g *Game) repelLeft() int {
+	return max(g.repelEnd-g.steps, 0)
+}
+
+// updateRepel checks whether an active repel has worn off with the step just
+// taken, offering to use another of the same kind if so. It reports whether
+// it interrupted the step with a message.
+func (g *Game) updateRepel() bool {
+	if g.repelItem == "" || g.repelLeft() > 0 {
+		return false
+	}
+
+	name := g.repelItem
+	g.repelItem = ""
+	if g.bag[name] == 0 {
+		g.showDialogue("The repel wore off.")
+		return true
+	}
+
+	g.showPrompt("The repel wore off. Use another "+name+"?", func() {
+		g.showDialogue(g.useRepel(name))
+	})
+	return true
+}
+
+// repelBlocks reports whether an active repel keeps a wild creature away:
+// only creatures weaker than the lead of the party are scared off
+func (g *Game) repelBlocks(wild Creature) bool {
+	if g.repelLeft() <= 0 {
+		return false
+	}
+
+	lead := &g.creatures[g.activeCreature]
+	if lead.hp <= 0 {
+		lead = g.nextHealthyCreature()
+	}
+	return lead != nil && wild.level < lead.level
+}
+
+// drawRepelCounter shows the steps left on an active repel in the corner of
+// the overworld, unless the HUD is showing them
+func (g *Game) drawRepelCounter(screen *ebiten.Image) {
+	if g.repelLeft() <= 0 || g.settings.HUD {
+		return
+	}
+
+	g.drawText(screen, g.countLabel("Repel ", g.repelLeft()), screenWidth-80, 5, color.RGBA{255, 255, 255, 220})
+}
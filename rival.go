@@ -0,0 +1,174 @@
+package main
+
+import (
+	"image/color"
+	"strconv"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Rival constants
+const (
+	// Flag set once the rival is beaten on the first route; the ones before
+	// each gym are this prefix and the gym's region index
+	rivalRouteFlag = "rival-route"
+	rivalGymFlag   = "rival-gym-"
+	// Tiles past the edge of the first town where the rival waits on the
+	// first route
+	rivalRouteOffset = 8
+	// Levels the rival's team gains with each battle
+	rivalLevelStep = 5
+)
+
+// rivalClothes is the color the rival wears
+var rivalClothes = color.RGBA{60, 90, 200, 255}
+
+// rivalRecruits join the rival's team in order, one more for each battle
+// they've had with the player, alongside their starter
+var rivalRecruits = []string{"Zephyrd", "Pebblit", "Frostfox", "Sandcrab", "Bogtoad"}
+
+// rivalChallenges are what the rival says before each battle, in order; the
+// last is repeated for any after
+var rivalChallenges = []string{
+	"Hey, {player}! I've been waiting. Let's see whose partner is tougher!",
+	"Heading for the gym, {player}? You'll have to get past me first!",
+	"You again? My team's grown since last time. Don't hold back!",
+}
+
+// rivalDefeats are what the rival says on losing, in the same order
+var rivalDefeats = []string{
+	"What? I picked the stronger one! I'll train harder, just watch.",
+	"Hmph. Go on then, the leader won't go easy on you.",
+	"You really are getting good. Next time will be different!",
+}
+
+// RivalAmbush is the rival stepping out in front of the player to battle
+type RivalAmbush struct {
+	active bool
+	x, y   int
+	sprite *AnimatedSprite
+}
+
+// rivalBattles returns how many times the player has beaten the rival
+func (g *Game) rivalBattles() int {
+	count := 0
+	for flag, set := range g.flags {
+		if set && (flag == rivalRouteFlag || strings.HasPrefix(flag, rivalGymFlag)) {
+			count++
+		}
+	}
+	return count
+}
+
+// rivalTeam builds the rival's team for a battle: a recruit for each battle
+// already had, led by their starter, evolved once it's strong enough
+func (g *Game) rivalTeam(battle int) []Creature {
+	level := 6 + battle*rivalLevelStep
+
+	starter := g.rivalStarter
+	if starter == "" {
+		starter = rivalStarter(g.creatures[0].name)
+	}
+	if species := findSpecies(starter); species.evolution != "" && level >= species.evolveLevel {
+		starter = species.evolution
+	}
+
+	team := []Creature{}
+	for _, name := range rivalRecruits[:min(battle, len(rivalRecruits))] {
+		team = append(team, newCreature(name, level-2))
+	}
+	team = append(team, newCreature(starter, level))
+	for i := range team {
+		team[i].trainer = g.rivalName
+	}
+	return team
+}
+
+// rivalRoutePoint returns where the rival waits on the first route out of
+// the first town
+func (g *Game) rivalRoutePoint() (Point, bool) {
+	for _, route := range g.maps[overworldID].plan.routes {
+		if route.from != 0 && route.to != 0 {
+			continue
+		}
+		path := route.tiles()
+		i := min(townWidth/2+rivalRouteOffset, len(path)-1)
+		if route.to == 0 {
+			i = len(path) - 1 - i
+		}
+		return path[i], true
+	}
+	return Point{}, false
+}
+
+// checkRivalAmbush starts a rival battle when the player walks up to one of
+// the rival's spots: partway along the first route, then outside each gym
+func (g *Game) checkRivalAmbush() bool {
+	if g.worldMap.id != overworldID || len(g.creatures) == 0 {
+		return false
+	}
+	x, y := g.player.tileX, g.player.tileY
+
+	if !g.flags[rivalRouteFlag] {
+		spot, ok := g.rivalRoutePoint()
+		if ok && abs(spot.x-x) <= routeWidth+1 && abs(spot.y-y) <= routeWidth+1 {
+			g.ambushPlayer(rivalRouteFlag)
+			return true
+		}
+		return false
+	}
+
+	for i, region := range g.worldMap.plan.regions {
+		flag := rivalGymFlag + strconv.Itoa(i)
+		if g.flags[flag] {
+			continue
+		}
+		for _, b := range region.buildings {
+			if b.kind == BuildingGym && abs(b.door.x-x) <= 1 && y > b.door.y && y-b.door.y <= 3 {
+				g.ambushPlayer(flag)
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ambushPlayer has the rival step up beside the player and challenge them,
+// starting the battle once they've had their say
+func (g *Game) ambushPlayer(flag string) {
+	dx, dy := directionDelta(g.player.direction)
+	g.rival = RivalAmbush{
+		active: true,
+		x:      g.player.tileX + dx,
+		y:      g.player.tileY + dy,
+		sprite: newPersonSprite(rivalClothes),
+	}
+	g.rival.sprite.direction = oppositeDirection(g.player.direction)
+	g.showEmote(Entity{kind: EntityRival}, EmoteExclaim, 0)
+
+	battle := g.rivalBattles()
+	line := rivalChallenges[min(battle, len(rivalChallenges)-1)]
+	g.showDialogueThen("{rival}: "+line, func() {
+		g.rival.active = false
+		team := g.rivalTeam(battle)
+		g.startTrainerBattle(Trainer{
+			name:       g.rivalName,
+			clothes:    rivalClothes,
+			team:       team,
+			defeatText: rivalDefeats[min(battle, len(rivalDefeats)-1)],
+			reward:     team[len(team)-1].level * 40,
+			flag:       flag,
+		})
+	})
+}
+
+// drawRival draws the rival where they stepped out, while they're talking
+func (g *Game) drawRival(screen *ebiten.Image) {
+	if !g.rival.active {
+		return
+	}
+	x := float32(g.rival.x*tileSize) - g.camera.x
+	y := float32(g.rival.y*tileSize) - g.camera.y
+	g.rival.sprite.draw(screen, x, y, g.ticks)
+}
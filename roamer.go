@@ -0,0 +1,89 @@
+package main
+
+import (
+	"math/rand"
+)
+
+// Roaming creature constants
+const (
+	roamerSpecies = "Voltigon"
+	roamerLevel   = 40
+	// Share of encounters on the roamer's route that are the roamer
+	roamerEncounterChance = 0.25
+	// Chance the roamer runs off instead of attacking on its turn
+	roamerFleeChance = 0.35
+	// How far from a route's center line still counts as being on it
+	routeReach = 8
+)
+
+// Roamer is a unique creature that moves to a different route whenever the
+// player changes maps; it keeps any damage between meetings
+type Roamer struct {
+	creature Creature
+	route    int
+	// Met at least once, so it shows up on the town map
+	seen bool
+	// Knocked out, so it's gone for good
+	defeated bool
+}
+
+// newRoamer places the roaming creature on a random route
+func newRoamer(plan *WorldPlan) Roamer {
+	return Roamer{
+		creature: newCreature(roamerSpecies, roamerLevel),
+		route:    rand.Intn(len(plan.routes)),
+	}
+}
+
+// moveRoamer sends the roamer off to a different route
+func (g *Game) moveRoamer() {
+	routes := len(g.maps[overworldID].plan.routes)
+	if g.roamer.defeated || routes < 2 {
+		return
+	}
+
+	next := rand.Intn(routes - 1)
+	if next >= g.roamer.route {
+		next++
+	}
+	g.roamer.route = next
+}
+
+// roamerEncounter reports whether a wild encounter at tile x, y should be the
+// roamer instead
+func (g *Game) roamerEncounter(x, y int) bool {
+	if g.roamer.defeated || g.worldMap.id != overworldID {
+		return false
+	}
+	route := g.worldMap.plan.routes[g.roamer.route]
+	return route.near(x, y, routeReach) && rand.Float32() < roamerEncounterChance
+}
+
+// near reports whether tile x, y is within reach tiles of the route
+func (r Route) near(x, y, reach int) bool {
+	for i := range len(r.points) - 1 {
+		a, b := r.points[i], r.points[i+1]
+		if x >= min(a.x, b.x)-reach && x <= max(a.x, b.x)+routeWidth+reach &&
+			y >= min(a.y, b.y)-reach && y <= max(a.y, b.y)+routeWidth+reach {
+			return true
+		}
+	}
+	return false
+}
+
+// startRoamerBattle starts a battle against the roamer as it is now
+func (g *Game) startRoamerBattle() {
+	g.startBattle(g.roamer.creature)
+	if g.gameState != StateBattle {
+		return
+	}
+
+	g.roamer.seen = true
+	g.battle.roamer = true
+	g.battle.announcement = "The legendary " + g.roamer.creature.name + " appeared!"
+}
+
+// roamerFlees rolls whether the roamer runs off on its turn
+func (g *Game) roamerFlees() bool {
+	return g.battle.roamer && rand.Float32() < roamerFleeChance
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"image/color"
+	"math"
+	"math/rand"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Rustle marker constants
+const (
+	// Chance of a nearby grass tile starting to rustle on each step through
+	// grass
+	rustleChance = 0.04
+	// Nearest and farthest a rustle starts from the player, in tiles
+	rustleMinDistance = 2
+	rustleMaxDistance = 4
+	// Frames a rustle lasts before the creature in it wanders off, and the
+	// last few of them it spends settling down
+	rustleFrames       = 4 * simulationRate
+	rustleSettleFrames = simulationRate
+)
+
+// RustleMarker is a grass tile shaking with a wild creature hiding in it,
+// which jumps out at the player if they step there before it settles
+type RustleMarker struct {
+	tile Point
+	// Frames until it settles; none are rustling at zero
+	frames int
+}
+
+// rustlingAt reports whether the grass at a tile is rustling now
+func (g *Game) rustlingAt(x, y int) bool {
+	return g.rustle.frames > 0 && g.rustle.tile == (Point{x, y})
+}
+
+// updateRustle lets the rustling grass settle down over time
+func (g *Game) updateRustle() {
+	if g.rustle.frames > 0 {
+		g.rustle.frames--
+	}
+}
+
+// enterRustle starts a battle with the creature hiding in the grass if the
+// player has just stepped into it, reporting whether they had. It's found in
+// the rare turn of the area's encounter table, where creatures only ever
+// found in rustles can turn up, and no repel keeps it away.
+func (g *Game) enterRustle() bool {
+	x, y := g.player.tileX, g.player.tileY
+	if !g.rustlingAt(x, y) {
+		return false
+	}
+	wild := g.rollWildCreature(ZoneRustlingGrass, x, y)
+	g.rustle = RustleMarker{}
+	g.rollTraits(&wild)
+	g.setUpBattle(wild)
+	return true
+}
+
+// spawnRustle now and then sets a grass tile a little way from the player
+// rustling, while they walk through grass outdoors
+func (g *Game) spawnRustle() {
+	m := g.worldMap
+	x, y := g.player.tileX, g.player.tileY
+	if m.static || g.rustle.frames > 0 || !m.IsGrass(x, y) || rand.Float32() >= rustleChance {
+		return
+	}
+
+	var spots []Point
+	for dy := -rustleMaxDistance; dy <= rustleMaxDistance; dy++ {
+		for dx := -rustleMaxDistance; dx <= rustleMaxDistance; dx++ {
+			distance := abs(dx) + abs(dy)
+			tx, ty := x+dx, y+dy
+			if distance < rustleMinDistance || distance > rustleMaxDistance ||
+				tx < 0 || ty < 0 || tx >= m.width || ty >= m.height {
+				continue
+			}
+			if _, ok := m.objects[Point{tx, ty}]; !ok && m.IsGrass(tx, ty) && !m.IsCollision(tx, ty) {
+				spots = append(spots, Point{tx, ty})
+			}
+		}
+	}
+	if len(spots) > 0 {
+		g.rustle = RustleMarker{tile: spots[rand.Intn(len(spots))], frames: rustleFrames}
+	}
+}
+
+// drawRustleMarker draws the rustling grass: blades swaying over the tile and
+// leaves tossed up out of it, calming as it settles
+func (g *Game) drawRustleMarker(screen *ebiten.Image) {
+	if g.rustle.frames <= 0 {
+		return
+	}
+	strength := min32(float32(g.rustle.frames)/rustleSettleFrames, 1)
+	p := g.rustle.tile
+	left := float32(p.x*tileSize) - g.camera.x
+	top := float32(p.y*tileSize) - g.camera.y
+	blade := shade(biomes[g.worldMap.BiomeAt(p.x, p.y)].palette[TileGrass], 1.3)
+
+	for i := range 4 {
+		base := left + 5 + float32(i)*7
+		sway := float32(math.Sin(float64(g.ticks)/3+float64(i)*1.7)) * 4 * strength
+		vector.StrokeLine(screen, base, top+tileSize-4, base+sway, top+tileSize-16, 2, blade, true)
+	}
+
+	// Each leaf is tossed up from the tile and falls back in, on a loop
+	for i := range 3 {
+		t := float32((g.ticks+i*9)%27) / 27
+		lift := 4 * t * (1 - t) * 14 * strength
+		x := left + 8 + float32(i)*8
+		y := top + tileSize - 10 - lift
+		vector.DrawFilledCircle(screen, x, y, 2, color.RGBA{blade.R, blade.G, blade.B, uint8(255 * strength)}, true)
+	}
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"image/color"
+	"math/rand"
+	"strconv"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Safari zone constants
+const (
+	safariZoneID = "safari-zone"
+	safariFee    = 500
+	safariBalls  = 30
+	safariSteps  = 500
+	// The most creatures carried in the party; the rest go to storage
+	partySize = 6
+)
+
+// safariActions replace moves in safari battles
+var safariActions = []string{"Throw Ball", "Throw Bait", "Throw Mud", "Run"}
+
+// safariEncounters are the wild creatures found in the safari zone, rarer
+// than anywhere else
+var safariEncounters = []EncounterEntry{
+	{species: "Frostfox", minLevel: 15, maxLevel: 20, weight: 25},
+	{species: "Magmite", minLevel: 15, maxLevel: 20, weight: 20},
+	{species: "Sandcrab", minLevel: 14, maxLevel: 18, weight: 25},
+	{species: "Bogtoad", minLevel: 14, maxLevel: 18, weight: 25},
+	{species: "Leafling", minLevel: 15, maxLevel: 20, weight: 5, when: EncounterCondition{time: TimeNight}},
+}
+
+// Safari is the player's progress through a visit to the safari zone
+type Safari struct {
+	active bool
+	balls  int
+	steps  int
+}
+
+// newSafariZone builds the safari zone: a field of tall grass dotted with
+// ponds, fenced in by trees
+func newSafariZone() *Map {
+	m := newStaticMap(safariZoneID, 24, 18)
+	m.noEncounters = false
+	m.encounters = safariEncounters
+
+	for y := range m.height {
+		for x := range m.width {
+			if x == 0 || y == 0 || x == m.width-1 || y == m.height-1 {
+				m.stamp(x, y, TileTree, true)
+			} else {
+				m.stamp(x, y, TileTallGrass, false)
+			}
+		}
+	}
+
+	// Ponds, kept away from the entrance
+	rng := rand.New(rand.NewSource(int64(len(safariZoneID))))
+	for range 4 {
+		cx, cy := 3+rng.Intn(m.width-6), 3+rng.Intn(m.height-9)
+		for y := cy - 1; y <= cy+1; y++ {
+			for x := cx - 2; x <= cx+2; x++ {
+				m.stamp(x, y, TileWater, true)
+			}
+		}
+	}
+
+	// A path in from the entrance
+	exit := Point{m.width / 2, m.height - 1}
+	for y := m.height - 4; y < m.height-1; y++ {
+		m.stamp(exit.x, y, TilePath, false)
+	}
+	m.stamp(exit.x, exit.y, TileDoor, false)
+	m.warps[exit] = Warp{back: true}
+	m.entrance = Point{exit.x, exit.y - 1}
+
+	return m
+}
+
+// newSafariGate builds the gatehouse in front of the safari zone, with an
+// attendant behind the counter
+func newSafariGate(id string) *Map {
+	m := newStaticMap(id, 9, 7)
+	for x := 2; x < m.width-2; x++ {
+		m.stamp(x, 2, TileCounter, true)
+	}
+	m.objects[Point{m.width / 2, 1}] = &MapObject{kind: ObjectSafariAttendant}
+	return m
+}
+
+// talkToSafariAttendant offers entry to the safari zone for a fee
+func (g *Game) talkToSafariAttendant() {
+	if g.money < safariFee {
+		g.showDialogue("Welcome to the Safari Zone! Entry is $" + strconv.Itoa(safariFee) + ". Come back when you can pay.")
+		return
+	}
+
+	g.showPrompt("Welcome to the Safari Zone! For $"+strconv.Itoa(safariFee)+" you get "+strconv.Itoa(safariBalls)+
+		" Safari Balls and "+strconv.Itoa(safariSteps)+" steps to catch what you can. Go in?", func() {
+		zone := g.maps[safariZoneID]
+		g.money -= safariFee
+		g.safari = Safari{active: true, balls: safariBalls, steps: safariSteps}
+		g.transition = Transition{active: true, warp: Warp{mapID: safariZoneID, x: zone.entrance.x, y: zone.entrance.y}}
+		g.audio.playSound("door")
+	})
+}
+
+// updateSafariSteps counts down the player's steps in the safari zone,
+// sending them out when they run out. It reports whether it ended the visit.
+func (g *Game) updateSafariSteps() bool {
+	if !g.safari.active || g.worldMap.id != safariZoneID {
+		return false
+	}
+
+	g.safari.steps--
+	if g.safari.steps > 0 {
+		return false
+	}
+
+	g.leaveSafari("Ding-dong! Time's up! Your Safari game is over.")
+	return true
+}
+
+// leaveSafari ends a safari visit, fading the player back to the gate
+func (g *Game) leaveSafari(message string) {
+	g.transition = Transition{active: true, warp: Warp{back: true}, message: message}
+}
+
+// updateSafariBattle handles input in a safari battle, where the player
+// throws things instead of fighting
+func (g *Game) updateSafariBattle() {
+	b := &g.battle
+
+	if b.currentTurn == 1 {
+		g.safariCreatureTurn()
+		return
+	}
+
+	if g.input.IsActionJustPressed(ebiten.KeyUp) || g.input.IsActionJustPressed(ebiten.KeyDown) {
+		b.selectedAction ^= 2
+	} else if g.input.IsActionJustPressed(ebiten.KeyLeft) || g.input.IsActionJustPressed(ebiten.KeyRight) {
+		b.selectedAction ^= 1
+	}
+
+	if g.input.IsActionJustPressed(ebiten.KeyEscape) {
+		b.selectedAction = len(safariActions) - 1
+	} else if !g.input.IsActionJustPressed(ebiten.KeySpace) {
+		return
+	}
+
+	wild := &b.enemyCreature
+	b.battleTextTimer = 60
+	b.currentTurn = 1
+
+	switch safariActions[b.selectedAction] {
+	case "Throw Ball":
+		g.safari.balls--
+		if b.boss != nil && !b.boss.boss.Catchable {
+			b.battleText = "The ball bounced off! The " + wild.name + " can't be caught!"
+			break
+		}
+		g.audio.playSting("capture")
+		if rand.Float32() < b.catchChance() {
+			g.endBattle()
+			message := "Gotcha! " + wild.name + " was caught! " + g.catchCreature(*wild)
+			if g.safari.balls <= 0 {
+				g.leaveSafari(message + " You have no Safari Balls left! Your Safari game is over.")
+			} else {
+				g.showDialogue(message)
+			}
+			return
+		}
+		b.battleText = "Oh no! The " + wild.name + " broke free!"
+
+	case "Throw Bait":
+		b.bait, b.mud = 1+rand.Intn(5), 0
+		b.battleText = "The " + wild.name + " is eating!"
+
+	case "Throw Mud":
+		b.mud, b.bait = 1+rand.Intn(5), 0
+		b.battleText = "The " + wild.name + " is angry!"
+
+	case "Run":
+		g.events.publish(Event{kind: EventFlee})
+		g.endBattle()
+	}
+}
+
+// safariCreatureTurn lets the wild creature react once the player's throw
+// has played out: it may run off, or keep watching
+func (g *Game) safariCreatureTurn() {
+	b := &g.battle
+	wild := &b.enemyCreature
+
+	if g.safari.balls <= 0 {
+		g.endBattle()
+		g.leaveSafari("You have no Safari Balls left! Your Safari game is over.")
+		return
+	}
+
+	if rand.Float32() < b.fleeChance() {
+		g.endBattle()
+		g.showDialogue("The " + wild.name + " fled!")
+		return
+	}
+
+	b.battleText = "The " + wild.name + " is watching carefully."
+	switch {
+	case b.bait > 0:
+		b.bait--
+		b.battleText = "The " + wild.name + " is eating."
+	case b.mud > 0:
+		b.mud--
+		b.battleText = "The " + wild.name + " is angry."
+	}
+	b.battleTextTimer = 40
+	b.currentTurn = 0
+}
+
+// catchChance is the chance of a thrown ball catching the wild creature;
+// stronger creatures are harder to catch, and mud makes them easier
+func (b *Battle) catchChance() float32 {
+	chance := 0.45 - float32(b.enemyCreature.level)/100
+	switch {
+	case b.mud > 0:
+		chance *= 2
+	case b.bait > 0:
+		chance /= 2
+	}
+	return chance
+}
+
+// fleeChance is the chance of the wild creature running off on its turn;
+// fast creatures are flighty, bait calms them and mud makes them bolt
+func (b *Battle) fleeChance() float32 {
+	chance := 0.05 + float32(b.enemyCreature.speed)/200
+	switch {
+	case b.bait > 0:
+		chance /= 4
+	case b.mud > 0:
+		chance *= 2
+	}
+	return chance
+}
+
+// catchCreature adds a caught creature to the party, or to storage once the
+// party is full, returning where it went
+func (g *Game) catchCreature(c Creature) string {
+	g.events.publish(Event{kind: EventCapture, strength: 0.6, species: c.name, form: c.form})
+
+	c.trainer = g.playerName
+	g.recordMet(&c)
+	if len(g.creatures) < partySize {
+		g.creatures = append(g.creatures, c)
+		return c.name + " joined the party."
+	}
+	g.storage = append(g.storage, c)
+	return c.name + " was sent to storage."
+}
+
+// drawSafariActions draws the safari battle choices in a two by two grid
+func (g *Game) drawSafariActions(screen *ebiten.Image) {
+	top := g.battleUITop() + 20
+	g.drawText(screen, "What will you do?", 10, float64(top), color.White)
+
+	columnWidth := max(130, g.selectorWidth()+g.widestText(safariActions)+10)
+	for i, action := range safariActions {
+		x := float64(15 + g.selectorWidth() + i%2*columnWidth)
+		y := float64(top + g.rowHeight() + i/2*g.lineSpacing())
+
+		g.drawText(screen, action, x, y, color.White)
+		if i == g.battle.selectedAction {
+			g.drawText(screen, ">", x-float64(g.selectorWidth()), y, color.White)
+		}
+	}
+
+	balls := g.countLabel("Balls ", g.safari.balls)
+	g.drawText(screen, balls, float64(screenWidth-20-g.textWidth(balls)), float64(top), color.RGBA{200, 200, 200, 255})
+}
+
+// drawSafariCounter shows the balls and steps left while in the safari zone
+func (g *Game) drawSafariCounter(screen *ebiten.Image) {
+	if !g.safari.active || g.worldMap.id != safariZoneID {
+		return
+	}
+
+	counter := g.cachedLabel(labelKey{prefix: "Safari", a: g.safari.balls, b: g.safari.steps}, func() string {
+		return "Balls " + strconv.Itoa(g.safari.balls) + " Steps " + strconv.Itoa(g.safari.steps)
+	})
+	width := float32(g.textWidth(counter) + 10)
+	top := float32(g.hudHeight())
+	g.drawPanel(screen, screenWidth-2-width, top+2, width, float32(g.lineHeight()+5), color.RGBA{0, 0, 0, 140})
+	g.drawText(screen, counter, float64(screenWidth-width+3), float64(top+4), color.White)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+)
+
+// checksumPrefix starts the line appended to every save holding its checksum
+const checksumPrefix = "checksum "
+
+// saveKey signs saves. It's in the source for anyone to read, so it stops
+// saves being edited by hand in passing rather than by someone set on it.
+var saveKey = []byte("creaturegame save v1")
+
+// errSaveDamaged is returned for a save whose contents don't match its
+// checksum, whether from a bad write or from being edited
+var errSaveDamaged = errors.New("save is damaged or was edited")
+
+// saveChecksum returns the checksum of a save's contents
+func saveChecksum(body []byte) string {
+	mac := hmac.New(sha256.New, saveKey)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// sealSave appends a save's checksum to its contents
+func sealSave(body []byte) []byte {
+	sealed := append(body, '\n')
+	sealed = append(sealed, checksumPrefix...)
+	sealed = append(sealed, saveChecksum(body)...)
+	return append(sealed, '\n')
+}
+
+// openSave checks a save file against its checksum and returns its contents
+// without it. Saves written before there were checksums have none and are
+// taken as they are; the next save adds one.
+func openSave(sealed []byte) ([]byte, error) {
+	trimmed := bytes.TrimRight(sealed, "\n")
+	i := bytes.LastIndexByte(trimmed, '\n')
+	if i < 0 || !bytes.HasPrefix(trimmed[i+1:], []byte(checksumPrefix)) {
+		return sealed, nil
+	}
+
+	body, sum := trimmed[:i], trimmed[i+1+len(checksumPrefix):]
+	if !hmac.Equal(sum, []byte(saveChecksum(body))) {
+		return nil, errSaveDamaged
+	}
+	return body, nil
+}
+
+// backUpSave copies the save file to the backup before it's overwritten, so
+// there's a good save to fall back on if the new one is damaged. A save that
+// is already damaged isn't copied over the backup.
+func backUpSave() {
+	sealed, err := saveStore.Read(saveFile)
+	if err != nil {
+		return
+	}
+	if _, err := openSave(sealed); err != nil {
+		return
+	}
+	if err := saveStore.Write(saveBackupFile, sealed); err != nil {
+		log.Println("Failed to back up the save:", err)
+	}
+}
+
+// loadSaveOrBackup loads the save file, falling back to the backup made
+// before the last save when the save file is damaged or can't be read
+func (g *Game) loadSaveOrBackup() error {
+	err := g.loadGameFrom(saveFile)
+	if err == nil || !saveStore.Exists(saveBackupFile) {
+		return err
+	}
+	log.Println("Failed to load save, trying the backup:", err)
+
+	if backupErr := g.loadGameFrom(saveBackupFile); backupErr != nil {
+		log.Println("Failed to load the backup:", backupErr)
+		return err
+	}
+	g.unsaved = true
+	g.showToast("The save was damaged. Loaded the one before.")
+	return nil
+}
@@ -0,0 +1,70 @@
+//go:build js
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall/js"
+)
+
+// browserKeyPrefix keeps the game's keys apart from others on the same site
+const browserKeyPrefix = "creaturegame-2/"
+
+// BrowserStore keeps files in the browser's localStorage
+type BrowserStore struct {
+	storage js.Value
+}
+
+// newSaveStore returns the store used by browser builds
+func newSaveStore() SaveStore {
+	return BrowserStore{storage: js.Global().Get("localStorage")}
+}
+
+// dataDirStore returns the browser store, as browsers have no folders to
+// keep files in
+func dataDirStore(string) SaveStore {
+	return newSaveStore()
+}
+
+// Read reads a whole file
+func (s BrowserStore) Read(name string) ([]byte, error) {
+	if !s.storage.Truthy() {
+		return nil, errors.New("localStorage is not available")
+	}
+	item := s.storage.Call("getItem", browserKeyPrefix+name)
+	if item.IsNull() {
+		return nil, fmt.Errorf("%s: %w", name, os.ErrNotExist)
+	}
+	return []byte(item.String()), nil
+}
+
+// Write replaces a file. Browsers throw if storage is full or disabled, which
+// comes back as an error instead of a crash.
+func (s BrowserStore) Write(name string, data []byte) (err error) {
+	if !s.storage.Truthy() {
+		return errors.New("localStorage is not available")
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("saving %s: %v", name, r)
+		}
+	}()
+	s.storage.Call("setItem", browserKeyPrefix+name, string(data))
+	return nil
+}
+
+// Exists reports whether a file is there
+func (s BrowserStore) Exists(name string) bool {
+	return s.storage.Truthy() && !s.storage.Call("getItem", browserKeyPrefix+name).IsNull()
+}
+
+// Remove deletes a file
+func (s BrowserStore) Remove(name string) error {
+	if !s.storage.Truthy() {
+		return errors.New("localStorage is not available")
+	}
+	s.storage.Call("removeItem", browserKeyPrefix+name)
+	return nil
+}
@@ -0,0 +1,76 @@
+//go:build !js
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// FileStore keeps files in the user's config directory, or in a folder of
+// their choosing
+type FileStore struct {
+	dir string
+}
+
+// newSaveStore returns the store used by desktop and mobile builds
+func newSaveStore() SaveStore {
+	return FileStore{}
+}
+
+// dataDirStore returns a store keeping files in a folder
+func dataDirStore(dir string) SaveStore {
+	return FileStore{dir: dir}
+}
+
+// path returns where a file lives
+func (s FileStore) path(name string) (string, error) {
+	if s.dir != "" {
+		return filepath.Join(s.dir, name), nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "creaturegame-2", name), nil
+}
+
+// Read reads a whole file
+func (s FileStore) Read(name string) ([]byte, error) {
+	path, err := s.path(name)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(path)
+}
+
+// Write replaces a file, creating the directory if needed
+func (s FileStore) Write(name string, data []byte) error {
+	path, err := s.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Exists reports whether a file is there
+func (s FileStore) Exists(name string) bool {
+	path, err := s.path(name)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+// Remove deletes a file
+func (s FileStore) Remove(name string) error {
+	path, err := s.path(name)
+	if err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
@@ -0,0 +1,29 @@
+package main
+
+// Names of the files kept in the save store
+const (
+	saveFile       = "save.json"
+	syncConfigFile = "sync.json"
+	// Where ranked battles are found, if not by typing a referee's address
+	matchmakingConfigFile = "matchmaking.json"
+	// The server's copy of the save, kept aside after a sync conflict
+	serverSaveFile = "save-server.json"
+	// The save as it was before the last save, in case that one is damaged
+	saveBackupFile = "save-backup.json"
+	// The game as it was when it crashed, and what went wrong
+	emergencySaveFile = "save-emergency.json"
+	crashReportFile   = "crash.txt"
+)
+
+// SaveStore keeps the game's files somewhere that lasts between runs. Desktop
+// builds use the user's config directory and browser builds use localStorage.
+// Reading a file that doesn't exist returns an error wrapping os.ErrNotExist.
+type SaveStore interface {
+	Read(name string) ([]byte, error)
+	Write(name string, data []byte) error
+	Exists(name string) bool
+	Remove(name string) error
+}
+
+// saveStore is where this build keeps its files
+var saveStore SaveStore = newSaveStore()
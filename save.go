@@ -0,0 +1,595 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+)
+
+// saveVersion is bumped whenever the save format changes
+const saveVersion = 2
+
+// SaveData is the on-disk save format
+type SaveData struct {
+	Version int `json:"version"`
+	// Bumped on every save, and the revision last agreed with the sync server
+	Revision  int            `json:"revision"`
+	Synced    int            `json:"synced"`
+	SavedAt   int64          `json:"savedAt"`
+	Seed      int64          `json:"seed"`
+	Map       string         `json:"map"`
+	X         int            `json:"x"`
+	Y         int            `json:"y"`
+	Direction int            `json:"direction"`
+	Returns   []ReturnSave   `json:"returns"`
+	Creatures []CreatureSave `json:"creatures"`
+	Active    int            `json:"active"`
+	Bag       map[string]int `json:"bag"`
+	Steps     int            `json:"steps"`
+	Name      string         `json:"name"`
+	Rival     string         `json:"rival,omitempty"`
+	// Starter the rival took
+	RivalStarter string             `json:"rivalStarter,omitempty"`
+	Money        int                `json:"money"`
+	Badges       int                `json:"badges"`
+	Flags        map[string]bool    `json:"flags"`
+	Roamer       RoamerSave         `json:"roamer"`
+	Storage      []CreatureSave     `json:"storage,omitempty"`
+	Safari       SafariSave         `json:"safari"`
+	Repel        int                `json:"repel,omitempty"`
+	RepelItem    string             `json:"repelItem,omitempty"`
+	Respawn      RespawnSave        `json:"respawn"`
+	Maps         map[string]MapSave `json:"maps"`
+	// Species seen and caught, for the dex
+	Seen   []string `json:"seen,omitempty"`
+	Caught []string `json:"caught,omitempty"`
+	// In-game date, missing from saves made before there was one
+	Calendar *CalendarSave `json:"calendar,omitempty"`
+	// Forms seen of each species, the regular one as an empty name
+	Forms map[string][]string `json:"forms,omitempty"`
+	// Species fused to make each fused form made so far, for the dex
+	Fusions [][2]string `json:"fusions,omitempty"`
+	// Items the player has learned to craft
+	Recipes []string `json:"recipes,omitempty"`
+	// Berries growing in the garden
+	Garden map[Point]*Plot `json:"garden,omitempty"`
+	// Day each raid den was last beaten, and the creature a friend lent for
+	// raids and whose it is
+	RaidsBeaten     map[Point]int `json:"raidsBeaten,omitempty"`
+	RaidAlly        *CreatureSave `json:"raidAlly,omitempty"`
+	RaidAllyTrainer string        `json:"raidAllyTrainer,omitempty"`
+	// Task taken from a bulletin board, and which of the day's were taken
+	Quest       *Quest `json:"quest,omitempty"`
+	QuestDay    int    `json:"questDay,omitempty"`
+	QuestsTaken []bool `json:"questsTaken,omitempty"`
+	TradeDay    int    `json:"tradeDay,omitempty"`
+	TradesTaken []bool `json:"tradesTaken,omitempty"`
+	// Species of the chain going, and how long it is
+	ChainSpecies string `json:"chainSpecies,omitempty"`
+	Chain        int    `json:"chain,omitempty"`
+	// Teams saved to call up at a PC
+	Presets []TeamPreset `json:"presets,omitempty"`
+	// Rating and record in ranked battles
+	Ranked RankedRecord `json:"ranked"`
+	// Towns, routes and regions the player has been to
+	Discovered []string `json:"discovered,omitempty"`
+	// Letters in the mailbox at home
+	Mail []Letter `json:"mail,omitempty"`
+	// Game corner tokens
+	Tokens int `json:"tokens,omitempty"`
+	// Terrain generator the overworld was made with; saves made before
+	// there was a choice have none, and used the noise generator
+	Terrain string `json:"terrain,omitempty"`
+}
+
+// CalendarSave is a saved Calendar
+type CalendarSave struct {
+	Day     int `json:"day"`
+	Frames  int `json:"frames"`
+	Lottery int `json:"lottery"`
+	Visitor int `json:"visitor"`
+}
+
+// ReturnSave is a saved ReturnPoint
+type ReturnSave struct {
+	Map       string `json:"map"`
+	X         int    `json:"x"`
+	Y         int    `json:"y"`
+	Direction int    `json:"direction"`
+}
+
+// RespawnSave is a saved Respawn
+type RespawnSave struct {
+	Map       string       `json:"map"`
+	X         int          `json:"x"`
+	Y         int          `json:"y"`
+	Direction int          `json:"direction"`
+	Returns   []ReturnSave `json:"returns"`
+}
+
+// SafariSave is a saved Safari
+type SafariSave struct {
+	Active bool `json:"active"`
+	Balls  int  `json:"balls"`
+	Steps  int  `json:"steps"`
+}
+
+// RoamerSave is a saved Roamer
+type RoamerSave struct {
+	Creature CreatureSave `json:"creature"`
+	Route    int          `json:"route"`
+	Seen     bool         `json:"seen"`
+	Defeated bool         `json:"defeated"`
+}
+
+// CreatureSave is a saved party creature
+type CreatureSave struct {
+	Name     string  `json:"name"`
+	HP       int     `json:"hp"`
+	MaxHP    int     `json:"maxHP"`
+	Attack   int     `json:"attack"`
+	Defense  int     `json:"defense"`
+	Speed    int     `json:"speed"`
+	Level    int     `json:"level"`
+	Exp      int     `json:"exp,omitempty"`
+	Held     string  `json:"held,omitempty"`
+	Mail     *Letter `json:"mail,omitempty"`
+	Status   int     `json:"status"`
+	Trainer  string  `json:"trainer,omitempty"`
+	Nickname string  `json:"nickname,omitempty"`
+	Met      string  `json:"met,omitempty"`
+	MetLevel int     `json:"metLevel,omitempty"`
+	ID       int     `json:"id,omitempty"`
+	Form     string  `json:"form,omitempty"`
+	Egg      bool    `json:"egg,omitempty"`
+	EggSteps int     `json:"eggSteps,omitempty"`
+	// Saves from before friendship leave it at the starting amount
+	Friendship int `json:"friendship,omitempty"`
+	// Individual values, left out when all are 0
+	IVs   []int `json:"ivs,omitempty"`
+	Shiny bool  `json:"shiny,omitempty"`
+	// Left out for the plain nature
+	Nature int `json:"nature,omitempty"`
+	// The two creatures a fused creature was made from
+	Parts []CreatureSave `json:"parts,omitempty"`
+	Moves []MoveSave     `json:"moves"`
+}
+
+// MoveSave is a saved move
+type MoveSave struct {
+	Name     string `json:"name"`
+	Power    int    `json:"power"`
+	Accuracy int    `json:"accuracy"`
+	Type     string `json:"type"`
+	PP       int    `json:"pp"`
+	MaxPP    int    `json:"maxPP"`
+	Effect   int    `json:"effect,omitempty"`
+	Chance   int    `json:"chance,omitempty"`
+	Kind     int    `json:"kind,omitempty"`
+}
+
+// MapSave is the saved state of objects on one map
+type MapSave struct {
+	PickedUp  []Point                 `json:"pickedUp,omitempty"`
+	Harvested map[Point]Harvest       `json:"harvested,omitempty"`
+	Obstacles map[Point]ObstacleState `json:"obstacles,omitempty"`
+}
+
+// MarshalText encodes a point as "x,y" so it can be used as a JSON map key
+func (p Point) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%d,%d", p.x, p.y)), nil
+}
+
+// UnmarshalText decodes a point written by MarshalText
+func (p *Point) UnmarshalText(data []byte) error {
+	_, err := fmt.Sscanf(string(data), "%d,%d", &p.x, &p.y)
+	return err
+}
+
+// hasSave reports whether there is a save file to continue from
+func hasSave() bool {
+	return saveStore.Exists(saveFile)
+}
+
+// saveGame writes the current game to the save file
+func (g *Game) saveGame() error {
+	g.saveRevision++
+	if err := writeSave(saveFile, g.saveData()); err != nil {
+		return err
+	}
+
+	// A save made normally replaces any left by a crash
+	if saveStore.Exists(emergencySaveFile) {
+		if err := saveStore.Remove(emergencySaveFile); err != nil {
+			log.Println("Failed to remove the emergency save:", err)
+		}
+	}
+
+	// Push the new save to the sync server, if there is one
+	g.sync.start(g.saveRevision)
+	g.unsaved = false
+	return nil
+}
+
+// saveData gathers the current game into save data
+func (g *Game) saveData() SaveData {
+	data := SaveData{
+		Version:      saveVersion,
+		Revision:     g.saveRevision,
+		Synced:       g.syncedRevision,
+		SavedAt:      time.Now().Unix(),
+		Seed:         g.maps[overworldID].seed,
+		Terrain:      terrainNames[g.maps[overworldID].config.terrain],
+		Map:          g.worldMap.id,
+		X:            g.player.tileX,
+		Y:            g.player.tileY,
+		Direction:    g.player.direction,
+		Active:       g.activeCreature,
+		Bag:          g.bag,
+		Steps:        g.steps,
+		Name:         g.playerName,
+		Rival:        g.rivalName,
+		RivalStarter: g.rivalStarter,
+		Money:        g.money,
+		Badges:       g.badges,
+		Flags:        g.flags,
+		Roamer: RoamerSave{
+			Creature: saveCreature(g.roamer.creature),
+			Route:    g.roamer.route,
+			Seen:     g.roamer.seen,
+			Defeated: g.roamer.defeated,
+		},
+		Repel:     g.repelLeft(),
+		RepelItem: g.repelItem,
+		Maps:      make(map[string]MapSave),
+	}
+
+	data.Returns = saveReturnPoints(g.returnPoints)
+	data.Respawn = RespawnSave{
+		Map:       g.respawn.mapID,
+		X:         g.respawn.x,
+		Y:         g.respawn.y,
+		Direction: g.respawn.direction,
+		Returns:   saveReturnPoints(g.respawn.returnPoints),
+	}
+
+	for _, c := range g.creatures {
+		data.Creatures = append(data.Creatures, saveCreature(c))
+	}
+	for _, c := range g.storage {
+		data.Storage = append(data.Storage, saveCreature(c))
+	}
+	data.Safari = SafariSave{Active: g.safari.active, Balls: g.safari.balls, Steps: g.safari.steps}
+	data.Seen, data.Caught = g.dex.list(g.dex.seen), g.dex.list(g.dex.caught)
+	data.Forms = make(map[string][]string)
+	for name, forms := range g.dex.forms {
+		for form := range forms {
+			data.Forms[name] = append(data.Forms[name], form)
+		}
+	}
+	for _, species := range fusedOrder {
+		data.Fusions = append(data.Fusions, species.fusedFrom)
+	}
+	data.Recipes = g.knownRecipes()
+	data.Garden = g.garden
+	data.RaidsBeaten = g.raidsBeaten
+	data.Quest, data.QuestDay, data.QuestsTaken = g.quests.active, g.quests.day, g.quests.taken[:]
+	data.Presets = g.presets
+	data.Ranked = g.ranked
+	data.Discovered = g.discoveredAreas()
+	data.Mail = g.mail
+	data.Tokens = g.tokens
+	data.TradeDay, data.TradesTaken = g.tradeBoard.day, g.tradeBoard.taken[:]
+	data.ChainSpecies, data.Chain = g.chain.species, g.chain.count
+	if g.raidAlly != nil {
+		ally := saveCreature(*g.raidAlly)
+		data.RaidAlly, data.RaidAllyTrainer = &ally, g.raidAllyTrainer
+	}
+	data.Calendar = &CalendarSave{Day: g.calendar.day, Frames: g.calendar.frames, Lottery: g.calendar.lotteryDay, Visitor: g.calendar.visitorWeek}
+
+	for id, m := range g.maps {
+		if len(m.pickedUp) == 0 && len(m.harvested) == 0 && len(m.obstacles) == 0 {
+			continue
+		}
+		ms := MapSave{Harvested: m.harvested, Obstacles: m.obstacles}
+		for pos := range m.pickedUp {
+			ms.PickedUp = append(ms.PickedUp, pos)
+		}
+		data.Maps[id] = ms
+	}
+	return data
+}
+
+// writeSave writes save data with its checksum to a file in the save store,
+// backing up the save file first when overwriting it
+func writeSave(name string, data SaveData) error {
+	encoded, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	if name == saveFile {
+		backUpSave()
+	}
+	return saveStore.Write(name, sealSave(encoded))
+}
+
+// loadGame rebuilds the world from the save file and restores the player's
+// progress, or from the backup if the save file is damaged
+func (g *Game) loadGame() error {
+	return g.loadSaveOrBackup()
+}
+
+// loadGameFrom rebuilds the world from a save file in the save store
+func (g *Game) loadGameFrom(name string) error {
+	sealed, err := saveStore.Read(name)
+	if err != nil {
+		return err
+	}
+	encoded, err := openSave(sealed)
+	if err != nil {
+		return err
+	}
+
+	var data SaveData
+	if err := json.Unmarshal(encoded, &data); err != nil {
+		return err
+	}
+	if data.Version != saveVersion {
+		return fmt.Errorf("unsupported save version %d", data.Version)
+	}
+
+	terrain, ok := parseTerrain(data.Terrain)
+	if !ok {
+		return fmt.Errorf("unknown terrain generator %q", data.Terrain)
+	}
+
+	g.saveRevision, g.syncedRevision = data.Revision, data.Synced
+
+	// The world regenerates identically from its seed and terrain generator
+	g.initMap(data.Seed, terrain)
+
+	// Restore what has changed on each map since it was generated
+	for id, ms := range data.Maps {
+		m, ok := g.maps[id]
+		if !ok {
+			continue
+		}
+		for _, pos := range ms.PickedUp {
+			m.pickedUp[pos] = true
+			delete(m.objects, pos)
+		}
+		for pos, h := range ms.Harvested {
+			m.harvested[pos] = h
+		}
+		for origin, state := range ms.Obstacles {
+			m.obstacles[origin] = state
+		}
+		m.applyObstacles()
+	}
+
+	resetFusions()
+	for _, parts := range data.Fusions {
+		fusionSpecies(parts[0], parts[1])
+	}
+	g.creatures = nil
+	for _, cs := range data.Creatures {
+		g.creatures = append(g.creatures, loadCreature(cs))
+	}
+	g.activeCreature = min(data.Active, len(g.creatures)-1)
+	g.storage = nil
+	for _, cs := range data.Storage {
+		g.storage = append(g.storage, loadCreature(cs))
+	}
+	g.safari = Safari{active: data.Safari.Active, balls: data.Safari.Balls, steps: data.Safari.Steps}
+
+	// Saves from before the dex still count the creatures the player has
+	g.dex = Dex{}
+	for _, name := range data.Seen {
+		g.dex.markSeen(name)
+	}
+	for _, name := range data.Caught {
+		g.dex.markCaught(name)
+	}
+	for name, forms := range data.Forms {
+		for _, form := range forms {
+			g.dex.markForm(name, form)
+		}
+	}
+	for _, list := range [][]Creature{g.creatures, g.storage} {
+		for _, c := range list {
+			if !c.egg {
+				g.dex.markCaught(c.name)
+			}
+		}
+	}
+
+	g.bag = data.Bag
+	if g.bag == nil {
+		g.bag = make(map[string]int)
+	}
+	g.steps = data.Steps
+	g.playerName = data.Name
+	g.rivalName = data.Rival
+	if g.rivalName == "" {
+		g.rivalName = defaultRivalName
+	}
+	g.rivalStarter = data.RivalStarter
+	g.money = data.Money
+	g.badges = data.Badges
+	g.flags = data.Flags
+	if g.flags == nil {
+		g.flags = make(map[string]bool)
+	}
+	g.recipes = make(map[string]bool)
+	for _, name := range data.Recipes {
+		g.recipes[name] = true
+	}
+	g.garden = make(map[Point]*Plot)
+	for pos, plot := range data.Garden {
+		if findItem(plot.Berry) != nil {
+			g.garden[pos] = plot
+		}
+	}
+	g.raidsBeaten = data.RaidsBeaten
+	if g.raidsBeaten == nil {
+		g.raidsBeaten = make(map[Point]int)
+	}
+	g.raidAlly, g.raidAllyTrainer = nil, ""
+	g.quests = QuestLog{active: data.Quest, day: data.QuestDay}
+	copy(g.quests.taken[:], data.QuestsTaken)
+	g.tradeBoard = TradeBoard{day: data.TradeDay}
+	copy(g.tradeBoard.taken[:], data.TradesTaken)
+	g.chain = Chain{species: data.ChainSpecies, count: data.Chain}
+	g.presets = data.Presets
+	g.ranked = data.Ranked
+	g.discovered = make(map[string]bool)
+	for _, area := range data.Discovered {
+		g.discovered[area] = true
+	}
+	g.hud = HUD{}
+	g.mail = data.Mail
+	g.tokens = data.Tokens
+	if data.RaidAlly != nil && loadableCreature(*data.RaidAlly) {
+		ally := loadCreature(*data.RaidAlly)
+		g.raidAlly, g.raidAllyTrainer = &ally, data.RaidAllyTrainer
+	}
+
+	// Beaten static encounters stay gone
+	for id := range g.flags {
+		for _, m := range g.maps {
+			m.removeStaticEncounter(id)
+		}
+	}
+	g.roamer = Roamer{
+		creature: loadCreature(data.Roamer.Creature),
+		route:    min(data.Roamer.Route, len(g.maps[overworldID].plan.routes)-1),
+		seen:     data.Roamer.Seen,
+		defeated: data.Roamer.Defeated,
+	}
+	g.repelEnd, g.repelItem = g.steps+data.Repel, data.RepelItem
+	g.calendar = newCalendar()
+	if cs := data.Calendar; cs != nil {
+		g.calendar = Calendar{day: cs.Day, frames: cs.Frames, lotteryDay: cs.Lottery, visitorWeek: cs.Visitor}
+	}
+	g.updateVisitors()
+
+	g.returnPoints = loadReturnPoints(data.Returns)
+	if _, ok := g.maps[data.Respawn.Map]; ok {
+		g.respawn = Respawn{
+			mapID:        data.Respawn.Map,
+			x:            data.Respawn.X,
+			y:            data.Respawn.Y,
+			direction:    data.Respawn.Direction,
+			returnPoints: loadReturnPoints(data.Respawn.Returns),
+		}
+	}
+
+	// Put the player back where they saved
+	g.placePlayer(data.Map, data.X, data.Y, data.Direction)
+
+	g.unsaved = false
+	return nil
+}
+
+// saveReturnPoints converts a return point stack to its saved form
+func saveReturnPoints(points []ReturnPoint) []ReturnSave {
+	saved := []ReturnSave{}
+	for _, r := range points {
+		saved = append(saved, ReturnSave{Map: r.mapID, X: r.x, Y: r.y, Direction: r.direction})
+	}
+	return saved
+}
+
+// loadReturnPoints rebuilds a return point stack from its saved form
+func loadReturnPoints(saved []ReturnSave) []ReturnPoint {
+	var points []ReturnPoint
+	for _, r := range saved {
+		points = append(points, ReturnPoint{mapID: r.Map, x: r.X, y: r.Y, direction: r.Direction})
+	}
+	return points
+}
+
+// saveCreature converts a creature to its saved form
+func saveCreature(c Creature) CreatureSave {
+	cs := CreatureSave{
+		Name:     c.name,
+		HP:       c.hp,
+		MaxHP:    c.maxHP,
+		Attack:   c.attack,
+		Defense:  c.defense,
+		Speed:    c.speed,
+		Level:    c.level,
+		Exp:      c.exp,
+		Held:     c.heldItem,
+		Mail:     c.mail,
+		Status:   c.status,
+		Trainer:  c.trainer,
+		Nickname: c.nickname,
+		Met:      c.metLocation,
+		MetLevel: c.metLevel,
+		ID:       c.id,
+		Form:     c.form,
+		Egg:      c.egg,
+		EggSteps: c.eggSteps,
+
+		Friendship: c.friendship,
+		Shiny:      c.shiny,
+		Nature:     c.nature,
+	}
+	if c.ivs != [StatCount]int{} {
+		cs.IVs = c.ivs[:]
+	}
+	for _, part := range c.fusedFrom {
+		cs.Parts = append(cs.Parts, saveCreature(part))
+	}
+	for _, m := range c.moves {
+		cs.Moves = append(cs.Moves, MoveSave{Name: m.name, Power: m.power, Accuracy: m.accuracy, Type: m.type1, PP: m.pp, MaxPP: m.maxPP, Effect: m.effect, Chance: m.effectChance, Kind: m.kind})
+	}
+	return cs
+}
+
+// loadCreature rebuilds a creature from its saved form
+func loadCreature(cs CreatureSave) Creature {
+	// Fused creatures need their fused form in the species list first
+	var parts []Creature
+	if len(cs.Parts) == 2 {
+		parts = []Creature{loadCreature(cs.Parts[0]), loadCreature(cs.Parts[1])}
+		cs.Name = fusionSpecies(parts[0].name, parts[1].name).name
+	}
+	c := newCreatureForm(cs.Name, cs.Form, cs.Level)
+	c.fusedFrom = parts
+	c.hp = cs.HP
+	c.maxHP = cs.MaxHP
+	c.attack = cs.Attack
+	c.defense = cs.Defense
+	c.speed = cs.Speed
+	c.exp = cs.Exp
+	c.heldItem = cs.Held
+	c.mail = cs.Mail
+	c.status = cs.Status
+	c.trainer = cs.Trainer
+	c.nickname = cs.Nickname
+	c.metLocation = cs.Met
+	c.metLevel = cs.MetLevel
+	// Creatures saved before they had IDs keep the one just made up
+	if cs.ID != 0 {
+		c.id = cs.ID
+	}
+	c.egg, c.eggSteps = cs.Egg, cs.EggSteps
+	if cs.Friendship != 0 {
+		c.friendship = cs.Friendship
+	}
+	copy(c.ivs[:], cs.IVs)
+	if cs.Nature > 0 && cs.Nature < len(natures) {
+		c.nature = cs.Nature
+	}
+	if cs.Shiny {
+		c.makeShiny()
+	}
+	c.moves = nil
+	for _, m := range cs.Moves {
+		c.moves = append(c.moves, Move{name: m.Name, power: m.Power, accuracy: m.Accuracy, type1: m.Type, pp: m.PP, maxPP: m.MaxPP, effect: m.Effect, effectChance: m.Chance, kind: m.Kind})
+	}
+	return c
+}
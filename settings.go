@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+)
+
+// settingsFile is where the options are kept in the save store
+const settingsFile = "settings.json"
+
+// Settings are the player's options, kept apart from the save so they apply
+// to every game
+type Settings struct {
+	Vibration bool `json:"vibration"`
+	// A whole number of pixels per game pixel, or 0 to fit the window
+	UIScale int `json:"uiScale"`
+	// Accessibility: a high-contrast UI, no screen flashes, and the speed of
+	// ambient animations
+	HighContrast  bool `json:"highContrast"`
+	ReduceFlashes bool `json:"reduceFlashes"`
+	Animation     int  `json:"animation"`
+	// Which keys or gamepad control the game
+	Controls int `json:"controls"`
+	// Tapping a direction turns the player without walking
+	TapToTurn bool `json:"tapToTurn"`
+	// The font, and its size as an index into textSizes
+	Font     int `json:"font"`
+	TextSize int `json:"textSize"`
+	// Keep the last few seconds of play, to save as a clip with F9
+	Recording bool `json:"recording"`
+	// How fast holding Tab runs the game, as an index into fastForwardModes
+	FastForward int `json:"fastForward"`
+	// Show the HUD strip along the top of the overworld
+	HUD bool `json:"hud"`
+	// Locale whose name filter checks the names typed in
+	Locale string `json:"locale"`
+}
+
+// defaultSettings returns the options used until the player changes them
+func defaultSettings() Settings {
+	return Settings{Vibration: true, TapToTurn: true, FastForward: 1, Locale: defaultLocale}
+}
+
+// loadSettings reads the options, falling back to the defaults
+func loadSettings() Settings {
+	settings := defaultSettings()
+	encoded, err := saveStore.Read(settingsFile)
+	if err != nil {
+		return settings
+	}
+	if err := json.Unmarshal(encoded, &settings); err != nil {
+		log.Println("Failed to read settings:", err)
+		return defaultSettings()
+	}
+	if settings.Controls < 0 || settings.Controls >= ControlsCount {
+		settings.Controls = ControlsArrows
+	}
+	if settings.Animation < 0 || settings.Animation >= AnimationCount {
+		settings.Animation = AnimationNormal
+	}
+	if settings.Font < 0 || settings.Font >= FontCount || settings.TextSize < 0 || settings.TextSize >= len(textSizes) {
+		settings.Font, settings.TextSize = FontPixel, 0
+	}
+	if settings.FastForward < 0 || settings.FastForward >= len(fastForwardModes) {
+		settings.FastForward = 1
+	}
+	return settings
+}
+
+// saveSettings writes the options
+func saveSettings(settings Settings) error {
+	encoded, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return err
+	}
+	return saveStore.Write(settingsFile, encoded)
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"math/rand"
+	"strconv"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Slot machine constants
+const (
+	slotReels = 3
+	// Most tokens bet on a spin
+	maxSlotBet = 3
+	// Height of a symbol on a reel, and how far a reel turns each frame
+	slotCellHeight = 28
+	slotSpinSpeed  = 9
+	// Width of a reel
+	slotReelWidth = 40
+	// Frames the winnings flash for
+	slotWinFrames = 90
+)
+
+// Slot machine symbols
+const (
+	SlotSeven = iota
+	SlotBar
+	SlotBall
+	SlotBerry
+	SlotCherry
+	SlotSymbolCount
+)
+
+// slotStrip is the symbols around each reel, the rarer ones paying more
+var slotStrip = []int{
+	SlotCherry, SlotBerry, SlotBall, SlotCherry, SlotBar, SlotBerry, SlotCherry, SlotSeven,
+	SlotBerry, SlotCherry, SlotBall, SlotBerry, SlotBar, SlotCherry, SlotBall,
+}
+
+// slotPayouts are the tokens paid for each token bet on three of a symbol
+// along the line
+var slotPayouts = [SlotSymbolCount]int{
+	SlotSeven:  100,
+	SlotBar:    40,
+	SlotBall:   15,
+	SlotBerry:  8,
+	SlotCherry: 6,
+}
+
+// SlotReel is one reel of a slot machine, turned to a position in pixels
+// along its strip
+type SlotReel struct {
+	position int
+	spinning bool
+}
+
+// symbol returns the symbol a reel has stopped on the line at
+func (r *SlotReel) symbol() int {
+	return slotStrip[r.position/slotCellHeight%len(slotStrip)]
+}
+
+// SlotMachine is the slot machine screen, one of the game corner's games
+type SlotMachine struct {
+	reels [slotReels]SlotReel
+	bet   int
+	// How many reels the player has stopped this spin
+	stopped int
+	// Result of the last spin, and how long its winnings flash for
+	message   string
+	winFrames int
+}
+
+// openSlots switches to the slot machine screen
+func (g *Game) openSlots() {
+	g.gameState = StateSlots
+	g.slots = SlotMachine{bet: 1}
+	for i := range g.slots.reels {
+		g.slots.reels[i].position = rand.Intn(len(slotStrip)) * slotCellHeight
+	}
+}
+
+// slotPayout works out what a spin pays for each token bet: three of a kind
+// along the line, or a cherry on the first reel
+func slotPayout(symbols [slotReels]int) int {
+	if symbols[0] == symbols[1] && symbols[1] == symbols[2] {
+		return slotPayouts[symbols[0]]
+	}
+	if symbols[0] == SlotCherry {
+		return 1
+	}
+	return 0
+}
+
+// updateSlots changes the bet and spins the reels, then stops them one at a
+// time from the left
+func (g *Game) updateSlots() {
+	s := &g.slots
+	if s.winFrames > 0 {
+		s.winFrames--
+	}
+	spinning := false
+	for i := range s.reels {
+		if r := &s.reels[i]; r.spinning {
+			r.position = (r.position + slotSpinSpeed) % (len(slotStrip) * slotCellHeight)
+			spinning = true
+		}
+	}
+	pressed := g.input.IsActionJustPressed(ebiten.KeySpace) || g.input.IsActionJustPressed(ebiten.KeyEnter)
+
+	if spinning {
+		if pressed {
+			g.stopReel()
+		}
+		return
+	}
+
+	if g.input.IsActionJustPressed(ebiten.KeyEscape) {
+		g.gameState = StateOverworld
+		return
+	}
+	if g.input.IsActionJustPressed(ebiten.KeyUp) {
+		s.bet = min(s.bet+1, maxSlotBet)
+	} else if g.input.IsActionJustPressed(ebiten.KeyDown) {
+		s.bet = max(s.bet-1, 1)
+	}
+	if !pressed {
+		return
+	}
+	if g.tokens < s.bet {
+		s.message = "You don't have enough tokens. Buy more at the prize counter."
+		return
+	}
+	g.tokens -= s.bet
+	s.stopped, s.message, s.winFrames = 0, "", 0
+	for i := range s.reels {
+		s.reels[i].spinning = true
+	}
+}
+
+// stopReel stops the next reel on the symbol coming up to the line, and pays
+// out once the last one stops
+func (g *Game) stopReel() {
+	s := &g.slots
+	r := &s.reels[s.stopped]
+	r.spinning = false
+	r.position = (r.position/slotCellHeight + 1) % len(slotStrip) * slotCellHeight
+	g.audio.playSound("bump")
+	s.stopped++
+	if s.stopped < slotReels {
+		return
+	}
+
+	var symbols [slotReels]int
+	for i := range s.reels {
+		symbols[i] = s.reels[i].symbol()
+	}
+	won := slotPayout(symbols) * s.bet
+	if won == 0 {
+		s.message = "No luck this time."
+		return
+	}
+	g.addTokens(won)
+	s.message = "You won " + strconv.Itoa(won) + " tokens!"
+	s.winFrames = slotWinFrames
+	if won >= slotPayouts[SlotBall] {
+		g.audio.playSound("fanfare")
+	} else {
+		g.audio.playSound("heal")
+	}
+}
+
+// drawSlots draws the slot machine: its reels behind a window with the line
+// across the middle, the bet and the tokens the player has
+func (g *Game) drawSlots(screen *ebiten.Image) {
+	s := &g.slots
+	g.drawPanel(screen, 10, 10, float32(screenWidth-20), float32(screenHeight-20), color.RGBA{120, 30, 50, 240})
+	g.drawText(screen, "Slots", 20, 30, color.White)
+	tokens := g.tokensText()
+	g.drawText(screen, tokens, float64(screenWidth-20-g.textWidth(tokens)), 30, color.RGBA{255, 220, 120, 255})
+
+	// The reels show a symbol above and below the one on the line
+	windowWidth := slotReels*(slotReelWidth+6) + 6
+	left := (screenWidth - windowWidth) / 2
+	top := g.listTop()
+	windowHeight := 3 * slotCellHeight
+	frame := color.RGBA{60, 20, 30, 255}
+	if s.winFrames > 0 && s.winFrames/8%2 == 0 {
+		frame = color.RGBA{255, 220, 80, 255}
+	}
+	vector.DrawFilledRect(screen, float32(left-4), float32(top-4), float32(windowWidth+8), float32(windowHeight+8), frame, false)
+	for i := range s.reels {
+		x := left + 6 + i*(slotReelWidth+6)
+		window := screen.SubImage(image.Rect(x, top, x+slotReelWidth, top+windowHeight)).(*ebiten.Image)
+		vector.DrawFilledRect(window, float32(x), float32(top), slotReelWidth, float32(windowHeight), color.RGBA{250, 245, 230, 255}, false)
+		position := s.reels[i].position
+		for row := -1; row <= 2; row++ {
+			index := (position/slotCellHeight + row - 1 + len(slotStrip)) % len(slotStrip)
+			y := top + row*slotCellHeight - position%slotCellHeight
+			g.drawSlotSymbol(window, float32(x+slotReelWidth/2), float32(y+slotCellHeight/2), slotStrip[index])
+		}
+	}
+	lineY := float32(top + windowHeight/2)
+	vector.StrokeLine(screen, float32(left-4), lineY, float32(left+windowWidth+4), lineY, 1, color.RGBA{220, 40, 40, 200}, false)
+
+	bet := g.fractionLabel("Bet: ", s.bet, maxSlotBet)
+	g.drawText(screen, bet, float64(left), float64(top+windowHeight+10), color.White)
+
+	hint := "Up/Down to bet, Space to spin, ESC to leave"
+	if s.stopped < slotReels && s.reels[s.stopped].spinning {
+		hint = "Space to stop the next reel"
+	}
+	hintTop := g.drawHint(screen, hint)
+	lines := g.wrapText(s.message, screenWidth-40)
+	for i, line := range lines {
+		g.drawText(screen, line, 20, float64(hintTop-(len(lines)-i)*g.lineSpacing()), color.RGBA{255, 255, 180, 255})
+	}
+}
+
+// drawSlotSymbol draws a reel symbol centered on x, y
+func (g *Game) drawSlotSymbol(screen *ebiten.Image, x, y float32, symbol int) {
+	switch symbol {
+	case SlotSeven:
+		g.drawText(screen, "7", float64(x)-float64(g.textWidth("7"))/2, float64(y)-float64(g.lineHeight())/2, color.RGBA{210, 30, 30, 255})
+	case SlotBar:
+		vector.DrawFilledRect(screen, x-14, y-6, 28, 12, color.RGBA{30, 30, 40, 255}, true)
+		g.drawText(screen, "BAR", float64(x)-float64(g.textWidth("BAR"))/2, float64(y)-float64(g.lineHeight())/2, color.White)
+	case SlotBall:
+		drawBall(screen, x, y, 8)
+	case SlotBerry:
+		vector.DrawFilledCircle(screen, x, y+1, 7, color.RGBA{60, 90, 210, 255}, true)
+		vector.DrawFilledRect(screen, x-1, y-9, 2, 4, color.RGBA{60, 140, 60, 255}, true)
+	case SlotCherry:
+		vector.StrokeLine(screen, x-4, y+2, x+1, y-8, 1.5, color.RGBA{60, 140, 60, 255}, true)
+		vector.StrokeLine(screen, x+4, y+2, x+1, y-8, 1.5, color.RGBA{60, 140, 60, 255}, true)
+		vector.DrawFilledCircle(screen, x-4, y+4, 4, color.RGBA{220, 30, 50, 255}, true)
+		vector.DrawFilledCircle(screen, x+4, y+4, 4, color.RGBA{220, 30, 50, 255}, true)
+	}
+}
@@ -0,0 +1,488 @@
+package main
+
+import (
+	"image/color"
+	"math/rand"
+
+	"creaturegame-2/engine"
+)
+
+// Species describes a kind of creature; stats are the base values at level 5
+type Species struct {
+	name    string
+	type1   string
+	hp      int
+	attack  int
+	defense int
+	speed   int
+	color   color.RGBA
+	moves   []Move
+	// Field ability the species can use outside battle, if any
+	ability string
+	// Species it evolves into when traded, if any
+	tradeEvolution string
+	// Species it evolves into on reaching a level, if any, and that level
+	evolution   string
+	evolveLevel int
+	// Moves it can pick up on evolving into it, once at a high enough level
+	learnset []LearnedMove
+	// Dex entry, and height in meters and weight in kilograms
+	entry          string
+	height, weight float64
+	// Second type, and the two species fused to make it, for fused forms
+	type2     string
+	fusedFrom [2]string
+	// Regional forms found in particular biomes
+	forms []SpeciesForm
+}
+
+// SpeciesForm is a regional form of a species: the same dex entry, but with
+// its own type, base stats, colors and moves where it's found in the wild
+type SpeciesForm struct {
+	name string
+	// Biome whose wild creatures of the species are all in this form
+	biome int
+	type1 string
+	// Base stats at level 5, in place of the species'
+	hp, attack, defense, speed int
+	color                      color.RGBA
+	moves                      []Move
+}
+
+// LearnedMove is a move a species can learn from a level on
+type LearnedMove struct {
+	level int
+	move  Move
+}
+
+// speciesList holds every species in the game
+var speciesList = []Species{
+	{
+		name:    "Sparkitty",
+		type1:   "Electric",
+		hp:      50,
+		attack:  12,
+		defense: 10,
+		speed:   15,
+		color:   color.RGBA{255, 255, 0, 255},
+		moves: []Move{
+			{name: "Tackle", power: 40, accuracy: 100, type1: "Normal", maxPP: 35},
+			{name: "Spark", power: 50, accuracy: 90, type1: "Electric", maxPP: 25},
+		},
+		ability: AbilityCut,
+		entry:   "Its fur builds up static as it naps in the sun. Petting one is a shocking experience.",
+		height:  0.4,
+		weight:  4.2,
+	},
+	{
+		name:    "Flamepup",
+		type1:   "Fire",
+		hp:      45,
+		attack:  15,
+		defense: 8,
+		speed:   12,
+		color:   color.RGBA{255, 100, 0, 255},
+		moves: []Move{
+			{name: "Tackle", power: 40, accuracy: 100, type1: "Normal", maxPP: 35},
+			{name: "Ember", power: 50, accuracy: 90, type1: "Fire", maxPP: 25},
+		},
+		forms: []SpeciesForm{
+			{
+				name: "Dune", biome: BiomeDesert, type1: "Ground",
+				hp: 48, attack: 14, defense: 11, speed: 12,
+				color: color.RGBA{215, 180, 110, 255},
+				moves: []Move{
+					{name: "Tackle", power: 40, accuracy: 100, type1: "Normal", maxPP: 35},
+					{name: "Mud Shot", power: 50, accuracy: 90, type1: "Ground", maxPP: 25},
+				},
+			},
+		},
+		learnset: []LearnedMove{
+			{12, Move{name: "Fire Spin", power: 35, accuracy: 85, type1: "Fire", maxPP: 15, kind: engine.KindBind}},
+		},
+		ability:     AbilityLure,
+		evolution:   "Magmite",
+		evolveLevel: 16,
+		entry:       "The flame on its tail burns hotter when it is happy. It sleeps curled around it to keep warm.",
+		height:      0.5,
+		weight:      7.5,
+	},
+	{
+		name:    "Bubblefrog",
+		type1:   "Water",
+		hp:      55,
+		attack:  10,
+		defense: 12,
+		speed:   10,
+		color:   color.RGBA{0, 100, 255, 255},
+		moves: []Move{
+			{name: "Tackle", power: 40, accuracy: 100, type1: "Normal", maxPP: 35},
+			{name: "Bubble", power: 50, accuracy: 90, type1: "Water", maxPP: 25},
+		},
+		forms: []SpeciesForm{
+			{
+				name: "Frost", biome: BiomeSnowfield, type1: "Ice",
+				hp: 58, attack: 11, defense: 13, speed: 8,
+				color: color.RGBA{170, 220, 250, 255},
+				moves: []Move{
+					{name: "Tackle", power: 40, accuracy: 100, type1: "Normal", maxPP: 35},
+					{name: "Ice Shard", power: 50, accuracy: 90, type1: "Ice", maxPP: 25},
+				},
+			},
+		},
+		learnset: []LearnedMove{
+			{14, Move{name: "Whirlpool", power: 35, accuracy: 85, type1: "Water", maxPP: 15, kind: engine.KindBind}},
+		},
+		ability: AbilityStrength,
+		entry:   "It blows bubbles from its throat sac to trap insects. Ponds where it lives are always clear.",
+		height:  0.4,
+		weight:  5.8,
+	},
+	{
+		name:    "Zephyrd",
+		type1:   "Flying",
+		hp:      42,
+		attack:  11,
+		defense: 9,
+		speed:   17,
+		color:   color.RGBA{180, 220, 255, 255},
+		moves: []Move{
+			{name: "Peck", power: 35, accuracy: 100, type1: "Flying", maxPP: 35},
+			{name: "Gust", power: 45, accuracy: 95, type1: "Flying", maxPP: 25},
+		},
+		learnset: []LearnedMove{
+			{18, Move{name: "Fly", power: 90, accuracy: 95, type1: "Flying", maxPP: 15, kind: engine.KindVanish}},
+		},
+		entry:  "It rides warm updrafts for days without flapping. Its calls carry across whole valleys.",
+		height: 0.6,
+		weight: 3.1,
+	},
+	{
+		name:    "Leafling",
+		type1:   "Grass",
+		hp:      48,
+		attack:  11,
+		defense: 12,
+		speed:   11,
+		color:   color.RGBA{60, 200, 80, 255},
+		moves: []Move{
+			{name: "Tackle", power: 40, accuracy: 100, type1: "Normal", maxPP: 35},
+			{name: "Vine Whip", power: 45, accuracy: 100, type1: "Grass", maxPP: 25},
+		},
+		learnset: []LearnedMove{
+			{10, Move{name: "Mega Drain", power: 40, accuracy: 100, type1: "Grass", maxPP: 15, kind: engine.KindDrain}},
+			{22, Move{name: "Solar Beam", power: 120, accuracy: 100, type1: "Grass", maxPP: 10, kind: engine.KindCharge}},
+		},
+		ability: AbilityCut,
+		entry:   "It buries its feet in the soil at night to drink. The leaf on its head turns toward the sun.",
+		height:  0.3,
+		weight:  3.9,
+	},
+	{
+		name:    "Sandcrab",
+		type1:   "Ground",
+		hp:      50,
+		attack:  13,
+		defense: 15,
+		speed:   7,
+		color:   color.RGBA{220, 190, 120, 255},
+		moves: []Move{
+			{name: "Scratch", power: 40, accuracy: 100, type1: "Normal", maxPP: 35},
+			{name: "Mud Shot", power: 50, accuracy: 90, type1: "Ground", maxPP: 25},
+		},
+		learnset: []LearnedMove{
+			{14, Move{name: "Dig", power: 80, accuracy: 100, type1: "Ground", maxPP: 10, kind: engine.KindVanish}},
+		},
+		ability: AbilityCut,
+		entry:   "It digs burrows in the desert sand and waits there for hours, with only its eyes showing.",
+		height:  0.5,
+		weight:  12.4,
+	},
+	{
+		name:    "Bogtoad",
+		type1:   "Poison",
+		hp:      58,
+		attack:  12,
+		defense: 11,
+		speed:   8,
+		color:   color.RGBA{120, 80, 160, 255},
+		moves: []Move{
+			{name: "Tackle", power: 40, accuracy: 100, type1: "Normal", maxPP: 35},
+			{name: "Sludge", power: 55, accuracy: 85, type1: "Poison", maxPP: 15, effect: EffectPoison, effectChance: 30},
+		},
+		ability: AbilityStrength,
+		entry:   "It lounges in swamp water that would make others ill. Its skin oozes a mild poison.",
+		height:  0.7,
+		weight:  15.0,
+	},
+	{
+		name:    "Frostfox",
+		type1:   "Ice",
+		hp:      46,
+		attack:  13,
+		defense: 9,
+		speed:   16,
+		color:   color.RGBA{200, 240, 255, 255},
+		moves: []Move{
+			{name: "Quick Attack", power: 40, accuracy: 100, type1: "Normal", maxPP: 35},
+			{name: "Ice Shard", power: 50, accuracy: 90, type1: "Ice", maxPP: 25},
+		},
+		ability: AbilityStealth,
+		entry:   "Its breath freezes into glittering dust in the air. It hunts alone across snowfields.",
+		height:  0.8,
+		weight:  11.6,
+	},
+	{
+		name:    "Pebblit",
+		type1:   "Rock",
+		hp:      52,
+		attack:  14,
+		defense: 16,
+		speed:   5,
+		color:   color.RGBA{140, 130, 120, 255},
+		moves: []Move{
+			{name: "Tackle", power: 40, accuracy: 100, type1: "Normal", maxPP: 35},
+			{name: "Rock Throw", power: 50, accuracy: 90, type1: "Rock", maxPP: 25},
+		},
+		learnset: []LearnedMove{
+			{12, Move{name: "Take Down", power: 90, accuracy: 85, type1: "Normal", maxPP: 20, kind: engine.KindRecoil}},
+		},
+		forms: []SpeciesForm{
+			{
+				name: "Molten", biome: BiomeVolcanic, type1: "Fire",
+				hp: 50, attack: 16, defense: 13, speed: 7,
+				color: color.RGBA{210, 90, 40, 255},
+				moves: []Move{
+					{name: "Tackle", power: 40, accuracy: 100, type1: "Normal", maxPP: 35},
+					{name: "Ember", power: 50, accuracy: 90, type1: "Fire", maxPP: 25},
+				},
+			},
+		},
+		ability:        AbilityStrength,
+		tradeEvolution: "Bouldron",
+		entry:          "It is often mistaken for a rock, which it does not mind. It eats gravel for the minerals.",
+		height:         0.3,
+		weight:         20.0,
+	},
+	{
+		name:    "Magmite",
+		type1:   "Fire",
+		hp:      54,
+		attack:  16,
+		defense: 13,
+		speed:   9,
+		color:   color.RGBA{200, 40, 20, 255},
+		moves: []Move{
+			{name: "Ember", power: 50, accuracy: 90, type1: "Fire", maxPP: 25},
+			{name: "Rock Throw", power: 50, accuracy: 90, type1: "Rock", maxPP: 25},
+		},
+		learnset: []LearnedMove{
+			{16, Move{name: "Rock Throw", power: 50, accuracy: 90, type1: "Rock", maxPP: 25}},
+			{20, Move{name: "Rock Slide", power: 75, accuracy: 90, type1: "Rock", maxPP: 10}},
+		},
+		entry:  "Magma flows through the cracks in its hide. The ground where it sleeps stays warm for days.",
+		height: 1.1,
+		weight: 42.0,
+	},
+	{
+		// Only obtained by trading a Pebblit
+		name:    "Bouldron",
+		type1:   "Rock",
+		hp:      66,
+		attack:  19,
+		defense: 21,
+		speed:   6,
+		color:   color.RGBA{110, 100, 90, 255},
+		moves: []Move{
+			{name: "Tackle", power: 40, accuracy: 100, type1: "Normal", maxPP: 35},
+			{name: "Rock Slide", power: 75, accuracy: 90, type1: "Rock", maxPP: 10},
+		},
+		ability: AbilityStrength,
+		learnset: []LearnedMove{
+			{1, Move{name: "Rock Slide", power: 75, accuracy: 90, type1: "Rock", maxPP: 10}},
+		},
+		entry:  "It only grows this large after being traded, though no one knows why. It can shrug off landslides.",
+		height: 1.6,
+		weight: 210.0,
+	},
+	{
+		// Legendary; only ever met roaming the routes
+		name:    "Voltigon",
+		type1:   "Electric",
+		hp:      70,
+		attack:  18,
+		defense: 14,
+		speed:   22,
+		color:   color.RGBA{250, 220, 60, 255},
+		moves: []Move{
+			{name: "Spark", power: 50, accuracy: 90, type1: "Electric", maxPP: 25},
+			{name: "Thunderclap", power: 80, accuracy: 85, type1: "Electric", maxPP: 10},
+		},
+		entry:  "It races across the land with the speed of lightning. Few have seen it and fewer have caught it.",
+		height: 2.1,
+		weight: 68.5,
+	},
+}
+
+// findSpecies looks up a species or a fused form made so far by name,
+// returning nil if it doesn't exist
+func findSpecies(name string) *Species {
+	if species := findBaseSpecies(name); species != nil {
+		return species
+	}
+	for _, species := range fusedOrder {
+		if species.name == name {
+			return species
+		}
+	}
+	return nil
+}
+
+// findBaseSpecies looks up a species by name, leaving out fused forms
+func findBaseSpecies(name string) *Species {
+	for i := range speciesList {
+		if speciesList[i].name == name {
+			return &speciesList[i]
+		}
+	}
+	return nil
+}
+
+// speciesCount returns how many species the dex lists: every base species
+// and the fused forms made so far
+func speciesCount() int {
+	return len(speciesList) + len(fusedOrder)
+}
+
+// speciesAt returns the species at an index in dex order, where fused forms
+// come after the base species in the order they were made
+func speciesAt(i int) *Species {
+	if i < len(speciesList) {
+		return &speciesList[i]
+	}
+	return fusedOrder[i-len(speciesList)]
+}
+
+// newCreature creates a fully healed creature of the named species at a level
+func newCreature(name string, level int) Creature {
+	return newCreatureForm(name, "", level)
+}
+
+// newCreatureForm creates a creature of a species' regional form, or of the
+// species itself if form is empty, at the given level
+func newCreatureForm(name, form string, level int) Creature {
+	species := findSpecies(name)
+	if species == nil {
+		panic("unknown species " + name)
+	}
+	variant := species.withForm(form)
+	if variant == species {
+		form = ""
+	}
+	species = variant
+
+	maxHP := scaleStat(species.hp, level)
+
+	// Every move starts with full PP
+	moves := append([]Move(nil), species.moves...)
+	for i := range moves {
+		moves[i].pp = moves[i].maxPP
+	}
+
+	return Creature{
+		name:    species.name,
+		hp:      maxHP,
+		maxHP:   maxHP,
+		attack:  scaleStat(species.attack, level),
+		defense: scaleStat(species.defense, level),
+		speed:   scaleStat(species.speed, level),
+		type1:   species.type1,
+		type2:   species.type2,
+		level:   level,
+		color:   species.color,
+		moves:   moves,
+		id:      rand.Intn(100000),
+		form:    form,
+
+		friendship: baseFriendship,
+	}
+}
+
+// withForm returns a species as it is in one of its regional forms, or the
+// species itself if it has no form by that name
+func (s *Species) withForm(form string) *Species {
+	for _, f := range s.forms {
+		if f.name != form {
+			continue
+		}
+		variant := *s
+		variant.type1, variant.type2 = f.type1, ""
+		variant.hp, variant.attack, variant.defense, variant.speed = f.hp, f.attack, f.defense, f.speed
+		variant.color, variant.moves = f.color, f.moves
+		return &variant
+	}
+	return s
+}
+
+// regionalForm returns the name of a species' form found in a biome, or
+// nothing if it looks the same there as anywhere else
+func regionalForm(name string, biome int) string {
+	for _, f := range findSpecies(name).forms {
+		if f.biome == biome {
+			return f.name
+		}
+	}
+	return ""
+}
+
+// formName returns a creature's species name, led by its form if it has one
+func formName(name, form string) string {
+	if form == "" {
+		return name
+	}
+	return form + " " + name
+}
+
+// scaleStat scales a level 5 base stat to the given level
+func scaleStat(base, level int) int {
+	return base * (level + 10) / 15
+}
+
+// EncounterEntry is one possible wild encounter in an encounter table
+type EncounterEntry struct {
+	species  string
+	minLevel int
+	maxLevel int
+	weight   int
+	// World state the entry only appears in; the zero value means always
+	when EncounterCondition
+}
+
+// rollEncounter picks a wild creature from a weighted encounter table,
+// leaving out entries whose conditions the world doesn't meet
+func rollEncounter(table []EncounterEntry, state WorldState) Creature {
+	total := 0
+	for _, entry := range table {
+		if entry.when.met(state) {
+			total += entry.weight
+		}
+	}
+	if total == 0 {
+		return newCreature(table[0].species, table[0].minLevel)
+	}
+
+	roll := rand.Intn(total)
+	for _, entry := range table {
+		if !entry.when.met(state) {
+			continue
+		}
+		if roll < entry.weight {
+			level := entry.minLevel + rand.Intn(entry.maxLevel-entry.minLevel+1)
+			return newCreature(entry.species, level)
+		}
+		roll -= entry.weight
+	}
+
+	// Unreachable with positive weights, but fall back to the first entry
+	return newCreature(table[0].species, table[0].minLevel)
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// spriteFrameSize is the width and height of a sprite sheet frame, in pixels
+const spriteFrameSize = tileSize
+
+// SpriteSheet is a grid of animation frames with a row for each direction
+type SpriteSheet struct {
+	image *ebiten.Image
+}
+
+// frame returns one frame of the sheet, which may sit anywhere in the atlas
+func (s *SpriteSheet) frame(direction, column int) *ebiten.Image {
+	corner := s.image.Bounds().Min
+	x, y := corner.X+column*spriteFrameSize, corner.Y+direction*spriteFrameSize
+	return s.image.SubImage(image.Rect(x, y, x+spriteFrameSize, y+spriteFrameSize)).(*ebiten.Image)
+}
+
+// Animation is a sequence of sheet columns, each shown for some ticks
+type Animation struct {
+	columns []int
+	ticks   []int
+}
+
+// length returns how many ticks the animation takes to play once
+func (a Animation) length() int {
+	total := 0
+	for _, t := range a.ticks {
+		total += t
+	}
+	return total
+}
+
+// column returns the column showing after some ticks, looping
+func (a Animation) column(elapsed int) int {
+	elapsed %= a.length()
+	for i, t := range a.ticks {
+		if elapsed < t {
+			return a.columns[i]
+		}
+		elapsed -= t
+	}
+	return a.columns[0]
+}
+
+// AnimatedSprite plays named animations from a sprite sheet in the direction
+// it faces. It's timed by the game's animation ticker, so it slows down and
+// stops along with the other ambient animations.
+type AnimatedSprite struct {
+	sheet      *SpriteSheet
+	animations map[string]Animation
+	current    string
+	direction  int
+	// Ticker value when the current animation started
+	started int
+}
+
+// play switches to an animation, starting it from the beginning unless it's
+// already playing
+func (s *AnimatedSprite) play(name string, ticks int) {
+	if s.current == name {
+		return
+	}
+	s.current = name
+	s.started = ticks
+}
+
+// draw draws the current frame with its top-left corner at x, y
+func (s *AnimatedSprite) draw(screen *ebiten.Image, x, y float32, ticks int) {
+	s.drawScaled(screen, x, y, 1, ticks)
+}
+
+// drawScaled draws the current frame scaled up by a whole number, with its
+// top-left corner at x, y
+func (s *AnimatedSprite) drawScaled(screen *ebiten.Image, x, y float32, scale, ticks int) {
+	animation := s.animations[s.current]
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(float64(scale), float64(scale))
+	op.GeoM.Translate(float64(x), float64(y))
+	screen.DrawImage(s.sheet.frame(s.direction, animation.column(ticks-s.started)), op)
+}
+
+// Animations shared by every person and creature sprite
+var (
+	personAnimations = map[string]Animation{
+		"idle": {columns: []int{0, 3}, ticks: []int{150, 8}},
+		"walk": {columns: []int{1, 0, 2, 0}, ticks: []int{4, 4, 4, 4}},
+		// Pedaling a bike, legs pumping faster than walking
+		"ride": {columns: []int{1, 2}, ticks: []int{3, 3}},
+	}
+	creatureAnimations = map[string]Animation{
+		"idle": {columns: []int{0, 1}, ticks: []int{30, 30}},
+		"walk": {columns: []int{0, 1}, ticks: []int{8, 8}},
+	}
+)
+
+// newPersonSprite creates a sprite for a person wearing the given color
+func newPersonSprite(clothes color.RGBA) *AnimatedSprite {
+	return &AnimatedSprite{sheet: drawPersonSheet(clothes), animations: personAnimations, current: "idle", direction: DirectionDown}
+}
+
+// newCreatureSprite creates a sprite for a creature of the given color
+func newCreatureSprite(body color.RGBA) *AnimatedSprite {
+	return &AnimatedSprite{sheet: drawCreatureSheet(body), animations: creatureAnimations, current: "idle", direction: DirectionDown}
+}
+
+// colorName names a sprite after its main color, like "person-f0c040"
+func colorName(kind string, clr color.RGBA) string {
+	return fmt.Sprintf("%s-%02x%02x%02x", kind, clr.R, clr.G, clr.B)
+}
+
+// drawSheet draws each frame of a sheet with a number of columns into the
+// atlas, or returns it if it's there already
+func drawSheet(name string, columns int, drawFrame func(frame *ebiten.Image, direction, column int)) *SpriteSheet {
+	sheetImage := spriteAtlas().sprite(name, columns*spriteFrameSize, 4*spriteFrameSize, func(canvas *ebiten.Image) {
+		sheet := &SpriteSheet{image: canvas}
+		for direction := range 4 {
+			for column := range columns {
+				drawInto(sheet.frame(direction, column), spriteFrameSize, spriteFrameSize, func(frame *ebiten.Image) {
+					drawFrame(frame, direction, column)
+				})
+			}
+		}
+	})
+	return &SpriteSheet{image: sheetImage}
+}
+
+// drawPersonSheet draws a person's sheet. Its columns are standing, two
+// walking steps and a blink.
+func drawPersonSheet(clothes color.RGBA) *SpriteSheet {
+	skin := color.RGBA{240, 200, 170, 255}
+	dark := color.RGBA{40, 30, 30, 255}
+
+	return drawSheet(colorName("person", clothes), 4, func(frame *ebiten.Image, direction, column int) {
+		// Legs, one forward on each walking step
+		leftLeg, rightLeg := float32(0), float32(0)
+		switch column {
+		case 1:
+			leftLeg = -2
+		case 2:
+			rightLeg = -2
+		}
+		vector.DrawFilledRect(frame, 11, 26+leftLeg, 4, 5-leftLeg, dark, true)
+		vector.DrawFilledRect(frame, 17, 26+rightLeg, 4, 5-rightLeg, dark, true)
+
+		// Body and head
+		vector.DrawFilledRect(frame, 9, 14, 14, 13, clothes, true)
+		vector.DrawFilledCircle(frame, 16, 9, 6, skin, true)
+
+		// Eyes show which way the person faces; nothing shows from behind
+		if column == 3 {
+			vector.StrokeLine(frame, 12, 9, 20, 9, 1, dark, true)
+			return
+		}
+		switch direction {
+		case DirectionDown:
+			vector.DrawFilledRect(frame, 13, 8, 2, 2, dark, true)
+			vector.DrawFilledRect(frame, 17, 8, 2, 2, dark, true)
+		case DirectionLeft:
+			vector.DrawFilledRect(frame, 11, 8, 2, 2, dark, true)
+		case DirectionRight:
+			vector.DrawFilledRect(frame, 19, 8, 2, 2, dark, true)
+		case DirectionUp:
+			vector.DrawFilledCircle(frame, 16, 8, 5, color.RGBA{90, 60, 40, 255}, true)
+		}
+	})
+}
+
+// drawCreatureSheet draws a small creature's sheet. Its columns are resting
+// and mid-hop.
+func drawCreatureSheet(body color.RGBA) *SpriteSheet {
+	dark := color.RGBA{30, 30, 30, 255}
+
+	return drawSheet(colorName("creature", body), 2, func(frame *ebiten.Image, direction, column int) {
+		lift := float32(column * 3)
+
+		vector.DrawFilledCircle(frame, 16, 29, 7, color.RGBA{0, 0, 0, 60}, true)
+		vector.DrawFilledCircle(frame, 16, 20-lift, 9, body, true)
+
+		dx, _ := directionDelta(direction)
+		switch direction {
+		case DirectionUp:
+		case DirectionDown:
+			vector.DrawFilledRect(frame, 12, 18-lift, 2, 3, dark, true)
+			vector.DrawFilledRect(frame, 18, 18-lift, 2, 3, dark, true)
+		default:
+			vector.DrawFilledRect(frame, 15+float32(dx*5), 18-lift, 2, 3, dark, true)
+		}
+	})
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// starterSpecies are the creatures offered at the start of a new game
+var starterSpecies = []string{"Sparkitty", "Flamepup", "Bubblefrog"}
+
+// starterLevel is the level starters are given at
+const starterLevel = 5
+
+// StarterScene is the scene at the start of a new game where the player
+// picks their first creature and the rival picks theirs
+type StarterScene struct {
+	selected   int
+	introduced bool
+	chosen     bool
+	sprites    []*AnimatedSprite
+}
+
+// openStarterScene switches to the starter scene
+func (g *Game) openStarterScene() {
+	g.starter = StarterScene{}
+	for _, name := range starterSpecies {
+		g.starter.sprites = append(g.starter.sprites, newCreatureSprite(findSpecies(name).color))
+	}
+	g.gameState = StateStarter
+}
+
+// starterRects lays out the starters side by side across the screen
+func starterRects() []Rect {
+	width := tileSize*2 + 16
+	gap := (screenWidth - len(starterSpecies)*width) / (len(starterSpecies) + 1)
+	rects := make([]Rect, len(starterSpecies))
+	for i := range rects {
+		rects[i] = Rect{gap + i*(width+gap), 50, width, tileSize*2 + 16}
+	}
+	return rects
+}
+
+// updateStarterScene reads out the scene's dialogue and lets the player look
+// over the starters, asking before they take one
+func (g *Game) updateStarterScene() {
+	s := &g.starter
+	if g.dialogue.active {
+		g.updateDialogue()
+		return
+	}
+
+	if !s.introduced {
+		s.introduced = true
+		g.showDialogue("Welcome, {player}! Every trainer starts out with a partner. Pick one of these three creatures to take with you.")
+		return
+	}
+	if s.chosen {
+		g.gameState = StateOverworld
+		return
+	}
+
+	if g.input.IsActionJustPressed(ebiten.KeyLeft) {
+		s.selected = (s.selected - 1 + len(starterSpecies)) % len(starterSpecies)
+	} else if g.input.IsActionJustPressed(ebiten.KeyRight) {
+		s.selected = (s.selected + 1) % len(starterSpecies)
+	}
+	clicked := g.mouseSelect(starterRects(), &s.selected)
+
+	if g.input.IsActionJustPressed(ebiten.KeySpace) || g.input.IsActionJustPressed(ebiten.KeyEnter) || clicked {
+		species := findSpecies(starterSpecies[s.selected])
+		g.audio.playCry(species.name)
+		choice := s.selected
+		g.showPrompt("So you want "+species.name+", the "+species.type1+" type creature?", func() {
+			g.chooseStarter(choice)
+		})
+	}
+}
+
+// chooseStarter gives the player the starter they picked, and the rival the
+// one strong against it
+func (g *Game) chooseStarter(i int) {
+	c := newCreature(starterSpecies[i], starterLevel)
+	c.trainer = g.playerName
+	g.recordMet(&c)
+	g.creatures = []Creature{c}
+	g.activeCreature = 0
+	g.events.publish(Event{kind: EventReceive, species: c.name})
+
+	g.rivalStarter = rivalStarter(c.name)
+	g.starter.chosen = true
+	g.audio.playSound("fanfare")
+	g.showDialogue("{player} received " + c.name + "! {rival}: Then I'll take " + g.rivalStarter + ". Its type beats yours, so don't expect to win when we battle!")
+}
+
+// rivalStarter picks the starter whose type hits the player's hardest,
+// breaking ties by which one the player's type hits least
+func rivalStarter(player string) string {
+	playerType := findSpecies(player).type1
+	best := ""
+	bestHit, bestTaken := float32(-1), float32(0)
+	for _, name := range starterSpecies {
+		if name == player {
+			continue
+		}
+		rivalType := findSpecies(name).type1
+		hit, taken := typeEffectiveness(rivalType, playerType), typeEffectiveness(playerType, rivalType)
+		if hit > bestHit || hit == bestHit && taken < bestTaken {
+			best, bestHit, bestTaken = name, hit, taken
+		}
+	}
+	return best
+}
+
+// drawStarterScene draws the starters on offer, with the highlighted one's
+// name, type and dex entry below
+func (g *Game) drawStarterScene(screen *ebiten.Image) {
+	s := &g.starter
+	screen.Fill(color.RGBA{50, 70, 60, 255})
+
+	drawText := func(x, y int, line string, clr color.Color) {
+		op := &text.DrawOptions{}
+		op.GeoM.Translate(float64(x), float64(y))
+		op.ColorScale.ScaleWithColor(g.uiText(clr))
+		text.Draw(screen, line, g.fontFace, op)
+	}
+	drawText(20, 30, "Choose your first creature", color.White)
+
+	for i, r := range starterRects() {
+		// Taken starters are left as empty balls
+		name := starterSpecies[i]
+		taken := s.chosen && (name == g.creatures[0].name || name == g.rivalStarter)
+		vector.DrawFilledRect(screen, float32(r.x), float32(r.y+r.height-10), float32(r.width), 10, color.RGBA{150, 100, 60, 255}, false)
+		if taken {
+			drawBall(screen, float32(r.x+r.width/2), float32(r.y+r.height-16), 6)
+			continue
+		}
+		if i == s.selected && !s.chosen {
+			vector.StrokeRect(screen, float32(r.x), float32(r.y), float32(r.width), float32(r.height), 2, color.RGBA{200, 60, 40, 255}, false)
+		}
+		s.sprites[i].drawScaled(screen, float32(r.x+8), float32(r.y), 2, g.ticks)
+	}
+
+	if s.chosen {
+		g.drawDialogue(screen)
+		return
+	}
+
+	species := findSpecies(starterSpecies[s.selected])
+	y := starterRects()[0].y + tileSize*2 + 26
+	drawText(20, y, species.name+"  "+species.type1+" type", color.White)
+	if !g.dialogue.active {
+		for i, line := range g.wrapText(species.entry, screenWidth-40) {
+			drawText(20, y+(i+1)*g.lineSpacing(), line, color.RGBA{220, 220, 200, 255})
+		}
+		g.drawHint(screen, "Left/Right to look, Space to choose")
+	}
+	g.drawDialogue(screen)
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// StaticEncounter is a fixed wild creature placed on the map that battles
+// the player when interacted with, and is gone for good once beaten
+type StaticEncounter struct {
+	// Flag set once the creature is beaten
+	id      string
+	species string
+	level   int
+	// Flag the player needs before the creature will battle; none if empty
+	requires string
+	// Shown while the requirement isn't met
+	blockedText string
+	// Question asked before the battle starts
+	prompt string
+	// Drawn asleep rather than awake
+	sleeping bool
+	// Boss the creature is fought as, from bosses/bosses.json, if any
+	boss string
+}
+
+// addStaticEncounter places a static encounter on the map, covering one or
+// more tiles
+func (m *Map) addStaticEncounter(encounter *StaticEncounter, tiles ...Point) {
+	for _, pos := range tiles {
+		m.objects[pos] = &MapObject{kind: ObjectStaticEncounter, encounter: encounter}
+	}
+}
+
+// removeStaticEncounter takes every tile of a static encounter off the map
+func (m *Map) removeStaticEncounter(id string) {
+	for pos, object := range m.objects {
+		if object.kind == ObjectStaticEncounter && object.encounter.id == id {
+			delete(m.objects, pos)
+		}
+	}
+}
+
+// placeRouteSleeper puts a sleeping creature across both lanes of the last
+// route, partway along
+func (m *Map) placeRouteSleeper() {
+	route := m.plan.routes[len(m.plan.routes)-1]
+	path := route.tiles()
+	i := len(path)/2 + 3
+	if i >= len(path)-1 {
+		return
+	}
+
+	// The lanes run beside the center line, away from the direction of travel
+	pos, next := path[i], path[i+1]
+	lane := Point{pos.x, pos.y + 1}
+	if next.y != pos.y {
+		lane = Point{pos.x + 1, pos.y}
+	}
+
+	m.addStaticEncounter(&StaticEncounter{
+		id:       "route-sleeper",
+		species:  "Bogtoad",
+		level:    12,
+		prompt:   "A huge Bogtoad is sleeping across the path, snoring loudly. Wake it up?",
+		sleeping: true,
+		boss:     "route-sleeper",
+	}, pos, lane)
+}
+
+// addCaveGuardian puts a guardian creature in a cave, standing at x, y
+func (m *Map) addCaveGuardian(x, y int) {
+	m.addStaticEncounter(&StaticEncounter{
+		id:          m.id + "-guardian",
+		species:     "Magmite",
+		level:       18,
+		requires:    "badge1",
+		blockedText: "The Magmite stands guard and won't move. It seems to be waiting for a trainer who has earned a badge.",
+		prompt:      "The Magmite guarding the way glares at you! Challenge it?",
+		boss:        "cave-guardian",
+	}, Point{x, y})
+}
+
+// interactStaticEncounter offers to battle a static encounter, if the player
+// has met its requirement
+func (g *Game) interactStaticEncounter(encounter *StaticEncounter) {
+	if encounter.requires != "" && !g.flags[encounter.requires] {
+		g.showDialogue(encounter.blockedText)
+		return
+	}
+
+	g.showPrompt(encounter.prompt, func() {
+		if encounter.boss != "" {
+			g.startBossBattle(encounter.boss)
+			g.battle.static = encounter
+			return
+		}
+		g.startBattle(newCreature(encounter.species, encounter.level))
+		if g.gameState == StateBattle {
+			g.battle.static = encounter
+			g.battle.announcement = fmt.Sprintf("The %s attacked!", encounter.species)
+		}
+	})
+}
+
+// finishStaticEncounter removes a static encounter for good once it's beaten
+func (g *Game) finishStaticEncounter(encounter *StaticEncounter) {
+	g.flags[encounter.id] = true
+	g.worldMap.removeStaticEncounter(encounter.id)
+}
+
+// drawStaticEncounter draws a static encounter's creature, with Zs over it
+// while it sleeps
+func (g *Game) drawStaticEncounter(screen *ebiten.Image, x, y float32, encounter *StaticEncounter) {
+	species := findSpecies(encounter.species)
+	vector.DrawFilledCircle(screen, x, y+2, 13, color.RGBA{30, 30, 30, 255}, true)
+	vector.DrawFilledCircle(screen, x, y+2, 12, species.color, true)
+
+	if encounter.sleeping {
+		// Zs drift up and down
+		offset := float32(g.ticks / 20 % 3)
+		for i := range 2 {
+			zx, zy := x+6+float32(i*5), y-12-float32(i*5)-offset
+			vector.StrokeLine(screen, zx, zy, zx+4, zy, 1, color.White, true)
+			vector.StrokeLine(screen, zx+4, zy, zx, zy+4, 1, color.White, true)
+			vector.StrokeLine(screen, zx, zy+4, zx+4, zy+4, 1, color.White, true)
+		}
+	}
+}
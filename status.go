@@ -0,0 +1,129 @@
+package main
+
+import (
+	"image/color"
+
+	"creaturegame-2/engine"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Overworld status constants
+const (
+	// Poisoned creatures lose 1 HP every this many steps
+	poisonStepInterval = 4
+	// Frames the screen flashes for when poison hurts the party
+	poisonFlashFrames = 8
+	// Share of max HP, as a divisor, below which a creature is in danger
+	lowHPDivisor = 4
+)
+
+// Move effect constants
+const (
+	EffectNone = iota
+	EffectPoison
+)
+
+// statusNames are the short labels shown beside a creature's HP
+var statusNames = map[int]string{
+	StatusPoison: "PSN",
+}
+
+// applyMoveEffect rolls a move's secondary effect on its target, returning a
+// message if it took hold
+func applyMoveEffect(move Move, target *Creature) string {
+	if !engine.EffectTakes(move.engineMove(), target.fighter(), battleRand) {
+		return ""
+	}
+	return inflictStatus(target, move.effect)
+}
+
+// inflictStatus gives a creature the status a move's effect causes,
+// returning the message saying so
+func inflictStatus(target *Creature, effect int) string {
+	switch effect {
+	case EffectPoison:
+		target.status = StatusPoison
+		return target.name + " was poisoned!"
+	}
+	return ""
+}
+
+// updatePoison hurts poisoned party creatures as the player walks, reporting
+// whether it interrupted the step with a message or a blackout
+func (g *Game) updatePoison() bool {
+	if g.steps%poisonStepInterval != 0 {
+		return false
+	}
+
+	message := ""
+	hurt := false
+	for i := range g.creatures {
+		c := &g.creatures[i]
+		if c.status != StatusPoison || c.hp <= 0 {
+			continue
+		}
+
+		wasLow := c.lowHP()
+		c.hp--
+		hurt = true
+
+		switch {
+		case c.hp <= 0:
+			c.status = StatusNone
+			message += c.displayName() + " fainted from poison! "
+		case c.lowHP() && !wasLow:
+			message += c.displayName() + " is badly hurt by poison! "
+		}
+	}
+
+	if !hurt {
+		return false
+	}
+	g.poisonFlash = poisonFlashFrames
+
+	if g.nextHealthyCreature() == nil {
+		g.blackout()
+		return true
+	}
+	if message != "" {
+		g.showDialogue(message)
+		return true
+	}
+	return false
+}
+
+// lowHP reports whether a creature is still standing but in danger of fainting
+func (c *Creature) lowHP() bool {
+	return c.hp > 0 && c.hp < c.maxHP/lowHPDivisor
+}
+
+// partyNeedsCare reports whether any party creature has a status condition or
+// is low on HP
+func (g *Game) partyNeedsCare() bool {
+	for _, c := range g.creatures {
+		if c.status != StatusNone || c.lowHP() {
+			return true
+		}
+	}
+	return false
+}
+
+// drawStatusIndicator draws a small warning in the corner of the overworld
+// while the party needs care, and flashes the screen when poison hurts
+func (g *Game) drawStatusIndicator(screen *ebiten.Image) {
+	if g.poisonFlash > 0 && !g.settings.ReduceFlashes {
+		vector.DrawFilledRect(screen, 0, 0, screenWidth, screenHeight, color.RGBA{150, 40, 170, uint8(12 * g.poisonFlash)}, true)
+	}
+
+	if !g.partyNeedsCare() {
+		return
+	}
+
+	// Pulse gently rather than blink
+	alpha := uint8(150 + 50*(g.ticks/30%2))
+	top := g.hudHeight()
+	vector.DrawFilledCircle(screen, 12, float32(top+12), 7, color.RGBA{200, 60, 60, alpha}, true)
+
+	g.drawText(screen, "!", 10, float64(top+5), color.White)
+}
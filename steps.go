@@ -0,0 +1,25 @@
+package main
+
+// takeStep counts a finished step onto a new tile and tells anything going by
+// the step count about it
+func (g *Game) takeStep() {
+	g.steps++
+	g.events.publish(Event{kind: EventStep})
+}
+
+// subscribeSteps has the party's eggs and friendship move along as the
+// player walks. Berry regrowth and repels compare against the step count
+// when they're checked, so they don't need to hear about every step.
+func (g *Game) subscribeSteps() {
+	g.events.subscribe(EventStep, func(Event) {
+		for i := range g.creatures {
+			c := &g.creatures[i]
+			switch {
+			case c.egg:
+				c.eggSteps = max(c.eggSteps-1, 0)
+			case g.steps%friendshipSteps == 0:
+				c.friendship = min(c.friendship+1, maxFriendship)
+			}
+		}
+	})
+}
@@ -0,0 +1,514 @@
+package main
+
+import (
+	"cmp"
+	"image/color"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Storage sort orders
+const (
+	SortCaught = iota
+	SortName
+	SortLevel
+	SortType
+	SortCount
+)
+
+// sortNames label the storage sort orders
+var sortNames = [SortCount]string{"Caught", "Name", "Level", "Type"}
+
+// Storage screen stages
+const (
+	// Moving through the creatures and marking them
+	StorageBrowsing = iota
+	// Typing in a search
+	StorageSearching
+	// Choosing what to do with the marked creatures
+	StorageActing
+	// Making sure before releasing them
+	StorageReleasing
+	// Picking a team preset, choosing what to do with it, and naming a new
+	// one
+	StoragePresets
+	StoragePresetActions
+	StorageNaming
+)
+
+// storageQueryMaxLen is the longest search that can be typed in
+const storageQueryMaxLen = 24
+
+// StorageMenu is what the storage screen is showing: the creatures in
+// storage or the party, narrowed by a search and in some order
+type StorageMenu struct {
+	// Showing the party rather than storage
+	party bool
+	stage int
+	// Search typed in, and the order creatures are listed in
+	query string
+	sort  int
+	// Row highlighted, the first one in view when the list scrolls, and the
+	// action highlighted
+	selected, top int
+	action        int
+	// Creatures marked to act on together, by where they are in storage or
+	// the party
+	marked  map[int]bool
+	message string
+	// Team preset highlighted, and the name being typed for a new one
+	preset     int
+	presetName string
+}
+
+// addStoragePC puts a PC for reaching storage in the corner of a heal center
+func (m *Map) addStoragePC() {
+	m.objects[Point{1, m.height - 2}] = &MapObject{kind: ObjectStoragePC}
+}
+
+// openStorage switches to the storage screen, showing storage
+func (g *Game) openStorage() {
+	g.gameState = StateStorage
+	g.storageMenu = StorageMenu{marked: make(map[int]bool)}
+}
+
+// storageCreatures returns the creatures the storage screen is showing
+func (g *Game) storageCreatures() []Creature {
+	if g.storageMenu.party {
+		return g.creatures
+	}
+	return g.storage
+}
+
+// storageMatches reports whether a creature matches every word of a search.
+// A number or range of numbers, like 10-20, matches its level, and any other
+// word matches part of its name, either type or where it was met.
+func storageMatches(c *Creature, query string) bool {
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		low, high, isRange := strings.Cut(word, "-")
+		if !isRange {
+			high = low
+		}
+		from, errFrom := strconv.Atoi(low)
+		to, errTo := strconv.Atoi(high)
+		if errFrom == nil && errTo == nil {
+			if c.level < from || c.level > to {
+				return false
+			}
+			continue
+		}
+
+		fields := []string{c.name, c.nickname, c.type1, c.type2, c.metLocation}
+		if !slices.ContainsFunc(fields, func(field string) bool {
+			return strings.Contains(strings.ToLower(field), word)
+		}) {
+			return false
+		}
+	}
+	return true
+}
+
+// storageView returns where each creature the storage screen lists is, in
+// storage or the party, after the search and sort
+func (g *Game) storageView() []int {
+	creatures := g.storageCreatures()
+	var view []int
+	for i := range creatures {
+		if storageMatches(&creatures[i], g.storageMenu.query) {
+			view = append(view, i)
+		}
+	}
+
+	slices.SortStableFunc(view, func(a, b int) int {
+		ca, cb := &creatures[a], &creatures[b]
+		switch g.storageMenu.sort {
+		case SortName:
+			return cmp.Compare(ca.name, cb.name)
+		case SortLevel:
+			return cmp.Compare(cb.level, ca.level)
+		case SortType:
+			return cmp.Or(cmp.Compare(ca.type1, cb.type1), cmp.Compare(ca.name, cb.name))
+		}
+		return 0
+	})
+	return view
+}
+
+// storageTargets returns the creatures an action applies to: the marked
+// ones, or the highlighted one if none are
+func (g *Game) storageTargets(view []int) []int {
+	var targets []int
+	for i := range g.storageCreatures() {
+		if g.storageMenu.marked[i] {
+			targets = append(targets, i)
+		}
+	}
+	if len(targets) == 0 && len(view) > 0 {
+		targets = []int{view[g.storageMenu.selected]}
+	}
+	return targets
+}
+
+// storageActions returns the actions for the creatures on the screen
+func (g *Game) storageActions() []string {
+	if g.storageMenu.party {
+		return []string{"Deposit", "Export", "Cancel"}
+	}
+	return []string{"Withdraw", "Release", "Export", "Cancel"}
+}
+
+// withoutCreatures returns creatures with those at some indexes taken out
+func withoutCreatures(creatures []Creature, indexes []int) []Creature {
+	var kept []Creature
+	for i, c := range creatures {
+		if !slices.Contains(indexes, i) {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+// depositCreatures moves creatures from the party into storage, keeping at
+// least one that can battle in the party
+func (g *Game) depositCreatures(targets []int) string {
+	left := 0
+	for i, c := range g.creatures {
+		if !c.egg && !slices.Contains(targets, i) {
+			left++
+		}
+	}
+	if left == 0 {
+		return "You can't deposit every creature that can battle."
+	}
+
+	for _, i := range targets {
+		g.storage = append(g.storage, g.creatures[i])
+	}
+	// The active creature stays the same one, or goes back to the first
+	active := g.activeCreature
+	g.activeCreature = 0
+	if !slices.Contains(targets, active) {
+		for _, i := range targets {
+			if i < active {
+				active--
+			}
+		}
+		g.activeCreature = active
+	}
+	g.creatures = withoutCreatures(g.creatures, targets)
+	return "Deposited " + creatureCount(len(targets)) + "."
+}
+
+// withdrawCreatures moves creatures from storage into the party, if there's
+// room for all of them
+func (g *Game) withdrawCreatures(targets []int) string {
+	if room := partySize - len(g.creatures); len(targets) > room {
+		return "The party only has room for " + creatureCount(room) + "."
+	}
+	for _, i := range targets {
+		g.creatures = append(g.creatures, g.storage[i])
+	}
+	g.storage = withoutCreatures(g.storage, targets)
+	return "Withdrew " + creatureCount(len(targets)) + "."
+}
+
+// releaseCreatures lets creatures in storage go for good, putting any items
+// they held back in the bag
+func (g *Game) releaseCreatures(targets []int) string {
+	for _, i := range targets {
+		if item := g.storage[i].heldItem; item != "" {
+			g.addItem(item, 1)
+		}
+	}
+	g.storage = withoutCreatures(g.storage, targets)
+	return "Released " + creatureCount(len(targets)) + ". Bye-bye!"
+}
+
+// creatureCount describes a number of creatures, like "3 creatures"
+func creatureCount(n int) string {
+	if n == 1 {
+		return "1 creature"
+	}
+	return strconv.Itoa(n) + " creatures"
+}
+
+// storageRows returns how many creatures fit in the list at once
+func (g *Game) storageRows() int {
+	return (screenHeight - 50 - g.storageListTop()) / g.rowHeight()
+}
+
+// storageListTop returns where the list starts, below the search line
+func (g *Game) storageListTop() int {
+	return g.listTop() + g.lineSpacing()
+}
+
+// storageRects lays out the rows of the list in view
+func (g *Game) storageRects(view []int) []Rect {
+	rows := min(g.storageRows(), len(view)-g.storageMenu.top)
+	return listRects(20, g.storageListTop(), 140, g.rowHeight(), max(rows, 0))
+}
+
+// updateStorage handles the storage screen: moving through the creatures,
+// marking them, searching, sorting, and acting on the ones marked
+func (g *Game) updateStorage() {
+	s := &g.storageMenu
+	switch s.stage {
+	case StorageSearching:
+		g.typeText(&s.query, storageQueryMaxLen)
+		if g.input.IsKeyJustPressed(ebiten.KeyEnter) || g.input.IsKeyJustPressed(ebiten.KeyEscape) {
+			s.stage = StorageBrowsing
+		}
+		s.selected, s.top = 0, 0
+		return
+	case StorageActing, StorageReleasing:
+		g.updateStorageActions()
+		return
+	case StoragePresets, StoragePresetActions, StorageNaming:
+		g.updatePresets()
+		return
+	}
+
+	if g.input.IsActionJustPressed(ebiten.KeyEscape) {
+		g.gameState = StateOverworld
+		return
+	}
+	if g.input.IsKeyJustPressed(ebiten.KeyTab) {
+		s.party = !s.party
+		s.selected, s.top, s.message = 0, 0, ""
+		s.marked = make(map[int]bool)
+		return
+	}
+	if g.input.IsKeyJustPressed(ebiten.KeyF) {
+		s.stage = StorageSearching
+		return
+	}
+	if g.input.IsKeyJustPressed(ebiten.KeyP) {
+		s.stage, s.preset, s.message = StoragePresets, 0, ""
+		return
+	}
+	if g.input.IsKeyJustPressed(ebiten.KeyI) {
+		s.message = g.importCreature()
+		return
+	}
+	if g.input.IsActionJustPressed(ebiten.KeyLeft) {
+		s.sort = (s.sort - 1 + SortCount) % SortCount
+	} else if g.input.IsActionJustPressed(ebiten.KeyRight) {
+		s.sort = (s.sort + 1) % SortCount
+	}
+
+	view := g.storageView()
+	if len(view) == 0 {
+		return
+	}
+	if g.input.IsActionJustPressed(ebiten.KeyUp) {
+		s.selected = (s.selected - 1 + len(view)) % len(view)
+	} else if g.input.IsActionJustPressed(ebiten.KeyDown) {
+		s.selected = (s.selected + 1) % len(view)
+	}
+	row := s.selected - s.top
+	clicked := g.mouseSelect(g.storageRects(view), &row)
+	s.selected = min(s.top+row, len(view)-1)
+
+	rows := g.storageRows()
+	if s.selected < s.top {
+		s.top = s.selected
+	} else if s.selected >= s.top+rows {
+		s.top = s.selected - rows + 1
+	}
+
+	if g.input.IsActionJustPressed(ebiten.KeySpace) || clicked {
+		i := view[s.selected]
+		if s.marked[i] {
+			delete(s.marked, i)
+		} else {
+			s.marked[i] = true
+		}
+	}
+	if g.input.IsActionJustPressed(ebiten.KeyEnter) {
+		s.stage, s.action, s.message = StorageActing, 0, ""
+	}
+}
+
+// updateStorageActions handles choosing what to do with the marked
+// creatures, and making sure before releasing them
+func (g *Game) updateStorageActions() {
+	s := &g.storageMenu
+	actions := g.storageActions()
+	if s.stage == StorageReleasing {
+		actions = []string{"Release", "Keep"}
+	}
+	if g.input.IsActionJustPressed(ebiten.KeyUp) {
+		s.action = (s.action - 1 + len(actions)) % len(actions)
+	} else if g.input.IsActionJustPressed(ebiten.KeyDown) {
+		s.action = (s.action + 1) % len(actions)
+	}
+	if g.input.IsActionJustPressed(ebiten.KeyEscape) {
+		s.stage = StorageBrowsing
+		return
+	}
+	if !g.input.IsActionJustPressed(ebiten.KeySpace) && !g.input.IsActionJustPressed(ebiten.KeyEnter) {
+		return
+	}
+
+	targets := g.storageTargets(g.storageView())
+	releasing := s.stage == StorageReleasing
+	s.stage = StorageBrowsing
+	if len(targets) == 0 {
+		return
+	}
+	switch actions[s.action] {
+	case "Deposit":
+		s.message = g.depositCreatures(targets)
+	case "Withdraw":
+		s.message = g.withdrawCreatures(targets)
+	case "Export":
+		if len(targets) > 1 {
+			s.message = "Creatures are exported one at a time."
+		} else {
+			s.message = g.exportCreature(&g.storageCreatures()[targets[0]])
+		}
+		return
+	case "Release":
+		if releasing {
+			s.message = g.releaseCreatures(targets)
+			break
+		}
+		s.stage, s.action = StorageReleasing, 1
+		s.message = "Release " + creatureCount(len(targets)) + " for good?"
+		return
+	default:
+		return
+	}
+
+	// Whatever was marked has moved, so start the list over
+	s.marked = make(map[int]bool)
+	s.selected, s.top = 0, 0
+}
+
+// drawStoragePC draws a PC: a monitor on a desk with its screen lit
+func (g *Game) drawStoragePC(screen *ebiten.Image, x, y float32) {
+	vector.DrawFilledRect(screen, x-12, y+2, 24, 10, color.RGBA{120, 90, 60, 255}, true)
+	vector.DrawFilledRect(screen, x-9, y-12, 18, 14, color.RGBA{200, 200, 210, 255}, true)
+	vector.DrawFilledRect(screen, x-7, y-10, 14, 9, color.RGBA{80, 170, 230, 255}, true)
+}
+
+// drawStorage draws the storage screen: the list of creatures with the
+// marked ones ticked off, and the highlighted one's details beside it
+func (g *Game) drawStorage(screen *ebiten.Image) {
+	s := &g.storageMenu
+	g.drawPanel(screen, 10, 10, float32(screenWidth-20), float32(screenHeight-20), color.RGBA{60, 90, 130, 240})
+	presets := s.stage == StoragePresets || s.stage == StoragePresetActions || s.stage == StorageNaming
+	if presets {
+		g.drawPresets(screen)
+		g.drawStorageFooter(screen)
+		return
+	}
+
+	title := "Storage (" + strconv.Itoa(len(g.storage)) + ")"
+	if s.party {
+		title = "Party (" + strconv.Itoa(len(g.creatures)) + "/" + strconv.Itoa(partySize) + ")"
+	}
+	g.drawText(screen, title, 20, 30, color.White)
+	sortLabel := "< Sort: " + sortNames[s.sort] + " >"
+	g.drawText(screen, sortLabel, float64(screenWidth-20-g.textWidth(sortLabel)), 30, color.White)
+
+	search := "Search: " + s.query
+	searchColor := color.RGBA{200, 200, 200, 255}
+	if s.stage == StorageSearching {
+		search += "_"
+		searchColor = color.RGBA{255, 255, 0, 255}
+	}
+	g.drawText(screen, search, 20, float64(g.listTop()-6), searchColor)
+
+	view := g.storageView()
+	creatures := g.storageCreatures()
+	if len(view) == 0 {
+		empty := "Nothing matches the search."
+		if len(creatures) == 0 {
+			empty = "There's nobody here."
+		}
+		g.drawText(screen, empty, 30, float64(g.storageListTop()), color.RGBA{200, 200, 200, 255})
+	}
+	for i, r := range g.storageRects(view) {
+		index := view[s.top+i]
+		c := &creatures[index]
+		clr := color.Color(color.White)
+		if s.top+i == s.selected {
+			clr = color.RGBA{255, 255, 0, 255}
+			g.drawText(screen, ">", float64(r.x), float64(r.y), clr)
+		}
+		label := c.displayName() + " Lv." + strconv.Itoa(c.level)
+		if c.egg {
+			label = "Egg"
+		}
+		g.drawText(screen, label, float64(r.x+g.selectorWidth()), float64(r.y), clr)
+		if s.marked[index] {
+			vector.DrawFilledCircle(screen, float32(r.x+r.width), float32(r.y+g.lineHeight()/2), 3, color.RGBA{120, 230, 120, 255}, true)
+		}
+	}
+
+	// The highlighted creature's details
+	if len(view) > 0 && s.stage != StorageActing && s.stage != StorageReleasing {
+		c := &creatures[view[s.selected]]
+		x, y := 175.0, float64(g.storageListTop())
+		if !c.egg {
+			types := c.type1
+			if c.type2 != "" {
+				types += "/" + c.type2
+			}
+			met := c.metLocation
+			if met == "" {
+				met = "somewhere"
+			}
+			for _, line := range []string{types, "Met in " + met, "at Lv." + strconv.Itoa(c.metLevel)} {
+				g.drawText(screen, line, x, y, color.White)
+				y += float64(g.lineSpacing())
+			}
+		}
+	}
+
+	// The actions, or making sure before a release
+	if s.stage == StorageActing || s.stage == StorageReleasing {
+		actions := g.storageActions()
+		if s.stage == StorageReleasing {
+			actions = []string{"Release", "Keep"}
+		}
+		count := creatureCount(len(g.storageTargets(view)))
+		g.drawText(screen, count+":", 175, float64(g.storageListTop()), color.White)
+		for i, action := range actions {
+			clr := color.Color(color.White)
+			y := float64(g.storageListTop() + (i+1)*g.rowHeight())
+			if i == s.action {
+				clr = color.RGBA{255, 255, 0, 255}
+				g.drawText(screen, ">", 175, y, clr)
+			}
+			g.drawText(screen, action, float64(175+g.selectorWidth()), y, clr)
+		}
+	}
+
+	g.drawStorageFooter(screen)
+}
+
+// drawStorageFooter draws the controls for the storage screen's stage along
+// the bottom, with the last message above them
+func (g *Game) drawStorageFooter(screen *ebiten.Image) {
+	s := &g.storageMenu
+	hint := "Space mark, Enter act, F search, P presets, I import, Tab party/storage"
+	switch s.stage {
+	case StorageSearching:
+		hint = "Type a name, type, place or level like 10-20. Enter when done"
+	case StorageNaming:
+		hint = "Type a name for the preset. Enter to save, ESC to cancel"
+	case StoragePresets, StoragePresetActions:
+		hint = "Space to choose, ESC to go back"
+	}
+	hintTop := g.drawHint(screen, hint)
+	lines := g.wrapText(s.message, screenWidth-40)
+	for i, line := range lines {
+		g.drawText(screen, line, 20, float64(hintTop-(len(lines)-i)*g.lineSpacing()), color.RGBA{255, 255, 180, 255})
+	}
+}
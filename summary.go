@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Summary page constants
+const (
+	SummaryInfo = iota
+	SummaryStats
+	SummaryMoves
+	SummaryPageCount
+)
+
+// summaryPageNames are the tabs along the top of a creature summary
+var summaryPageNames = [SummaryPageCount]string{"Info", "Stats", "Moves"}
+
+// locationName names where the player is, after the region of the
+// overworld they're in or entered the current map from
+func (g *Game) locationName() string {
+	if g.worldMap.id == safariZoneID {
+		return "the Safari Zone"
+	}
+	overworld, ok := g.maps[overworldID]
+	if !ok || overworld.plan == nil {
+		return "an unknown place"
+	}
+	p := g.overworldTile()
+	return overworld.plan.regionAt(p.x, p.y).name
+}
+
+// overworldTile returns the overworld tile the player is on, or went indoors
+// from
+func (g *Game) overworldTile() Point {
+	if len(g.returnPoints) > 0 {
+		return Point{g.returnPoints[0].x, g.returnPoints[0].y}
+	}
+	return Point{g.player.tileX, g.player.tileY}
+}
+
+// recordMet notes where and at what level a creature joined the player
+func (g *Game) recordMet(c *Creature) {
+	c.metLocation = g.locationName()
+	c.metLevel = c.level
+}
+
+// summaryTabRects lays out the page tabs across the top of a creature summary
+func (g *Game) summaryTabRects(top int) []Rect {
+	width := g.widestText(summaryPageNames[:]) + 16
+	rects := make([]Rect, SummaryPageCount)
+	for i := range rects {
+		rects[i] = Rect{30 + i*width, top, width, g.rowHeight()}
+	}
+	return rects
+}
+
+// updateSummary flips between the pages of a creature summary with left and
+// right, or by clicking their tabs
+func (g *Game) updateSummary(top int) {
+	if g.input.IsActionJustPressed(ebiten.KeyLeft) {
+		g.summaryPage = (g.summaryPage - 1 + SummaryPageCount) % SummaryPageCount
+	} else if g.input.IsActionJustPressed(ebiten.KeyRight) {
+		g.summaryPage = (g.summaryPage + 1) % SummaryPageCount
+	}
+
+	g.mouseSelect(g.summaryTabRects(top), &g.summaryPage)
+}
+
+// drawSummary draws the current page of a creature's summary from top down,
+// with tabs for the pages across the top. It only needs the creature, so
+// any list of creatures can show it.
+func (g *Game) drawSummary(screen *ebiten.Image, c *Creature, top int) {
+	rects := g.summaryTabRects(top)
+	x := rects[0].x
+	y := top
+	drawText := func(x, y int, line string, clr color.Color) {
+		op := &text.DrawOptions{}
+		op.GeoM.Translate(float64(x), float64(y))
+		op.ColorScale.ScaleWithColor(g.uiText(clr))
+		text.Draw(screen, line, g.fontFace, op)
+	}
+
+	// Tabs, with the open page highlighted
+	for i, name := range summaryPageNames {
+		clr := color.RGBA{150, 150, 180, 255}
+		if i == g.summaryPage {
+			clr = color.RGBA{255, 255, 0, 255}
+		}
+		drawText(rects[i].x, y, name, clr)
+	}
+	last := rects[len(rects)-1]
+	drawText(last.x+last.width, y, "<  >", color.RGBA{150, 150, 180, 255})
+	y += g.rowHeight()
+
+	drawLine := func(line string) {
+		drawText(x, y, line, color.White)
+		y += g.lineSpacing()
+	}
+
+	// Nothing is known about an egg yet but how close it is to hatching
+	if c.egg {
+		drawLine("Egg")
+		for _, line := range g.wrapText(eggText(c), screenWidth-2*x) {
+			drawLine(line)
+		}
+		return
+	}
+
+	switch g.summaryPage {
+	case SummaryInfo:
+		species := formName(c.name, c.form)
+		if c.nickname != "" {
+			species = c.nickname + " (" + species + ")"
+		}
+		drawLine(species + "  Lv." + strconv.Itoa(c.level))
+		drawLine("Type: " + typeNames(c.type1, c.type2))
+		status := "OK"
+		if name, ok := statusNames[c.status]; ok {
+			status = name
+		}
+		drawLine("Status: " + status)
+		held := "None"
+		if c.heldItem != "" {
+			held = c.heldItem
+		}
+		drawLine("Held item: " + held)
+		drawLine("Friendship: " + friendshipText(c.friendship))
+		if c.level < maxLevel {
+			drawLine("EXP: " + strconv.Itoa(c.exp) + "/" + strconv.Itoa(expToNextLevel(c.level)) + " to Lv." + strconv.Itoa(c.level+1))
+		}
+		if c.trainer != "" {
+			drawLine("Trainer: " + c.trainer + "  ID No. " + fmt.Sprintf("%05d", c.id))
+		}
+		if c.metLocation != "" {
+			drawLine("Met at Lv." + strconv.Itoa(c.metLevel) + " in " + c.metLocation)
+		}
+
+	case SummaryStats:
+		// Stats are measured against what a strong species has at this level
+		high := scaleStat(25, c.level)
+		barX := x + g.widestText([]string{"Defense 000"}) + 10
+		barWidth := float32(screenWidth - 40 - barX)
+		stats := []struct {
+			label       string
+			value, most int
+		}{
+			{"HP " + strconv.Itoa(c.hp) + "/" + strconv.Itoa(c.maxHP), c.hp, c.maxHP},
+			{"Attack " + strconv.Itoa(c.attack), c.attack, high},
+			{"Defense " + strconv.Itoa(c.defense), c.defense, high},
+			{"Speed " + strconv.Itoa(c.speed), c.speed, high},
+		}
+		for i, stat := range stats {
+			ratio := min32(float32(stat.value)/float32(stat.most), 1)
+			clr := color.RGBA{90, 170, 255, 255}
+			if i == 0 {
+				clr = hpColor(ratio)
+			}
+			barY := float32(y) + float32(g.lineHeight())/2 - 2
+			vector.DrawFilledRect(screen, float32(barX), barY, barWidth, 5, color.RGBA{60, 60, 60, 255}, true)
+			vector.DrawFilledRect(screen, float32(barX), barY, barWidth*ratio, 5, clr, true)
+			drawText(x, y, stat.label, color.White)
+			y += g.rowHeight()
+		}
+		drawText(x, y, "Nature: "+natures[c.nature].text(), color.White)
+
+	case SummaryMoves:
+		for _, move := range c.moves {
+			drawText(x, y, move.name+" ("+move.type1+")", color.White)
+			pp := "PP " + strconv.Itoa(move.pp) + "/" + strconv.Itoa(move.maxPP)
+			drawText(screenWidth-30-g.textWidth(pp), y, pp, color.White)
+			y += g.lineSpacing()
+			drawText(x+10, y, moveDescription(move), color.RGBA{190, 190, 210, 255})
+			y += g.rowHeight()
+		}
+	}
+}
+
+// moveDescription describes what a move does in a line
+func moveDescription(move Move) string {
+	description := "Power " + strconv.Itoa(move.power) + ", " + strconv.Itoa(move.accuracy) + "% accurate."
+	switch move.effect {
+	case EffectPoison:
+		description += " " + strconv.Itoa(move.effectChance) + "% chance to poison."
+	}
+	return description
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// syncTimeout bounds each request to the sync server
+const syncTimeout = 15 * time.Second
+
+// SyncConfig is read from sync.json in the save store. With it in place,
+// the save is kept in sync with an HTTP endpoint: GET returns the stored save
+// (404 if there is none yet) and PUT replaces it. Both send the token as a
+// bearer token, and PUT sends the revision it was based on in
+// X-Base-Revision so the server can refuse it with 409 if another machine
+// got there first.
+type SyncConfig struct {
+	URL   string `json:"url"`
+	Token string `json:"token"`
+}
+
+// SyncResult reports the outcome of a sync back to the game, which applies
+// it to the save file itself
+type SyncResult struct {
+	status string
+	// The game's save revision when the sync started
+	base int
+	// Revision both sides now agree on, or -1 if unchanged
+	synced int
+	// The server's save, to replace the local one with, if it was newer
+	downloaded *SaveData
+}
+
+// SyncClient syncs the save file in the background
+type SyncClient struct {
+	config  SyncConfig
+	client  *http.Client
+	results chan SyncResult
+	busy    bool
+	status  string
+}
+
+// newSyncClient creates a sync client from the sync settings, or returns nil
+// if sync isn't set up
+func newSyncClient() *SyncClient {
+	encoded, err := saveStore.Read(syncConfigFile)
+	if err != nil {
+		return nil
+	}
+
+	var config SyncConfig
+	if err := json.Unmarshal(encoded, &config); err != nil || config.URL == "" {
+		return &SyncClient{status: "Sync settings are invalid"}
+	}
+
+	return &SyncClient{
+		config:  config,
+		client:  &http.Client{Timeout: syncTimeout},
+		results: make(chan SyncResult, 1),
+		status:  "Not synced yet",
+	}
+}
+
+// start kicks off a sync in the background, unless one is already running.
+// The revision is the game's, so the result can be dropped if the player
+// saves before it arrives.
+func (s *SyncClient) start(revision int) {
+	if s == nil || s.client == nil || s.busy {
+		return
+	}
+	s.busy = true
+	s.status = "Syncing..."
+
+	go func() {
+		result, err := s.sync()
+		if err != nil {
+			result = SyncResult{status: "Sync failed: " + err.Error(), synced: -1}
+		}
+		result.base = revision
+		s.results <- result
+	}()
+}
+
+// poll collects a finished sync, if any, on the game's goroutine
+func (s *SyncClient) poll() (SyncResult, bool) {
+	if s == nil || s.client == nil {
+		return SyncResult{}, false
+	}
+	select {
+	case result := <-s.results:
+		s.busy = false
+		s.status = result.status
+		return result, true
+	default:
+		return SyncResult{}, false
+	}
+}
+
+// sync compares the local save with the server's and moves whichever is
+// newer across. If both have changed since they were last in sync, neither
+// is overwritten: the server's copy is kept beside the local save for the
+// player to sort out. The save file itself is left to the game's goroutine,
+// which saves to it too.
+func (s *SyncClient) sync() (SyncResult, error) {
+	local, localData, err := readSaveRevision(saveFile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return SyncResult{}, err
+	}
+
+	remoteData, err := s.download()
+	if err != nil {
+		return SyncResult{}, err
+	}
+	var remote SaveData
+	if remoteData != nil {
+		encoded, err := openSave(remoteData)
+		if err != nil {
+			return SyncResult{}, fmt.Errorf("server sent a bad save: %w", err)
+		}
+		if err := json.Unmarshal(encoded, &remote); err != nil {
+			return SyncResult{}, fmt.Errorf("server sent a bad save: %w", err)
+		}
+	}
+
+	switch {
+	case localData == nil && remoteData == nil:
+		return SyncResult{status: "Nothing to sync", synced: -1}, nil
+
+	case remoteData == nil, remote.Revision == local.Synced && local.Revision > local.Synced:
+		// Only the local save has moved on
+		base := 0
+		if remoteData != nil {
+			base = remote.Revision
+		}
+		if err := s.upload(localData, base); err != nil {
+			return SyncResult{}, err
+		}
+		return SyncResult{status: "Uploaded save " + formatSyncTime(local.SavedAt), synced: local.Revision}, nil
+
+	case localData == nil, local.Revision == local.Synced && remote.Revision > local.Synced:
+		// Only the server's save has moved on
+		remote.Synced = remote.Revision
+		return SyncResult{status: "Downloaded save " + formatSyncTime(remote.SavedAt), synced: remote.Revision, downloaded: &remote}, nil
+
+	case remote.Revision == local.Revision:
+		return SyncResult{status: "Up to date", synced: local.Revision}, nil
+
+	default:
+		// Both moved on; keep the server's copy for the player to choose
+		if err := saveStore.Write(serverSaveFile, remoteData); err != nil {
+			return SyncResult{}, err
+		}
+		return SyncResult{status: "Sync conflict: server copy saved as save-server.json", synced: -1}, nil
+	}
+}
+
+// applySync records a finished sync in the save file, on the game's
+// goroutine. A downloaded save only replaces the local one if the player
+// hasn't saved since the sync started, as theirs is newer. An upload marks
+// whatever save is there now as following on from the uploaded one, and
+// syncs again if the player saved meanwhile.
+func (g *Game) applySync(result SyncResult) {
+	switch {
+	case result.synced < 0:
+		return
+	case result.downloaded != nil && g.saveRevision != result.base:
+		g.sync.status = "Saved during sync; kept this save over the server's"
+		return
+	case result.downloaded != nil:
+		if err := writeSave(saveFile, *result.downloaded); err != nil {
+			g.sync.status = "Sync failed: " + err.Error()
+			return
+		}
+		if !slices.Contains(g.menuOptions, "Continue") {
+			i := slices.Index(g.menuOptions, "New Game")
+			g.menuOptions = slices.Insert(g.menuOptions, i, "Continue")
+		}
+		g.syncedRevision = result.synced
+	default:
+		local, _, err := readSaveRevision(saveFile)
+		if err == nil && local.Synced != result.synced && local.Revision >= result.synced {
+			local.Synced = result.synced
+			err = writeSave(saveFile, local)
+		}
+		if err != nil {
+			g.sync.status = "Sync failed: " + err.Error()
+			return
+		}
+		g.syncedRevision = result.synced
+		if g.saveRevision != result.base {
+			g.sync.start(g.saveRevision)
+		}
+	}
+}
+
+// download fetches the server's save, returning nil if it has none
+func (s *SyncClient) download() ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, s.config.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.config.Token)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return io.ReadAll(resp.Body)
+	case http.StatusNotFound:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("server returned %s", resp.Status)
+	}
+}
+
+// upload replaces the server's save, which must still be at revision base
+func (s *SyncClient) upload(data []byte, base int) error {
+	req, err := http.NewRequest(http.MethodPut, s.config.URL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.config.Token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Base-Revision", strconv.Itoa(base))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusConflict:
+		return errors.New("another machine synced first, try again")
+	case resp.StatusCode >= 300:
+		return fmt.Errorf("server returned %s", resp.Status)
+	}
+	return nil
+}
+
+// readSaveRevision reads a save file, returning its parsed contents and raw
+// bytes. A damaged save is an error, so it's never uploaded.
+func readSaveRevision(name string) (SaveData, []byte, error) {
+	sealed, err := saveStore.Read(name)
+	if err != nil {
+		return SaveData{}, nil, err
+	}
+	encoded, err := openSave(sealed)
+	if err != nil {
+		return SaveData{}, nil, err
+	}
+	var data SaveData
+	if err := json.Unmarshal(encoded, &data); err != nil {
+		return SaveData{}, nil, err
+	}
+	return data, sealed, nil
+}
+
+// formatSyncTime formats a save time for the sync status
+func formatSyncTime(unix int64) string {
+	return time.Unix(unix, 0).Format("Jan 2 15:04")
+}
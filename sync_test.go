@@ -0,0 +1,32 @@
+package main
+
+import "testing"
+
+func TestApplySync(t *testing.T) {
+	g := newTestGame(t)
+	g.sync = &SyncClient{}
+	if err := g.saveGame(); err != nil {
+		t.Fatal(err)
+	}
+	base := g.saveRevision
+
+	// A save made while the sync was out keeps the server's from landing
+	server := g.saveData()
+	server.Revision, server.Name = base+5, "Server"
+	g.saveGame()
+	g.applySync(SyncResult{base: base, synced: server.Revision, downloaded: &server})
+	if local, _, _ := readSaveRevision(saveFile); local.Name == "Server" || local.Revision != g.saveRevision {
+		t.Fatalf("a sync started at revision %d replaced save %d", base, g.saveRevision)
+	}
+
+	// An upload marks the save there now as synced
+	g.applySync(SyncResult{base: g.saveRevision, synced: g.saveRevision})
+	if local, _, _ := readSaveRevision(saveFile); local.Synced != g.saveRevision {
+		t.Errorf("save synced at %d after uploading %d", local.Synced, g.saveRevision)
+	}
+
+	g.applySync(SyncResult{base: g.saveRevision, synced: server.Revision, downloaded: &server})
+	if local, _, _ := readSaveRevision(saveFile); local.Name != "Server" {
+		t.Error("the server's save wasn't downloaded")
+	}
+}
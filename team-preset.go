@@ -0,0 +1,238 @@
+package main
+
+import (
+	"image/color"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Team preset constants
+const (
+	// Most presets that can be kept
+	maxPresets = 6
+	// Longest name a preset can be given
+	presetNameMaxLen = 16
+)
+
+// presetActions are the choices offered for a saved preset
+var presetActions = []string{"Apply", "Overwrite", "Delete", "Cancel"}
+
+// TeamPreset is a named team saved to be called up again at a PC, listing
+// the creatures in it in party order
+type TeamPreset struct {
+	Name    string         `json:"name"`
+	Members []PresetMember `json:"members"`
+}
+
+// PresetMember is a creature in a preset, found again by its ID number. Its
+// species and level are kept to say who's missing if it can't be.
+type PresetMember struct {
+	ID      int    `json:"id"`
+	Species string `json:"species"`
+	Level   int    `json:"level"`
+}
+
+// makePreset makes a preset of the party as it is, leaving out eggs
+func (g *Game) makePreset(name string) (TeamPreset, bool) {
+	p := TeamPreset{Name: name}
+	for _, c := range g.creatures {
+		if !c.egg {
+			p.Members = append(p.Members, PresetMember{ID: c.id, Species: c.name, Level: c.level})
+		}
+	}
+	return p, len(p.Members) > 0
+}
+
+// applyPreset makes the party the preset's team, taking its members out of
+// storage and putting the rest of the party there, and says who couldn't
+// be found. The party is left as it was if nobody could.
+func (g *Game) applyPreset(p TeamPreset) string {
+	all := append(slices.Clone(g.creatures), g.storage...)
+	used := make([]bool, len(all))
+	var team []Creature
+	var missing []string
+	for _, m := range p.Members {
+		found := false
+		for i, c := range all {
+			if !used[i] && !c.egg && c.id == m.ID {
+				used[i], found = true, true
+				team = append(team, c)
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, m.Species+" Lv."+strconv.Itoa(m.Level))
+		}
+	}
+	if len(team) == 0 {
+		return "None of " + p.Name + " could be found."
+	}
+
+	// Whoever's left over goes to storage, what was there first
+	var rest []Creature
+	for i := len(g.creatures); i < len(all); i++ {
+		if !used[i] {
+			rest = append(rest, all[i])
+		}
+	}
+	for i := range g.creatures {
+		if !used[i] {
+			rest = append(rest, all[i])
+		}
+	}
+	g.creatures, g.storage = team, rest
+	g.activeCreature = 0
+
+	message := "The party is now " + p.Name + "."
+	if len(missing) > 0 {
+		message += " Missing: " + strings.Join(missing, ", ") + "."
+	}
+	return message
+}
+
+// text describes who's in a preset, like "Sparkitty 12, Zephyrd 9"
+func (p TeamPreset) text() string {
+	var names []string
+	for _, m := range p.Members {
+		names = append(names, m.Species+" "+strconv.Itoa(m.Level))
+	}
+	return strings.Join(names, ", ")
+}
+
+// updatePresets handles the presets on the storage screen: picking one to
+// apply, overwrite or delete, or naming a new one made from the party
+func (g *Game) updatePresets() {
+	s := &g.storageMenu
+	switch s.stage {
+	case StorageNaming:
+		g.typeText(&s.presetName, presetNameMaxLen)
+		if g.input.IsKeyJustPressed(ebiten.KeyEscape) {
+			s.stage = StoragePresets
+			return
+		}
+		name := strings.TrimSpace(s.presetName)
+		if !g.input.IsKeyJustPressed(ebiten.KeyEnter) || name == "" {
+			return
+		}
+		s.stage = StoragePresets
+		if p, ok := g.makePreset(name); ok {
+			g.presets = append(g.presets, p)
+			s.message = "Saved the party as " + name + "."
+		} else {
+			s.message = "There's nobody in the party to save."
+		}
+		return
+
+	case StoragePresetActions:
+		if g.input.IsActionJustPressed(ebiten.KeyUp) {
+			s.action = (s.action - 1 + len(presetActions)) % len(presetActions)
+		} else if g.input.IsActionJustPressed(ebiten.KeyDown) {
+			s.action = (s.action + 1) % len(presetActions)
+		}
+		if g.input.IsActionJustPressed(ebiten.KeyEscape) {
+			s.stage = StoragePresets
+			return
+		}
+		if !g.input.IsActionJustPressed(ebiten.KeySpace) && !g.input.IsActionJustPressed(ebiten.KeyEnter) {
+			return
+		}
+		s.stage = StoragePresets
+		p := &g.presets[s.preset]
+		switch presetActions[s.action] {
+		case "Apply":
+			s.message = g.applyPreset(*p)
+			s.marked = make(map[int]bool)
+			s.selected, s.top = 0, 0
+		case "Overwrite":
+			if updated, ok := g.makePreset(p.Name); ok {
+				*p = updated
+				s.message = "Saved the party as " + p.Name + "."
+			}
+		case "Delete":
+			s.message = "Deleted " + p.Name + "."
+			g.presets = slices.Delete(g.presets, s.preset, s.preset+1)
+			s.preset = 0
+		}
+		return
+	}
+
+	// The presets, then a row for saving the party as a new one
+	rows := len(g.presets) + 1
+	if g.input.IsActionJustPressed(ebiten.KeyUp) {
+		s.preset = (s.preset - 1 + rows) % rows
+	} else if g.input.IsActionJustPressed(ebiten.KeyDown) {
+		s.preset = (s.preset + 1) % rows
+	}
+	clicked := g.mouseSelect(g.presetRects(), &s.preset)
+	if g.input.IsActionJustPressed(ebiten.KeyEscape) {
+		s.stage = StorageBrowsing
+		return
+	}
+	if !g.input.IsActionJustPressed(ebiten.KeySpace) && !g.input.IsActionJustPressed(ebiten.KeyEnter) && !clicked {
+		return
+	}
+	switch {
+	case s.preset < len(g.presets):
+		s.stage, s.action = StoragePresetActions, 0
+	case len(g.presets) >= maxPresets:
+		s.message = "There's only room for " + strconv.Itoa(maxPresets) + " presets. Overwrite or delete one."
+	default:
+		s.stage, s.presetName = StorageNaming, ""
+	}
+}
+
+// presetLabels returns the rows of the preset list
+func (g *Game) presetLabels() []string {
+	var labels []string
+	for _, p := range g.presets {
+		labels = append(labels, p.Name)
+	}
+	return append(labels, "Save the party...")
+}
+
+// presetRects lays out the preset list
+func (g *Game) presetRects() []Rect {
+	return listRects(20, g.storageListTop(), 140, g.rowHeight(), len(g.presetLabels()))
+}
+
+// drawPresets draws the preset list, with who's in the highlighted preset or
+// what can be done with it beside it
+func (g *Game) drawPresets(screen *ebiten.Image) {
+	s := &g.storageMenu
+	g.drawText(screen, "Team presets", 20, 30, color.White)
+
+	for i, r := range g.presetRects() {
+		label := g.presetLabels()[i]
+		clr := color.Color(color.White)
+		if i == s.preset {
+			clr = color.RGBA{255, 255, 0, 255}
+			g.drawText(screen, ">", float64(r.x), float64(r.y), clr)
+		}
+		if i == len(g.presets) && s.stage == StorageNaming {
+			label = "Name: " + s.presetName + "_"
+		}
+		g.drawText(screen, label, float64(r.x+g.selectorWidth()), float64(r.y), clr)
+	}
+
+	x, y := 175, g.storageListTop()
+	switch {
+	case s.stage == StoragePresetActions:
+		for i, action := range presetActions {
+			clr := color.Color(color.White)
+			if i == s.action {
+				clr = color.RGBA{255, 255, 0, 255}
+				g.drawText(screen, ">", float64(x), float64(y), clr)
+			}
+			g.drawText(screen, action, float64(x+g.selectorWidth()), float64(y), clr)
+			y += g.rowHeight()
+		}
+	case s.preset < len(g.presets):
+		for _, line := range g.wrapText(g.presets[s.preset].text(), screenWidth-x-20) {
+			g.drawText(screen, line, float64(x), float64(y), color.RGBA{200, 200, 200, 255})
+			y += g.lineSpacing()
+		}
+	}
+}
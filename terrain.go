@@ -0,0 +1,87 @@
+package main
+
+import "slices"
+
+// Terrain generator constants
+const (
+	// TerrainCellular grows water with cellular automata and drops mountain clusters
+	TerrainCellular = iota
+	// TerrainNoise derives every tile from elevation and moisture noise
+	TerrainNoise
+)
+
+// GenerationConfig controls how a map's terrain is generated
+type GenerationConfig struct {
+	terrain int
+	// Tiles per unit of elevation noise; larger values give broader features
+	elevationScale float64
+	// Elevation thresholds, in noise units (roughly -1..1)
+	waterLevel    float64
+	beachLevel    float64
+	mountainLevel float64
+	// Moisture above which grass becomes forest
+	forestMoisture float64
+}
+
+// terrainNames are what the launch config and saves call each terrain
+// generator
+var terrainNames = []string{
+	TerrainCellular: "cellular",
+	TerrainNoise:    "noise",
+}
+
+// parseTerrain returns the terrain generator with a name. An empty name is
+// the noise generator, which every world used before there was a choice.
+func parseTerrain(name string) (int, bool) {
+	if name == "" {
+		return TerrainNoise, true
+	}
+	terrain := slices.Index(terrainNames, name)
+	return terrain, terrain >= 0
+}
+
+// overworldConfig is the generation config used for the overworld, whichever
+// terrain generator it's made with
+var overworldConfig = GenerationConfig{
+	elevationScale: 40,
+	waterLevel:     -0.15,
+	beachLevel:     -0.1,
+	mountainLevel:  0.28,
+	forestMoisture: 0.25,
+}
+
+// generateNoiseTerrain fills a chunk whose top-left tile is originX, originY
+// from the elevation and moisture maps; waterDensity shifts the water level
+// so wetter regions still get more lakes
+func (m *Map) generateNoiseTerrain(c *Chunk, originX, originY int, waterDensity float32) {
+	cfg := m.config
+
+	// A typical region has a water density of 0.3; scale around that
+	shift := float64(waterDensity-0.3) * 0.5
+	waterLevel := cfg.waterLevel + shift
+	beachLevel := cfg.beachLevel + shift
+
+	for y := range chunkSize {
+		for x := range chunkSize {
+			wx, wy := originX+x, originY+y
+			elevation := m.elevation.Fractal(float64(wx)/cfg.elevationScale, float64(wy)/cfg.elevationScale, 4)
+			moisture := m.moisture.Fractal(float64(wx)/cfg.elevationScale, float64(wy)/cfg.elevationScale, 2)
+
+			tile := TileGrass
+			switch {
+			case elevation < waterLevel:
+				tile = TileWater
+			case elevation < beachLevel:
+				tile = TileSand
+			case elevation > cfg.mountainLevel:
+				tile = TileMountain
+			case moisture > cfg.forestMoisture:
+				tile = TileTree
+			}
+
+			c.tiles[LayerBase][y][x] = tile
+			c.setGrass(x, y, tile == TileGrass)
+			c.setCollision(x, y, tile == TileWater || tile == TileMountain || tile == TileTree)
+		}
+	}
+}
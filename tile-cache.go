@@ -0,0 +1,141 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// chunkPixels is the width and height of a chunk's tiles drawn out
+const chunkPixels = chunkSize * tileSize
+
+// TileCache holds a chunk's tile layers drawn to offscreen images, so each
+// frame draws an image a chunk rather than a rectangle a tile. Tiles are
+// filled from the sprite atlas, so drawing a chunk is one batch.
+type TileCache struct {
+	// Each layer's tiles; nil for an overlay with nothing on it
+	layers [LayerCount]*ebiten.Image
+	// Chunk-local rustling grass tiles, which flicker and so are drawn over
+	// the cached image every frame
+	rustling []Point
+	// Chunk-local current tiles, whose streaks flow over the cached image
+	currents []Point
+}
+
+// tileColor returns the color a tile is drawn in on a map. Rustling grass
+// is given its darker shade, the one it flickers from.
+func (m *Map) tileColor(tile, x, y int) color.RGBA {
+	// Each biome has its own palette for the same tile types
+	palette := &biomes[m.BiomeAt(x, y)].palette
+	tileColor := palette[tile]
+	switch {
+	case tile == TileTallGrass || tile == TileRustlingGrass:
+		// Tall grass is a darker shade of the biome's grass
+		tileColor = shade(palette[TileGrass], 0.75)
+	case isCurrent(tile):
+		tileColor = shade(palette[TileWater], 0.85)
+	case tileColor.A == 0:
+		tileColor = tileColors[tile]
+	}
+	return tileColor
+}
+
+// renderChunk draws the tiles of the chunk at chunk coordinate key to its
+// cache
+func (m *Map) renderChunk(c *Chunk, key Point) *TileCache {
+	cache := &TileCache{}
+	originX, originY := key.x*chunkSize, key.y*chunkSize
+	for layer := range LayerCount {
+		for ly := range chunkSize {
+			for lx := range chunkSize {
+				x, y := originX+lx, originY+ly
+				tile := c.tiles[layer][ly][lx]
+				if override, ok := m.overrides[Point{x, y}]; ok && layer == LayerBase {
+					tile = override
+				}
+				if x >= m.width || y >= m.height || tile < 0 || tile >= TileCount {
+					continue
+				}
+				if tile == 0 && layer > LayerBase {
+					continue // Skip empty tiles in overlay layers
+				}
+				if tile == TileRustlingGrass {
+					cache.rustling = append(cache.rustling, Point{lx, ly})
+				}
+				if isCurrent(tile) && layer == LayerBase {
+					cache.currents = append(cache.currents, Point{lx, ly})
+				}
+
+				if cache.layers[layer] == nil {
+					cache.layers[layer] = ebiten.NewImage(chunkPixels, chunkPixels)
+				}
+				spriteAtlas().fillRect(cache.layers[layer], float32(lx*tileSize), float32(ly*tileSize), tileSize, tileSize, m.tileColor(tile, x, y))
+			}
+		}
+	}
+	return cache
+}
+
+// invalidate drops the chunk's drawn tiles, to be drawn again the next time
+// the chunk is on screen. Anything changing a chunk's tiles after it has
+// been drawn calls this.
+func (c *Chunk) invalidate() {
+	if c.cache == nil {
+		return
+	}
+	for _, image := range c.cache.layers {
+		if image != nil {
+			image.Deallocate()
+		}
+	}
+	c.cache = nil
+}
+
+// drawMapLayer draws a layer of the map from the chunks on screen, drawing
+// any chunk not yet cached first
+func (g *Game) drawMapLayer(screen *ebiten.Image, layer int) {
+	m := g.worldMap
+	startX, startY, endX, endY := g.visibleTiles()
+	if startX >= endX || startY >= endY {
+		return
+	}
+
+	for cy := startY / chunkSize; cy <= (endY-1)/chunkSize; cy++ {
+		for cx := startX / chunkSize; cx <= (endX-1)/chunkSize; cx++ {
+			c, _, _ := m.chunkAt(cx*chunkSize, cy*chunkSize)
+			if c == nil {
+				continue
+			}
+			key := Point{cx, cy}
+			if c.cache == nil {
+				c.cache = m.renderChunk(c, key)
+			}
+
+			left := float32(cx*chunkPixels) - g.camera.x
+			top := float32(cy*chunkPixels) - g.camera.y
+			if image := c.cache.layers[layer]; image != nil {
+				op := &g.tileOptions
+				op.GeoM.Reset()
+				op.GeoM.Translate(float64(left), float64(top))
+				screen.DrawImage(image, op)
+			}
+			if layer == LayerBase {
+				g.drawRustling(screen, c, key, left, top)
+				g.drawCurrents(screen, c, left, top)
+			}
+		}
+	}
+}
+
+// drawRustling draws a chunk's rustling grass in its lighter shade on the
+// frames it flickers to
+func (g *Game) drawRustling(screen *ebiten.Image, c *Chunk, key Point, left, top float32) {
+	for _, p := range c.cache.rustling {
+		x, y := key.x*chunkSize+p.x, key.y*chunkSize+p.y
+		if (g.ticks/10+x+y)%2 == 0 {
+			continue
+		}
+		palette := &biomes[g.worldMap.BiomeAt(x, y)].palette
+		spriteAtlas().fillRect(screen, left+float32(p.x*tileSize), top+float32(p.y*tileSize), tileSize, tileSize, shade(palette[TileGrass], 0.9))
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Toast timing constants, in frames
+const (
+	toastSlideFrames = 12 // Sliding in or out
+	toastShowFrames  = 120
+)
+
+// Toast is a short notice that slides in at the top of the screen and goes
+// away by itself, without stopping play
+type Toast struct {
+	message string
+	frame   int
+}
+
+// showToast queues a notice; it's shown once the ones before it have gone
+func (g *Game) showToast(message string) {
+	g.toasts = append(g.toasts, Toast{message: message})
+}
+
+// toastLength returns how many frames a toast is up for, which is shorter
+// when animations are off as it doesn't slide
+func (g *Game) toastLength() int {
+	if g.settings.Animation == AnimationOff {
+		return toastShowFrames
+	}
+	return toastShowFrames + 2*toastSlideFrames
+}
+
+// updateToasts ages the toast being shown and moves on to the next once
+// it's done
+func (g *Game) updateToasts() {
+	if len(g.toasts) == 0 {
+		return
+	}
+	g.toasts[0].frame++
+	if g.toasts[0].frame >= g.toastLength() {
+		g.toasts = g.toasts[1:]
+	}
+}
+
+// drawToasts draws the toast being shown at the top of the screen, sliding
+// down into place and back up when it's done
+func (g *Game) drawToasts(screen *ebiten.Image) {
+	if len(g.toasts) == 0 {
+		return
+	}
+	toast := g.toasts[0]
+
+	width := float32(g.textWidth(toast.message) + 20)
+	height := float32(g.lineHeight() + 10)
+	x, y := (screenWidth-width)/2, float32(6)
+	if g.settings.Animation != AnimationOff {
+		shown := min(min(toast.frame, g.toastLength()-toast.frame), toastSlideFrames)
+		y = -height + (y+height)*float32(shown)/toastSlideFrames
+	}
+
+	g.drawPanel(screen, x, y, width, height, color.RGBA{30, 30, 60, 235})
+	vector.DrawFilledRect(screen, x, y+height-2, width, 2, color.RGBA{255, 220, 80, 255}, false)
+	g.drawText(screen, toast.message, float64(x+10), float64(y+5), color.White)
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Touch control layout, in screen pixels
+const (
+	dpadX, dpadY     = 48, screenHeight - 48
+	dpadButtonSize   = 26
+	touchButtonSize  = 17
+	swipeMinDistance = 24
+)
+
+// TouchButton is an on-screen button that stands in for a key
+type TouchButton struct {
+	label string
+	key   ebiten.Key
+	x, y  float32
+	// D-pad buttons are squares, the rest are round
+	round bool
+}
+
+// touchButtons are the on-screen controls: a D-pad on the left, and A, B and
+// Start on the right standing in for Space, Escape and Enter
+var touchButtons = []TouchButton{
+	{key: ebiten.KeyUp, x: dpadX, y: dpadY - dpadButtonSize},
+	{key: ebiten.KeyDown, x: dpadX, y: dpadY + dpadButtonSize},
+	{key: ebiten.KeyLeft, x: dpadX - dpadButtonSize, y: dpadY},
+	{key: ebiten.KeyRight, x: dpadX + dpadButtonSize, y: dpadY},
+	{label: "A", key: ebiten.KeySpace, x: screenWidth - 30, y: screenHeight - 60, round: true},
+	{label: "B", key: ebiten.KeyEscape, x: screenWidth - 68, y: screenHeight - 38, round: true},
+	{label: "+", key: ebiten.KeyEnter, x: screenWidth - 30, y: 24, round: true},
+}
+
+// TouchControls turns touches into key presses
+type TouchControls struct {
+	// Shown once the player has touched the screen
+	active bool
+	// Keys held down by touches this frame and last frame
+	held, prev map[ebiten.Key]bool
+	// Where each touch started, to tell taps and swipes apart
+	starts map[ebiten.TouchID]Point
+	ids    []ebiten.TouchID
+}
+
+// contains reports whether a screen position is on the button
+func (b TouchButton) contains(x, y int) bool {
+	dx, dy := float32(x)-b.x, float32(y)-b.y
+	if b.round {
+		return dx*dx+dy*dy <= touchButtonSize*touchButtonSize
+	}
+	half := float32(dpadButtonSize) / 2
+	return dx >= -half && dx < half && dy >= -half && dy < half
+}
+
+// touchButtonAt returns the button at a screen position, if any
+func touchButtonAt(x, y int) (TouchButton, bool) {
+	for _, b := range touchButtons {
+		if b.contains(x, y) {
+			return b, true
+		}
+	}
+	return TouchButton{}, false
+}
+
+// updateTouch works out which keys touches are pressing this frame. Touches on
+// a button hold its key down. Anywhere else, a tap advances dialogue and a
+// swipe moves through menus like an arrow key.
+func (g *Game) updateTouch() {
+	t := &g.touch
+	if t.starts == nil {
+		t.starts = make(map[ebiten.TouchID]Point)
+	}
+	t.prev, t.held = t.held, make(map[ebiten.Key]bool)
+
+	t.ids = inpututil.AppendJustPressedTouchIDs(t.ids[:0])
+	for _, id := range t.ids {
+		x, y := g.toLogical(ebiten.TouchPosition(id))
+		t.starts[id] = Point{x, y}
+		t.active = true
+	}
+
+	t.ids = ebiten.AppendTouchIDs(t.ids[:0])
+	for _, id := range t.ids {
+		if b, ok := touchButtonAt(g.toLogical(ebiten.TouchPosition(id))); ok {
+			t.held[b.key] = true
+		}
+	}
+
+	t.ids = inpututil.AppendJustReleasedTouchIDs(t.ids[:0])
+	for _, id := range t.ids {
+		start, ok := t.starts[id]
+		delete(t.starts, id)
+		if !ok {
+			continue
+		}
+		if _, onButton := touchButtonAt(start.x, start.y); onButton {
+			continue
+		}
+
+		x, y := g.toLogical(inpututil.TouchPositionInPreviousTick(id))
+		dx, dy := x-start.x, y-start.y
+		switch {
+		case g.gameState == StateOverworld && !g.dialogue.active:
+			// Swipes would fight the D-pad on the overworld
+		case dx*dx+dy*dy < swipeMinDistance*swipeMinDistance:
+			if g.dialogue.active {
+				t.held[ebiten.KeySpace] = true
+			}
+		case abs(dx) > abs(dy) && dx > 0:
+			t.held[ebiten.KeyRight] = true
+		case abs(dx) > abs(dy):
+			t.held[ebiten.KeyLeft] = true
+		case dy > 0:
+			t.held[ebiten.KeyDown] = true
+		default:
+			t.held[ebiten.KeyUp] = true
+		}
+	}
+}
+
+// drawTouchControls draws the on-screen buttons once touch has been used
+func (g *Game) drawTouchControls(screen *ebiten.Image) {
+	if !g.touch.active {
+		return
+	}
+
+	for _, b := range touchButtons {
+		fill := color.RGBA{40, 40, 40, 110}
+		if g.touch.held[b.key] {
+			fill = color.RGBA{200, 200, 200, 150}
+		}
+
+		if b.round {
+			vector.DrawFilledCircle(screen, b.x, b.y, touchButtonSize, fill, true)
+			vector.StrokeCircle(screen, b.x, b.y, touchButtonSize, 1, color.RGBA{255, 255, 255, 120}, true)
+		} else {
+			half := float32(dpadButtonSize) / 2
+			vector.DrawFilledRect(screen, b.x-half, b.y-half, dpadButtonSize, dpadButtonSize, fill, true)
+			vector.StrokeRect(screen, b.x-half, b.y-half, dpadButtonSize, dpadButtonSize, 1, color.RGBA{255, 255, 255, 120}, true)
+		}
+
+		if b.label != "" {
+			op := &text.DrawOptions{}
+			op.GeoM.Translate(float64(b.x)-4, float64(b.y)-7)
+			op.ColorScale.ScaleWithColor(color.RGBA{255, 255, 255, 200})
+			text.Draw(screen, b.label, g.fontFace, op)
+		}
+	}
+}
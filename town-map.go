@@ -0,0 +1,100 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Town map layout constants
+const (
+	townMapSize    = 200 // Pixels the whole overworld is scaled into
+	townMapOriginX = (screenWidth - townMapSize) / 2
+	townMapOriginY = 24
+)
+
+// openTownMap switches to the town map screen
+func (g *Game) openTownMap() {
+	g.gameState = StateTownMap
+}
+
+// updateTownMap handles updates for the town map screen
+func (g *Game) updateTownMap() {
+	if g.input.IsActionJustPressed(ebiten.KeyEscape) || g.input.IsActionJustPressed(ebiten.KeyM) {
+		g.gameState = StateOverworld
+	}
+}
+
+// townMapPoint converts an overworld tile to a position on the town map
+func townMapPoint(p Point) (float32, float32) {
+	return townMapOriginX + float32(p.x)*townMapSize/worldWidth,
+		townMapOriginY + float32(p.y)*townMapSize/worldHeight
+}
+
+// drawTownMap draws the overworld's towns and routes, faded until they're
+// discovered, the player, and the roamer once it has been met
+func (g *Game) drawTownMap(screen *ebiten.Image) {
+	screen.Fill(color.RGBA{20, 40, 70, 255})
+	vector.DrawFilledRect(screen, townMapOriginX, townMapOriginY, townMapSize, townMapSize, color.RGBA{60, 120, 70, 255}, true)
+
+	plan := g.maps[overworldID].plan
+
+	// Routes as lines between towns, and towns as squares, faded until the
+	// player has been there
+	found := 0
+	for n, route := range plan.routes {
+		clr := color.RGBA{210, 180, 140, 255}
+		if g.discovered[routeName(n+1)] {
+			found++
+		} else {
+			clr = color.RGBA{90, 110, 80, 255}
+		}
+		for i := range len(route.points) - 1 {
+			x1, y1 := townMapPoint(route.points[i])
+			x2, y2 := townMapPoint(route.points[i+1])
+			vector.StrokeLine(screen, x1, y1, x2, y2, 2, clr, true)
+		}
+	}
+	for _, region := range plan.regions {
+		clr := color.RGBA{220, 60, 60, 255}
+		if g.discovered[region.name] {
+			found++
+		} else {
+			clr = color.RGBA{110, 90, 90, 255}
+		}
+		x, y := townMapPoint(region.town)
+		vector.DrawFilledRect(screen, x-4, y-4, 8, 8, clr, true)
+	}
+
+	// The roamer sits at the middle of its route
+	if g.roamer.seen && !g.roamer.defeated {
+		route := plan.routes[g.roamer.route].tiles()
+		x, y := townMapPoint(route[len(route)/2])
+		vector.DrawFilledCircle(screen, x, y, 4, color.RGBA{250, 220, 60, 255}, true)
+	}
+
+	// The player blinks; indoors they're shown at the door they came in by
+	player := g.overworldTile()
+	if g.ticks/15%2 == 0 {
+		x, y := townMapPoint(player)
+		vector.DrawFilledCircle(screen, x, y, 3, color.White, true)
+	}
+
+	titleOp := &text.DrawOptions{}
+	titleOp.GeoM.Translate(10, 5)
+	titleOp.ColorScale.ScaleWithColor(g.uiText(color.White))
+	text.Draw(screen, plan.areaAt(player.x, player.y), g.fontFace, titleOp)
+
+	discovered := g.fractionLabel("Discovered ", found, len(plan.regions)+len(plan.routes))
+	discoveredOp := &text.DrawOptions{}
+	discoveredOp.GeoM.Translate(float64(screenWidth-10-g.textWidth(discovered)), 5)
+	discoveredOp.ColorScale.ScaleWithColor(g.uiText(color.RGBA{200, 200, 200, 255}))
+	text.Draw(screen, discovered, g.fontFace, discoveredOp)
+
+	instructionsOp := &text.DrawOptions{}
+	instructionsOp.GeoM.Translate(10, float64(screenHeight-15))
+	instructionsOp.ColorScale.ScaleWithColor(g.uiText(color.RGBA{200, 200, 200, 255}))
+	text.Draw(screen, "M or ESC to close", g.fontFace, instructionsOp)
+}
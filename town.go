@@ -0,0 +1,169 @@
+package main
+
+import "image/color"
+
+// Building kind constants
+const (
+	BuildingHealCenter = iota
+	BuildingShop
+	BuildingHouse
+	BuildingGym
+	BuildingCave
+	BuildingSafari
+	BuildingLab
+	BuildingGameCorner
+)
+
+// tileColors are the colors of tiles that look the same in every biome, used
+// when a biome palette doesn't define the tile
+var tileColors = [TileCount]color.RGBA{
+	TileRoof:          {160, 82, 45, 255},
+	TileRoofHeal:      {220, 60, 60, 255},
+	TileRoofShop:      {60, 100, 200, 255},
+	TileRoofGym:       {130, 70, 160, 255},
+	TileWall:          {230, 220, 200, 255},
+	TileDoor:          {90, 50, 20, 255},
+	TileSign:          {180, 140, 80, 255},
+	TileFloor:         {200, 170, 120, 255},
+	TileCounter:       {120, 80, 50, 255},
+	TileInnerWall:     {60, 50, 70, 255},
+	TileCave:          {20, 20, 20, 255},
+	TileCaveFloor:     {90, 80, 70, 255},
+	TileRock:          {60, 55, 50, 255},
+	TileHole:          {25, 20, 15, 255},
+	TileFilledHole:    {120, 110, 100, 255},
+	TileSwitch:        {200, 170, 60, 255},
+	TileGate:          {150, 40, 40, 255},
+	TileGateOpen:      {110, 90, 80, 255},
+	TileTallGrass:     {25, 110, 25, 255},
+	TileRoofSafari:    {90, 140, 60, 255},
+	TileRoofLab:       {70, 160, 170, 255},
+	TileRustlingGrass: {40, 130, 40, 255},
+	TileSoil:          {115, 80, 50, 255},
+	TileIce:           {185, 225, 245, 255},
+	TileRoofCorner:    {230, 180, 40, 255},
+}
+
+// safariRegion is the index of the region whose town has the safari zone
+const safariRegion = 4
+
+// Building is a structure in a town with a door leading to an interior map
+type Building struct {
+	kind int
+	// Top-left tile and size of the footprint
+	x, y          int
+	width, height int
+	// Door tile, in the middle of the bottom row
+	door Point
+	// ID of the interior map behind the door
+	interior string
+	// Map file the interior is loaded from, if it isn't generated
+	layout string
+}
+
+// townBuildings lays out the buildings of a town around its center. The rows
+// and columns between buildings are left open so routes entering the town
+// from any side can always reach every door. Every town but the first has a
+// gym, one has the fusion lab, one the game corner, and mountainous regions
+// get a cave in the corner of town.
+func townBuildings(regionIndex int, region *Region) []Building {
+	minX := region.town.x - townWidth/2
+	minY := region.town.y - townHeight/2
+
+	buildings := []Building{
+		{kind: BuildingHealCenter, x: minX + 1, y: minY + 1, width: 5, height: 3},
+		{kind: BuildingShop, x: minX + 7, y: minY + 1, width: 4, height: 3},
+		{kind: BuildingHouse, x: minX + 12, y: minY + 1, width: 3, height: 3},
+	}
+
+	if regionIndex == 0 {
+		// The first town's house is the player's home
+		buildings[2].layout = "home"
+		buildings = append(buildings, Building{kind: BuildingHouse, x: minX + 1, y: minY + 8, width: 3, height: 3})
+	} else {
+		buildings = append(buildings, Building{kind: BuildingGym, x: minX + 1, y: minY + 8, width: 5, height: 3})
+	}
+
+	// One town's house is the fusion lab
+	if regionIndex == fusionLabRegion {
+		buildings[2].kind = BuildingLab
+	}
+
+	// One town has the gate to the safari zone, and another the game corner
+	// in the same spot
+	if regionIndex == safariRegion {
+		buildings = append(buildings, Building{kind: BuildingSafari, x: minX + 7, y: minY + 8, width: 4, height: 3})
+	}
+	if regionIndex == gameCornerRegion {
+		buildings = append(buildings, Building{kind: BuildingGameCorner, x: minX + 7, y: minY + 8, width: 4, height: 3})
+	}
+
+	if region.mountainClusters >= 3 {
+		buildings = append(buildings, Building{kind: BuildingCave, x: minX + 12, y: minY + 8, width: 3, height: 3})
+	}
+
+	for i := range buildings {
+		b := &buildings[i]
+		b.door = Point{b.x + b.width/2, b.y + b.height - 1}
+		b.interior = interiorID(regionIndex, i)
+	}
+
+	return buildings
+}
+
+// roofTile returns the roof tile used for a building kind
+func roofTile(kind int) int {
+	switch kind {
+	case BuildingHealCenter:
+		return TileRoofHeal
+	case BuildingShop:
+		return TileRoofShop
+	case BuildingGym:
+		return TileRoofGym
+	case BuildingCave:
+		return TileMountain
+	case BuildingSafari:
+		return TileRoofSafari
+	case BuildingLab:
+		return TileRoofLab
+	case BuildingGameCorner:
+		return TileRoofCorner
+	default:
+		return TileRoof
+	}
+}
+
+// stampBuilding draws a building's roof, front wall and door onto the chunk
+// whose top-left tile is originX, originY
+func (c *Chunk) stampBuilding(b Building, originX, originY int) {
+	for y := b.y; y < b.y+b.height; y++ {
+		for x := b.x; x < b.x+b.width; x++ {
+			tile := roofTile(b.kind)
+			if y == b.y+b.height-1 && b.kind != BuildingCave {
+				tile = TileWall
+			}
+			if x == b.door.x && y == b.door.y {
+				tile = TileDoor
+				if b.kind == BuildingCave {
+					tile = TileCave
+				}
+			}
+			c.stamp(x-originX, y-originY, tile, tile != TileDoor && tile != TileCave)
+		}
+	}
+}
+
+// stamp sets the chunk-local tile x, y to a non-encounter tile, ignoring
+// tiles outside the chunk
+func (c *Chunk) stamp(x, y, tile int, solid bool) {
+	if x < 0 || x >= chunkSize || y < 0 || y >= chunkSize {
+		return
+	}
+
+	c.tiles[LayerBase][y][x] = tile
+	c.tiles[LayerOverlay][y][x] = 0
+	c.setBridge(x, y, false)
+	c.setGrass(x, y, false)
+	c.setCollision(x, y, solid)
+	c.invalidate()
+}
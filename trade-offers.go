@@ -0,0 +1,142 @@
+package main
+
+import (
+	"image/color"
+	"math/rand"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// tradeOffersPerDay is how many trades are pinned to the trade boards each day
+const tradeOffersPerDay = 3
+
+// traders are the townsfolk who put trades up on the board
+var traders = []string{"Ramona", "Osric", "Mina", "Tobias", "Clem", "Hugo", "Priya", "Dmitri"}
+
+// TradeOffer is a trade someone has put up on the trade board: a species they
+// want, and one they'll give for it with the nature that suits it best. The
+// creature given is the level of the one taken.
+type TradeOffer struct {
+	trader       string
+	wants, gives string
+	nature       int
+}
+
+// TradeBoard is which of the day's trades the player has made
+type TradeBoard struct {
+	day   int
+	taken [tradeOffersPerDay]bool
+}
+
+// text describes a trade, like it's written on the board
+func (o TradeOffer) text() string {
+	return o.trader + " wants a " + o.wants + ", and will give a " + natures[o.nature].name + " " + o.gives + " for it."
+}
+
+// addTradeBoard pins a board of trades on offer to the wall of a heal center,
+// across from the bulletin board
+func (m *Map) addTradeBoard() {
+	m.objects[Point{1, 1}] = &MapObject{kind: ObjectTradeBoard}
+}
+
+// tradeOffers makes up the day's trades, which are the same on every board.
+// Both species are ones found in the wild somewhere.
+func (g *Game) tradeOffers(day int) []TradeOffer {
+	overworld := g.maps[overworldID]
+	plan := overworld.plan
+	rng := rand.New(rand.NewSource(overworld.seed + int64(day)*6151))
+	wild := func() string {
+		region := plan.regions[rng.Intn(len(plan.regions))]
+		table := biomes[overworld.BiomeAt(region.center.x, region.center.y)].encounters
+		return table[rng.Intn(len(table))].species
+	}
+
+	var offers []TradeOffer
+	for range tradeOffersPerDay {
+		wants, gives := wild(), wild()
+		for tries := 0; gives == wants && tries < 10; tries++ {
+			gives = wild()
+		}
+		offers = append(offers, TradeOffer{
+			trader: traders[rng.Intn(len(traders))],
+			wants:  wants,
+			gives:  gives,
+			nature: goodNature(findSpecies(gives)),
+		})
+	}
+	return offers
+}
+
+// readTradeBoard offers the day's trades still open
+func (g *Game) readTradeBoard() {
+	board := &g.tradeBoard
+	if board.day != g.calendar.day {
+		board.day, board.taken = g.calendar.day, [tradeOffersPerDay]bool{}
+	}
+
+	var open []int
+	for i := range tradeOffersPerDay {
+		if !board.taken[i] {
+			open = append(open, i)
+		}
+	}
+	if len(open) == 0 {
+		g.showDialogue("Every trade on the board has been made. New offers go up each day.")
+		return
+	}
+	g.offerTrades(g.tradeOffers(board.day), open)
+}
+
+// offerTrades asks about each open trade in turn until one is made, or one
+// can't be
+func (g *Game) offerTrades(offers []TradeOffer, open []int) {
+	o := offers[open[0]]
+	g.showPrompt("Trade: "+o.text()+" Make the trade?", func() {
+		g.makeOfferedTrade(o, open[0])
+	})
+	if len(open) > 1 {
+		g.dialogue.after = func() {
+			if !g.dialogue.active && g.gameState != StateTrade {
+				g.offerTrades(offers, open[1:])
+			}
+		}
+	}
+}
+
+// makeOfferedTrade hands over the first creature in the party of the species
+// a trade wants, playing the trade animation as for a trade with another
+// player
+func (g *Game) makeOfferedTrade(o TradeOffer, posting int) {
+	offer := -1
+	for i, c := range g.creatures {
+		if !c.egg && c.name == o.wants {
+			offer = i
+			break
+		}
+	}
+	if offer < 0 {
+		g.showDialogue("You don't have a " + o.wants + " in your party to trade.")
+		return
+	}
+
+	received := newCreature(o.gives, g.creatures[offer].level)
+	received.trainer = o.trader
+	received.nature = o.nature
+	received.applyTraits()
+
+	g.tradeBoard.taken[posting] = true
+	g.gameState = StateTrade
+	g.trade = TradeSession{stage: TradeAnimating, partner: o.trader, offer: offer, theirOffer: &received}
+}
+
+// drawTradeBoard draws a trade board: a board with two creatures pinned to
+// it and arrows between them
+func (g *Game) drawTradeBoard(screen *ebiten.Image, x, y float32) {
+	vector.DrawFilledRect(screen, x-12, y-10, 24, 18, color.RGBA{70, 80, 120, 255}, true)
+	vector.DrawFilledRect(screen, x-10, y-8, 20, 14, color.RGBA{215, 220, 235, 255}, true)
+	vector.DrawFilledCircle(screen, x-6, y-1, 3, color.RGBA{220, 90, 70, 255}, true)
+	vector.DrawFilledCircle(screen, x+6, y-1, 3, color.RGBA{70, 140, 220, 255}, true)
+	vector.StrokeLine(screen, x-2, y-4, x+2, y-4, 1, color.RGBA{40, 40, 60, 255}, true)
+	vector.StrokeLine(screen, x-2, y+2, x+2, y+2, 1, color.RGBA{40, 40, 60, 255}, true)
+}
@@ -0,0 +1,588 @@
+package main
+
+import (
+	"encoding/json"
+	"image/color"
+	"net"
+	"strconv"
+	"strings"
+
+	"creaturegame-2/engine"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Trading constants
+const (
+	tradePort           = "7777"
+	tradeAnimFrames     = 150
+	tradeAddressMaxLen  = 40
+	defaultTradeAddress = "localhost:" + tradePort
+)
+
+// Trade stage constants
+const (
+	TradeMenu = iota
+	TradeAddress
+	TradeConnecting
+	TradeSelecting
+	TradeAnimating
+	// Connected to a referee, waiting to be matched for a ranked battle
+	TradeMatching
+	// Making a challenge code, or typing one in
+	TradeChallenge
+	TradeCode
+	// Queued on a matchmaking server for a ranked battle
+	TradeQueued
+)
+
+// Trade message types sent between the two players
+const (
+	tradeHello   = "hello"
+	tradeOffer   = "offer"
+	tradeConfirm = "confirm"
+	tradeCancel  = "cancel"
+	tradeLend    = "lend"
+)
+
+// tradeMenuOptions are the choices on the first trade screen
+var tradeMenuOptions = []string{"Host a trade", "Join a trade", "Ranked battle", "Challenge code", "Back"}
+
+// tradeMessage is one line of the trade protocol: newline-delimited JSON
+// over TCP. Each side says hello with its trainer name, offers a creature,
+// and confirms; the swap happens once both sides have confirmed the same pair
+// of offers, which a confirm names by the creatures' ids, the sender's
+// first. Cancel withdraws an offer. Lend sends a copy of a creature to
+// fight alongside the other player in raids, without trading it away.
+type tradeMessage struct {
+	Type     string        `json:"type"`
+	Trainer  string        `json:"trainer,omitempty"`
+	Creature *CreatureSave `json:"creature,omitempty"`
+	Pair     [2]int        `json:"pair"`
+}
+
+// TradeSession is a trade in progress with another player
+type TradeSession struct {
+	stage    int
+	selected int
+	address  string
+	status   string
+	listener net.Listener
+	conn     net.Conn
+	encoder  *json.Encoder
+	// Connection results and messages arrive from background goroutines
+	connected chan net.Conn
+	incoming  chan tradeMessage
+	closed    chan error
+	partner   string
+	// Party index offered, or -1, and what the partner offered
+	offer      int
+	theirOffer *Creature
+	confirmed  bool
+	theirOK    bool
+	frames     int
+	// Looking for a ranked battle rather than a trade, and the referee
+	// connection while waiting for an opponent
+	ranked  bool
+	referee *OnlineBattle
+	// Place in the matchmaking queue, and the match code it gave for the
+	// referee
+	queue *MatchQueue
+	match string
+	// Rules and ruleset challenge codes are made with, and the code being
+	// typed
+	rules   int
+	ruleset int
+	code    string
+}
+
+// openTrade switches to the trade screen
+func (g *Game) openTrade() {
+	g.gameState = StateTrade
+	g.trade = TradeSession{address: defaultTradeAddress, offer: -1}
+}
+
+// closeTrade hangs up and returns to the overworld with a message
+func (g *Game) closeTrade(message string) {
+	t := &g.trade
+	if t.conn != nil {
+		t.conn.Close()
+	}
+	if t.listener != nil {
+		t.listener.Close()
+	}
+	if t.referee != nil {
+		t.referee.hangUp()
+	}
+	if t.queue != nil {
+		t.queue.leave()
+	}
+	g.trade = TradeSession{}
+	g.gameState = StateOverworld
+	if message != "" {
+		g.showDialogue(message)
+	}
+}
+
+// host waits for another player to connect
+func (t *TradeSession) host() {
+	listener, err := net.Listen("tcp", ":"+tradePort)
+	if err != nil {
+		t.status = "Couldn't host: " + err.Error()
+		t.stage = TradeMenu
+		return
+	}
+	t.listener = listener
+	t.connected = make(chan net.Conn, 1)
+	t.status = "Waiting for a player on port " + tradePort + "..."
+	t.stage = TradeConnecting
+
+	connected := t.connected
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			close(connected)
+			return
+		}
+		connected <- conn
+	}()
+}
+
+// join connects to another player hosting a trade
+func (t *TradeSession) join() {
+	t.connected = make(chan net.Conn, 1)
+	t.status = "Connecting to " + t.address + "..."
+	t.stage = TradeConnecting
+
+	address, connected := t.address, t.connected
+	go func() {
+		conn, err := net.Dial("tcp", address)
+		if err != nil {
+			close(connected)
+			return
+		}
+		connected <- conn
+	}()
+}
+
+// startTradeSession begins talking to the other player once connected
+func (g *Game) startTradeSession(conn net.Conn) {
+	t := &g.trade
+	t.conn = conn
+	t.encoder = json.NewEncoder(conn)
+	t.incoming = make(chan tradeMessage, 8)
+	t.closed = make(chan error, 1)
+	t.stage = TradeSelecting
+	t.status = "Connected! Choose a creature to offer."
+
+	incoming, closed := t.incoming, t.closed
+	go func() {
+		decoder := json.NewDecoder(conn)
+		for {
+			var msg tradeMessage
+			if err := decoder.Decode(&msg); err != nil {
+				closed <- err
+				return
+			}
+			incoming <- msg
+		}
+	}()
+
+	g.sendTrade(tradeMessage{Type: tradeHello, Trainer: g.playerName})
+}
+
+// sendTrade sends a message to the other player
+func (g *Game) sendTrade(msg tradeMessage) {
+	if err := g.trade.encoder.Encode(msg); err != nil {
+		g.trade.status = "Lost connection: " + err.Error()
+	}
+}
+
+// updateTrade handles input and network traffic on the trade screen
+func (g *Game) updateTrade() {
+	t := &g.trade
+
+	switch t.stage {
+	case TradeMenu:
+		if g.input.IsActionJustPressed(ebiten.KeyUp) {
+			t.selected = (t.selected - 1 + len(tradeMenuOptions)) % len(tradeMenuOptions)
+		} else if g.input.IsActionJustPressed(ebiten.KeyDown) {
+			t.selected = (t.selected + 1) % len(tradeMenuOptions)
+		}
+		if g.input.IsActionJustPressed(ebiten.KeyEscape) {
+			g.closeTrade("")
+			return
+		}
+		if g.input.IsActionJustPressed(ebiten.KeySpace) || g.input.IsActionJustPressed(ebiten.KeyEnter) {
+			switch tradeMenuOptions[t.selected] {
+			case "Host a trade":
+				t.host()
+			case "Join a trade":
+				if t.ranked {
+					t.ranked, t.address = false, defaultTradeAddress
+				}
+				t.stage = TradeAddress
+			case "Ranked battle":
+				if len(g.rankedTeam()) == 0 {
+					t.status = "None of your creatures can battle."
+					break
+				}
+				if g.matchmaker != nil {
+					g.joinQueue()
+					break
+				}
+				if !t.ranked {
+					t.ranked, t.address = true, defaultRefereeAddress
+				}
+				t.stage = TradeAddress
+			case "Challenge code":
+				t.stage, t.selected, t.status = TradeChallenge, 0, ""
+			case "Back":
+				g.closeTrade("")
+			}
+		}
+
+	case TradeAddress:
+		// Type the host's address
+		g.typeText(&t.address, tradeAddressMaxLen)
+		if g.input.IsKeyJustPressed(ebiten.KeyEscape) {
+			t.stage = TradeMenu
+		}
+		if g.input.IsKeyJustPressed(ebiten.KeyEnter) {
+			if !strings.Contains(t.address, ":") && t.ranked {
+				t.address += ":" + engine.RefereePort
+			} else if !strings.Contains(t.address, ":") {
+				t.address += ":" + tradePort
+			}
+			t.join()
+		}
+
+	case TradeConnecting:
+		select {
+		case conn, ok := <-t.connected:
+			if !ok {
+				t.status = "Couldn't connect."
+				t.stage = TradeMenu
+				return
+			}
+			if t.ranked {
+				g.joinReferee(conn)
+				return
+			}
+			g.startTradeSession(conn)
+		default:
+		}
+		if g.input.IsActionJustPressed(ebiten.KeyEscape) {
+			g.closeTrade("")
+		}
+
+	case TradeSelecting:
+		if g.receiveTrade() {
+			return
+		}
+		g.updateTradeSelection()
+
+	case TradeMatching:
+		g.updateMatching()
+
+	case TradeQueued:
+		g.updateQueue()
+
+	case TradeChallenge:
+		g.updateChallengeMenu()
+
+	case TradeCode:
+		g.updateChallengeCode()
+
+	case TradeAnimating:
+		t.frames++
+		if t.frames >= tradeAnimFrames {
+			g.finishTrade()
+		}
+	}
+}
+
+// typeText types into a line of text on the trade screen. Typing reads the
+// keyboard directly, so letters bound to actions by the control preset can
+// still be typed.
+func (g *Game) typeText(line *string, maxLen int) {
+	for _, r := range g.input.TypedChars() {
+		if len(*line) < maxLen && r < 128 {
+			*line += string(r)
+		}
+	}
+	if g.input.IsKeyJustPressed(ebiten.KeyBackspace) && len(*line) > 0 {
+		*line = (*line)[:len(*line)-1]
+	}
+}
+
+// receiveTrade handles messages from the other player, reporting whether the
+// session ended
+func (g *Game) receiveTrade() bool {
+	t := &g.trade
+	for {
+		select {
+		case msg := <-t.incoming:
+			switch msg.Type {
+			case tradeHello:
+				t.partner = msg.Trainer
+			case tradeOffer:
+				// Ignore offers of creatures this game couldn't use, or
+				// couldn't have made
+				if !receivable(msg.Creature) || legitimate(*msg.Creature) != nil {
+					continue
+				}
+				c := loadCreature(*msg.Creature)
+				t.theirOffer = &c
+				t.confirmed, t.theirOK = false, false
+			case tradeConfirm:
+				// A confirm of offers since changed doesn't count
+				if t.offer < 0 || t.theirOffer == nil || msg.Pair != [2]int{t.theirOffer.id, g.creatures[t.offer].id} {
+					continue
+				}
+				t.theirOK = true
+			case tradeCancel:
+				t.theirOffer = nil
+				t.confirmed, t.theirOK = false, false
+			case tradeLend:
+				if !receivable(msg.Creature) || legitimate(*msg.Creature) != nil {
+					continue
+				}
+				c := loadCreature(*msg.Creature)
+				g.lendToRaids(c, t.partner)
+				t.status = t.partner + " lent you " + c.name + " to help in raids!"
+			}
+
+			if t.confirmed && t.theirOK {
+				t.stage = TradeAnimating
+				t.frames = 0
+				return false
+			}
+		case <-t.closed:
+			g.closeTrade("The other player left the trade.")
+			return true
+		default:
+			return false
+		}
+	}
+}
+
+// receivable reports whether a creature sent over by the other player is one
+// this game could use
+func receivable(cs *CreatureSave) bool {
+	return cs != nil && loadableCreature(*cs) && !cs.Egg && cs.Level >= 1 && len(cs.Moves) > 0
+}
+
+// updateTradeSelection lets the player pick, offer and confirm a creature
+func (g *Game) updateTradeSelection() {
+	t := &g.trade
+
+	if g.input.IsActionJustPressed(ebiten.KeyEscape) {
+		if t.offer >= 0 {
+			// Withdraw the offer first; the partner has to confirm again
+			t.offer = -1
+			t.confirmed, t.theirOK = false, false
+			g.sendTrade(tradeMessage{Type: tradeCancel})
+			return
+		}
+		g.closeTrade("The trade was cancelled.")
+		return
+	}
+
+	if t.offer < 0 {
+		if g.input.IsActionJustPressed(ebiten.KeyUp) {
+			t.selected = (t.selected - 1 + len(g.creatures)) % len(g.creatures)
+		} else if g.input.IsActionJustPressed(ebiten.KeyDown) {
+			t.selected = (t.selected + 1) % len(g.creatures)
+		}
+		// Eggs stay with the player who was given them
+		if g.input.IsActionJustPressed(ebiten.KeySpace) && !g.creatures[t.selected].egg {
+			t.offer = t.selected
+			t.confirmed, t.theirOK = false, false
+			offer := saveCreature(g.creatures[t.offer])
+			g.sendTrade(tradeMessage{Type: tradeOffer, Creature: &offer})
+		}
+		// A creature lent for raids stays in the party too
+		if g.input.IsKeyJustPressed(ebiten.KeyL) && !g.creatures[t.selected].egg {
+			lent := saveCreature(g.creatures[t.selected])
+			g.sendTrade(tradeMessage{Type: tradeLend, Creature: &lent})
+			t.status = "Lent " + lent.Name + " to " + t.partner + " for raids."
+		}
+		return
+	}
+
+	// Both offers are in; confirm to swap
+	if t.theirOffer != nil && !t.confirmed && g.input.IsActionJustPressed(ebiten.KeySpace) {
+		t.confirmed = true
+		g.sendTrade(tradeMessage{Type: tradeConfirm, Pair: [2]int{g.creatures[t.offer].id, t.theirOffer.id}})
+		if t.theirOK {
+			t.stage = TradeAnimating
+			t.frames = 0
+		}
+	}
+}
+
+// finishTrade swaps the offered creatures once the animation has played,
+// evolving the one received if its species evolves by trade
+func (g *Game) finishTrade() {
+	t := &g.trade
+	sent := g.creatures[t.offer].displayName()
+	received := *t.theirOffer
+	if received.trainer == "" {
+		received.trainer = t.partner
+	}
+	g.filterNickname(&received)
+
+	message := "Sent " + sent + " to " + t.partner + " and received " + received.displayName() + "!" + g.fileLetter(&received)
+	offer := t.offer
+	g.creatures[offer] = received
+	g.events.publish(Event{kind: EventReceive, species: received.name, form: received.form})
+	g.closeTrade(message)
+
+	if species := findSpecies(received.name); species != nil && species.tradeEvolution != "" {
+		g.startEvolution(&g.creatures[offer], species.tradeEvolution)
+	}
+}
+
+// evolve turns a creature into another species at the same level, keeping
+// its moves, EXP, damage, status, held item and mail, nickname, ID and
+// where it was met. A fainted creature stays fainted.
+// Fused creatures keep what they were made from, and regional forms stay in
+// their form if what they evolve into has one by the same name.
+func (c *Creature) evolve(into string) {
+	evolved := newCreatureForm(into, c.form, c.level)
+	evolved.ivs, evolved.nature = c.ivs, c.nature
+	evolved.applyTraits()
+	if c.shiny {
+		evolved.makeShiny()
+	}
+	evolved.hp = max(evolved.maxHP-(c.maxHP-c.hp), 1)
+	if c.hp <= 0 {
+		evolved.hp = 0
+	}
+	evolved.moves = c.moves
+	evolved.exp = c.exp
+	evolved.status = c.status
+	evolved.heldItem, evolved.mail = c.heldItem, c.mail
+	evolved.trainer, evolved.nickname = c.trainer, c.nickname
+	evolved.id = c.id
+	evolved.fusedFrom = c.fusedFrom
+	evolved.metLocation, evolved.metLevel = c.metLocation, c.metLevel
+	*c = evolved
+}
+
+// drawTrade draws the trade screen
+func (g *Game) drawTrade(screen *ebiten.Image) {
+	t := &g.trade
+	g.drawPanel(screen, 10, 10, float32(screenWidth-20), float32(screenHeight-20), color.RGBA{40, 70, 90, 240})
+
+	titleOp := &text.DrawOptions{}
+	titleOp.GeoM.Translate(20, 20)
+	titleOp.ColorScale.ScaleWithColor(g.uiText(color.White))
+	title := "Trade"
+	if t.partner != "" {
+		title += " with " + t.partner
+	}
+	text.Draw(screen, title, g.fontFace, titleOp)
+
+	switch t.stage {
+	case TradeMenu:
+		for i, option := range tradeMenuOptions {
+			g.drawTradeLine(screen, option, 40, 50+i*20, i == t.selected)
+		}
+		g.drawTradeLine(screen, g.ranked.text(), 30, 55+len(tradeMenuOptions)*20, false)
+	case TradeAddress:
+		label := "Host address:"
+		if t.ranked {
+			label = "Referee address:"
+		}
+		g.drawTradeLine(screen, label, 30, 50, false)
+		g.drawTradeLine(screen, t.address+g.textCursor(), 40, 70, true)
+	case TradeChallenge:
+		g.drawChallengeMenu(screen)
+	case TradeCode:
+		g.drawTradeLine(screen, "Challenge code:", 30, 50, false)
+		g.drawTradeLine(screen, t.code+g.textCursor(), 40, 70, true)
+	case TradeSelecting:
+		g.drawTradeSelection(screen)
+	case TradeAnimating:
+		g.drawTradeAnimation(screen)
+	}
+
+	if t.status != "" && t.stage != TradeAnimating {
+		for i, line := range g.wrapText(t.status, screenWidth-30) {
+			g.drawTradeLine(screen, line, 20, screenHeight-50+i*15, false)
+		}
+	}
+}
+
+// drawTradeSelection draws the party to offer from beside the partner's offer
+func (g *Game) drawTradeSelection(screen *ebiten.Image) {
+	t := &g.trade
+	for i, label := range g.partyLabels() {
+		if i == t.offer {
+			label += " *"
+		}
+		g.drawTradeLine(screen, label, 30, 45+i*16, i == t.selected)
+	}
+
+	theirs := "Waiting for an offer..."
+	if t.theirOffer != nil {
+		theirs = t.theirOffer.name + " Lv." + strconv.Itoa(t.theirOffer.level)
+	}
+	g.drawTradeLine(screen, "They offer:", 180, 45, false)
+	g.drawTradeLine(screen, theirs, 180, 61, false)
+
+	hint := "Space: offer  L: lend  Esc: leave"
+	switch {
+	case t.offer >= 0 && t.confirmed:
+		hint = "Waiting for them to confirm..."
+	case t.offer >= 0 && t.theirOffer != nil:
+		hint = "Space: confirm trade  Esc: withdraw"
+	case t.offer >= 0:
+		hint = "Esc: withdraw offer"
+	}
+	g.drawTradeLine(screen, hint, 20, screenHeight-65, false)
+}
+
+// drawTradeAnimation draws the two creatures passing each other
+func (g *Game) drawTradeAnimation(screen *ebiten.Image) {
+	t := &g.trade
+	progress := float32(t.frames) / tradeAnimFrames
+	mine := g.creatures[t.offer]
+
+	// Each creature crosses the screen the other way, bobbing as it goes
+	bob := float32((t.frames/8)%2) * 3
+	x1 := 40 + progress*(screenWidth-80)
+	x2 := screenWidth - 40 - progress*(screenWidth-80)
+	vector.DrawFilledCircle(screen, x1, 100+bob, 14, mine.color, true)
+	vector.DrawFilledCircle(screen, x2, 140-bob, 14, t.theirOffer.color, true)
+
+	g.drawTradeLine(screen, "Trading "+mine.name+" for "+t.theirOffer.name+"...", 20, screenHeight-50, false)
+}
+
+// textCursor returns the blinking cursor drawn after text being typed
+func (g *Game) textCursor() string {
+	if g.ticks/20%2 == 0 {
+		return "_"
+	}
+	return ""
+}
+
+// drawTradeLine draws one line of trade screen text, highlighted if selected
+func (g *Game) drawTradeLine(screen *ebiten.Image, line string, x, y int, selected bool) {
+	op := &text.DrawOptions{}
+	op.GeoM.Translate(float64(x), float64(y))
+	if selected {
+		op.ColorScale.ScaleWithColor(g.uiText(color.RGBA{255, 255, 0, 255}))
+
+		selectorOp := &text.DrawOptions{}
+		selectorOp.GeoM.Translate(float64(x-10), float64(y))
+		selectorOp.ColorScale.ScaleWithColor(g.uiText(color.RGBA{255, 255, 0, 255}))
+		text.Draw(screen, ">", g.fontFace, selectorOp)
+	} else {
+		op.ColorScale.ScaleWithColor(g.uiText(color.White))
+	}
+	text.Draw(screen, line, g.fontFace, op)
+}
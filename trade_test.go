@@ -0,0 +1,23 @@
+package main
+
+import "testing"
+
+func TestTradeConfirmPair(t *testing.T) {
+	g := newTestGame(t)
+	theirs := newCreature(g.creatures[0].name, 5)
+	g.trade = TradeSession{offer: 0, theirOffer: &theirs, incoming: make(chan tradeMessage, 1), closed: make(chan error)}
+	mine := g.creatures[0].id
+
+	// A confirm of some other pair, as sent before an offer changed
+	g.trade.incoming <- tradeMessage{Type: tradeConfirm, Pair: [2]int{theirs.id, mine + 1}}
+	g.receiveTrade()
+	if g.trade.theirOK {
+		t.Fatal("a confirm of another pair counted")
+	}
+
+	g.trade.incoming <- tradeMessage{Type: tradeConfirm, Pair: [2]int{theirs.id, mine}}
+	g.receiveTrade()
+	if !g.trade.theirOK {
+		t.Fatal("a confirm of the offered pair didn't count")
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"image/color"
+	"strconv"
+)
+
+// Trainer is another trainer the player can battle, who sends out their
+// creatures one after another
+type Trainer struct {
+	name    string
+	clothes color.RGBA
+	team    []Creature
+	// Said on losing, money handed over and story flag set, if any
+	defeatText string
+	reward     int
+	flag       string
+}
+
+// startTrainerBattle starts a battle against another trainer's team. The
+// trainer is drawn in their clothes color, and can't be run from.
+func (g *Game) startTrainerBattle(trainer Trainer) {
+	g.setUpBattle(trainer.team[0])
+	g.battle.trainer = trainer.name
+	g.battle.trainerSprite = newPersonSprite(trainer.clothes)
+	g.battle.opponent, g.battle.nextEnemy = trainer, 1
+	g.battle.announcement = "Trainer " + trainer.name + " wants to battle!"
+}
+
+// sendNextEnemy has the trainer send out their next creature once one has
+// fainted, reporting false once they have none left
+func (g *Game) sendNextEnemy() bool {
+	b := &g.battle
+	if b.trainer == "" || b.nextEnemy >= len(b.opponent.team) {
+		return false
+	}
+
+	b.enemyCreature = b.opponent.team[b.nextEnemy]
+	b.leaveField(false)
+	b.nextEnemy++
+	b.enemyBar = HPBar{}
+	b.victory = Victory{}
+	b.battleText = b.trainer + " sent out " + b.enemyCreature.name + "!"
+	b.battleTextTimer = 60
+	b.currentTurn = 0
+	g.audio.playSound("ball")
+	g.audio.playCry(b.enemyCreature.name)
+	g.events.publish(Event{kind: EventEncounter, species: b.enemyCreature.name})
+	return true
+}
+
+// winTrainerBattle pays out for beating a trainer's whole team and sets the
+// story flag for it
+func (g *Game) winTrainerBattle() {
+	t := &g.battle.opponent
+	g.money += t.reward
+	if t.flag != "" {
+		g.flags[t.flag] = true
+	}
+	g.showDialogue(t.name + ": " + t.defeatText + " You got $" + strconv.Itoa(t.reward) + " for winning!")
+}
@@ -0,0 +1,32 @@
+package main
+
+import "creaturegame-2/engine"
+
+// typeEffectiveness returns the damage multiplier of a move type against a
+// creature type
+func typeEffectiveness(moveType, defenderType string) float32 {
+	return engine.Effectiveness(moveType, defenderType)
+}
+
+// typeNames returns a creature's type, or both its types for fused creatures
+// that have two
+func typeNames(type1, type2 string) string {
+	if type2 == "" {
+		return type1
+	}
+	return type1 + "/" + type2
+}
+
+// effectivenessText returns the popup shown for a hit's type effectiveness,
+// or nothing for a normal hit
+func effectivenessText(effectiveness float32) string {
+	switch {
+	case effectiveness == 0:
+		return "No effect..."
+	case effectiveness > 1:
+		return "Super effective!"
+	case effectiveness < 1:
+		return "Not very effective..."
+	}
+	return ""
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"image/color"
+	"math/rand"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Water traversal constants
+const (
+	// Tries at running a current through a chunk's water, and how long one
+	// runs at the shortest and longest
+	currentTries     = 6
+	currentMinLength = 3
+	currentMaxLength = 8
+	// Tries at finding a gap in a chunk's water for a platform to ferry
+	// across, and the widest gap one crosses
+	platformTries    = 6
+	platformMaxWidth = 6
+	// Frames a platform waits before drifting each tile, and at each end
+	platformStepFrames  = 20
+	platformPauseFrames = 90
+)
+
+// Platform is a log floating back and forth across a gap in the water on a
+// timed loop, carrying whoever stands on it
+type Platform struct {
+	// Water tile at one end, the way to the other end, and how many tiles
+	// apart the ends are
+	start  Point
+	dx, dy int
+	length int
+	// Tiles from the start it's at, whether it's heading back there, and
+	// frames until it next moves
+	pos  int
+	back bool
+	wait int
+	// Where it's drawn, catching up with its tile
+	visualX, visualY float32
+}
+
+// tile returns the tile a platform is on
+func (p *Platform) tile() Point {
+	return Point{p.start.x + p.dx*p.pos, p.start.y + p.dy*p.pos}
+}
+
+// isCurrent reports whether a tile is water with a current through it
+func isCurrent(tile int) bool {
+	return tile >= TileCurrentUp && tile <= TileCurrentRight
+}
+
+// IsWater reports whether the tile at x, y is open water that can be surfed
+// on, currents included, with no bridge over it or object in it
+func (m *Map) IsWater(x, y int) bool {
+	tile := m.Tile(LayerBase, x, y)
+	if tile != TileWater && !isCurrent(tile) || m.IsBridge(x, y) {
+		return false
+	}
+	_, ok := m.objects[Point{x, y}]
+	return !ok
+}
+
+// platformAt returns the platform on the tile at x, y, or nil
+func (m *Map) platformAt(x, y int) *Platform {
+	c, _, _ := m.chunkAt(x, y)
+	if c == nil {
+		return nil
+	}
+	for _, p := range c.platforms {
+		if p.tile() == (Point{x, y}) {
+			return p
+		}
+	}
+	return nil
+}
+
+// surfer returns the first party creature able to carry the player across
+// water, which any Water type can, or nil if none can
+func (g *Game) surfer() *Creature {
+	for i := range g.creatures {
+		c := &g.creatures[i]
+		if !c.egg && (c.type1 == "Water" || c.type2 == "Water") {
+			return c
+		}
+	}
+	return nil
+}
+
+// offerSurf asks to surf out onto the water the player is facing
+func (g *Game) offerSurf() {
+	c := g.surfer()
+	if c == nil {
+		g.showDialogue("The water is deep. A Water-type creature could carry you across it.")
+		return
+	}
+	g.showPrompt("The water is deep. Surf across it on "+c.name+"?", func() {
+		dx, dy := directionDelta(g.player.direction)
+		g.player.surfing = true
+		g.player.tileX += dx
+		g.player.tileY += dy
+		g.follow(g.player.tileX-dx, g.player.tileY-dy)
+		g.player.movementState = MovementMoving
+	})
+}
+
+// updateSurfing gets the player off the water once they reach land
+func (g *Game) updateSurfing() {
+	x, y := g.player.tileX, g.player.tileY
+	if g.player.surfing && !g.worldMap.IsWater(x, y) {
+		g.player.surfing = false
+	}
+}
+
+// drift lets the current under a surfing player carry them a tile the way it
+// flows, reporting whether it did
+func (g *Game) drift() bool {
+	tile := g.worldMap.Tile(LayerBase, g.player.tileX, g.player.tileY)
+	if !g.player.surfing || !isCurrent(tile) {
+		return false
+	}
+	direction := tile - TileCurrentUp
+	if !g.canStep(direction) {
+		return false
+	}
+	g.carryPlayer(directionDelta(direction))
+	return true
+}
+
+// updatePlatforms moves the platforms in the loaded chunks along their
+// loops, carrying the player on any they stand on. Platforms hold still while
+// the player is mid-step, so one is never stepped onto as it leaves.
+func (g *Game) updatePlatforms() {
+	idle := g.player.movementState == MovementIdle
+	for _, c := range g.worldMap.chunks {
+		for _, p := range c.platforms {
+			tile := p.tile()
+			p.visualX = approach(p.visualX, float32(tile.x*tileSize), g.movementSpeed())
+			p.visualY = approach(p.visualY, float32(tile.y*tileSize), g.movementSpeed())
+			if !idle {
+				continue
+			}
+			if p.wait > 0 {
+				p.wait--
+				continue
+			}
+
+			step := 1
+			if p.back {
+				step = -1
+			}
+			p.pos += step
+			p.wait = platformStepFrames
+			if p.pos == 0 || p.pos == p.length {
+				p.back = !p.back
+				p.wait = platformPauseFrames
+			}
+			if tile == (Point{g.player.tileX, g.player.tileY}) {
+				g.carryPlayer(p.dx*step, p.dy*step)
+			}
+		}
+	}
+}
+
+// placeWaterFeatures runs currents through some of a chunk's water and
+// floats platforms across gaps in it. It has a random source of its own, so
+// the rest of the chunk comes out as it did before there were either.
+func (m *Map) placeWaterFeatures(c *Chunk, cx, cy int) {
+	rng := rand.New(rand.NewSource(m.seed ^ int64(cx)*83492791 ^ int64(cy)*2654435761))
+	open := func(x, y int) bool {
+		return x >= 0 && y >= 0 && x < chunkSize && y < chunkSize &&
+			c.tiles[LayerBase][y][x] == TileWater && !c.bridgeTiles.get(y*chunkSize+x)
+	}
+	land := func(x, y int) bool {
+		return x >= 0 && y >= 0 && x < chunkSize && y < chunkSize &&
+			c.tiles[LayerBase][y][x] != TileWater && !c.collisionMap.get(y*chunkSize+x)
+	}
+
+	for range currentTries {
+		x, y, direction := rng.Intn(chunkSize), rng.Intn(chunkSize), rng.Intn(4)
+		dx, dy := directionDelta(direction)
+		var run []Point
+		for len(run) < currentMaxLength && open(x, y) {
+			run = append(run, Point{x, y})
+			x, y = x+dx, y+dy
+		}
+		if len(run) < currentMinLength {
+			continue
+		}
+		for _, p := range run {
+			c.tiles[LayerBase][p.y][p.x] = TileCurrentUp + direction
+		}
+		break
+	}
+
+	// A gap is a straight stretch of water with land at both ends
+	for range platformTries {
+		x, y, direction := rng.Intn(chunkSize), rng.Intn(chunkSize), rng.Intn(4)
+		dx, dy := directionDelta(direction)
+		if !land(x-dx, y-dy) {
+			continue
+		}
+		width := 0
+		for width <= platformMaxWidth && open(x+dx*width, y+dy*width) {
+			width++
+		}
+		if width < 2 || width > platformMaxWidth || !land(x+dx*width, y+dy*width) {
+			continue
+		}
+
+		start := Point{cx*chunkSize + x, cy*chunkSize + y}
+		c.platforms = append(c.platforms, &Platform{
+			start: start, dx: dx, dy: dy, length: width - 1,
+			visualX: float32(start.x * tileSize), visualY: float32(start.y * tileSize),
+		})
+		break
+	}
+}
+
+// drawCurrents draws streaks flowing along a chunk's currents
+func (g *Game) drawCurrents(screen *ebiten.Image, c *Chunk, left, top float32) {
+	for _, p := range c.cache.currents {
+		dx, dy := directionDelta(c.tiles[LayerBase][p.y][p.x] - TileCurrentUp)
+		for i := range 2 {
+			// Each streak flows a tile, then starts over at the tile's far side
+			along := float32((g.ticks+i*tileSize/2)%tileSize) - tileSize/2
+			across := float32(i*12 - 6)
+			x := left + float32(p.x*tileSize) + tileSize/2 + float32(dx)*along + float32(dy)*across
+			y := top + float32(p.y*tileSize) + tileSize/2 + float32(dy)*along + float32(dx)*across
+			vector.StrokeLine(screen, x, y, x+float32(dx)*6, y+float32(dy)*6, 1, color.RGBA{230, 245, 255, 170}, true)
+		}
+	}
+}
+
+// drawPlatforms draws the platforms in the loaded chunks as rafts of logs
+func (g *Game) drawPlatforms(screen *ebiten.Image) {
+	for _, c := range g.worldMap.chunks {
+		for _, p := range c.platforms {
+			x, y := p.visualX-g.camera.x, p.visualY-g.camera.y
+			if x < -tileSize || y < -tileSize || x > screenWidth || y > screenHeight {
+				continue
+			}
+			for i := range 3 {
+				offset := float32(3 + i*9)
+				vector.DrawFilledRect(screen, x+2, y+offset, tileSize-4, 8, color.RGBA{120, 80, 45, 255}, true)
+				vector.StrokeLine(screen, x+4, y+offset+4, x+tileSize-6, y+offset+4, 1, color.RGBA{90, 60, 35, 255}, true)
+			}
+		}
+	}
+}
+
+// drawSurfMount draws the creature the player is surfing on beneath them
+func (g *Game) drawSurfMount(screen *ebiten.Image) {
+	if !g.player.surfing || g.worldMap.platformAt(g.player.tileX, g.player.tileY) != nil {
+		return
+	}
+	clr := color.RGBA{0, 100, 255, 255}
+	if c := g.surfer(); c != nil {
+		clr = c.color
+	}
+	x, y := g.player.visualX-g.camera.x+tileSize/2, g.player.visualY-g.camera.y+tileSize-8
+	vector.DrawFilledCircle(screen, x, y, 13, clr, true)
+	vector.StrokeCircle(screen, x, y, 13, 1.5, color.RGBA{230, 245, 255, 200}, true)
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Weather traversal constants
+const (
+	// Pixels the player moves a frame, and in snow
+	walkSpeed     = 4.0
+	snowWalkSpeed = 2.0
+	// Paths lower than this elevation flood while it rains, a little above
+	// where the beaches end
+	floodLevel = -0.07
+	// How far around the player, in tiles, paths flood
+	floodRadius = 12
+	// Size of the sandstorm haze image, which is drawn scaled up to twice the
+	// screen so it covers it wherever the player stands
+	hazeWidth  = 64
+	hazeHeight = 48
+	hazeScale  = 2 * screenWidth / hazeWidth
+	// Distance from the player, in pixels, the haze starts, and over which
+	// it thickens until nothing can be seen through it
+	hazeClear = 2.5 * tileSize
+	hazeFade  = 2 * tileSize
+)
+
+// movementSpeed returns how many pixels the player moves a frame, slowed by
+// snow outdoors and sped up by the bike on paths and bridges
+func (g *Game) movementSpeed() float32 {
+	speed := float32(walkSpeed)
+	if g.weather == WeatherSnow && !g.worldMap.static {
+		speed = snowWalkSpeed
+	}
+	if g.player.riding && g.onRideableGround() {
+		speed *= bikeSpeedFactor
+	}
+	return speed
+}
+
+// updateFlooding floods the low paths around the player while it rains, and
+// drains them all once it stops. Flooded paths are laid over the map as
+// overrides, so draining them brings back the paths underneath.
+func (g *Game) updateFlooding() {
+	m := g.maps[overworldID]
+	if g.weather != WeatherRain {
+		m.clearOverrides()
+		return
+	}
+	if g.worldMap != m {
+		return
+	}
+
+	px, py := g.player.tileX, g.player.tileY
+	for y := max(py-floodRadius, 0); y <= min(py+floodRadius, m.height-1); y++ {
+		for x := max(px-floodRadius, 0); x <= min(px+floodRadius, m.width-1); x++ {
+			// The player's own tile stays dry, so they're never left
+			// standing in water
+			if x == px && y == py || m.Tile(LayerBase, x, y) != TilePath {
+				continue
+			}
+			scale := m.config.elevationScale
+			if m.elevation.Fractal(float64(x)/scale, float64(y)/scale, 4) < floodLevel {
+				m.setOverride(Point{x, y}, TileWater)
+			}
+		}
+	}
+}
+
+// setOverride lays a tile over the base map at a point, redrawing its chunk
+func (m *Map) setOverride(p Point, tile int) {
+	m.overrides[p] = tile
+	m.redrawTile(p)
+}
+
+// clearOverrides lifts every tile laid over the base map
+func (m *Map) clearOverrides() {
+	for p := range m.overrides {
+		delete(m.overrides, p)
+		m.redrawTile(p)
+	}
+}
+
+// redrawTile drops the drawn tiles of the chunk holding a point, if it's
+// loaded, so a change to the tile shows
+func (m *Map) redrawTile(p Point) {
+	if c, ok := m.chunks[Point{p.x / chunkSize, p.y / chunkSize}]; ok {
+		c.invalidate()
+	}
+}
+
+// sandstormHaze returns the haze a sandstorm hides everything beyond a little
+// way around the player in: clear in the middle, thickening outwards
+func sandstormHaze() *ebiten.Image {
+	atlas := spriteAtlas()
+	if haze, ok := atlas.sprites["sandstorm-haze"]; ok {
+		return haze
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, hazeWidth, hazeHeight))
+	for y := range hazeHeight {
+		for x := range hazeWidth {
+			distance := math.Hypot(float64(x-hazeWidth/2)+0.5, float64(y-hazeHeight/2)+0.5) * hazeScale
+			thickness := math.Min(math.Max((distance-hazeClear)/hazeFade, 0), 1)
+			img.Set(x, y, color.NRGBA{190, 150, 90, uint8(235 * thickness)})
+		}
+	}
+	atlas.add("sandstorm-haze", img)
+	return atlas.sprites["sandstorm-haze"]
+}
+
+// drawSandstormHaze draws the sandstorm's haze centered on the player
+func (g *Game) drawSandstormHaze(screen *ebiten.Image) {
+	op := &ebiten.DrawImageOptions{Filter: ebiten.FilterLinear}
+	op.GeoM.Scale(hazeScale, hazeScale)
+	op.GeoM.Translate(
+		float64(g.player.visualX-g.camera.x+tileSize/2)-hazeWidth*hazeScale/2,
+		float64(g.player.visualY-g.camera.y+tileSize/2)-hazeHeight*hazeScale/2,
+	)
+	screen.DrawImage(sandstormHaze(), op)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Weather constants
+const (
+	WeatherClear = iota
+	WeatherRain
+	WeatherSnow
+	WeatherSandstorm
+	WeatherFog
+)
+
+// weatherNames names each weather, for describing when creatures appear
+var weatherNames = map[int]string{
+	WeatherClear:     "clear weather",
+	WeatherRain:      "rain",
+	WeatherSnow:      "snow",
+	WeatherSandstorm: "sandstorms",
+	WeatherFog:       "fog",
+}
+
+// updateBiome checks whether the player has walked into a different biome and
+// rolls new weather for it if so
+func (g *Game) updateBiome() {
+	// Interiors keep whatever weather is outside
+	if g.worldMap.static {
+		return
+	}
+
+	biome := g.worldMap.BiomeAt(g.player.tileX, g.player.tileY)
+	if biome == g.currentBiome {
+		return
+	}
+
+	g.currentBiome = biome
+	g.weather = biomes[biome].rollWeather()
+}
+
+// drawWeather draws the current weather over the overworld
+func (g *Game) drawWeather(screen *ebiten.Image) {
+	switch g.weather {
+	case WeatherRain:
+		// Diagonal streaks that fall quickly
+		for i := range 60 {
+			x := float32((i*53 + g.ticks*3) % screenWidth)
+			y := float32((i*97 + g.ticks*9) % screenHeight)
+			vector.StrokeLine(screen, x, y, x-3, y+8, 1, color.RGBA{170, 190, 255, 160}, true)
+		}
+	case WeatherSnow:
+		// Flakes drifting slowly downwards
+		for i := range 50 {
+			x := float32((i*71 + g.ticks/2 + (i%5)*g.ticks/8) % screenWidth)
+			y := float32((i*37 + g.ticks) % screenHeight)
+			vector.DrawFilledCircle(screen, x, y, 1.5, color.RGBA{255, 255, 255, 220}, true)
+		}
+	case WeatherSandstorm:
+		// A sandy tint that hides all but what's close by, with fast
+		// horizontal grit
+		vector.DrawFilledRect(screen, 0, 0, screenWidth, screenHeight, color.RGBA{200, 160, 90, 70}, true)
+		g.drawSandstormHaze(screen)
+		for i := range 80 {
+			x := float32((i*89 + g.ticks*7) % screenWidth)
+			y := float32((i*43 + g.ticks/3) % screenHeight)
+			vector.StrokeLine(screen, x, y, x+6, y+1, 1, color.RGBA{230, 200, 140, 150}, true)
+		}
+	case WeatherFog:
+		vector.DrawFilledRect(screen, 0, 0, screenWidth, screenHeight, color.RGBA{220, 220, 220, 90}, true)
+	}
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"math/rand"
+	"strconv"
+)
+
+// World layout constants
+const (
+	// Size of the overworld in tiles
+	worldWidth  = 512
+	worldHeight = 512
+	// Regions are laid out on a jittered grid of this many cells per side
+	regionGridSize = 3
+	// Size of the cleared town site at the center of each region
+	townWidth  = 16
+	townHeight = 12
+	// Width of the path carved along each route
+	routeWidth = 2
+)
+
+// regionTemplates are the named regions the generator picks from, each with
+// its own mix of water and mountains
+var regionTemplates = []struct {
+	name             string
+	waterDensity     float32
+	mountainClusters int
+}{
+	{"Meadowbrook", 0.25, 1},
+	{"Azure Lakes", 0.45, 1},
+	{"Granite Ridge", 0.15, 5},
+	{"Willow Marsh", 0.40, 2},
+	{"Sunset Plains", 0.20, 2},
+	{"Frostpeak", 0.20, 4},
+	{"Coral Coast", 0.50, 1},
+	{"Emberfield", 0.10, 3},
+	{"Mistvale", 0.30, 3},
+}
+
+// Region is a named area of the overworld with its own terrain mix and a town
+type Region struct {
+	name string
+	// Center of the region, used to decide which region a tile belongs to
+	center Point
+	// Center of the guaranteed town site
+	town Point
+	// Share of tiles seeded as water before the cellular automata pass
+	waterDensity float32
+	// Upper bound on mountain clusters per chunk
+	mountainClusters int
+	// Buildings in the region's town
+	buildings []Building
+}
+
+// Route is a path connecting two towns, made of axis-aligned segments
+type Route struct {
+	from, to int // Region indices
+	points   []Point
+}
+
+// WorldPlan is the large-scale layout of the overworld, decided up front so
+// that chunks can be generated independently and still line up
+type WorldPlan struct {
+	regions []Region
+	routes  []Route
+	// Signs in towns and along routes
+	signs []Sign
+}
+
+// newWorldPlan lays out regions, towns and the route graph for a world
+func newWorldPlan(seed int64, width, height int) *WorldPlan {
+	rng := rand.New(rand.NewSource(seed))
+	plan := &WorldPlan{}
+
+	// Shuffle the templates so every world gets a different arrangement
+	order := rng.Perm(len(regionTemplates))
+
+	// Place one region per grid cell, jittered so the borders aren't straight
+	cellW := width / regionGridSize
+	cellH := height / regionGridSize
+	for gy := range regionGridSize {
+		for gx := range regionGridSize {
+			template := regionTemplates[order[len(plan.regions)%len(order)]]
+
+			center := Point{
+				gx*cellW + cellW/4 + rng.Intn(cellW/2),
+				gy*cellH + cellH/4 + rng.Intn(cellH/2),
+			}
+
+			plan.regions = append(plan.regions, Region{
+				name:             template.name,
+				center:           center,
+				town:             center,
+				waterDensity:     template.waterDensity,
+				mountainClusters: template.mountainClusters,
+			})
+		}
+	}
+
+	// Lay out each town's buildings
+	for i := range plan.regions {
+		region := &plan.regions[i]
+		region.buildings = townBuildings(i, region)
+		plan.signs = append(plan.signs, townSigns(region)...)
+	}
+
+	plan.connectTowns(rng)
+
+	// Name each route on signs at either end
+	for i, route := range plan.routes {
+		plan.signs = append(plan.signs, plan.routeSigns(rng, i+1, route)...)
+	}
+
+	return plan
+}
+
+// connectTowns builds a minimum spanning tree over the towns so every town
+// is reachable, then turns each edge into a route
+func (p *WorldPlan) connectTowns(rng *rand.Rand) {
+	connected := make([]bool, len(p.regions))
+	connected[0] = true
+
+	// Prim's algorithm using Manhattan distance between towns
+	for range len(p.regions) - 1 {
+		bestFrom, bestTo, bestDist := -1, -1, 0
+		for from := range p.regions {
+			if !connected[from] {
+				continue
+			}
+			for to := range p.regions {
+				if connected[to] {
+					continue
+				}
+				a, b := p.regions[from].town, p.regions[to].town
+				dist := abs(a.x-b.x) + abs(a.y-b.y)
+				if bestTo < 0 || dist < bestDist {
+					bestFrom, bestTo, bestDist = from, to, dist
+				}
+			}
+		}
+
+		connected[bestTo] = true
+		p.routes = append(p.routes, newRoute(rng, bestFrom, bestTo, p.regions[bestFrom].town, p.regions[bestTo].town))
+	}
+}
+
+// newRoute creates a route between two towns that bends at a random column
+func newRoute(rng *rand.Rand, from, to int, a, b Point) Route {
+	lo, hi := min(a.x, b.x), max(a.x, b.x)
+	bendX := lo
+	if hi > lo {
+		bendX = lo + rng.Intn(hi-lo+1)
+	}
+
+	return Route{
+		from: from,
+		to:   to,
+		points: []Point{
+			a,
+			{bendX, a.y},
+			{bendX, b.y},
+			b,
+		},
+	}
+}
+
+// regionAt returns the region whose center is closest to tile x, y
+func (p *WorldPlan) regionAt(x, y int) *Region {
+	best := 0
+	bestDist := -1
+	for i, region := range p.regions {
+		dx, dy := region.center.x-x, region.center.y-y
+		dist := dx*dx + dy*dy
+		if bestDist < 0 || dist < bestDist {
+			best, bestDist = i, dist
+		}
+	}
+	return &p.regions[best]
+}
+
+// areaAt names the area tile x, y is in: the town there, the route it's on,
+// or failing those the region around it
+func (p *WorldPlan) areaAt(x, y int) string {
+	for _, region := range p.regions {
+		if abs(x-region.town.x) <= townWidth/2 && abs(y-region.town.y) <= townHeight/2 {
+			return region.name
+		}
+	}
+	for i, route := range p.routes {
+		for j := range len(route.points) - 1 {
+			a, b := route.points[j], route.points[j+1]
+			if x >= min(a.x, b.x) && x <= max(a.x, b.x)+routeWidth-1 &&
+				y >= min(a.y, b.y) && y <= max(a.y, b.y)+routeWidth-1 {
+				return routeName(i + 1)
+			}
+		}
+	}
+	return p.regionAt(x, y).name
+}
+
+// routeName names a route by its number
+func routeName(number int) string {
+	return "Route " + strconv.Itoa(number)
+}
+
+// carveChunk clears town sites and paves routes that overlap a chunk whose
+// top-left tile is originX, originY
+func (p *WorldPlan) carveChunk(c *Chunk, originX, originY int) {
+	// Town sites are flat, open squares
+	for _, region := range p.regions {
+		minX := region.town.x - townWidth/2
+		minY := region.town.y - townHeight/2
+		c.paveRect(minX-originX, minY-originY, minX+townWidth-1-originX, minY+townHeight-1-originY)
+	}
+
+	// Routes are paved along each segment, bridging any water they cross
+	for _, route := range p.routes {
+		for i := range len(route.points) - 1 {
+			a, b := route.points[i], route.points[i+1]
+			c.paveRect(
+				min(a.x, b.x)-originX, min(a.y, b.y)-originY,
+				max(a.x, b.x)+routeWidth-1-originX, max(a.y, b.y)+routeWidth-1-originY,
+			)
+		}
+	}
+
+	// Buildings and signs go on top of the paved town
+	for _, region := range p.regions {
+		for _, building := range region.buildings {
+			c.stampBuilding(building, originX, originY)
+		}
+	}
+	for _, sign := range p.signs {
+		c.stamp(sign.pos.x-originX, sign.pos.y-originY, TileSign, true)
+	}
+	for _, plot := range p.gardenPlots() {
+		c.stamp(plot.x-originX, plot.y-originY, TileSoil, true)
+	}
+}
+
+// paveRect paves the chunk-local rectangle from minX, minY to maxX, maxY
+// inclusive, clipped to the chunk
+func (c *Chunk) paveRect(minX, minY, maxX, maxY int) {
+	for y := max(minY, 0); y <= min(maxY, chunkSize-1); y++ {
+		for x := max(minX, 0); x <= min(maxX, chunkSize-1); x++ {
+			c.pave(x, y)
+		}
+	}
+}
+
+// pave turns the chunk-local tile x, y into walkable path, or a bridge if it
+// is water
+func (c *Chunk) pave(x, y int) {
+	if c.tiles[LayerBase][y][x] == TileWater {
+		c.tiles[LayerOverlay][y][x] = TileBridge
+		c.setBridge(x, y, true)
+	} else {
+		c.tiles[LayerBase][y][x] = TilePath
+	}
+	c.setCollision(x, y, false)
+	c.setGrass(x, y, false)
+}